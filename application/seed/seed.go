@@ -0,0 +1,63 @@
+// Package seed loads a small fixture of users, follows and tweets so a fresh
+// local instance has data to try the API with.
+package seed
+
+import (
+	"fmt"
+
+	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Usernames of the fixture users, in creation order
+var Usernames = []string{"alice", "bob", "carol", "dave"}
+
+// Follows of the fixture, as follower username to followed usernames
+var Follows = map[string][]string{
+	"alice": {"bob", "carol"},
+	"bob":   {"alice"},
+	"carol": {"alice", "bob", "dave"},
+	"dave":  {"carol"},
+}
+
+// Tweets of the fixture, as author username to tweet contents in posting order
+var Tweets = map[string][]string{
+	"alice": {"Hola a todos, ¡primer tweet!", "Probando la plataforma en local"},
+	"bob":   {"Buenos días desde Buenos Aires ☀️"},
+	"carol": {"Leyendo sobre arquitectura limpia", "Go 1.23 ya está disponible"},
+	"dave":  {"Recién llegado, ¿a quién sigo?"},
+}
+
+// Creates the fixture users, follows and tweets through the use cases
+// Returns the created users by username, as they were before following anyone
+func Load(userUseCase *usecase.UserUseCase, tweetUseCase *usecase.TweetUseCase) (map[string]*entity.User, error) {
+	// Create users
+	users := make(map[string]*entity.User, len(Usernames))
+	for _, username := range Usernames {
+		user, err := userUseCase.CreateUser(username)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create user %s: %w", username, err)
+		}
+		users[username] = user
+	}
+
+	// Follow users in a fixed order
+	for _, follower := range Usernames {
+		for _, followed := range Follows[follower] {
+			if err := userUseCase.FollowUser(users[follower].ID, users[followed].ID); err != nil {
+				return nil, fmt.Errorf("failed to make %s follow %s: %w", follower, followed, err)
+			}
+		}
+	}
+
+	// Post tweets
+	for _, author := range Usernames {
+		for _, content := range Tweets[author] {
+			if _, err := tweetUseCase.CreateTweet(users[author].ID, content); err != nil {
+				return nil, fmt.Errorf("failed to post tweet for %s: %w", author, err)
+			}
+		}
+	}
+
+	return users, nil
+}
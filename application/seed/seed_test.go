@@ -0,0 +1,51 @@
+package seed_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/develpudu/go-challenge/application/seed"
+	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/repository"
+	"github.com/develpudu/go-challenge/infrastructure/repository/memory"
+)
+
+func TestLoad(t *testing.T) {
+	// Arrange
+	userRepo := memory.NewUserRepository()
+	tweetRepo := memory.NewTweetRepository(userRepo)
+	userUseCase := usecase.NewUserUseCase(userRepo, nil)
+	tweetUseCase := usecase.NewTweetUseCase(tweetRepo, userRepo)
+
+	// Act
+	users, err := seed.Load(userUseCase, tweetUseCase)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	all, _ := userUseCase.GetAllUsers()
+	if len(all) != len(seed.Usernames) {
+		t.Fatalf("Expected %d users, got %d", len(seed.Usernames), len(all))
+	}
+	for follower, followed := range seed.Follows {
+		user, _ := userUseCase.GetUser(users[follower].ID)
+		for _, username := range followed {
+			if !user.IsFollowing(users[username].ID) {
+				t.Errorf("Expected %s to follow %s", follower, username)
+			}
+		}
+	}
+	for author, contents := range seed.Tweets {
+		tweets, err := tweetUseCase.GetTweetsByUser(users[author].ID)
+		if err != nil || len(tweets) != len(contents) {
+			t.Errorf("Expected %d tweets by %s, got %d and %v", len(contents), author, len(tweets), err)
+		}
+	}
+
+	// The fixture fills the timelines of followers
+	timeline, err := tweetUseCase.GetTimeline(context.Background(), users["dave"].ID, repository.TimelineOptions{})
+	if err != nil || len(timeline) != len(seed.Tweets["carol"]) {
+		t.Errorf("Expected carol's tweets in dave's timeline, got %d and %v", len(timeline), err)
+	}
+}
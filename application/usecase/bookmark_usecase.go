@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+)
+
+// Implements the bookmark use cases
+// Bookmarks are private: every operation is scoped to the requesting user
+type BookmarkUseCase struct {
+	bookmarkRepository repository.BookmarkRepository
+	tweetRepository    repository.TweetRepository
+	userRepository     repository.UserRepository
+	clock              Clock
+}
+
+// Creates a new bookmark use case
+func NewBookmarkUseCase(
+	bookmarkRepository repository.BookmarkRepository,
+	tweetRepository repository.TweetRepository,
+	userRepository repository.UserRepository,
+	opts ...Option,
+) *BookmarkUseCase {
+	o := newOptions(opts)
+	return &BookmarkUseCase{
+		bookmarkRepository: bookmarkRepository,
+		tweetRepository:    tweetRepository,
+		userRepository:     userRepository,
+		clock:              o.clock,
+	}
+}
+
+// Bookmarks a tweet for a user
+// Bookmarking an already bookmarked tweet succeeds and keeps the original bookmark time
+func (uc *BookmarkUseCase) AddBookmark(userID, tweetID string) error {
+	if err := uc.checkUserExists(userID); err != nil {
+		return err
+	}
+
+	// Check if tweet exists
+	tweet, err := uc.tweetRepository.FindByID(tweetID)
+	if err != nil {
+		return err
+	}
+	if tweet == nil || tweet.IsExpired(uc.clock.Now()) {
+		return entity.ErrTweetNotFound
+	}
+
+	return uc.bookmarkRepository.Save(entity.NewBookmark(userID, tweetID, uc.clock.Now()))
+}
+
+// Removes a user's bookmark of a tweet
+// Removing a tweet that is not bookmarked succeeds
+func (uc *BookmarkUseCase) RemoveBookmark(userID, tweetID string) error {
+	if err := uc.checkUserExists(userID); err != nil {
+		return err
+	}
+
+	return uc.bookmarkRepository.Delete(userID, tweetID)
+}
+
+// Retrieves the tweets bookmarked by a user, most recently bookmarked first
+// Bookmarked tweets that no longer exist or have expired are skipped
+func (uc *BookmarkUseCase) ListBookmarks(userID string) ([]*entity.Tweet, error) {
+	if err := uc.checkUserExists(userID); err != nil {
+		return nil, err
+	}
+
+	bookmarks, err := uc.bookmarkRepository.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tweets := make([]*entity.Tweet, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		tweet, err := uc.tweetRepository.FindByID(bookmark.TweetID)
+		if err != nil {
+			return nil, err
+		}
+		if tweet != nil && !tweet.IsExpired(uc.clock.Now()) {
+			tweets = append(tweets, tweet)
+		}
+	}
+
+	return tweets, nil
+}
+
+// Returns ErrUserNotFound if the user does not exist
+func (uc *BookmarkUseCase) checkUserExists(userID string) error {
+	user, err := uc.userRepository.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return entity.ErrUserNotFound
+	}
+	return nil
+}
+
+// Retrieves the authors with the given IDs in a single batch, keyed by ID
+// Authors whose account was deleted are absent from the map
+func (uc *BookmarkUseCase) GetAuthors(userIDs []string) (map[string]*entity.User, error) {
+	return uc.userRepository.FindByIDs(userIDs)
+}
@@ -0,0 +1,124 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/infrastructure/repository/memory"
+)
+
+// Returns a bookmark use case with two users and three tweets, driven by the returned clock
+func setupBookmarkUseCase() (*usecase.BookmarkUseCase, *usecase.FakeClock) {
+	userRepo := NewMockUserRepository()
+	tweetRepo := NewMockTweetRepository()
+	clock := usecase.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	userRepo.Save(entity.NewUser("user1", "user1"))
+	userRepo.Save(entity.NewUser("user2", "user2"))
+	for _, id := range []string{"tweet1", "tweet2", "tweet3"} {
+		tweet, _ := entity.NewTweet(id, "user2", "Tweet "+id)
+		tweetRepo.Save(tweet)
+	}
+
+	useCase := usecase.NewBookmarkUseCase(memory.NewBookmarkRepository(), tweetRepo, userRepo, usecase.WithClock(clock))
+	return useCase, clock
+}
+
+func TestListBookmarksNewestFirst(t *testing.T) {
+	// Arrange
+	useCase, clock := setupBookmarkUseCase()
+	for _, id := range []string{"tweet2", "tweet1", "tweet3"} {
+		if err := useCase.AddBookmark("user1", id); err != nil {
+			t.Fatalf("Failed to bookmark %s: %v", id, err)
+		}
+		clock.Advance(time.Minute)
+	}
+
+	// Act
+	tweets, err := useCase.ListBookmarks("user1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []string{"tweet3", "tweet1", "tweet2"}
+	if len(tweets) != len(expected) {
+		t.Fatalf("Expected %d bookmarked tweets, got %d", len(expected), len(tweets))
+	}
+	for i, tweet := range tweets {
+		if tweet.ID != expected[i] {
+			t.Errorf("Expected tweet %d to be %s, got %s", i, expected[i], tweet.ID)
+		}
+	}
+}
+
+func TestAddBookmarkIsIdempotent(t *testing.T) {
+	// Arrange
+	useCase, clock := setupBookmarkUseCase()
+	useCase.AddBookmark("user1", "tweet1")
+	clock.Advance(time.Minute)
+	useCase.AddBookmark("user1", "tweet2")
+	clock.Advance(time.Minute)
+
+	// Act: bookmarking tweet1 again must not move it to the top
+	err := useCase.AddBookmark("user1", "tweet1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	tweets, _ := useCase.ListBookmarks("user1")
+	if len(tweets) != 2 || tweets[0].ID != "tweet2" {
+		t.Errorf("Expected the original bookmark to be kept, got %d tweets", len(tweets))
+	}
+}
+
+func TestRemoveBookmarkIsIdempotent(t *testing.T) {
+	// Arrange
+	useCase, _ := setupBookmarkUseCase()
+	useCase.AddBookmark("user1", "tweet1")
+
+	// Act
+	err1 := useCase.RemoveBookmark("user1", "tweet1")
+	err2 := useCase.RemoveBookmark("user1", "tweet1")
+
+	// Assert
+	if err1 != nil || err2 != nil {
+		t.Fatalf("Expected removals to succeed, got %v and %v", err1, err2)
+	}
+	if tweets, _ := useCase.ListBookmarks("user1"); len(tweets) != 0 {
+		t.Errorf("Expected no bookmarks, got %d", len(tweets))
+	}
+}
+
+func TestBookmarksArePrivate(t *testing.T) {
+	// Arrange
+	useCase, _ := setupBookmarkUseCase()
+	useCase.AddBookmark("user1", "tweet1")
+
+	// Act
+	tweets, err := useCase.ListBookmarks("user2")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tweets) != 0 {
+		t.Errorf("Expected user2 not to see user1's bookmarks, got %d", len(tweets))
+	}
+}
+
+func TestAddBookmarkTweetNotFound(t *testing.T) {
+	// Arrange
+	useCase, _ := setupBookmarkUseCase()
+
+	// Act
+	err := useCase.AddBookmark("user1", "nonexistent")
+
+	// Assert
+	if err != entity.ErrTweetNotFound {
+		t.Errorf("Expected ErrTweetNotFound, got %v", err)
+	}
+}
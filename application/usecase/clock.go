@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+)
+
+// Provides the current time to the use cases
+type Clock interface {
+	Now() time.Time
+}
+
+// Implements Clock using the system time
+type SystemClock struct{}
+
+// Returns the current system time
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Implements Clock with a manually controlled time, for deterministic tests
+type FakeClock struct {
+	now   time.Time
+	mutex sync.Mutex
+}
+
+// Creates a fake clock set to the given time
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Returns the fake clock's current time
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Sets the fake clock to the given time
+func (c *FakeClock) Set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = now
+}
+
+// Moves the fake clock forward by the given duration
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
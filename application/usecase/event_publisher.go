@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Publishes domain events to downstream systems (search indexers, notifications)
+type EventPublisher interface {
+	// Publishes an event for a newly created tweet
+	TweetCreated(ctx context.Context, tweet *entity.Tweet) error
+}
+
+// Implements EventPublisher by discarding every event
+type NoopEventPublisher struct{}
+
+// Discards the event
+func (NoopEventPublisher) TweetCreated(ctx context.Context, tweet *entity.Tweet) error {
+	return nil
+}
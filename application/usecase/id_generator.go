@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Generates unique IDs for new entities
+type IDGenerator interface {
+	NewID() string
+}
+
+// Implements IDGenerator using random UUIDs
+type UUIDGenerator struct{}
+
+// Returns a new random UUID
+func (UUIDGenerator) NewID() string {
+	return uuid.New().String()
+}
+
+// Implements IDGenerator with deterministic, sequential IDs, for tests
+type SequentialIDGenerator struct {
+	prefix string
+	next   int
+	mutex  sync.Mutex
+}
+
+// Creates a sequential ID generator producing prefix-1, prefix-2, ...
+func NewSequentialIDGenerator(prefix string) *SequentialIDGenerator {
+	return &SequentialIDGenerator{prefix: prefix}
+}
+
+// Returns the next ID in the sequence
+func (g *SequentialIDGenerator) NewID() string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.next++
+	return fmt.Sprintf("%s-%d", g.prefix, g.next)
+}
+
+// Crockford's base32 alphabet used by ULIDs
+const ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// Implements IDGenerator using ULIDs: a 48-bit millisecond timestamp followed by
+// 80 random bits, encoded as 26 characters. IDs sort lexicographically in creation
+// order, so they can be used directly as a sort key and as a pagination cursor.
+// IDs generated within the same millisecond stay ordered by incrementing the random part,
+// which is also how IDs stay unique when the entropy source fails.
+type ULIDGenerator struct {
+	clock   Clock
+	entropy io.Reader
+	lastMs  uint64
+	last    [10]byte
+	mutex   sync.Mutex
+}
+
+// Creates a ULID generator timestamping IDs with the given clock
+func NewULIDGenerator(clock Clock) *ULIDGenerator {
+	return &ULIDGenerator{
+		clock:   clock,
+		entropy: rand.Reader,
+	}
+}
+
+// Creates a ULID generator drawing the random part of IDs from entropy, for tests
+func NewULIDGeneratorWithEntropy(clock Clock, entropy io.Reader) *ULIDGenerator {
+	return &ULIDGenerator{
+		clock:   clock,
+		entropy: entropy,
+	}
+}
+
+// Returns a new ULID
+func (g *ULIDGenerator) NewID() string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	ms := uint64(g.clock.Now().UnixMilli())
+	if ms <= g.lastMs {
+		// Same millisecond (or a clock going backwards): keep monotonic order
+		ms = g.lastMs
+		incrementBytes(g.last[:])
+	} else {
+		var random [10]byte
+		if _, err := io.ReadFull(g.entropy, random[:]); err != nil {
+			// Without entropy, fall back to counting up from the previous random part
+			slog.Warn("Failed to read entropy for ULID, incrementing the previous random part", "error", err)
+			incrementBytes(g.last[:])
+		} else {
+			g.last = random
+		}
+		g.lastMs = ms
+	}
+
+	var id [16]byte
+	for i := 0; i < 6; i++ {
+		id[i] = byte(ms >> (40 - 8*i))
+	}
+	copy(id[6:], g.last[:])
+	return encodeULID(id)
+}
+
+// Increments a big-endian byte slice by one
+func incrementBytes(b []byte) {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return
+		}
+	}
+}
+
+// Encodes 128 bits as 26 base32 characters, most significant first
+func encodeULID(id [16]byte) string {
+	n := new(big.Int).SetBytes(id[:])
+	mask := big.NewInt(31)
+	digit := new(big.Int)
+
+	out := make([]byte, 26)
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = ulidAlphabet[digit.And(n, mask).Int64()]
+		n.Rsh(n, 5)
+	}
+	return string(out)
+}
@@ -0,0 +1,90 @@
+package usecase_test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/application/usecase"
+)
+
+func TestULIDGeneratorSortsInCreationOrder(t *testing.T) {
+	// Arrange
+	clock := usecase.NewFakeClock(time.Date(2024, time.January, 2, 15, 4, 5, 0, time.UTC))
+	generator := usecase.NewULIDGenerator(clock)
+
+	// Act: several IDs per millisecond across many milliseconds
+	ids := make([]string, 0, 300)
+	for i := 0; i < 100; i++ {
+		for j := 0; j < 3; j++ {
+			ids = append(ids, generator.NewID())
+		}
+		clock.Advance(time.Millisecond)
+	}
+
+	// Assert
+	if !sort.StringsAreSorted(ids) {
+		t.Error("Expected ULIDs to sort in creation order")
+	}
+
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if len(id) != 26 {
+			t.Errorf("Expected ULID of 26 characters, got %q", id)
+		}
+		if seen[id] {
+			t.Errorf("Expected unique ULIDs, got duplicate %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestULIDGeneratorEncodesTimestampPrefix(t *testing.T) {
+	// Arrange
+	clock := usecase.NewFakeClock(time.UnixMilli(0))
+	generator := usecase.NewULIDGenerator(clock)
+
+	// Act
+	id := generator.NewID()
+
+	// Assert: a zero timestamp encodes as ten leading zeros
+	if id[:10] != "0000000000" {
+		t.Errorf("Expected zero timestamp prefix, got %q", id[:10])
+	}
+}
+
+func TestULIDGeneratorSurvivesEntropyFailure(t *testing.T) {
+	// Arrange: an entropy source that always fails
+	clock := usecase.NewFakeClock(time.Date(2024, time.January, 2, 15, 4, 5, 0, time.UTC))
+	failing := &errReader{err: errors.New("entropy unavailable")}
+	generator := usecase.NewULIDGeneratorWithEntropy(clock, failing)
+
+	// Act: IDs within and across milliseconds
+	ids := make([]string, 0, 20)
+	for i := 0; i < 10; i++ {
+		ids = append(ids, generator.NewID(), generator.NewID())
+		clock.Advance(time.Millisecond)
+	}
+
+	// Assert
+	if !sort.StringsAreSorted(ids) {
+		t.Error("Expected ULIDs to sort in creation order without entropy")
+	}
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			t.Fatalf("Expected unique ULIDs without entropy, got %s twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+// Reader failing every read with err
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
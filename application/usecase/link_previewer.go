@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+)
+
+const (
+	// Maximum number of link previews fetched at once; further tweets are left without a preview
+	maxConcurrentLinkPreviews = 8
+	// Deadline for fetching and storing the preview of a link
+	linkPreviewTimeout = 10 * time.Second
+)
+
+// Fetches the preview card of a web page linked from a tweet
+type LinkPreviewFetcher interface {
+	Fetch(ctx context.Context, url string) (*entity.LinkPreview, error)
+}
+
+// Fetches the previews of the links in tweets in the background and stores them on the tweets
+type linkPreviewer struct {
+	fetcher         LinkPreviewFetcher
+	tweetRepository repository.TweetRepository
+	// Holds one token per preview being fetched
+	slots chan struct{}
+}
+
+// Creates a link previewer storing the previews in the repository, or returns nil when there is no fetcher
+func newLinkPreviewer(fetcher LinkPreviewFetcher, tweetRepository repository.TweetRepository) *linkPreviewer {
+	if fetcher == nil {
+		return nil
+	}
+	return &linkPreviewer{fetcher: fetcher, tweetRepository: tweetRepository, slots: make(chan struct{}, maxConcurrentLinkPreviews)}
+}
+
+// Fetches the preview of the first URL in the tweet without blocking the caller and stores it on the tweet
+// Previews are best effort: they are skipped while too many are being fetched, and failures are only logged
+func (p *linkPreviewer) preview(tweet *entity.Tweet) {
+	url := entity.FirstURL(tweet.Content)
+	if url == "" || (tweet.LinkPreview != nil && tweet.LinkPreview.URL == url) {
+		return
+	}
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		slog.Debug("Too many link previews being fetched, skipping", "tweetID", tweet.ID)
+		return
+	}
+
+	go func() {
+		defer func() { <-p.slots }()
+		ctx, cancel := context.WithTimeout(context.Background(), linkPreviewTimeout)
+		defer cancel()
+
+		preview, err := p.fetcher.Fetch(ctx, url)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to fetch link preview", "tweetID", tweet.ID, "url", url, "error", err)
+			return
+		}
+
+		// Store the preview on the latest version of the tweet, unless it was deleted or edited to link elsewhere
+		current, err := p.tweetRepository.FindByID(tweet.ID)
+		if err != nil || current == nil || entity.FirstURL(current.Content) != url {
+			slog.DebugContext(ctx, "Tweet changed while fetching its link preview, discarding it", "tweetID", tweet.ID, "error", err)
+			return
+		}
+		updated := *current
+		updated.LinkPreview = preview
+		if err := p.tweetRepository.Update(&updated); err != nil {
+			slog.WarnContext(ctx, "Failed to store link preview", "tweetID", tweet.ID, "error", err)
+		}
+	}()
+}
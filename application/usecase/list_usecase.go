@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+	"github.com/develpudu/go-challenge/infrastructure/cache"
+	"golang.org/x/sync/errgroup"
+)
+
+// Implements the list use cases
+// Lists can be read by anyone, but only their owner can change the members
+type ListUseCase struct {
+	listRepository    repository.ListRepository
+	tweetRepository   repository.TweetRepository
+	userRepository    repository.UserRepository
+	listTimelineCache cache.TimelineCache // Keyed by list ID
+	clock             Clock
+	idGenerator       IDGenerator
+}
+
+// Creates a new list use case
+// The cache may be nil, in which case list timelines are built on every request
+func NewListUseCase(
+	listRepository repository.ListRepository,
+	tweetRepository repository.TweetRepository,
+	userRepository repository.UserRepository,
+	listTimelineCache cache.TimelineCache,
+	opts ...Option,
+) *ListUseCase {
+	o := newOptions(opts)
+	return &ListUseCase{
+		listRepository:    listRepository,
+		tweetRepository:   tweetRepository,
+		userRepository:    userRepository,
+		listTimelineCache: listTimelineCache,
+		clock:             o.clock,
+		idGenerator:       o.idGenerator,
+	}
+}
+
+// Creates a new list owned by a user with the given initial members
+func (uc *ListUseCase) CreateList(ownerID, name string, memberIDs []string) (*entity.List, error) {
+	// Check if owner and members exist
+	if err := uc.checkUserExists(ownerID); err != nil {
+		return nil, err
+	}
+	for _, memberID := range memberIDs {
+		if err := uc.checkUserExists(memberID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create a new list
+	list, err := entity.NewList(uc.idGenerator.NewID(), ownerID, name, uc.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	for _, memberID := range memberIDs {
+		list.AddMember(memberID)
+	}
+
+	// Save the list
+	if err := uc.listRepository.SaveList(list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Retrieves a list by ID
+func (uc *ListUseCase) GetList(listID string) (*entity.List, error) {
+	list, err := uc.listRepository.FindListByID(listID)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		return nil, entity.ErrListNotFound
+	}
+	return list, nil
+}
+
+// Retrieves all lists owned by a user, oldest first
+func (uc *ListUseCase) GetListsByOwner(ownerID string) ([]*entity.List, error) {
+	if err := uc.checkUserExists(ownerID); err != nil {
+		return nil, err
+	}
+	return uc.listRepository.FindListsByOwner(ownerID)
+}
+
+// Adds a user to a list owned by the requesting user
+func (uc *ListUseCase) AddMember(ownerID, listID, userID string) error {
+	if err := uc.checkOwner(ownerID, listID); err != nil {
+		return err
+	}
+	if err := uc.checkUserExists(userID); err != nil {
+		return err
+	}
+
+	if err := uc.listRepository.AddMember(listID, userID); err != nil {
+		return err
+	}
+
+	uc.invalidateListTimeline(context.Background(), listID, "AddMember")
+	return nil
+}
+
+// Removes a user from a list owned by the requesting user
+func (uc *ListUseCase) RemoveMember(ownerID, listID, userID string) error {
+	if err := uc.checkOwner(ownerID, listID); err != nil {
+		return err
+	}
+
+	if err := uc.listRepository.RemoveMember(listID, userID); err != nil {
+		return err
+	}
+
+	uc.invalidateListTimeline(context.Background(), listID, "RemoveMember")
+	return nil
+}
+
+// Retrieves the timeline of a list: the tweets of its members, newest first
+func (uc *ListUseCase) GetListTimeline(listID string) ([]*entity.Tweet, error) {
+	ctx := context.Background()
+
+	list, err := uc.GetList(listID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check cache first
+	if uc.listTimelineCache != nil {
+		cachedTimeline, found, err := uc.listTimelineCache.GetTimeline(ctx, listID)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to get list timeline from cache, building it", "listID", listID, "error", err)
+		}
+		if found {
+			return withoutExpired(cachedTimeline, uc.clock.Now()), nil
+		}
+	}
+
+	// Fetch the tweets of every member concurrently
+	var timeline []*entity.Tweet
+	var mu sync.Mutex
+	var g errgroup.Group
+	for _, memberID := range list.GetMembers() {
+		g.Go(func() error {
+			tweets, err := uc.tweetRepository.FindByUserID(memberID)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			timeline = append(timeline, tweets...)
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(timeline, func(i, j int) bool {
+		return timeline[i].IsNewerThan(timeline[j])
+	})
+
+	// Store the built timeline in cache
+	if uc.listTimelineCache != nil {
+		if err := uc.listTimelineCache.SetTimeline(ctx, listID, timeline); err != nil {
+			slog.WarnContext(ctx, "Failed to set list timeline cache", "listID", listID, "error", err)
+		}
+	}
+
+	return withoutExpired(timeline, uc.clock.Now()), nil
+}
+
+// Returns ErrListNotFound if the list does not exist or ErrNotListOwner if it belongs to someone else
+func (uc *ListUseCase) checkOwner(ownerID, listID string) error {
+	list, err := uc.GetList(listID)
+	if err != nil {
+		return err
+	}
+	if list.OwnerID != ownerID {
+		return entity.ErrNotListOwner
+	}
+	return nil
+}
+
+// Returns ErrUserNotFound if the user does not exist
+func (uc *ListUseCase) checkUserExists(userID string) error {
+	user, err := uc.userRepository.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return entity.ErrUserNotFound
+	}
+	return nil
+}
+
+// Removes a list's cached timeline after its members change, logging any failure
+func (uc *ListUseCase) invalidateListTimeline(ctx context.Context, listID, operation string) {
+	if uc.listTimelineCache == nil {
+		return
+	}
+	if err := uc.listTimelineCache.InvalidateTimeline(ctx, listID); err != nil {
+		slog.WarnContext(ctx, "Failed to invalidate list timeline cache after membership change", "listID", listID, "operation", operation, "error", err)
+	}
+}
+
+// Retrieves the authors with the given IDs in a single batch, keyed by ID
+// Authors whose account was deleted are absent from the map
+func (uc *ListUseCase) GetAuthors(userIDs []string) (map[string]*entity.User, error) {
+	return uc.userRepository.FindByIDs(userIDs)
+}
@@ -0,0 +1,155 @@
+package usecase_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/infrastructure/cache"
+	"github.com/develpudu/go-challenge/infrastructure/repository/memory"
+)
+
+// Timeline cache backed by a map, so cached timelines are actually served
+type MapTimelineCache struct {
+	mutex     sync.Mutex
+	timelines map[string][]*entity.Tweet
+}
+
+func NewMapTimelineCache() *MapTimelineCache {
+	return &MapTimelineCache{timelines: make(map[string][]*entity.Tweet)}
+}
+
+func (c *MapTimelineCache) GetTimeline(ctx context.Context, id string) ([]*entity.Tweet, bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	timeline, found := c.timelines[id]
+	return timeline, found, nil
+}
+
+func (c *MapTimelineCache) SetTimeline(ctx context.Context, id string, timeline []*entity.Tweet) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.timelines[id] = timeline
+	return nil
+}
+
+func (c *MapTimelineCache) InvalidateTimeline(ctx context.Context, id string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.timelines, id)
+	return nil
+}
+
+func (c *MapTimelineCache) InvalidateTimelines(ctx context.Context, ids []string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, id := range ids {
+		delete(c.timelines, id)
+	}
+	return nil
+}
+
+// Compile-time check
+var _ cache.TimelineCache = (*MapTimelineCache)(nil)
+
+// Returns a list use case with three users who have each posted one tweet, a minute apart
+func setupListUseCase() (*usecase.ListUseCase, *MapTimelineCache) {
+	userRepo := NewMockUserRepository()
+	tweetRepo := NewMockTweetRepository()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, id := range []string{"owner", "alice", "bob"} {
+		userRepo.Save(entity.NewUser(id, id))
+		tweet, _ := entity.NewTweetAt("tweet-"+id, id, "Hello from "+id, start.Add(time.Duration(i)*time.Minute))
+		tweetRepo.Save(tweet)
+	}
+
+	listCache := NewMapTimelineCache()
+	useCase := usecase.NewListUseCase(memory.NewListRepository(), tweetRepo, userRepo, listCache)
+	return useCase, listCache
+}
+
+// Returns the IDs of the given tweets, in order
+func tweetIDs(tweets []*entity.Tweet) []string {
+	ids := make([]string, len(tweets))
+	for i, tweet := range tweets {
+		ids[i] = tweet.ID
+	}
+	return ids
+}
+
+func TestListTimelineOnlyIncludesMembers(t *testing.T) {
+	// Arrange
+	useCase, _ := setupListUseCase()
+	list, err := useCase.CreateList("owner", "Friends", []string{"alice", "bob"})
+	if err != nil {
+		t.Fatalf("Failed to create list: %v", err)
+	}
+
+	// Act
+	timeline, err := useCase.GetListTimeline(list.ID)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ids := tweetIDs(timeline)
+	if len(ids) != 2 || ids[0] != "tweet-bob" || ids[1] != "tweet-alice" {
+		t.Errorf("Expected the members' tweets newest first, got %v", ids)
+	}
+}
+
+func TestListMembershipChangesInvalidateTimeline(t *testing.T) {
+	// Arrange
+	useCase, listCache := setupListUseCase()
+	list, _ := useCase.CreateList("owner", "Friends", []string{"alice"})
+	if _, err := useCase.GetListTimeline(list.ID); err != nil {
+		t.Fatalf("Failed to build list timeline: %v", err)
+	}
+	if _, cached := listCache.timelines[list.ID]; !cached {
+		t.Fatal("Expected the list timeline to be cached")
+	}
+
+	// Act: adding a member must drop the cached timeline
+	if err := useCase.AddMember("owner", list.ID, "bob"); err != nil {
+		t.Fatalf("Failed to add member: %v", err)
+	}
+	afterAdd, _ := useCase.GetListTimeline(list.ID)
+
+	// Act: so must removing one
+	if err := useCase.RemoveMember("owner", list.ID, "alice"); err != nil {
+		t.Fatalf("Failed to remove member: %v", err)
+	}
+	afterRemove, _ := useCase.GetListTimeline(list.ID)
+
+	// Assert
+	if ids := tweetIDs(afterAdd); len(ids) != 2 {
+		t.Errorf("Expected the new member's tweets after adding, got %v", ids)
+	}
+	if ids := tweetIDs(afterRemove); len(ids) != 1 || ids[0] != "tweet-bob" {
+		t.Errorf("Expected only the remaining member's tweets after removing, got %v", ids)
+	}
+}
+
+func TestListMembersCanOnlyBeChangedByOwner(t *testing.T) {
+	// Arrange
+	useCase, _ := setupListUseCase()
+	list, _ := useCase.CreateList("owner", "Friends", nil)
+
+	// Act
+	err := useCase.AddMember("alice", list.ID, "bob")
+
+	// Assert
+	if err != entity.ErrNotListOwner {
+		t.Errorf("Expected ErrNotListOwner, got %v", err)
+	}
+	if err := useCase.AddMember("owner", "missing", "bob"); err != entity.ErrListNotFound {
+		t.Errorf("Expected ErrListNotFound for an unknown list, got %v", err)
+	}
+	if err := useCase.AddMember("owner", list.ID, "nonexistent"); err != entity.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound for an unknown member, got %v", err)
+	}
+}
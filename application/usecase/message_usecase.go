@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+)
+
+// Removes the follows between two users, like UserUseCase
+type FollowRemover interface {
+	RemoveFollowsBetween(userID, otherID string) error
+}
+
+// Implements the direct message use cases
+// Conversations are private: a user only ever reads the conversation they take part in
+type MessageUseCase struct {
+	messageRepository repository.MessageRepository
+	blockRepository   repository.BlockRepository
+	userRepository    repository.UserRepository
+	clock             Clock
+	idGenerator       IDGenerator
+	// Removes the follows between users when one blocks the other, nil to keep them
+	followRemover FollowRemover
+}
+
+// Creates a new message use case
+func NewMessageUseCase(
+	messageRepository repository.MessageRepository,
+	blockRepository repository.BlockRepository,
+	userRepository repository.UserRepository,
+	opts ...Option,
+) *MessageUseCase {
+	o := newOptions(opts)
+	return &MessageUseCase{
+		messageRepository: messageRepository,
+		blockRepository:   blockRepository,
+		userRepository:    userRepository,
+		clock:             o.clock,
+		idGenerator:       o.idGenerator,
+		followRemover:     o.followRemover,
+	}
+}
+
+// Sends a message from a user to another
+// Returns ErrMessagingBlocked if either user blocks the other
+func (uc *MessageUseCase) SendMessage(senderID, recipientID, content string) (*entity.Message, error) {
+	if err := uc.checkUserExists(senderID); err != nil {
+		return nil, err
+	}
+	if err := uc.checkUserExists(recipientID); err != nil {
+		return nil, err
+	}
+
+	message, err := entity.NewMessage(uc.idGenerator.NewID(), senderID, recipientID, content, uc.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Blocks are one-way, but either of them stops the conversation
+	if err := uc.checkNotBlocked(senderID, recipientID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.messageRepository.Save(message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// Retrieves up to limit of the latest messages between a user and another, oldest first
+// The conversation is looked up from the requesting user, so only its participants can read it
+func (uc *MessageUseCase) GetConversation(userID, otherID string, limit int) ([]*entity.Message, error) {
+	if err := uc.checkUserExists(userID); err != nil {
+		return nil, err
+	}
+	if err := uc.checkUserExists(otherID); err != nil {
+		return nil, err
+	}
+
+	messages, err := uc.messageRepository.FindByConversationID(entity.ConversationIDFor(userID, otherID), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Guard against a store returning messages of another conversation
+	conversation := make([]*entity.Message, 0, len(messages))
+	for _, message := range messages {
+		if message.HasParticipant(userID) && message.HasParticipant(otherID) {
+			conversation = append(conversation, message)
+		}
+	}
+	return conversation, nil
+}
+
+// Blocks a user on behalf of another, so neither can message the other
+// The follows between both users are removed in both directions once the block is stored.
+// Blocking an already blocked user succeeds, removing any follows left by an earlier failure
+func (uc *MessageUseCase) BlockUser(blockerID, blockedID string) error {
+	if blockerID == blockedID {
+		return entity.ErrCannotBlockSelf
+	}
+	if err := uc.checkUserExists(blockedID); err != nil {
+		return err
+	}
+	if err := uc.blockRepository.Block(blockerID, blockedID); err != nil {
+		return err
+	}
+	if uc.followRemover == nil {
+		return nil
+	}
+	return uc.followRemover.RemoveFollowsBetween(blockerID, blockedID)
+}
+
+// Removes a user's block of another; removing a missing block succeeds
+// The users can message each other again unless the other user blocks the first
+func (uc *MessageUseCase) UnblockUser(blockerID, blockedID string) error {
+	if err := uc.checkUserExists(blockedID); err != nil {
+		return err
+	}
+	return uc.blockRepository.Unblock(blockerID, blockedID)
+}
+
+// Returns ErrMessagingBlocked if either user blocks the other
+func (uc *MessageUseCase) checkNotBlocked(userID, otherID string) error {
+	for _, pair := range [][2]string{{userID, otherID}, {otherID, userID}} {
+		blocked, err := uc.blockRepository.IsBlocked(pair[0], pair[1])
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return entity.ErrMessagingBlocked
+		}
+	}
+	return nil
+}
+
+// Returns ErrUserNotFound if the user does not exist
+func (uc *MessageUseCase) checkUserExists(userID string) error {
+	user, err := uc.userRepository.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return entity.ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/infrastructure/repository/memory"
+)
+
+// Returns a message use case with three users, driven by the returned clock
+func setupMessageUseCase() (*usecase.MessageUseCase, *usecase.FakeClock) {
+	userRepo := memory.NewUserRepository()
+	for _, id := range []string{"alice", "bob", "carol"} {
+		userRepo.Save(entity.NewUser(id, id))
+	}
+	clock := usecase.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	useCase := usecase.NewMessageUseCase(memory.NewMessageRepository(), memory.NewBlockRepository(), userRepo, usecase.WithClock(clock))
+	return useCase, clock
+}
+
+func TestSendMessageAndReadConversation(t *testing.T) {
+	// Arrange
+	useCase, clock := setupMessageUseCase()
+
+	// Act
+	first, err := useCase.SendMessage("alice", "bob", "  Hi bob  ")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	clock.Advance(time.Minute)
+	useCase.SendMessage("bob", "alice", "Hi alice")
+	useCase.SendMessage("carol", "alice", "Hi from carol")
+
+	// Assert: both participants read the same conversation, oldest first
+	for _, reader := range [][2]string{{"alice", "bob"}, {"bob", "alice"}} {
+		messages, err := useCase.GetConversation(reader[0], reader[1], 10)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(messages) != 2 || messages[0].ID != first.ID || messages[0].Content != "Hi bob" || messages[1].SenderID != "bob" {
+			t.Errorf("Expected %s to read both messages with %s, got %d", reader[0], reader[1], len(messages))
+		}
+	}
+	if first.ConversationID != entity.ConversationIDFor("bob", "alice") {
+		t.Errorf("Expected a conversation ID independent of the sender, got %s", first.ConversationID)
+	}
+	if latest, _ := useCase.GetConversation("alice", "bob", 1); len(latest) != 1 || latest[0].SenderID != "bob" {
+		t.Errorf("Expected the limit to keep the latest message, got %+v", latest)
+	}
+
+	// A third user only ever reads their own conversations
+	messages, _ := useCase.GetConversation("carol", "bob", 10)
+	if len(messages) != 0 {
+		t.Errorf("Expected carol to read no messages between alice and bob, got %d", len(messages))
+	}
+
+	// Invalid messages
+	if _, err := useCase.SendMessage("alice", "alice", "Hi me"); err != entity.ErrCannotMessageSelf {
+		t.Errorf("Expected ErrCannotMessageSelf, got %v", err)
+	}
+	if _, err := useCase.SendMessage("alice", "ghost", "Hi"); err != entity.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+	if _, err := useCase.SendMessage("alice", "bob", "   "); err != entity.ErrEmptyMessage {
+		t.Errorf("Expected ErrEmptyMessage, got %v", err)
+	}
+}
+
+func TestBlockedUsersCannotMessageEachOther(t *testing.T) {
+	// Arrange
+	useCase, _ := setupMessageUseCase()
+	if err := useCase.BlockUser("alice", "bob"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Act & Assert: the block stops messages both ways
+	if _, err := useCase.SendMessage("bob", "alice", "Hi"); err != entity.ErrMessagingBlocked {
+		t.Errorf("Expected ErrMessagingBlocked from the blocked user, got %v", err)
+	}
+	if _, err := useCase.SendMessage("alice", "bob", "Hi"); err != entity.ErrMessagingBlocked {
+		t.Errorf("Expected ErrMessagingBlocked from the blocker, got %v", err)
+	}
+	if _, err := useCase.SendMessage("carol", "alice", "Hi"); err != nil {
+		t.Errorf("Expected other users to still message alice, got %v", err)
+	}
+
+	// Unblocking allows messages again
+	useCase.UnblockUser("alice", "bob")
+	if _, err := useCase.SendMessage("bob", "alice", "Hi again"); err != nil {
+		t.Errorf("Expected messages after unblocking, got %v", err)
+	}
+	if err := useCase.BlockUser("alice", "alice"); err != entity.ErrCannotBlockSelf {
+		t.Errorf("Expected ErrCannotBlockSelf, got %v", err)
+	}
+}
+
+func TestBlockUserRemovesFollowsBothWays(t *testing.T) {
+	// Arrange
+	userRepo := memory.NewUserRepository()
+	for _, id := range []string{"alice", "bob", "carol"} {
+		userRepo.Save(entity.NewUser(id, id))
+	}
+	userRepo.Follow("alice", "bob")
+	userRepo.Follow("bob", "alice")
+	userRepo.Follow("carol", "alice")
+	users := usecase.NewUserUseCase(userRepo, &MockTimelineCache{})
+	useCase := usecase.NewMessageUseCase(memory.NewMessageRepository(), memory.NewBlockRepository(), userRepo, usecase.WithFollowRemoval(users))
+
+	// Act
+	if err := useCase.BlockUser("alice", "bob"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Assert: both follows between alice and bob are gone, other follows are kept
+	alice, _ := userRepo.FindByID("alice")
+	bob, _ := userRepo.FindByID("bob")
+	carol, _ := userRepo.FindByID("carol")
+	if alice.IsFollowing("bob") {
+		t.Error("Expected the blocker to no longer follow the blocked user")
+	}
+	if bob.IsFollowing("alice") {
+		t.Error("Expected the blocked user to no longer follow the blocker")
+	}
+	if !carol.IsFollowing("alice") {
+		t.Error("Expected other follows to be kept")
+	}
+
+	// Blocking again succeeds with no follows left to remove
+	if err := useCase.BlockUser("alice", "bob"); err != nil {
+		t.Errorf("Expected blocking again to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+)
+
+// Implements the notification use cases
+// Notifications are private: every operation is scoped to the requesting user
+type NotificationUseCase struct {
+	notificationRepository repository.NotificationRepository
+	userRepository         repository.UserRepository
+	clock                  Clock
+}
+
+// Creates a new notification use case
+func NewNotificationUseCase(
+	notificationRepository repository.NotificationRepository,
+	userRepository repository.UserRepository,
+	opts ...Option,
+) *NotificationUseCase {
+	o := newOptions(opts)
+	return &NotificationUseCase{
+		notificationRepository: notificationRepository,
+		userRepository:         userRepository,
+		clock:                  o.clock,
+	}
+}
+
+// Represents a page of notifications, with the cursor of the next page if there is one
+// and the number of unread notifications of the user across every page
+type NotificationPage struct {
+	Notifications []*entity.Notification
+	NextCursor    string
+	UnreadCount   int
+}
+
+// Retrieves a page of up to limit notifications of a user, newest first
+// An empty cursor starts from the newest notification
+func (uc *NotificationUseCase) GetNotifications(userID string, limit int, cursor string) (*NotificationPage, error) {
+	if err := uc.checkUserExists(userID); err != nil {
+		return nil, err
+	}
+	afterID, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// One extra notification is requested to know whether a next page exists
+	notifications, err := uc.notificationRepository.FindByRecipientID(userID, afterID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &NotificationPage{Notifications: notifications}
+	if len(notifications) > limit {
+		page.Notifications = notifications[:limit]
+		page.NextCursor = encodeCursor(page.Notifications[limit-1].ID)
+	}
+
+	page.UnreadCount, err = uc.notificationRepository.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// Marks every notification a user has received so far as read
+func (uc *NotificationUseCase) MarkAllRead(userID string) error {
+	if err := uc.checkUserExists(userID); err != nil {
+		return err
+	}
+	return uc.notificationRepository.MarkAllRead(userID, uc.clock.Now())
+}
+
+// Retrieves the users who took the notified actions in a single batch, keyed by ID
+// Users whose account was deleted are absent from the map
+func (uc *NotificationUseCase) GetActors(userIDs []string) (map[string]*entity.User, error) {
+	return uc.userRepository.FindByIDs(userIDs)
+}
+
+// Returns ErrUserNotFound if the user does not exist
+func (uc *NotificationUseCase) checkUserExists(userID string) error {
+	user, err := uc.userRepository.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return entity.ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/infrastructure/repository/memory"
+)
+
+// Use cases sharing one notification store, driven by the same clock
+type notificationFixture struct {
+	users         *usecase.UserUseCase
+	tweets        *usecase.TweetUseCase
+	notifications *usecase.NotificationUseCase
+	clock         *usecase.FakeClock
+}
+
+func setupNotificationFixture() notificationFixture {
+	userRepo := memory.NewUserRepository()
+	for _, id := range []string{"alice", "bob", "carol"} {
+		userRepo.Save(entity.NewUser(id, id))
+	}
+	notificationRepo := memory.NewNotificationRepository()
+	clock := usecase.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	opts := []usecase.Option{usecase.WithNotifications(notificationRepo), usecase.WithClock(clock)}
+	return notificationFixture{
+		users:         usecase.NewUserUseCase(userRepo, nil, opts...),
+		tweets:        usecase.NewTweetUseCase(memory.NewTweetRepository(userRepo), userRepo, opts...),
+		notifications: usecase.NewNotificationUseCase(notificationRepo, userRepo, opts...),
+		clock:         clock,
+	}
+}
+
+// Returns every notification of a user, newest first
+func (f notificationFixture) list(t *testing.T, userID string) *usecase.NotificationPage {
+	t.Helper()
+	page, err := f.notifications.GetNotifications(userID, 100, "")
+	if err != nil {
+		t.Fatalf("Failed to get notifications of %s: %v", userID, err)
+	}
+	return page
+}
+
+func TestEachActionNotifiesItsRecipient(t *testing.T) {
+	// Arrange
+	f := setupNotificationFixture()
+	root, _ := f.tweets.CreateTweet("alice", "Hello")
+
+	// Act
+	f.clock.Advance(time.Minute)
+	f.users.FollowUser("bob", "alice")
+	f.clock.Advance(time.Minute)
+	reply, _ := f.tweets.CreateReply("bob", root.ID, "Hi @alice and @Carol", "")
+	f.clock.Advance(time.Minute)
+	mention, _ := f.tweets.CreateTweet("carol", "Thanks @bob, and @nobody")
+	f.tweets.CreateTweet("alice", "Talking to myself, @alice")
+
+	// Assert: alice is notified once of the reply that also mentions her
+	expected := map[string][]entity.Notification{
+		"alice": {
+			{Type: entity.NotificationReply, ActorID: "bob", TweetID: reply.ID},
+			{Type: entity.NotificationFollow, ActorID: "bob"},
+		},
+		"bob":   {{Type: entity.NotificationMention, ActorID: "carol", TweetID: mention.ID}},
+		"carol": {{Type: entity.NotificationMention, ActorID: "bob", TweetID: reply.ID}},
+	}
+	for recipientID, want := range expected {
+		got := f.list(t, recipientID).Notifications
+		if len(got) != len(want) {
+			t.Errorf("Expected %d notifications for %s, got %d", len(want), recipientID, len(got))
+			continue
+		}
+		for i := range want {
+			if got[i].RecipientID != recipientID || got[i].Type != want[i].Type || got[i].ActorID != want[i].ActorID || got[i].TweetID != want[i].TweetID {
+				t.Errorf("Expected notification %d of %s to be %+v, got %+v", i, recipientID, want[i], *got[i])
+			}
+		}
+	}
+}
+
+func TestNotificationsPageAndMarkRead(t *testing.T) {
+	// Arrange: carol follows alice, then bob does after alice reads her notifications
+	f := setupNotificationFixture()
+	f.users.FollowUser("carol", "alice")
+	f.clock.Advance(time.Minute)
+	if err := f.notifications.MarkAllRead("alice"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	f.clock.Advance(time.Minute)
+	f.users.FollowUser("bob", "alice")
+
+	// Act
+	first, err := f.notifications.GetNotifications("alice", 1, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	second, err := f.notifications.GetNotifications("alice", 1, first.NextCursor)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Assert
+	if len(first.Notifications) != 1 || first.Notifications[0].ActorID != "bob" || first.Notifications[0].Read {
+		t.Errorf("Expected bob's unread follow first, got %+v", first.Notifications)
+	}
+	if len(second.Notifications) != 1 || second.Notifications[0].ActorID != "carol" || !second.Notifications[0].Read {
+		t.Errorf("Expected carol's read follow second, got %+v", second.Notifications)
+	}
+	if first.NextCursor == "" || second.NextCursor != "" {
+		t.Errorf("Expected a next cursor on the first page only, got %q and %q", first.NextCursor, second.NextCursor)
+	}
+	if first.UnreadCount != 1 {
+		t.Errorf("Expected 1 unread notification, got %d", first.UnreadCount)
+	}
+	f.notifications.MarkAllRead("alice")
+	if unread := f.list(t, "alice").UnreadCount; unread != 0 {
+		t.Errorf("Expected no unread notifications after marking them read, got %d", unread)
+	}
+	if _, err := f.notifications.GetNotifications("ghost", 10, ""); err != entity.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound for an unknown user, got %v", err)
+	}
+}
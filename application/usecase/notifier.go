@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+)
+
+// Stores notifications of actions for their recipients
+type notifier struct {
+	notificationRepository repository.NotificationRepository
+	clock                  Clock
+}
+
+// Creates a notifier over the repository, or nil when notifications are disabled
+func newNotifier(notificationRepository repository.NotificationRepository, clock Clock) *notifier {
+	if notificationRepository == nil {
+		return nil
+	}
+	return &notifier{notificationRepository: notificationRepository, clock: clock}
+}
+
+// Notifies the recipient of an action by the actor, taken with the tweet if any
+// Users are not notified of their own actions. Notifying is best effort: the action
+// already happened, so a failure only costs the notification and is logged
+func (n *notifier) notify(ctx context.Context, recipientID string, notificationType entity.NotificationType, actorID, tweetID string) {
+	if n == nil || recipientID == actorID {
+		return
+	}
+	notification := entity.NewNotification(recipientID, notificationType, actorID, tweetID, n.clock.Now())
+	if err := n.notificationRepository.Save(notification); err != nil {
+		slog.WarnContext(ctx, "Failed to save notification", "recipientID", recipientID, "type", notificationType, "actorID", actorID, "error", err)
+	}
+}
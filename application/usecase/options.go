@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+	"github.com/develpudu/go-challenge/infrastructure/cache"
+)
+
+// Time after creation during which a tweet can be edited unless WithEditWindow overrides it
+const DefaultEditWindow = 5 * time.Minute
+
+// Configures optional dependencies of the use cases
+type Option func(*options)
+
+// Holds the optional dependencies shared by the use cases
+type options struct {
+	clock          Clock
+	idGenerator    IDGenerator
+	eventPublisher EventPublisher
+	// Window in which a tweet repeating the author's latest one is rejected, zero to disable
+	duplicateWindow time.Duration
+	// Time after creation during which a tweet can be edited, zero for no limit
+	editWindow time.Duration
+	// Maximum number of tweets a user can post within tweetRateWindow, zero to disable the limit
+	tweetRateLimit  int
+	tweetRateWindow time.Duration
+	// Maximum number of characters allowed in a tweet
+	maxTweetLength int
+	// Cache of users recently looked up and not found, nil to disable
+	userCache cache.UserCache
+	// Name of the ranking applied to timelines that do not request one
+	timelineRanking string
+	// Usernames that cannot be registered
+	reservedUsernames entity.ReservedUsernames
+	// Counter of tweet impressions, nil to skip counting
+	impressionCounter cache.ImpressionCounter
+	// Builder of the timelines rebuilt after each follow, nil to disable warming
+	timelineBuilder TimelineBuilder
+	// Store of the blocks between users, nil when follows ignore blocks
+	blockRepository repository.BlockRepository
+	// Remover of the follows between users when one blocks the other, nil to keep them
+	followRemover FollowRemover
+	// Store of the notifications of follows, replies and mentions, nil to disable notifications
+	notificationRepository repository.NotificationRepository
+	// Profane words filtered out of new tweet content
+	profanity entity.ProfanityList
+	// How profanity is handled, entity.ProfanityReject or entity.ProfanityMask; empty to disable the filter
+	profanityMode string
+	// Fetcher of the previews of links in tweets, nil to disable previews
+	linkPreviewFetcher LinkPreviewFetcher
+	// Cache of the timelines invalidated when the tweet use case removes follows, nil when timelines are not cached
+	timelineCache cache.TimelineCache
+}
+
+// Returns the options with defaults applied, overridden by opts
+func newOptions(opts []Option) options {
+	o := options{
+		clock:           SystemClock{},
+		idGenerator:     UUIDGenerator{},
+		eventPublisher:  NoopEventPublisher{},
+		editWindow:      DefaultEditWindow,
+		maxTweetLength:  entity.MaxTweetLength,
+		timelineRanking: repository.ChronologicalRanking,
+		// Reserved usernames shipped with the platform
+		reservedUsernames: entity.DefaultReservedUsernames(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Sets the clock used to timestamp new entities
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// Sets the generator used to assign IDs to new entities
+func WithIDGenerator(idGenerator IDGenerator) Option {
+	return func(o *options) {
+		o.idGenerator = idGenerator
+	}
+}
+
+// Sets the publisher notified of domain events
+func WithEventPublisher(eventPublisher EventPublisher) Option {
+	return func(o *options) {
+		o.eventPublisher = eventPublisher
+	}
+}
+
+// Rejects a tweet whose content repeats the author's latest tweet posted within the window
+// A non-positive window disables the check, which is the default
+func WithDuplicateWindow(window time.Duration) Option {
+	return func(o *options) {
+		o.duplicateWindow = window
+	}
+}
+
+// Limits each user to limit new tweets, replies and quotes within the window, counted from their stored tweets
+// A non-positive limit or window disables the limit, which is the default
+func WithTweetRateLimit(limit int, window time.Duration) Option {
+	return func(o *options) {
+		if limit <= 0 || window <= 0 {
+			limit, window = 0, 0
+		}
+		o.tweetRateLimit = limit
+		o.tweetRateWindow = window
+	}
+}
+
+// Sets how long after creation a tweet can be edited
+// A non-positive window allows edits at any time
+func WithEditWindow(window time.Duration) Option {
+	return func(o *options) {
+		o.editWindow = window
+	}
+}
+
+// Sets the maximum number of characters allowed when creating or editing tweets
+// Non-positive values keep the default of entity.MaxTweetLength
+func WithMaxTweetLength(maxLength int) Option {
+	return func(o *options) {
+		if maxLength > 0 {
+			o.maxTweetLength = maxLength
+		}
+	}
+}
+
+// Sets the cache used to remember users that were recently looked up and not found
+func WithUserCache(userCache cache.UserCache) Option {
+	return func(o *options) {
+		o.userCache = userCache
+	}
+}
+
+// Sets the ranking applied to timelines that do not request one, chronological by default
+// The name must be one of repository.TimelineRankings
+func WithTimelineRanking(name string) Option {
+	return func(o *options) {
+		o.timelineRanking = name
+	}
+}
+
+// Sets the usernames that cannot be registered, replacing the default list
+func WithReservedUsernames(reserved entity.ReservedUsernames) Option {
+	return func(o *options) {
+		o.reservedUsernames = reserved
+	}
+}
+
+// Sets the counter that records an impression each time a tweet is viewed
+func WithImpressionCounter(counter cache.ImpressionCounter) Option {
+	return func(o *options) {
+		o.impressionCounter = counter
+	}
+}
+
+// Rebuilds the follower's timeline in the background after each follow, so their next read hits the cache
+// The builder is expected to cache what it builds; the follow never waits for it
+func WithTimelineWarming(builder TimelineBuilder) Option {
+	return func(o *options) {
+		o.timelineBuilder = builder
+	}
+}
+
+// Rejects follows between users when either one blocks the other, with ErrBlocked
+func WithBlocks(blockRepository repository.BlockRepository) Option {
+	return func(o *options) {
+		o.blockRepository = blockRepository
+	}
+}
+
+// Removes the follows between two users in both directions when one blocks the other
+func WithFollowRemoval(remover FollowRemover) Option {
+	return func(o *options) {
+		o.followRemover = remover
+	}
+}
+
+// Notifies users of follows, replies and mentions by storing them in the repository
+func WithNotifications(notificationRepository repository.NotificationRepository) Option {
+	return func(o *options) {
+		o.notificationRepository = notificationRepository
+	}
+}
+
+// Filters the profane words out of new tweet content with the given mode:
+// entity.ProfanityReject rejects the tweet and entity.ProfanityMask masks the words
+// Any other mode disables the filter, which is the default
+func WithProfanityFilter(words entity.ProfanityList, mode string) Option {
+	return func(o *options) {
+		o.profanity = words
+		o.profanityMode = mode
+	}
+}
+
+// Fetches a preview of the first link in each new or edited tweet in the background and stores it on the tweet
+// Tweets are returned before their preview is fetched, and keep no preview when fetching fails
+func WithLinkPreviews(fetcher LinkPreviewFetcher) Option {
+	return func(o *options) {
+		o.linkPreviewFetcher = fetcher
+	}
+}
+
+// Sets the timeline cache invalidated when follows of deleted users are removed while reading a timeline
+func WithTimelineCache(timelineCache cache.TimelineCache) Option {
+	return func(o *options) {
+		o.timelineCache = timelineCache
+	}
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"log/slog"
+	"math"
+	"sort"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+)
+
+// Orders timelines newest first, breaking ties by tweet ID
+type ChronologicalRanker struct{}
+
+// Returns the name of the ranking
+func (ChronologicalRanker) Name() string {
+	return repository.ChronologicalRanking
+}
+
+// Returns the tweets newest first
+func (ChronologicalRanker) Rank(tweets []*entity.Tweet, viewer *entity.User) []*entity.Tweet {
+	ranked := append([]*entity.Tweet(nil), tweets...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].IsNewerThan(ranked[j])
+	})
+	return ranked
+}
+
+// Exponent applied to a tweet's age, in hours, when scoring its engagement
+// Higher values make older tweets sink faster despite their replies
+const engagementGravity = 1.5
+
+// Orders timelines by engagement: tweets with more replies are boosted,
+// while older tweets slowly sink, so a popular tweet stays on top for a while
+type EngagementRanker struct {
+	tweetRepository repository.TweetRepository
+	clock           Clock
+}
+
+// Creates a new engagement ranker counting replies in the tweet repository
+func NewEngagementRanker(tweetRepository repository.TweetRepository, clock Clock) *EngagementRanker {
+	return &EngagementRanker{
+		tweetRepository: tweetRepository,
+		clock:           clock,
+	}
+}
+
+// Returns the name of the ranking
+func (r *EngagementRanker) Name() string {
+	return repository.EngagementRanking
+}
+
+// Returns the tweets with the highest engagement score first
+// The score is (replies + 1) / (age in hours + 2) ^ gravity; ties keep the newest tweet first
+func (r *EngagementRanker) Rank(tweets []*entity.Tweet, viewer *entity.User) []*entity.Tweet {
+	replies := r.countReplies(tweets)
+	now := r.clock.Now()
+	scores := make(map[string]float64, len(tweets))
+	for _, tweet := range tweets {
+		ageHours := math.Max(now.Sub(tweet.CreatedAt).Hours(), 0)
+		scores[tweet.ID] = float64(replies[tweet.ID]+1) / math.Pow(ageHours+2, engagementGravity)
+	}
+
+	ranked := append([]*entity.Tweet(nil), tweets...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if scores[ranked[i].ID] != scores[ranked[j].ID] {
+			return scores[ranked[i].ID] > scores[ranked[j].ID]
+		}
+		return ranked[i].IsNewerThan(ranked[j])
+	})
+	return ranked
+}
+
+// Returns the number of direct replies to each tweet, reading each conversation once
+// A conversation that cannot be read counts as having no replies
+func (r *EngagementRanker) countReplies(tweets []*entity.Tweet) map[string]int {
+	replies := make(map[string]int)
+	read := make(map[string]bool)
+	for _, tweet := range tweets {
+		rootID := tweet.RootID()
+		if read[rootID] {
+			continue
+		}
+		read[rootID] = true
+
+		thread, err := r.tweetRepository.FindByConversationID(rootID)
+		if err != nil {
+			slog.Warn("Failed to read conversation for engagement ranking", "conversationID", rootID, "error", err)
+			continue
+		}
+		for _, reply := range thread {
+			if reply.InReplyToID != "" {
+				replies[reply.InReplyToID]++
+			}
+		}
+	}
+	return replies
+}
+
+// Compile-time checks to ensure the rankers implement TimelineRanker
+var (
+	_ repository.TimelineRanker = ChronologicalRanker{}
+	_ repository.TimelineRanker = (*EngagementRanker)(nil)
+)
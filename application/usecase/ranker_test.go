@@ -0,0 +1,75 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+	"github.com/develpudu/go-challenge/infrastructure/repository/memory"
+)
+
+func TestRankersOrderTheSameTimelineDifferently(t *testing.T) {
+	// Arrange: an older tweet with several replies and a newer one without any
+	userRepo := memory.NewUserRepository()
+	tweetRepo := memory.NewTweetRepository(userRepo)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := usecase.NewFakeClock(now)
+	popular, _ := entity.NewTweetAt("popular", "alice", "Popular", now.Add(-2*time.Hour))
+	quiet, _ := entity.NewTweetAt("quiet", "bob", "Quiet", now.Add(-time.Hour))
+	tweetRepo.Save(popular)
+	tweetRepo.Save(quiet)
+	for i, id := range []string{"reply1", "reply2", "reply3"} {
+		reply, _ := entity.NewReplyAt(id, "carol", "Reply", popular, now.Add(time.Duration(i-50)*time.Minute))
+		tweetRepo.Save(reply)
+	}
+	timeline := []*entity.Tweet{quiet, popular}
+	viewer := entity.NewUser("viewer", "viewer")
+
+	// Act
+	chronological := usecase.ChronologicalRanker{}.Rank(timeline, viewer)
+	engagement := usecase.NewEngagementRanker(tweetRepo, clock).Rank(timeline, viewer)
+
+	// Assert
+	if ids := tweetIDs(chronological); ids[0] != "quiet" || ids[1] != "popular" {
+		t.Errorf("Expected the newest tweet first chronologically, got %v", ids)
+	}
+	if ids := tweetIDs(engagement); ids[0] != "popular" || ids[1] != "quiet" {
+		t.Errorf("Expected the replied tweet first by engagement, got %v", ids)
+	}
+	if timeline[0].ID != "quiet" {
+		t.Error("Expected ranking not to reorder the input slice")
+	}
+}
+
+func TestTimelineRankingIsPartOfTheCacheKey(t *testing.T) {
+	// Arrange
+	useCase := usecase.NewTweetUseCase(NewMockTweetRepository(), NewMockUserRepository())
+	chronological, _ := useCase.TimelineRanker(repository.ChronologicalRanking)
+	engagement, _ := useCase.TimelineRanker(repository.EngagementRanking)
+
+	// Act
+	_, unknownErr := useCase.TimelineRanker("random")
+	defaultKey := repository.DefaultTimelineOptions().CacheKey("alice")
+	chronologicalKey := repository.TimelineOptions{IncludeSelf: true, Ranker: chronological}.CacheKey("alice")
+	engagementKey := repository.TimelineOptions{IncludeSelf: true, Ranker: engagement}.CacheKey("alice")
+
+	// Assert
+	if unknownErr != entity.ErrUnknownRanking {
+		t.Errorf("Expected ErrUnknownRanking, got %v", unknownErr)
+	}
+	if chronologicalKey != defaultKey {
+		t.Errorf("Expected the chronological timeline under the default key %q, got %q", defaultKey, chronologicalKey)
+	}
+	if engagementKey == defaultKey {
+		t.Error("Expected the engagement timeline to be cached under its own key")
+	}
+	found := false
+	for _, key := range repository.TimelineCacheKeys("alice") {
+		found = found || key == engagementKey
+	}
+	if !found {
+		t.Error("Expected the engagement key to be invalidated with the other variants")
+	}
+}
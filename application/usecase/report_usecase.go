@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+)
+
+// Implements the report use cases
+// Any user can report a tweet once; only moderators list the reports
+type ReportUseCase struct {
+	reportRepository repository.ReportRepository
+	tweetRepository  repository.TweetRepository
+	clock            Clock
+}
+
+// Creates a new report use case
+func NewReportUseCase(
+	reportRepository repository.ReportRepository,
+	tweetRepository repository.TweetRepository,
+	opts ...Option,
+) *ReportUseCase {
+	o := newOptions(opts)
+	return &ReportUseCase{
+		reportRepository: reportRepository,
+		tweetRepository:  tweetRepository,
+		clock:            o.clock,
+	}
+}
+
+// Reports a tweet on behalf of a user with the given reason
+// Returns ErrAlreadyReported if the user already reported the tweet
+func (uc *ReportUseCase) ReportTweet(reporterID, tweetID, reason string) (*entity.Report, error) {
+	// Check if tweet exists
+	tweet, err := uc.tweetRepository.FindByID(tweetID)
+	if err != nil {
+		return nil, err
+	}
+	if tweet == nil || tweet.IsExpired(uc.clock.Now()) {
+		return nil, entity.ErrTweetNotFound
+	}
+
+	report, err := entity.NewReport(reporterID, tweetID, reason, uc.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.reportRepository.Save(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Retrieves every report, newest first
+func (uc *ReportUseCase) ListReports() ([]*entity.Report, error) {
+	return uc.reportRepository.FindAll()
+}
@@ -0,0 +1,74 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/infrastructure/repository/memory"
+)
+
+// Returns a report use case with one tweet, driven by the returned clock
+func setupReportUseCase() (*usecase.ReportUseCase, *usecase.FakeClock) {
+	tweetRepo := NewMockTweetRepository()
+	clock := usecase.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	tweet, _ := entity.NewTweet("tweet1", "alice", "Hello")
+	tweetRepo.Save(tweet)
+
+	useCase := usecase.NewReportUseCase(memory.NewReportRepository(), tweetRepo, usecase.WithClock(clock))
+	return useCase, clock
+}
+
+func TestReportTweet(t *testing.T) {
+	// Arrange
+	useCase, clock := setupReportUseCase()
+	useCase.ReportTweet("bob", "tweet1", "Spam")
+	clock.Advance(time.Minute)
+
+	// Act
+	report, err := useCase.ReportTweet("carol", "tweet1", "  Abuse  ")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Reason != "Abuse" || !report.CreatedAt.Equal(clock.Now()) {
+		t.Errorf("Expected a trimmed reason stamped with the clock, got %+v", report)
+	}
+	reports, _ := useCase.ListReports()
+	if len(reports) != 2 || reports[0].ReporterID != "carol" || reports[1].ReporterID != "bob" {
+		t.Errorf("Expected both reports newest first, got %+v", reports)
+	}
+}
+
+func TestReportTweetTwice(t *testing.T) {
+	// Arrange
+	useCase, _ := setupReportUseCase()
+	useCase.ReportTweet("bob", "tweet1", "Spam")
+
+	// Act
+	_, err := useCase.ReportTweet("bob", "tweet1", "Spam again")
+
+	// Assert
+	if err != entity.ErrAlreadyReported {
+		t.Errorf("Expected ErrAlreadyReported, got %v", err)
+	}
+}
+
+func TestReportTweetValidation(t *testing.T) {
+	// Arrange
+	useCase, _ := setupReportUseCase()
+
+	// Act
+	_, missingTweet := useCase.ReportTweet("bob", "missing", "Spam")
+	_, noReason := useCase.ReportTweet("bob", "tweet1", "   ")
+
+	// Assert
+	if missingTweet != entity.ErrTweetNotFound {
+		t.Errorf("Expected ErrTweetNotFound, got %v", missingTweet)
+	}
+	if noReason != entity.ErrReportReasonRequired {
+		t.Errorf("Expected ErrReportReasonRequired, got %v", noReason)
+	}
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+)
+
+// Names of the histogram bucket sizes
+const (
+	HourBucket = "hour"
+	DayBucket  = "day"
+)
+
+// Maximum number of buckets in a tweet histogram, a month of hours
+const MaxHistogramBuckets = 31 * 24
+
+// Bucket sizes by name
+var bucketSizes = map[string]time.Duration{
+	HourBucket: repository.HourBucket,
+	DayBucket:  repository.DayBucket,
+}
+
+// Platform-wide totals for operators
+type PlatformStats struct {
+	Users   int
+	Tweets  int
+	Follows int
+	// Tweets divided by users, zero when there are no users
+	AverageTweetsPerUser float64
+}
+
+// Implements the platform stats use case
+type StatsUseCase struct {
+	userRepository  repository.UserRepository
+	tweetRepository repository.TweetRepository
+}
+
+// Creates a new stats use case
+func NewStatsUseCase(userRepository repository.UserRepository, tweetRepository repository.TweetRepository) *StatsUseCase {
+	return &StatsUseCase{
+		userRepository:  userRepository,
+		tweetRepository: tweetRepository,
+	}
+}
+
+// Computes the platform totals from the repository counts
+func (uc *StatsUseCase) GetStats() (*PlatformStats, error) {
+	users, err := uc.userRepository.Count()
+	if err != nil {
+		return nil, err
+	}
+	tweets, err := uc.tweetRepository.Count()
+	if err != nil {
+		return nil, err
+	}
+	follows, err := uc.userRepository.CountFollows()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &PlatformStats{
+		Users:   users,
+		Tweets:  tweets,
+		Follows: follows,
+	}
+	if users > 0 {
+		stats.AverageTweetsPerUser = float64(tweets) / float64(users)
+	}
+	return stats, nil
+}
+
+// Counts the tweets created in each hour or day bucket of the range [from, to)
+// The buckets are aligned to UTC hours or days, so the first and last ones may extend beyond the range
+// Returns ErrUnknownBucket for a bucket other than hour or day, ErrInvalidTimeRange unless to is after from,
+// and ErrTimeRangeTooLarge when the range spans more than MaxHistogramBuckets buckets
+func (uc *StatsUseCase) GetTweetHistogram(from, to time.Time, bucket string) (*repository.TweetHistogram, error) {
+	size, ok := bucketSizes[bucket]
+	if !ok {
+		return nil, entity.ErrUnknownBucket
+	}
+	if from.IsZero() || !to.After(from) {
+		return nil, entity.ErrInvalidTimeRange
+	}
+
+	histogram := repository.NewTweetHistogram(from, to, size)
+	if len(histogram.Counts) > MaxHistogramBuckets {
+		return nil, entity.ErrTimeRangeTooLarge
+	}
+	if err := uc.tweetRepository.CountCreatedPerBucket(histogram); err != nil {
+		return nil, err
+	}
+	return histogram, nil
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+)
+
+const (
+	// Maximum number of timelines rebuilt at once after follows; further follows skip warming
+	maxConcurrentTimelineWarms = 8
+	// Deadline for rebuilding a timeline after a follow
+	timelineWarmTimeout = 5 * time.Second
+)
+
+// Builds timelines, caching them as a side effect, like TweetUseCase over a cached repository
+type TimelineBuilder interface {
+	GetTimeline(ctx context.Context, userID string, opts repository.TimelineOptions) ([]*entity.Tweet, error)
+}
+
+// Rebuilds timelines in the background so the next read is served from the cache
+type timelineWarmer struct {
+	builder TimelineBuilder
+	// Holds one token per timeline being rebuilt
+	slots chan struct{}
+}
+
+// Creates a timeline warmer over the builder
+func newTimelineWarmer(builder TimelineBuilder) *timelineWarmer {
+	return &timelineWarmer{builder: builder, slots: make(chan struct{}, maxConcurrentTimelineWarms)}
+}
+
+// Rebuilds the default timeline of a user without blocking the caller
+// Warming is best effort: it is skipped while too many timelines are being rebuilt, and failures are only logged
+func (w *timelineWarmer) warm(userID string) {
+	select {
+	case w.slots <- struct{}{}:
+	default:
+		slog.Debug("Too many timelines being warmed, skipping", "userID", userID)
+		return
+	}
+
+	go func() {
+		defer func() { <-w.slots }()
+		ctx, cancel := context.WithTimeout(context.Background(), timelineWarmTimeout)
+		defer cancel()
+
+		if _, err := w.builder.GetTimeline(ctx, userID, repository.DefaultTimelineOptions()); err != nil {
+			slog.WarnContext(ctx, "Failed to warm timeline after follow", "userID", userID, "error", err)
+			return
+		}
+		slog.DebugContext(ctx, "Warmed timeline after follow", "userID", userID)
+	}()
+}
@@ -1,30 +1,117 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"time"
+
 	"github.com/develpudu/go-challenge/domain/entity"
 	"github.com/develpudu/go-challenge/domain/repository"
-	"github.com/google/uuid"
+	"github.com/develpudu/go-challenge/infrastructure/cache"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Implements the tweet use cases
 type TweetUseCase struct {
 	tweetRepository repository.TweetRepository
 	userRepository  repository.UserRepository
+	clock           Clock
+	idGenerator     IDGenerator
+	eventPublisher  EventPublisher
+	duplicateWindow time.Duration
+	editWindow      time.Duration
+	tweetRateLimit  int
+	tweetRateWindow time.Duration
+	maxTweetLength  int
+	timelineRanking string
+	impressions     cache.ImpressionCounter
+	profanity       entity.ProfanityList
+	profanityMode   string
+	// Notifies users of replies to their tweets and of mentions, nil when notifications are disabled
+	notifier *notifier
+	// Fetches the previews of links in tweets, nil when previews are disabled
+	linkPreviewer *linkPreviewer
+	// Invalidated when follows of deleted users are removed, nil when timelines are not cached
+	timelineCache cache.TimelineCache
 }
 
 // Creates a new tweet use case
 func NewTweetUseCase(
 	tweetRepository repository.TweetRepository,
 	userRepository repository.UserRepository,
+	opts ...Option,
 ) *TweetUseCase {
+	o := newOptions(opts)
 	return &TweetUseCase{
 		tweetRepository: tweetRepository,
 		userRepository:  userRepository,
+		clock:           o.clock,
+		idGenerator:     o.idGenerator,
+		eventPublisher:  o.eventPublisher,
+		duplicateWindow: o.duplicateWindow,
+		editWindow:      o.editWindow,
+		tweetRateLimit:  o.tweetRateLimit,
+		tweetRateWindow: o.tweetRateWindow,
+		maxTweetLength:  o.maxTweetLength,
+		timelineRanking: o.timelineRanking,
+		impressions:     o.impressionCounter,
+		profanity:       o.profanity,
+		profanityMode:   o.profanityMode,
+		notifier:        newNotifier(o.notificationRepository, o.clock),
+		linkPreviewer:   newLinkPreviewer(o.linkPreviewFetcher, tweetRepository),
+		timelineCache:   o.timelineCache,
+	}
+}
+
+// Returns the maximum number of characters allowed in a tweet
+func (uc *TweetUseCase) MaxTweetLength() int {
+	return uc.maxTweetLength
+}
+
+// Checks whether content can be posted as a tweet without storing anything
+// Returns the length of the normalized content, as counted against the limit, and the reason it is invalid if any
+func (uc *TweetUseCase) ValidateContent(content string) (int, error) {
+	_, err := entity.PrepareContent(content, uc.maxTweetLength)
+	if err == nil {
+		_, err = uc.filterProfanity(content)
+	}
+	return entity.ContentLength(entity.NormalizeContent(content)), err
+}
+
+// Applies the profanity filter to new tweet content
+// Returns ErrProfanity in reject mode and the content with profane words masked in mask mode
+func (uc *TweetUseCase) filterProfanity(content string) (string, error) {
+	switch uc.profanityMode {
+	case entity.ProfanityReject:
+		if uc.profanity.Contains(content) {
+			return "", entity.ErrProfanity
+		}
+	case entity.ProfanityMask:
+		return uc.profanity.Mask(content), nil
 	}
+	return content, nil
 }
 
 // Creates a new tweet for a user
 func (uc *TweetUseCase) CreateTweet(userID, content string) (*entity.Tweet, error) {
+	return uc.CreateExpiringTweet(userID, content, "", 0)
+}
+
+// Creates a new tweet for a user in the given ISO 639-1 language that expires after expiresIn
+// An empty lang leaves the language unknown, and a zero expiresIn creates a tweet that never expires
+func (uc *TweetUseCase) CreateExpiringTweet(userID, content, lang string, expiresIn time.Duration) (*entity.Tweet, error) {
+	if expiresIn < 0 {
+		return nil, entity.ErrInvalidExpiration
+	}
+	lang, err := entity.NormalizeLang(lang)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if user exists
 	user, err := uc.userRepository.FindByID(userID)
 	if err != nil {
@@ -34,24 +121,316 @@ func (uc *TweetUseCase) CreateTweet(userID, content string) (*entity.Tweet, erro
 		return nil, entity.ErrUserNotFound
 	}
 
+	// Reject users posting faster than the rate limit
+	if err := uc.checkRateLimit(userID); err != nil {
+		return nil, err
+	}
+
+	// Reject or mask profanity before comparing with the latest tweet, stored filtered
+	content, err = uc.filterProfanity(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject accidental double submits
+	if err := uc.checkDuplicate(userID, content); err != nil {
+		return nil, err
+	}
+
 	// Generate a unique ID for the tweet
-	tweetID := uuid.New().String()
+	tweetID := uc.idGenerator.NewID()
 
 	// Create a new tweet
-	tweet, err := entity.NewTweet(tweetID, userID, content)
+	tweet, err := entity.NewTweetWithMaxLength(tweetID, userID, content, uc.clock.Now(), uc.maxTweetLength)
+	if err != nil {
+		return nil, err
+	}
+	if expiresIn > 0 {
+		tweet.ExpiresAt = tweet.CreatedAt.Add(expiresIn)
+	}
+	tweet.Lang = lang
+
+	return uc.saveTweet(tweet, nil)
+}
+
+// Creates a new tweet for a user in reply to another tweet, in the given ISO 639-1 language or an unknown one
+// The reply joins the conversation of the tweet it replies to
+func (uc *TweetUseCase) CreateReply(userID, inReplyToID, content, lang string) (*entity.Tweet, error) {
+	lang, err := entity.NormalizeLang(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if user exists
+	user, err := uc.userRepository.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, entity.ErrUserNotFound
+	}
+
+	// Reject users posting faster than the rate limit
+	if err := uc.checkRateLimit(userID); err != nil {
+		return nil, err
+	}
+
+	// Check if the replied tweet exists
+	parent, err := uc.GetTweetByID(inReplyToID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject or mask profanity before comparing with the latest tweet, stored filtered
+	content, err = uc.filterProfanity(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject accidental double submits
+	if err := uc.checkDuplicate(userID, content); err != nil {
+		return nil, err
+	}
+
+	// Create a new reply
+	reply, err := entity.NewReplyWithMaxLength(uc.idGenerator.NewID(), userID, content, parent, uc.clock.Now(), uc.maxTweetLength)
+	if err != nil {
+		return nil, err
+	}
+	reply.Lang = lang
+
+	return uc.saveTweet(reply, parent)
+}
+
+// Creates a new tweet by the user that quotes another tweet with its own content
+func (uc *TweetUseCase) QuoteTweet(userID, quotedID, content string) (*entity.Tweet, error) {
+	// Check if user exists
+	user, err := uc.userRepository.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, entity.ErrUserNotFound
+	}
+
+	// Reject users posting faster than the rate limit
+	if err := uc.checkRateLimit(userID); err != nil {
+		return nil, err
+	}
+
+	// Check if the quoted tweet exists
+	quoted, err := uc.GetTweetByID(quotedID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject or mask profanity before comparing with the latest tweet, stored filtered
+	content, err = uc.filterProfanity(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject accidental double submits
+	if err := uc.checkDuplicate(userID, content); err != nil {
+		return nil, err
+	}
+
+	// Create a new quote
+	quote, err := entity.NewQuoteWithMaxLength(uc.idGenerator.NewID(), userID, content, quoted, uc.clock.Now(), uc.maxTweetLength)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.saveTweet(quote, nil)
+}
+
+// Replaces the content of a tweet written by the user
+// Edits are only allowed within the edit window counted from the tweet's creation
+func (uc *TweetUseCase) UpdateTweet(userID, tweetID, content string) (*entity.Tweet, error) {
+	tweet, err := uc.GetTweetByID(tweetID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the author can edit a tweet
+	if tweet.UserID != userID {
+		return nil, entity.ErrNotTweetAuthor
+	}
+
+	// Reject edits once the window has closed
+	if uc.editWindow > 0 && uc.clock.Now().Sub(tweet.CreatedAt) > uc.editWindow {
+		return nil, entity.ErrEditWindowExpired
+	}
+
+	// Reject or mask profanity as in new tweets
+	content, err = uc.filterProfanity(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Edit a copy so a failed update leaves the stored tweet untouched
+	updated := *tweet
+	if err := updated.EditWithMaxLength(content, uc.maxTweetLength, uc.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := uc.tweetRepository.Update(&updated); err != nil {
+		return nil, err
+	}
+	if uc.linkPreviewer != nil {
+		uc.linkPreviewer.preview(&updated)
+	}
+
+	return &updated, nil
+}
+
+// Deletes a tweet written by the user
+func (uc *TweetUseCase) DeleteTweet(userID, tweetID string) error {
+	tweet, err := uc.GetTweetByID(tweetID)
+	if err != nil {
+		return err
+	}
+
+	// Only the author can delete a tweet
+	if tweet.UserID != userID {
+		return entity.ErrNotTweetAuthor
+	}
+
+	return uc.tweetRepository.Delete(tweet.ID)
+}
+
+// Deletes any tweet regardless of its author, for moderation
+// Returns the deleted tweet so the moderator action can be recorded
+func (uc *TweetUseCase) DeleteTweetAsModerator(tweetID string) (*entity.Tweet, error) {
+	tweet, err := uc.GetTweetByID(tweetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.tweetRepository.Delete(tweet.ID); err != nil {
+		return nil, err
+	}
+	return tweet, nil
+}
+
+// Returns ErrDuplicateTweet if the user's latest tweet has the same content and
+// was posted within the duplicate window. Does nothing when the window is disabled.
+func (uc *TweetUseCase) checkDuplicate(userID, content string) error {
+	if uc.duplicateWindow <= 0 {
+		return nil
+	}
+
+	tweets, err := uc.tweetRepository.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	// Repositories may order tweets by ID, so look for the latest by creation time
+	var latest *entity.Tweet
+	for _, tweet := range tweets {
+		if latest == nil || tweet.CreatedAt.After(latest.CreatedAt) {
+			latest = tweet
+		}
+	}
+
+	if latest != nil && latest.Content == entity.NormalizeContent(content) && uc.clock.Now().Sub(latest.CreatedAt) < uc.duplicateWindow {
+		return entity.ErrDuplicateTweet
+	}
+	return nil
+}
+
+// Returns a ThrottledError wrapping ErrTweetRateLimited if the user has posted as many tweets as the rate
+// limit allows within the rate window, with the time until the oldest of them leaves the window
+// Does nothing when the limit is disabled.
+func (uc *TweetUseCase) checkRateLimit(userID string) error {
+	if uc.tweetRateLimit <= 0 {
+		return nil
+	}
+
+	now := uc.clock.Now()
+	recent, err := uc.recentTweetTimes(userID, now)
+	if err != nil {
+		return err
+	}
+	return uc.rateLimitError(recent, now)
+}
+
+// Returns the creation times of the user's tweets inside the rate window at now
+// Only the window is read from the repository; tweets imported with a past creation time count while inside it
+func (uc *TweetUseCase) recentTweetTimes(userID string, now time.Time) ([]time.Time, error) {
+	times, err := uc.tweetRepository.FindCreatedAtByUserID(userID, now.Add(-uc.tweetRateWindow), now)
 	if err != nil {
 		return nil, err
 	}
 
+	// The repository includes both bounds, the window starts right after its first instant
+	recent := times[:0]
+	for _, createdAt := range times {
+		if now.Sub(createdAt) < uc.tweetRateWindow {
+			recent = append(recent, createdAt)
+		}
+	}
+	return recent, nil
+}
+
+// Returns a ThrottledError wrapping ErrTweetRateLimited if recent, the creation times inside the rate window,
+// already reach the limit
+func (uc *TweetUseCase) rateLimitError(recent []time.Time, now time.Time) error {
+	if len(recent) < uc.tweetRateLimit {
+		return nil
+	}
+
+	// A tweet is allowed again once all but limit-1 of the recent tweets have left the window
+	sorted := append([]time.Time(nil), recent...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+	retryAfter := sorted[len(sorted)-uc.tweetRateLimit].Add(uc.tweetRateWindow).Sub(now)
+	return &entity.ThrottledError{RetryAfter: retryAfter, Err: entity.ErrTweetRateLimited}
+}
+
+// Stores a new tweet, publishes its creation event and notifies the users it replies to or mentions
+// repliedTo is the tweet a reply answers, nil for other tweets
+func (uc *TweetUseCase) saveTweet(tweet *entity.Tweet, repliedTo *entity.Tweet) (*entity.Tweet, error) {
 	// Save the tweet
-	err = uc.tweetRepository.Save(tweet)
+	err := uc.tweetRepository.Save(tweet)
 	if err != nil {
 		return nil, err
 	}
 
+	// Notify downstream systems; the tweet is already stored, so a publish failure is only logged
+	ctx := context.Background()
+	if err := uc.eventPublisher.TweetCreated(ctx, tweet); err != nil {
+		slog.WarnContext(ctx, "Failed to publish tweet created event", "tweetID", tweet.ID, "userID", tweet.UserID, "error", err)
+	}
+	uc.notifyRecipients(ctx, tweet, repliedTo)
+	if uc.linkPreviewer != nil {
+		uc.linkPreviewer.preview(tweet)
+	}
+
 	return tweet, nil
 }
 
+// Notifies the author of the replied tweet of a reply, and every mentioned user of the mention
+// The author of the replied tweet is notified once, of the reply, even when also mentioned
+func (uc *TweetUseCase) notifyRecipients(ctx context.Context, tweet *entity.Tweet, repliedTo *entity.Tweet) {
+	if uc.notifier == nil {
+		return
+	}
+	if repliedTo != nil {
+		uc.notifier.notify(ctx, repliedTo.UserID, entity.NotificationReply, tweet.UserID, tweet.ID)
+	}
+	for _, username := range tweet.Mentions() {
+		user, err := uc.userRepository.FindByUsername(username)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to look up mentioned user", "tweetID", tweet.ID, "username", username, "error", err)
+			continue
+		}
+		if user == nil || (repliedTo != nil && user.ID == repliedTo.UserID) {
+			continue
+		}
+		uc.notifier.notify(ctx, user.ID, entity.NotificationMention, tweet.UserID, tweet.ID)
+	}
+}
+
 // Retrieves all tweets by a specific user
 func (uc *TweetUseCase) GetTweetsByUser(userID string) ([]*entity.Tweet, error) {
 	// Check if user exists
@@ -59,17 +438,153 @@ func (uc *TweetUseCase) GetTweetsByUser(userID string) ([]*entity.Tweet, error)
 	if err != nil {
 		return nil, err
 	}
-	if user == nil {
+	if user == nil || !user.Active {
 		return nil, entity.ErrUserNotFound
 	}
 
 	// Get tweets by user ID
-	return uc.tweetRepository.FindByUserID(userID)
+	tweets, err := uc.tweetRepository.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return withoutExpired(tweets, uc.clock.Now()), nil
+}
+
+// Returns the number of tweets of a user, as GetTweetsByUser would list them, without retrieving them
+func (uc *TweetUseCase) CountTweetsByUser(userID string) (int, error) {
+	// Check if user exists
+	user, err := uc.userRepository.FindByID(userID)
+	if err != nil {
+		return 0, err
+	}
+	if user == nil || !user.Active {
+		return 0, entity.ErrUserNotFound
+	}
+
+	return uc.tweetRepository.CountByUserID(userID, uc.clock.Now())
+}
+
+// Maximum number of tweets accepted in a single import
+const MaxImportTweets = 1000
+
+// Tweet to import, with the creation time it had where it was exported from
+type ImportedTweet struct {
+	Content   string
+	CreatedAt time.Time
+}
+
+// Outcome of importing one tweet: the stored tweet, or the reason it was rejected
+type ImportResult struct {
+	Tweet *entity.Tweet
+	Err   error
+}
+
+// Creates tweets for a user keeping their original creation times
+// Each entry is validated on its own and the results are returned in the same order;
+// valid entries are stored together so cached timelines are invalidated once.
+// Imported tweets are historical, so they skip the duplicate check; profanity is filtered as for new tweets.
+// Entries created inside the rate window count against the rate limit, and those over it fail with a ThrottledError.
+func (uc *TweetUseCase) ImportTweets(userID string, entries []ImportedTweet) ([]ImportResult, error) {
+	if len(entries) > MaxImportTweets {
+		return nil, entity.ErrImportTooLarge
+	}
+
+	// Check if user exists
+	user, err := uc.userRepository.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, entity.ErrUserNotFound
+	}
+
+	// Tweets created inside the rate window count against the limit, imported or not
+	now := uc.clock.Now()
+	var recent []time.Time
+	if uc.tweetRateLimit > 0 {
+		recent, err = uc.recentTweetTimes(userID, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate every entry
+	results := make([]ImportResult, len(entries))
+	tweets := make([]*entity.Tweet, 0, len(entries))
+	for i, entry := range entries {
+		if entry.CreatedAt.IsZero() || entry.CreatedAt.After(now) {
+			results[i].Err = entity.ErrInvalidCreatedAt
+			continue
+		}
+		content, err := uc.filterProfanity(entry.Content)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		tweet, err := entity.NewTweetWithMaxLength(uc.idGenerator.NewID(), userID, content, entry.CreatedAt, uc.maxTweetLength)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if uc.tweetRateLimit > 0 && now.Sub(tweet.CreatedAt) < uc.tweetRateWindow {
+			if err := uc.rateLimitError(recent, now); err != nil {
+				results[i].Err = err
+				continue
+			}
+			recent = append(recent, tweet.CreatedAt)
+		}
+		results[i].Tweet = tweet
+		tweets = append(tweets, tweet)
+	}
+	if len(tweets) == 0 {
+		return results, nil
+	}
+
+	// Save the valid tweets in one batch
+	if err := uc.tweetRepository.SaveAll(tweets); err != nil {
+		return nil, err
+	}
+
+	// Notify downstream systems; a publish failure is only logged
+	ctx := context.Background()
+	for _, tweet := range tweets {
+		if err := uc.eventPublisher.TweetCreated(ctx, tweet); err != nil {
+			slog.WarnContext(ctx, "Failed to publish tweet created event", "tweetID", tweet.ID, "userID", tweet.UserID, "error", err)
+		}
+	}
+
+	return results, nil
+}
+
+// Retrieves all tweets of a user for them to download, oldest first
+// Unlike GetTweetsByUser, this works while the account is deactivated
+func (uc *TweetUseCase) ExportTweets(userID string) ([]*entity.Tweet, error) {
+	// Check if user exists
+	user, err := uc.userRepository.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, entity.ErrUserNotFound
+	}
+
+	tweets, err := uc.tweetRepository.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	tweets = withoutExpired(tweets, uc.clock.Now())
+	sort.SliceStable(tweets, func(i, j int) bool {
+		return tweets[j].IsNewerThan(tweets[i])
+	})
+	return tweets, nil
 }
 
 // Retrieves the timeline for a specific user
-// The timeline includes tweets from users that the user follows and their own tweets
-func (uc *TweetUseCase) GetTimeline(userID string) ([]*entity.Tweet, error) {
+// The timeline includes tweets from users that the user follows, and their own tweets and replies when opts includes them
+func (uc *TweetUseCase) GetTimeline(ctx context.Context, userID string, opts repository.TimelineOptions) (timeline []*entity.Tweet, err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "TweetUseCase.GetTimeline", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer func() { endSpan(span, err) }()
+
 	// Check if user exists
 	user, err := uc.userRepository.FindByID(userID)
 	if err != nil {
@@ -79,13 +594,123 @@ func (uc *TweetUseCase) GetTimeline(userID string) ([]*entity.Tweet, error) {
 		return nil, entity.ErrUserNotFound
 	}
 
+	// Apply the default ranking unless another one was requested
+	if opts.Ranker == nil {
+		if opts.Ranker, err = uc.TimelineRanker(uc.timelineRanking); err != nil {
+			return nil, err
+		}
+	}
+
 	// Get timeline
-	return uc.tweetRepository.GetTimeline(userID)
+	timeline, err = uc.tweetRepository.GetTimeline(ctx, userID, opts)
+	if err != nil {
+		return nil, err
+	}
+	timeline, deletedIDs, err := uc.withoutDeactivatedAuthors(withoutExpired(timeline, uc.clock.Now()))
+	if err != nil {
+		return nil, err
+	}
+	uc.forgetDeletedFollows(ctx, user, deletedIDs)
+	return timeline, nil
+}
+
+// Removes users that no longer exist from the follows of a user
+// This reconciles follows left behind when a user was deleted without their followers being updated,
+// then invalidates the user's cached timelines so they are rebuilt without the deleted users' tweets;
+// failures are only logged, as the timeline already leaves out the tweets of deleted users
+func (uc *TweetUseCase) forgetDeletedFollows(ctx context.Context, user *entity.User, deletedIDs []string) {
+	removed := 0
+	for _, deletedID := range deletedIDs {
+		if !user.IsFollowing(deletedID) {
+			continue
+		}
+		if err := uc.userRepository.Unfollow(user.ID, deletedID); err != nil {
+			slog.WarnContext(ctx, "Failed to remove follow of deleted user", "userID", user.ID, "deletedID", deletedID, "error", err)
+			continue
+		}
+		removed++
+		slog.InfoContext(ctx, "Removed follow of deleted user", "userID", user.ID, "deletedID", deletedID)
+	}
+	if removed == 0 || uc.timelineCache == nil {
+		return
+	}
+	if err := uc.timelineCache.InvalidateTimelines(ctx, repository.TimelineCacheKeys(user.ID)); err != nil {
+		slog.WarnContext(ctx, "Failed to invalidate timeline cache after removing follows of deleted users", "userID", user.ID, "error", err)
+	}
+}
+
+// Returns the timeline ranker with the given name, one of repository.TimelineRankings
+// Returns ErrUnknownRanking for any other name
+func (uc *TweetUseCase) TimelineRanker(name string) (repository.TimelineRanker, error) {
+	switch name {
+	case repository.ChronologicalRanking:
+		return ChronologicalRanker{}, nil
+	case repository.EngagementRanking:
+		return NewEngagementRanker(uc.tweetRepository, uc.clock), nil
+	}
+	return nil, entity.ErrUnknownRanking
+}
+
+// Returns the tweets whose authors exist and have not deactivated their account, keeping their order,
+// along with the IDs of the authors that no longer exist
+// Cached timelines still hold these tweets, so they reappear as soon as the author reactivates
+// The authors are read in a single batch rather than one lookup per author
+func (uc *TweetUseCase) withoutDeactivatedAuthors(tweets []*entity.Tweet) ([]*entity.Tweet, []string, error) {
+	authorIDs := make([]string, 0, len(tweets))
+	for _, tweet := range tweets {
+		authorIDs = append(authorIDs, tweet.UserID)
+	}
+	authors, err := uc.userRepository.FindByIDs(authorIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	visible := make([]*entity.Tweet, 0, len(tweets))
+	var deletedIDs []string
+	deleted := make(map[string]bool)
+	for _, tweet := range tweets {
+		author, ok := authors[tweet.UserID]
+		if !ok {
+			if !deleted[tweet.UserID] {
+				deleted[tweet.UserID] = true
+				deletedIDs = append(deletedIDs, tweet.UserID)
+			}
+			continue
+		}
+		if author.Active {
+			visible = append(visible, tweet)
+		}
+	}
+	return visible, deletedIDs, nil
 }
 
 // Retrieves all tweets from the repository
 func (uc *TweetUseCase) GetAllTweets() ([]*entity.Tweet, error) {
-	return uc.tweetRepository.FindAll()
+	tweets, err := uc.tweetRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return withoutExpired(tweets, uc.clock.Now()), nil
+}
+
+// Retrieves all tweets written in the given ISO 639-1 language
+// Tweets of unknown language are left out
+func (uc *TweetUseCase) GetAllTweetsInLang(lang string) ([]*entity.Tweet, error) {
+	lang, err := entity.NormalizeLang(lang)
+	if err != nil {
+		return nil, err
+	}
+	tweets, err := uc.GetAllTweets()
+	if err != nil {
+		return nil, err
+	}
+	inLang := make([]*entity.Tweet, 0, len(tweets))
+	for _, tweet := range tweets {
+		if tweet.Lang == lang {
+			inLang = append(inLang, tweet)
+		}
+	}
+	return inLang, nil
 }
 
 // Retrieves a specific tweet by its ID
@@ -94,8 +719,113 @@ func (uc *TweetUseCase) GetTweetByID(tweetID string) (*entity.Tweet, error) {
 	if err != nil {
 		return nil, err
 	}
-	if tweet == nil {
+	if tweet == nil || tweet.IsExpired(uc.clock.Now()) {
 		return nil, entity.ErrTweetNotFound
 	}
 	return tweet, nil
 }
+
+// Retrieves a tweet viewed by viewerID and records the impression
+// Returns the number of impressions of the tweet: those stored with it plus those still pending in the counter
+// Failures to count are logged and never fail the request
+func (uc *TweetUseCase) ViewTweet(ctx context.Context, tweetID, viewerID string) (*entity.Tweet, int64, error) {
+	tweet, err := uc.GetTweetByID(tweetID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if uc.impressions == nil {
+		return tweet, tweet.Impressions, nil
+	}
+	count, err := uc.impressions.RecordImpression(ctx, tweet.ID, viewerID)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to record impression, skipping", "tweetID", tweet.ID, "error", err)
+		return tweet, tweet.Impressions, nil
+	}
+	return tweet, tweet.Impressions + count, nil
+}
+
+// Default interval between impression flushes
+const DefaultImpressionFlushInterval = 5 * time.Minute
+
+// Moves the impressions pending in the counter to the stored tweets and returns how many tweets were updated
+// Counts that cannot be stored go back to the counter for the next flush, those of deleted tweets are dropped
+func (uc *TweetUseCase) FlushImpressions(ctx context.Context) (int, error) {
+	if uc.impressions == nil {
+		return 0, nil
+	}
+	counts, takeErr := uc.impressions.TakeImpressions(ctx)
+
+	flushed := 0
+	failed := make(map[string]int64)
+	var storeErr error
+	for tweetID, count := range counts {
+		err := uc.tweetRepository.AddImpressions(tweetID, count)
+		switch {
+		case err == nil:
+			flushed++
+		case errors.Is(err, entity.ErrTweetNotFound):
+			// Deleted since it was viewed
+		default:
+			failed[tweetID] = count
+			storeErr = err
+		}
+	}
+	if len(failed) > 0 {
+		if err := uc.impressions.RestoreImpressions(ctx, failed); err != nil {
+			slog.ErrorContext(ctx, "Failed to restore unflushed impressions, dropping them", "tweets", len(failed), "error", err)
+		}
+	}
+	return flushed, errors.Join(takeErr, storeErr)
+}
+
+// Flushes the pending impressions every interval until the context is cancelled
+func (uc *TweetUseCase) RunImpressionFlush(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if flushed, err := uc.FlushImpressions(ctx); err != nil {
+			slog.ErrorContext(ctx, "Impression flush failed", "flushed", flushed, "error", err)
+		} else if flushed > 0 {
+			slog.InfoContext(ctx, "Flushed impressions", "tweets", flushed)
+		}
+	}
+}
+
+// Retrieves the authors with the given IDs in a single batch, keyed by ID
+// Authors whose account was deleted are absent from the map
+func (uc *TweetUseCase) GetAuthors(userIDs []string) (map[string]*entity.User, error) {
+	return uc.userRepository.FindByIDs(userIDs)
+}
+
+// Retrieves the whole conversation a tweet belongs to, oldest first
+func (uc *TweetUseCase) GetConversation(tweetID string) ([]*entity.Tweet, error) {
+	tweet, err := uc.GetTweetByID(tweetID)
+	if err != nil {
+		return nil, err
+	}
+
+	thread, err := uc.tweetRepository.FindByConversationID(tweet.RootID())
+	if err != nil {
+		return nil, err
+	}
+	return withoutExpired(thread, uc.clock.Now()), nil
+}
+
+// Returns the tweets that have not expired at the given time, keeping their order
+// Expired tweets are removed by the storage eventually, so reads must skip them meanwhile
+func withoutExpired(tweets []*entity.Tweet, now time.Time) []*entity.Tweet {
+	visible := make([]*entity.Tweet, 0, len(tweets))
+	for _, tweet := range tweets {
+		if !tweet.IsExpired(now) {
+			visible = append(visible, tweet)
+		}
+	}
+	return visible
+}
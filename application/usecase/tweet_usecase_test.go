@@ -1,15 +1,37 @@
 package usecase_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/develpudu/go-challenge/application/usecase"
 	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+	"github.com/develpudu/go-challenge/infrastructure/repository/memory"
 )
 
 // Mock implementation of the TweetRepository interface
 type MockTweetRepository struct {
 	tweets map[string]*entity.Tweet
+	// Number of SaveAll calls, one per imported batch
+	saveAllCalls int
+}
+
+// Mock implementation of the EventPublisher interface
+type MockEventPublisher struct {
+	published []*entity.Tweet
+	err       error
+}
+
+// Records the published tweet and returns the configured error
+func (p *MockEventPublisher) TweetCreated(ctx context.Context, tweet *entity.Tweet) error {
+	p.published = append(p.published, tweet)
+	return p.err
 }
 
 // Creates a new mock tweet repository
@@ -25,6 +47,24 @@ func (r *MockTweetRepository) Save(tweet *entity.Tweet) error {
 	return nil
 }
 
+// Stores several tweets in the repository
+func (r *MockTweetRepository) SaveAll(tweets []*entity.Tweet) error {
+	r.saveAllCalls++
+	for _, tweet := range tweets {
+		r.tweets[tweet.ID] = tweet
+	}
+	return nil
+}
+
+// Replaces an existing tweet
+func (r *MockTweetRepository) Update(tweet *entity.Tweet) error {
+	if _, exists := r.tweets[tweet.ID]; !exists {
+		return entity.ErrTweetNotFound
+	}
+	r.tweets[tweet.ID] = tweet
+	return nil
+}
+
 // Retrieves a tweet by its ID
 func (r *MockTweetRepository) FindByID(id string) (*entity.Tweet, error) {
 	tweet, exists := r.tweets[id]
@@ -45,6 +85,38 @@ func (r *MockTweetRepository) FindByUserID(userID string) ([]*entity.Tweet, erro
 	return result, nil
 }
 
+// Returns the number of tweets by a specific user that have not expired at now
+func (r *MockTweetRepository) CountByUserID(userID string, now time.Time) (int, error) {
+	count := 0
+	for _, tweet := range r.tweets {
+		if tweet.UserID == userID && !tweet.IsExpired(now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Returns the creation times of the tweets by a specific user created between from and to
+func (r *MockTweetRepository) FindCreatedAtByUserID(userID string, from, to time.Time) ([]time.Time, error) {
+	times := make([]time.Time, 0)
+	for _, tweet := range r.tweets {
+		if tweet.UserID == userID && !tweet.CreatedAt.Before(from) && !tweet.CreatedAt.After(to) {
+			times = append(times, tweet.CreatedAt)
+		}
+	}
+	return times, nil
+}
+
+// Adds a number of impressions to a stored tweet
+func (r *MockTweetRepository) AddImpressions(id string, count int64) error {
+	tweet, exists := r.tweets[id]
+	if !exists {
+		return entity.ErrTweetNotFound
+	}
+	tweet.Impressions += count
+	return nil
+}
+
 // Retrieves all tweets
 func (r *MockTweetRepository) FindAll() ([]*entity.Tweet, error) {
 	result := make([]*entity.Tweet, 0, len(r.tweets))
@@ -54,6 +126,33 @@ func (r *MockTweetRepository) FindAll() ([]*entity.Tweet, error) {
 	return result, nil
 }
 
+// Returns the number of tweets in the repository
+func (r *MockTweetRepository) Count() (int, error) {
+	return len(r.tweets), nil
+}
+
+// Counts the tweets created in each bucket of the histogram
+func (r *MockTweetRepository) CountCreatedPerBucket(histogram *repository.TweetHistogram) error {
+	for _, tweet := range r.tweets {
+		histogram.Add(tweet.CreatedAt)
+	}
+	return nil
+}
+
+// Retrieves all tweets of a conversation, oldest first
+func (r *MockTweetRepository) FindByConversationID(conversationID string) ([]*entity.Tweet, error) {
+	result := make([]*entity.Tweet, 0)
+	for _, tweet := range r.tweets {
+		if tweet.RootID() == conversationID {
+			result = append(result, tweet)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
 // Removes a tweet from the repository
 func (r *MockTweetRepository) Delete(id string) error {
 	delete(r.tweets, id)
@@ -61,7 +160,7 @@ func (r *MockTweetRepository) Delete(id string) error {
 }
 
 // GetTimeline retrieves the timeline for a specific user
-func (r *MockTweetRepository) GetTimeline(userID string) ([]*entity.Tweet, error) {
+func (r *MockTweetRepository) GetTimeline(ctx context.Context, userID string, opts repository.TimelineOptions) ([]*entity.Tweet, error) {
 	// In a real implementation, this would get tweets from the user and all followed users
 	// For the mock, we'll just return all tweets as a simplification
 	return r.FindAll()
@@ -145,7 +244,7 @@ func TestCreateTweetTooLong(t *testing.T) {
 	_, err := useCase.CreateTweet(user.ID, content)
 
 	// Assert
-	if err != entity.ErrTweetTooLong {
+	if !errors.Is(err, entity.ErrTweetTooLong) {
 		t.Errorf("Expected ErrTweetTooLong, got %v", err)
 	}
 }
@@ -231,7 +330,7 @@ func TestGetTimeline(t *testing.T) {
 	tweetRepo.Save(notFollowedTweet)
 
 	// Act
-	timeline, err := useCase.GetTimeline(user.ID)
+	timeline, err := useCase.GetTimeline(context.Background(), user.ID, repository.DefaultTimelineOptions())
 
 	// Assert
 	if err != nil {
@@ -294,3 +393,1096 @@ func TestGetTweetByIDNotFound(t *testing.T) {
 		t.Errorf("Expected ErrTweetNotFound, got %v", err)
 	}
 }
+
+func TestCreateTweetUsesClock(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	start := time.Date(2024, time.January, 2, 15, 4, 5, 0, time.UTC)
+	clock := usecase.NewFakeClock(start)
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithClock(clock))
+
+	user := entity.NewUser("user123", "testuser")
+	userRepo.Save(user)
+
+	// Act
+	first, err := useCase.CreateTweet(user.ID, "First tweet")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	clock.Advance(time.Second)
+	second, err := useCase.CreateTweet(user.ID, "Second tweet")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Assert
+	if !first.CreatedAt.Equal(start) {
+		t.Errorf("Expected first tweet CreatedAt to be %v, got %v", start, first.CreatedAt)
+	}
+
+	if !second.CreatedAt.Equal(start.Add(time.Second)) {
+		t.Errorf("Expected second tweet CreatedAt to be %v, got %v", start.Add(time.Second), second.CreatedAt)
+	}
+
+	if !second.CreatedAt.After(first.CreatedAt) {
+		t.Error("Expected second tweet to be created after the first one")
+	}
+}
+
+func TestCreateTweetUsesIDGenerator(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	idGenerator := usecase.NewSequentialIDGenerator("tweet")
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithIDGenerator(idGenerator))
+
+	user := entity.NewUser("user123", "testuser")
+	userRepo.Save(user)
+
+	// Act
+	tweet, err := useCase.CreateTweet(user.ID, "Test tweet")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if tweet.ID != "tweet-1" {
+		t.Errorf("Expected tweet ID to be tweet-1, got %s", tweet.ID)
+	}
+
+	if saved, _ := tweetRepo.FindByID("tweet-1"); saved == nil {
+		t.Error("Expected tweet to be saved under the generated ID")
+	}
+}
+
+func TestCreateTweetPublishesEvent(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	publisher := &MockEventPublisher{}
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithEventPublisher(publisher))
+
+	user := entity.NewUser("user123", "testuser")
+	userRepo.Save(user)
+
+	// Act
+	tweet, err := useCase.CreateTweet(user.ID, "Test tweet")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(publisher.published) != 1 {
+		t.Fatalf("Expected 1 published event, got %d", len(publisher.published))
+	}
+
+	if publisher.published[0] != tweet {
+		t.Error("Expected the created tweet to be published")
+	}
+}
+
+func TestCreateTweetPublishFailure(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	publisher := &MockEventPublisher{err: errors.New("publish failed")}
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithEventPublisher(publisher))
+
+	user := entity.NewUser("user123", "testuser")
+	userRepo.Save(user)
+
+	// Act
+	tweet, err := useCase.CreateTweet(user.ID, "Test tweet")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected a publish failure not to fail tweet creation, got %v", err)
+	}
+
+	if saved, _ := tweetRepo.FindByID(tweet.ID); saved == nil {
+		t.Error("Expected tweet to be saved despite the publish failure")
+	}
+}
+
+func TestCreateTweetFailureDoesNotPublish(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	publisher := &MockEventPublisher{}
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithEventPublisher(publisher))
+
+	// Act
+	_, err := useCase.CreateTweet("nonexistent", "Test tweet")
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+
+	if len(publisher.published) != 0 {
+		t.Errorf("Expected no published events, got %d", len(publisher.published))
+	}
+}
+
+func TestGetConversationReturnsWholeThreadInOrder(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	clock := usecase.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithClock(clock), usecase.WithIDGenerator(usecase.NewSequentialIDGenerator("tweet")))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	userRepo.Save(entity.NewUser("bob", "bob"))
+
+	// Build a 3-level reply chain, plus an unrelated tweet
+	root, _ := useCase.CreateTweet("alice", "Root")
+	clock.Advance(time.Minute)
+	reply, _ := useCase.CreateReply("bob", root.ID, "Reply", "")
+	clock.Advance(time.Minute)
+	nested, err := useCase.CreateReply("alice", reply.ID, "Nested reply", "")
+	if err != nil {
+		t.Fatalf("Failed to create nested reply: %v", err)
+	}
+	clock.Advance(time.Minute)
+	useCase.CreateTweet("bob", "Unrelated")
+
+	// Act: the conversation can be fetched from any tweet in it
+	thread, err := useCase.GetConversation(nested.ID)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if nested.ConversationID != root.ID || nested.InReplyToID != reply.ID {
+		t.Errorf("Expected nested reply in conversation %s replying to %s, got %s replying to %s", root.ID, reply.ID, nested.ConversationID, nested.InReplyToID)
+	}
+	expected := []string{root.ID, reply.ID, nested.ID}
+	ids := tweetIDs(thread)
+	if len(ids) != len(expected) {
+		t.Fatalf("Expected thread %v, got %v", expected, ids)
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Errorf("Expected thread %v, got %v", expected, ids)
+			break
+		}
+	}
+}
+
+func TestCreateReplyToMissingTweet(t *testing.T) {
+	// Arrange
+	userRepo := NewMockUserRepository()
+	useCase := usecase.NewTweetUseCase(NewMockTweetRepository(), userRepo)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+
+	// Act
+	_, err := useCase.CreateReply("alice", "missing", "Reply", "")
+
+	// Assert
+	if err != entity.ErrTweetNotFound {
+		t.Errorf("Expected ErrTweetNotFound, got %v", err)
+	}
+}
+
+func TestCreateTweetDuplicateWithinWindow(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	clock := usecase.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithClock(clock), usecase.WithDuplicateWindow(time.Minute))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+
+	if _, err := useCase.CreateTweet("alice", "Hello"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Act
+	clock.Advance(30 * time.Second)
+	_, err := useCase.CreateTweet("alice", "Hello")
+
+	// Assert
+	if err != entity.ErrDuplicateTweet {
+		t.Errorf("Expected ErrDuplicateTweet, got %v", err)
+	}
+
+	// Different content is still accepted
+	if _, err := useCase.CreateTweet("alice", "Hello again"); err != nil {
+		t.Errorf("Expected no error for different content, got %v", err)
+	}
+}
+
+func TestCreateTweetDuplicateOutsideWindow(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	clock := usecase.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithClock(clock), usecase.WithDuplicateWindow(time.Minute))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+
+	if _, err := useCase.CreateTweet("alice", "Hello"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Act
+	clock.Advance(time.Minute)
+	_, err := useCase.CreateTweet("alice", "Hello")
+
+	// Assert
+	if err != nil {
+		t.Errorf("Expected no error once the window has passed, got %v", err)
+	}
+}
+
+func TestCreateTweetRateLimitReached(t *testing.T) {
+	// Arrange: three tweets allowed per hour, posted ten minutes apart
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	clock := usecase.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithClock(clock), usecase.WithTweetRateLimit(3, time.Hour))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	var first *entity.Tweet
+	for i := range 3 {
+		tweet, err := useCase.CreateTweet("alice", fmt.Sprintf("Tweet %d", i))
+		if err != nil {
+			t.Fatalf("Expected tweet %d to stay under the limit, got %v", i, err)
+		}
+		if first == nil {
+			first = tweet
+		}
+		clock.Advance(10 * time.Minute)
+	}
+
+	// Act
+	_, tweetErr := useCase.CreateTweet("alice", "One too many")
+	_, replyErr := useCase.CreateReply("alice", first.ID, "A reply", "")
+	_, quoteErr := useCase.QuoteTweet("alice", first.ID, "A quote")
+
+	// Assert: every way of posting is limited, and the wait lasts until the first tweet leaves the window
+	var throttled *entity.ThrottledError
+	if !errors.As(tweetErr, &throttled) || !errors.Is(tweetErr, entity.ErrTweetRateLimited) || !errors.Is(tweetErr, entity.ErrThrottled) {
+		t.Fatalf("Expected a throttled ErrTweetRateLimited, got %v", tweetErr)
+	}
+	if throttled.RetryAfter != 30*time.Minute {
+		t.Errorf("Expected to retry after 30m, got %v", throttled.RetryAfter)
+	}
+	if !errors.Is(replyErr, entity.ErrTweetRateLimited) || !errors.Is(quoteErr, entity.ErrTweetRateLimited) {
+		t.Errorf("Expected replies and quotes to be limited too, got %v and %v", replyErr, quoteErr)
+	}
+	if count, _ := tweetRepo.Count(); count != 3 {
+		t.Errorf("Expected no tweet to be stored over the limit, got %d tweets", count)
+	}
+
+	// Other users have their own limit
+	if _, err := useCase.CreateTweet("bob", "Hello"); err != nil {
+		t.Errorf("Expected bob to stay under the limit, got %v", err)
+	}
+}
+
+func TestCreateTweetRateLimitWindowSlides(t *testing.T) {
+	// Arrange: the limit is reached
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	clock := usecase.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithClock(clock), usecase.WithTweetRateLimit(2, time.Hour))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	useCase.CreateTweet("alice", "First")
+	clock.Advance(30 * time.Minute)
+	useCase.CreateTweet("alice", "Second")
+
+	// Act: the first tweet leaves the window
+	clock.Advance(30*time.Minute - time.Second)
+	_, beforeErr := useCase.CreateTweet("alice", "Too early")
+	clock.Advance(time.Second)
+	_, afterErr := useCase.CreateTweet("alice", "Third")
+
+	// Assert
+	if !errors.Is(beforeErr, entity.ErrTweetRateLimited) {
+		t.Errorf("Expected ErrTweetRateLimited while both tweets are in the window, got %v", beforeErr)
+	}
+	if afterErr != nil {
+		t.Errorf("Expected no error once the first tweet left the window, got %v", afterErr)
+	}
+}
+
+func TestImportTweetsCountAgainstRateLimit(t *testing.T) {
+	// Arrange: two tweets allowed per hour, one already posted
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithClock(usecase.NewFakeClock(now)), usecase.WithTweetRateLimit(2, time.Hour))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	if _, err := useCase.CreateTweet("alice", "Posted"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Act
+	results, err := useCase.ImportTweets("alice", []usecase.ImportedTweet{
+		{Content: "Inside the window", CreatedAt: now.Add(-20 * time.Minute)},
+		{Content: "Over the limit", CreatedAt: now.Add(-10 * time.Minute)},
+		{Content: "Before the window", CreatedAt: now.Add(-2 * time.Hour)},
+	})
+	_, createErr := useCase.CreateTweet("alice", "One too many")
+
+	// Assert: rows inside the window fill the limit, older rows are not limited
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if results[0].Err != nil || results[2].Err != nil {
+		t.Errorf("Expected the first and last rows imported, got %v and %v", results[0].Err, results[2].Err)
+	}
+	var throttled *entity.ThrottledError
+	if !errors.As(results[1].Err, &throttled) || !errors.Is(results[1].Err, entity.ErrTweetRateLimited) {
+		t.Fatalf("Expected a throttled ErrTweetRateLimited for the row over the limit, got %v", results[1].Err)
+	}
+	if throttled.RetryAfter != 40*time.Minute {
+		t.Errorf("Expected to retry after 40m, when the imported row leaves the window, got %v", throttled.RetryAfter)
+	}
+	if !errors.Is(createErr, entity.ErrTweetRateLimited) {
+		t.Errorf("Expected imported rows to limit new tweets, got %v", createErr)
+	}
+	if count, _ := tweetRepo.Count(); count != 3 {
+		t.Errorf("Expected 3 tweets stored, got %d", count)
+	}
+}
+
+func TestUpdateTweetWithinEditWindow(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	clock := usecase.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithClock(clock), usecase.WithEditWindow(5*time.Minute))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	tweet, _ := useCase.CreateTweet("alice", "Helo")
+
+	// Act
+	clock.Advance(5 * time.Minute)
+	updated, err := useCase.UpdateTweet("alice", tweet.ID, "Hello")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Content != "Hello" {
+		t.Errorf("Expected content 'Hello', got '%s'", updated.Content)
+	}
+	stored, _ := useCase.GetTweetByID(tweet.ID)
+	if stored.Content != "Hello" {
+		t.Errorf("Expected stored content 'Hello', got '%s'", stored.Content)
+	}
+}
+
+func TestUpdateTweetBumpsUpdatedAt(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := usecase.NewFakeClock(createdAt)
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithClock(clock))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	tweet, _ := useCase.CreateTweet("alice", "Helo")
+	if !tweet.UpdatedAt.Equal(createdAt) {
+		t.Fatalf("Expected UpdatedAt to equal CreatedAt on creation, got %v", tweet.UpdatedAt)
+	}
+
+	// Reading the tweet leaves UpdatedAt alone
+	clock.Advance(time.Minute)
+	read, _ := useCase.GetTweetByID(tweet.ID)
+	if !read.UpdatedAt.Equal(createdAt) {
+		t.Errorf("Expected UpdatedAt unchanged by a read, got %v", read.UpdatedAt)
+	}
+
+	// Act
+	clock.Advance(time.Minute)
+	_, err := useCase.UpdateTweet("alice", tweet.ID, "Hello")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stored, _ := useCase.GetTweetByID(tweet.ID)
+	if !stored.UpdatedAt.Equal(createdAt.Add(2 * time.Minute)) {
+		t.Errorf("Expected UpdatedAt to be the edit time, got %v", stored.UpdatedAt)
+	}
+	if !stored.CreatedAt.Equal(createdAt) {
+		t.Errorf("Expected CreatedAt unchanged by the edit, got %v", stored.CreatedAt)
+	}
+}
+
+func TestUpdateTweetAfterEditWindow(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	clock := usecase.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithClock(clock), usecase.WithEditWindow(5*time.Minute))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	tweet, _ := useCase.CreateTweet("alice", "Helo")
+
+	// Act
+	clock.Advance(5*time.Minute + time.Second)
+	_, err := useCase.UpdateTweet("alice", tweet.ID, "Hello")
+
+	// Assert
+	if err != entity.ErrEditWindowExpired {
+		t.Errorf("Expected ErrEditWindowExpired, got %v", err)
+	}
+	stored, _ := useCase.GetTweetByID(tweet.ID)
+	if stored.Content != "Helo" {
+		t.Errorf("Expected stored content to stay 'Helo', got '%s'", stored.Content)
+	}
+}
+
+func TestUpdateTweetByAnotherUser(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	tweet, _ := useCase.CreateTweet("alice", "Hello")
+
+	// Act
+	_, err := useCase.UpdateTweet("bob", tweet.ID, "Hijacked")
+
+	// Assert
+	if err != entity.ErrNotTweetAuthor {
+		t.Errorf("Expected ErrNotTweetAuthor, got %v", err)
+	}
+}
+
+func TestDeleteTweetByAnotherUser(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	tweet, _ := useCase.CreateTweet("alice", "Hello")
+
+	// Act
+	err := useCase.DeleteTweet("bob", tweet.ID)
+
+	// Assert
+	if err != entity.ErrNotTweetAuthor {
+		t.Errorf("Expected ErrNotTweetAuthor, got %v", err)
+	}
+	if _, err := useCase.GetTweetByID(tweet.ID); err != nil {
+		t.Errorf("Expected the tweet to remain, got %v", err)
+	}
+}
+
+func TestDeleteTweetAsModeratorIgnoresAuthor(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	tweet, _ := useCase.CreateTweet("alice", "Abusive")
+
+	// Act
+	deleted, err := useCase.DeleteTweetAsModerator(tweet.ID)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if deleted.UserID != "alice" {
+		t.Errorf("Expected the deleted tweet to be returned, got %+v", deleted)
+	}
+	if _, err := useCase.GetTweetByID(tweet.ID); err != entity.ErrTweetNotFound {
+		t.Errorf("Expected ErrTweetNotFound after deletion, got %v", err)
+	}
+}
+
+func TestGetTimelineExcludesExpiredTweets(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	clock := usecase.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithClock(clock))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+
+	ephemeral, err := useCase.CreateExpiringTweet("alice", "Gone soon", "", time.Hour)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	permanent, _ := useCase.CreateTweet("alice", "Here to stay")
+
+	// Act
+	clock.Advance(time.Hour)
+	timeline, err := useCase.GetTimeline(context.Background(), "alice", repository.DefaultTimelineOptions())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(timeline) != 1 || timeline[0].ID != permanent.ID {
+		t.Errorf("Expected only the permanent tweet, got %v", tweetIDs(timeline))
+	}
+	// The repository still holds the expired tweet until TTL removes it
+	if stored, _ := tweetRepo.FindByID(ephemeral.ID); stored == nil {
+		t.Error("Expected the expired tweet to still be stored")
+	}
+	if _, err := useCase.GetTweetByID(ephemeral.ID); err != entity.ErrTweetNotFound {
+		t.Errorf("Expected ErrTweetNotFound for the expired tweet, got %v", err)
+	}
+}
+
+// Tweet repository reading timelines through a timeline cache, as the DynamoDB repository does
+type CachingTweetRepository struct {
+	*MockTweetRepository
+	cache *MapTimelineCache
+	// Number of timelines built on a cache miss
+	builds int
+}
+
+func (r *CachingTweetRepository) GetTimeline(ctx context.Context, userID string, opts repository.TimelineOptions) ([]*entity.Tweet, error) {
+	key := opts.CacheKey(userID)
+	if timeline, found, _ := r.cache.GetTimeline(ctx, key); found {
+		return timeline, nil
+	}
+	r.builds++
+	timeline, err := r.MockTweetRepository.GetTimeline(ctx, userID, opts)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.SetTimeline(ctx, key, timeline)
+	return timeline, nil
+}
+
+func TestGetTimelineInvalidatesCacheAfterForgettingDeletedFollows(t *testing.T) {
+	// Arrange: alice's cached timeline holds the tweet of bob, deleted without alice's follow being removed
+	timelineCache := NewMapTimelineCache()
+	tweetRepo := &CachingTweetRepository{MockTweetRepository: NewMockTweetRepository(), cache: timelineCache}
+	userRepo := NewMockUserRepository()
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithTimelineCache(timelineCache))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	userRepo.Follow("alice", "bob")
+	tweet, _ := useCase.CreateTweet("bob", "Hello")
+	ctx := context.Background()
+	useCase.GetTimeline(ctx, "alice", repository.DefaultTimelineOptions())
+	userRepo.Delete("bob")
+	tweetRepo.Delete(tweet.ID)
+
+	// Act
+	cached, err := useCase.GetTimeline(ctx, "alice", repository.DefaultTimelineOptions())
+	rebuilt, rebuiltErr := useCase.GetTimeline(ctx, "alice", repository.DefaultTimelineOptions())
+
+	// Assert
+	if err != nil || rebuiltErr != nil {
+		t.Fatalf("Expected no errors, got %v and %v", err, rebuiltErr)
+	}
+	if len(cached) != 0 || len(rebuilt) != 0 {
+		t.Errorf("Expected bob's tweet to be left out, got %v and %v", tweetIDs(cached), tweetIDs(rebuilt))
+	}
+	if alice, _ := userRepo.FindByID("alice"); alice.IsFollowing("bob") {
+		t.Error("Expected the follow of the deleted user to be removed")
+	}
+	if tweetRepo.builds != 2 {
+		t.Errorf("Expected the timeline to be rebuilt after the cache was invalidated, got %d builds", tweetRepo.builds)
+	}
+	for key, timeline := range timelineCache.timelines {
+		if len(timeline) != 0 {
+			t.Errorf("Expected no cached timeline to keep bob's tweet, got %v under %s", tweetIDs(timeline), key)
+		}
+	}
+}
+
+func TestCountTweetsByUserMatchesGetTweetsByUser(t *testing.T) {
+	// Arrange
+	userRepo := NewMockUserRepository()
+	clock := usecase.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	useCase := usecase.NewTweetUseCase(NewMockTweetRepository(), userRepo, usecase.WithClock(clock))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	useCase.CreateTweet("alice", "First")
+	useCase.CreateTweet("alice", "Second")
+	useCase.CreateExpiringTweet("alice", "Gone soon", "", time.Hour)
+	useCase.CreateTweet("bob", "Not alice's")
+
+	for _, advance := range []time.Duration{0, time.Hour} {
+		// Act
+		clock.Advance(advance)
+		count, err := useCase.CountTweetsByUser("alice")
+		tweets, _ := useCase.GetTweetsByUser("alice")
+
+		// Assert: expired tweets are left out of both
+		if err != nil || count != len(tweets) {
+			t.Errorf("After %v: expected the count to match the %d listed tweets, got %d and %v", advance, len(tweets), count, err)
+		}
+	}
+	if _, err := useCase.CountTweetsByUser("nonexistent"); err != entity.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound for an unknown user, got %v", err)
+	}
+}
+
+func TestCreateTweetsWithLangAndFilterByIt(t *testing.T) {
+	// Arrange
+	userRepo := NewMockUserRepository()
+	useCase := usecase.NewTweetUseCase(NewMockTweetRepository(), userRepo)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+
+	// Act
+	spanish, err := useCase.CreateExpiringTweet("alice", "Hola", "ES", 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	reply, _ := useCase.CreateReply("alice", spanish.ID, "Hello", "en")
+	useCase.CreateTweet("alice", "Unknown")
+	_, invalidErr := useCase.CreateExpiringTweet("alice", "Hallo", "deu", 0)
+	inSpanish, _ := useCase.GetAllTweetsInLang("es")
+
+	// Assert
+	if spanish.Lang != "es" || reply.Lang != "en" {
+		t.Errorf("Expected languages es and en, got %q and %q", spanish.Lang, reply.Lang)
+	}
+	if invalidErr != entity.ErrInvalidLang {
+		t.Errorf("Expected ErrInvalidLang, got %v", invalidErr)
+	}
+	if len(inSpanish) != 1 || inSpanish[0].ID != spanish.ID {
+		t.Errorf("Expected only the Spanish tweet, got %v", inSpanish)
+	}
+}
+
+func TestProfanityFilterRejectMode(t *testing.T) {
+	// Arrange
+	userRepo := NewMockUserRepository()
+	useCase := usecase.NewTweetUseCase(NewMockTweetRepository(), userRepo,
+		usecase.WithProfanityFilter(entity.DefaultProfanityList(), entity.ProfanityReject))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	root, _ := useCase.CreateTweet("alice", "Greetings from Scunthorpe")
+
+	// Act
+	_, createErr := useCase.CreateTweet("alice", "Oh shit")
+	_, replyErr := useCase.CreateReply("alice", root.ID, "Mierda", "")
+	_, updateErr := useCase.UpdateTweet("alice", root.ID, "Fuck Scunthorpe")
+	_, validateErr := useCase.ValidateContent("puta madre")
+
+	// Assert
+	if root == nil {
+		t.Fatal("Expected the tweet without profane words to be created")
+	}
+	for name, err := range map[string]error{"create": createErr, "reply": replyErr, "update": updateErr, "validate": validateErr} {
+		if err != entity.ErrProfanity {
+			t.Errorf("%s: expected ErrProfanity, got %v", name, err)
+		}
+	}
+}
+
+func TestProfanityFilterMaskMode(t *testing.T) {
+	// Arrange
+	userRepo := NewMockUserRepository()
+	useCase := usecase.NewTweetUseCase(NewMockTweetRepository(), userRepo,
+		usecase.WithProfanityFilter(entity.NewProfanityList([]string{"darn"}), entity.ProfanityMask))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+
+	// Act
+	tweet, err := useCase.CreateTweet("alice", "Darn it, darned printer")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tweet.Content != "**** it, darned printer" {
+		t.Errorf("Expected only the whole word to be masked, got %q", tweet.Content)
+	}
+}
+
+// Fetches previews from a fixed set of pages, failing for any other URL
+type StubLinkPreviewFetcher map[string]*entity.LinkPreview
+
+func (f StubLinkPreviewFetcher) Fetch(ctx context.Context, url string) (*entity.LinkPreview, error) {
+	if preview, ok := f[url]; ok {
+		return preview, nil
+	}
+	return nil, errors.New("page not found")
+}
+
+// Waits for the stored tweet to satisfy the condition, failing the test after a second
+func waitForTweet(t *testing.T, tweetRepo repository.TweetRepository, id string, condition func(*entity.Tweet) bool) *entity.Tweet {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		tweet, _ := tweetRepo.FindByID(id)
+		if tweet != nil && condition(tweet) {
+			return tweet
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for tweet %s, got %+v", id, tweet)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCreateTweetStoresLinkPreview(t *testing.T) {
+	// Arrange
+	userRepo := memory.NewUserRepository()
+	tweetRepo := memory.NewTweetRepository(userRepo)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	preview := &entity.LinkPreview{URL: "https://go.dev/blog", Title: "The Go Blog"}
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo,
+		usecase.WithLinkPreviews(StubLinkPreviewFetcher{preview.URL: preview}))
+
+	// Act
+	tweet, err := useCase.CreateTweet("alice", "Read https://go.dev/blog.")
+
+	// Assert: the tweet is returned at once and gets its preview shortly after
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tweet.LinkPreview != nil {
+		t.Error("Expected the tweet to be returned before its preview is fetched")
+	}
+	stored := waitForTweet(t, tweetRepo, tweet.ID, func(tweet *entity.Tweet) bool { return tweet.LinkPreview != nil })
+	if *stored.LinkPreview != *preview || stored.Content != tweet.Content {
+		t.Errorf("Expected the preview to be stored on the unchanged tweet, got %+v", stored)
+	}
+
+	// Editing the tweet to link elsewhere drops the old preview
+	edited, err := useCase.UpdateTweet("alice", tweet.ID, "Read https://example.com instead")
+	if err != nil || edited.LinkPreview != nil {
+		t.Errorf("Expected the edit to drop the preview, got %+v and %v", edited, err)
+	}
+}
+
+func TestCreateTweetWithFailingLinkPreview(t *testing.T) {
+	// Arrange
+	userRepo := memory.NewUserRepository()
+	tweetRepo := memory.NewTweetRepository(userRepo)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithLinkPreviews(StubLinkPreviewFetcher{}))
+
+	// Act
+	tweet, err := useCase.CreateTweet("alice", "Broken link http://unreachable.invalid/page")
+
+	// Assert: the failure never reaches the author and the tweet stays without preview
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if stored, _ := tweetRepo.FindByID(tweet.ID); stored == nil || stored.LinkPreview != nil {
+		t.Errorf("Expected the tweet to be stored without preview, got %+v", stored)
+	}
+}
+
+func TestCreateExpiringTweetRejectsNegativeDuration(t *testing.T) {
+	// Arrange
+	userRepo := NewMockUserRepository()
+	useCase := usecase.NewTweetUseCase(NewMockTweetRepository(), userRepo)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+
+	// Act
+	_, err := useCase.CreateExpiringTweet("alice", "Hello", "", -time.Minute)
+
+	// Assert
+	if err != entity.ErrInvalidExpiration {
+		t.Errorf("Expected ErrInvalidExpiration, got %v", err)
+	}
+}
+
+func TestCustomMaxTweetLength(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithMaxTweetLength(10))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+
+	// Act
+	tweet, err := useCase.CreateTweet("alice", strings.Repeat("a", 10))
+	_, createErr := useCase.CreateTweet("alice", strings.Repeat("a", 11))
+	_, replyErr := useCase.CreateReply("alice", tweet.ID, strings.Repeat("b", 11), "")
+	_, editErr := useCase.UpdateTweet("alice", tweet.ID, strings.Repeat("c", 11))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected a tweet at the limit to be accepted, got %v", err)
+	}
+	for name, err := range map[string]error{"create": createErr, "reply": replyErr, "edit": editErr} {
+		if !errors.Is(err, entity.ErrTweetTooLong) {
+			t.Errorf("Expected ErrTweetTooLong on %s, got %v", name, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), "10") {
+			t.Errorf("Expected the %s error to report the limit, got %q", name, err.Error())
+		}
+	}
+}
+
+func TestCustomMaxTweetLengthAboveDefault(t *testing.T) {
+	// Arrange
+	userRepo := NewMockUserRepository()
+	useCase := usecase.NewTweetUseCase(NewMockTweetRepository(), userRepo, usecase.WithMaxTweetLength(500))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+
+	// Act
+	_, err := useCase.CreateTweet("alice", strings.Repeat("a", entity.MaxTweetLength+1))
+
+	// Assert
+	if err != nil {
+		t.Errorf("Expected no error below the custom limit, got %v", err)
+	}
+}
+
+func TestImportTweetsMixedEntries(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	publisher := &MockEventPublisher{}
+	now := time.Date(2024, time.January, 2, 15, 4, 5, 0, time.UTC)
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithClock(usecase.NewFakeClock(now)), usecase.WithEventPublisher(publisher))
+
+	user := entity.NewUser("user123", "testuser")
+	userRepo.Save(user)
+
+	// Act
+	past := now.Add(-48 * time.Hour)
+	results, err := useCase.ImportTweets(user.ID, []usecase.ImportedTweet{
+		{Content: "Old tweet", CreatedAt: past},
+		{Content: "   ", CreatedAt: past},
+		{Content: "From the future", CreatedAt: now.Add(time.Hour)},
+		{Content: "No timestamp"},
+		{Content: "Same content as before", CreatedAt: past.Add(time.Minute)},
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("Expected a result per entry, got %d", len(results))
+	}
+	if results[0].Err != nil || !results[0].Tweet.CreatedAt.Equal(past) {
+		t.Errorf("Expected the first tweet imported with its original time, got %+v", results[0])
+	}
+	if results[1].Err != entity.ErrEmptyTweet {
+		t.Errorf("Expected ErrEmptyTweet for blank content, got %v", results[1].Err)
+	}
+	if results[2].Err != entity.ErrInvalidCreatedAt || results[3].Err != entity.ErrInvalidCreatedAt {
+		t.Errorf("Expected ErrInvalidCreatedAt for future and missing times, got %v and %v", results[2].Err, results[3].Err)
+	}
+	if results[4].Err != nil {
+		t.Errorf("Expected the last tweet imported, got %v", results[4].Err)
+	}
+	if tweetRepo.saveAllCalls != 1 || len(tweetRepo.tweets) != 2 {
+		t.Errorf("Expected the 2 valid tweets saved in one batch, got %d calls and %d tweets", tweetRepo.saveAllCalls, len(tweetRepo.tweets))
+	}
+	if len(publisher.published) != 2 {
+		t.Errorf("Expected 2 published events, got %d", len(publisher.published))
+	}
+}
+
+func TestImportTweetsProfanityRejectMode(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	now := time.Date(2024, time.January, 2, 15, 4, 5, 0, time.UTC)
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithClock(usecase.NewFakeClock(now)),
+		usecase.WithProfanityFilter(entity.NewProfanityList([]string{"darn"}), entity.ProfanityReject))
+	userRepo.Save(entity.NewUser("user123", "testuser"))
+
+	// Act
+	past := now.Add(-48 * time.Hour)
+	results, err := useCase.ImportTweets("user123", []usecase.ImportedTweet{
+		{Content: "Darn it", CreatedAt: past},
+		{Content: "Clean tweet", CreatedAt: past},
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if results[0].Err != entity.ErrProfanity || results[0].Tweet != nil {
+		t.Errorf("Expected ErrProfanity for the profane entry, got %+v", results[0])
+	}
+	if results[1].Err != nil {
+		t.Errorf("Expected the clean entry imported, got %v", results[1].Err)
+	}
+	if len(tweetRepo.tweets) != 1 {
+		t.Errorf("Expected only the clean tweet stored, got %d tweets", len(tweetRepo.tweets))
+	}
+}
+
+func TestImportTweetsProfanityMaskMode(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	now := time.Date(2024, time.January, 2, 15, 4, 5, 0, time.UTC)
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithClock(usecase.NewFakeClock(now)),
+		usecase.WithProfanityFilter(entity.NewProfanityList([]string{"darn"}), entity.ProfanityMask))
+	userRepo.Save(entity.NewUser("user123", "testuser"))
+
+	// Act
+	results, err := useCase.ImportTweets("user123", []usecase.ImportedTweet{
+		{Content: "Darn it", CreatedAt: now.Add(-48 * time.Hour)},
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if results[0].Err != nil {
+		t.Fatalf("Expected the entry imported, got %v", results[0].Err)
+	}
+	stored, _ := tweetRepo.FindByID(results[0].Tweet.ID)
+	if stored.Content != "**** it" {
+		t.Errorf("Expected the profane word masked, got %q", stored.Content)
+	}
+}
+
+func TestImportTweetsTooMany(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo)
+	userRepo.Save(entity.NewUser("user123", "testuser"))
+
+	// Act
+	_, err := useCase.ImportTweets("user123", make([]usecase.ImportedTweet, usecase.MaxImportTweets+1))
+
+	// Assert
+	if err != entity.ErrImportTooLarge {
+		t.Errorf("Expected ErrImportTooLarge, got %v", err)
+	}
+}
+
+// Impression counter whose calls always fail, as when Redis is unavailable
+type FailingImpressionCounter struct{}
+
+func (FailingImpressionCounter) RecordImpression(ctx context.Context, tweetID, viewerID string) (int64, error) {
+	return 0, errors.New("redis unavailable")
+}
+
+func (FailingImpressionCounter) TakeImpressions(ctx context.Context) (map[string]int64, error) {
+	return nil, errors.New("redis unavailable")
+}
+
+func (FailingImpressionCounter) RestoreImpressions(ctx context.Context, counts map[string]int64) error {
+	return errors.New("redis unavailable")
+}
+
+// Impression counter keeping pending counts in a map
+type MapImpressionCounter struct {
+	counts map[string]int64
+}
+
+func (c *MapImpressionCounter) RecordImpression(ctx context.Context, tweetID, viewerID string) (int64, error) {
+	c.counts[tweetID]++
+	return c.counts[tweetID], nil
+}
+
+func (c *MapImpressionCounter) TakeImpressions(ctx context.Context) (map[string]int64, error) {
+	taken := c.counts
+	c.counts = make(map[string]int64)
+	return taken, nil
+}
+
+func (c *MapImpressionCounter) RestoreImpressions(ctx context.Context, counts map[string]int64) error {
+	for tweetID, count := range counts {
+		c.counts[tweetID] += count
+	}
+	return nil
+}
+
+func TestFlushImpressionsStoresPendingCounts(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	counter := &MapImpressionCounter{counts: make(map[string]int64)}
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithImpressionCounter(counter))
+	user := entity.NewUser("user123", "testuser")
+	userRepo.Save(user)
+	tweet, _ := entity.NewTweet("tweet123", user.ID, "Test tweet")
+	tweetRepo.Save(tweet)
+	ctx := context.Background()
+	useCase.ViewTweet(ctx, tweet.ID, "user:a")
+	useCase.ViewTweet(ctx, tweet.ID, "user:b")
+	counter.counts["deleted"] = 3
+
+	// Act
+	flushed, err := useCase.FlushImpressions(ctx)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if flushed != 1 || tweet.Impressions != 2 {
+		t.Errorf("Expected 2 impressions stored with the tweet, got %d flushed and %d stored", flushed, tweet.Impressions)
+	}
+	if len(counter.counts) != 0 {
+		t.Errorf("Expected the counts of deleted tweets to be dropped, got %v", counter.counts)
+	}
+	_, impressions, _ := useCase.ViewTweet(ctx, tweet.ID, "user:c")
+	if impressions != 3 {
+		t.Errorf("Expected the stored and the pending impressions together, got %d", impressions)
+	}
+}
+
+func TestViewTweetSkipsCountingWhenCounterFails(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithImpressionCounter(FailingImpressionCounter{}))
+	user := entity.NewUser("user123", "testuser")
+	userRepo.Save(user)
+	tweet, _ := entity.NewTweet("tweet123", user.ID, "Test tweet")
+	tweetRepo.Save(tweet)
+
+	// Act
+	viewed, impressions, err := useCase.ViewTweet(context.Background(), tweet.ID, "user:viewer")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if viewed == nil || viewed.ID != tweet.ID {
+		t.Errorf("Expected the tweet, got %v", viewed)
+	}
+	if impressions != 0 {
+		t.Errorf("Expected no impressions counted, got %d", impressions)
+	}
+}
+
+func TestQuoteTweetMissingQuoted(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo)
+	userRepo.Save(entity.NewUser("user123", "testuser"))
+
+	// Act
+	quote, err := useCase.QuoteTweet("user123", "nonexistent", "Look at this")
+
+	// Assert
+	if err != entity.ErrTweetNotFound {
+		t.Errorf("Expected ErrTweetNotFound, got %v", err)
+	}
+	if quote != nil {
+		t.Errorf("Expected no quote, got %v", quote)
+	}
+}
+
+func TestQuoteTweet(t *testing.T) {
+	// Arrange
+	tweetRepo := NewMockTweetRepository()
+	userRepo := NewMockUserRepository()
+	useCase := usecase.NewTweetUseCase(tweetRepo, userRepo)
+	userRepo.Save(entity.NewUser("user123", "testuser"))
+	userRepo.Save(entity.NewUser("user456", "otheruser"))
+	quoted, _ := entity.NewTweet("tweet123", "user456", "Original")
+	tweetRepo.Save(quoted)
+
+	// Act
+	quote, err := useCase.QuoteTweet("user123", quoted.ID, "Look at this")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if quote.QuotedTweetID != quoted.ID || quote.Content != "Look at this" {
+		t.Errorf("Expected a quote of %s, got %+v", quoted.ID, quote)
+	}
+	if quote.ConversationID != quote.ID || quote.InReplyToID != "" {
+		t.Errorf("Expected the quote to start its own conversation, got %+v", quote)
+	}
+}
@@ -2,33 +2,60 @@ package usecase
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"log/slog"
+	"sort"
 
 	"github.com/develpudu/go-challenge/domain/entity"
 	"github.com/develpudu/go-challenge/domain/repository"
 	"github.com/develpudu/go-challenge/infrastructure/cache"
-	"github.com/google/uuid"
 )
 
 // Implements the user use cases
 type UserUseCase struct {
 	userRepository repository.UserRepository
 	timelineCache  cache.TimelineCache
+	userCache      cache.UserCache
+	idGenerator    IDGenerator
+	// Usernames that cannot be registered
+	reservedUsernames entity.ReservedUsernames
+	// Rebuilds timelines after follows, nil when warming is disabled
+	timelineWarmer *timelineWarmer
+	// Blocks between users, nil when follows ignore blocks
+	blockRepository repository.BlockRepository
+	// Notifies followed users of their new followers, nil when notifications are disabled
+	notifier *notifier
 }
 
 // Creates a new user use case
-func NewUserUseCase(userRepository repository.UserRepository, timelineCache cache.TimelineCache) *UserUseCase {
-	return &UserUseCase{
-		userRepository: userRepository,
-		timelineCache:  timelineCache,
+func NewUserUseCase(userRepository repository.UserRepository, timelineCache cache.TimelineCache, opts ...Option) *UserUseCase {
+	o := newOptions(opts)
+	uc := &UserUseCase{
+		userRepository:    userRepository,
+		timelineCache:     timelineCache,
+		userCache:         o.userCache,
+		idGenerator:       o.idGenerator,
+		reservedUsernames: o.reservedUsernames,
+		blockRepository:   o.blockRepository,
+		notifier:          newNotifier(o.notificationRepository, o.clock),
 	}
+	if o.timelineBuilder != nil {
+		uc.timelineWarmer = newTimelineWarmer(o.timelineBuilder)
+	}
+	return uc
 }
 
 // Creates a new user
+// Returns ErrUsernameReserved if the username is reserved and ErrUsernameTaken if
+// another user has it, in any case; the username keeps its casing for display
 func (uc *UserUseCase) CreateUser(username string) (*entity.User, error) {
+	if uc.reservedUsernames.Contains(username) {
+		return nil, entity.ErrUsernameReserved
+	}
+
 	// Generate a unique ID for the user
-	userID := uuid.New().String()
+	userID := uc.idGenerator.NewID()
 
 	// Create a new user
 	user := entity.NewUser(userID, username)
@@ -39,66 +66,148 @@ func (uc *UserUseCase) CreateUser(username string) (*entity.User, error) {
 		return nil, err
 	}
 
+	// Forget any earlier lookup that found no user with this ID
+	if uc.userCache != nil {
+		if err := uc.userCache.ClearUserMissing(context.Background(), user.ID); err != nil {
+			slog.Warn("Failed to clear missing user marker", "userID", user.ID, "error", err)
+		}
+	}
+
 	return user, nil
 }
 
 // Retrieves a user by ID
+// Lookups of missing users are remembered briefly so repeated ones skip the repository
 func (uc *UserUseCase) GetUser(userID string) (*entity.User, error) {
+	ctx := context.Background()
+	if uc.userCache != nil {
+		if missing, err := uc.userCache.IsUserMissing(ctx, userID); err == nil && missing {
+			return nil, entity.ErrUserNotFound
+		}
+	}
+
 	user, err := uc.userRepository.FindByID(userID)
 	if err != nil {
 		return nil, err
 	}
 	if user == nil {
+		if uc.userCache != nil {
+			if err := uc.userCache.MarkUserMissing(ctx, userID); err != nil {
+				slog.Warn("Failed to cache missing user", "userID", userID, "error", err)
+			}
+		}
 		return nil, entity.ErrUserNotFound
 	}
 
 	return user, nil
 }
 
-// Makes a user follow another user
-func (uc *UserUseCase) FollowUser(followerID, followedID string) error {
+// Retrieves a user's profile as seen by the viewer
+// Deactivated users are reported as not found to everyone but themselves
+func (uc *UserUseCase) GetProfile(viewerID, userID string) (*entity.User, error) {
+	user, err := uc.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Active && viewerID != user.ID {
+		return nil, entity.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// Deactivates a user's account, hiding their profile and tweets until they reactivate it
+// Deactivating an inactive account succeeds
+func (uc *UserUseCase) DeactivateUser(userID string) error {
+	return uc.setActive(userID, false)
+}
+
+// Reactivates a deactivated account, restoring its profile and tweets
+// Reactivating an active account succeeds
+func (uc *UserUseCase) ReactivateUser(userID string) error {
+	return uc.setActive(userID, true)
+}
+
+// Deletes a user's account for good
+// The user is first removed from the follows of each of their followers, whose cached timelines
+// are invalidated so they stop showing the user's tweets, and their own follows are removed
+// so no follow outlives them. Their tweets are kept but no longer appear in timelines
+func (uc *UserUseCase) DeleteUser(userID string) error {
 	ctx := context.Background()
-	// Check if both users exist
-	follower, err := uc.userRepository.FindByID(followerID)
+	user, err := uc.userRepository.FindByID(userID)
 	if err != nil {
 		return err
 	}
-	if follower == nil {
+	if user == nil {
 		return entity.ErrUserNotFound
 	}
 
-	followed, err := uc.userRepository.FindByID(followedID)
+	followers, err := uc.userRepository.FindFollowers(userID)
 	if err != nil {
 		return err
 	}
-	if followed == nil {
-		return entity.ErrUserNotFound
+	followerIDs := make([]string, 0, len(followers))
+	for _, follower := range followers {
+		if err := uc.unfollow(ctx, follower.ID, userID); err != nil && err != entity.ErrNotFollowing {
+			uc.invalidateTimelines(ctx, "DeleteUser", followerIDs...)
+			return err
+		}
+		followerIDs = append(followerIDs, follower.ID)
+	}
+	uc.invalidateTimelines(ctx, "DeleteUser", followerIDs...)
+	for _, followedID := range user.GetFollowing() {
+		if err := uc.unfollow(ctx, userID, followedID); err != nil && err != entity.ErrNotFollowing {
+			return err
+		}
+	}
+
+	if err := uc.userRepository.Delete(userID); err != nil {
+		return err
 	}
+	uc.invalidateTimelines(ctx, "DeleteUser", userID)
+	slog.InfoContext(ctx, "User deleted", "userID", userID, "followers", len(followers))
+	return nil
+}
 
-	// Make follower follow followed
-	err = follower.Follow(followedID)
+// Stores whether a user's account is active
+func (uc *UserUseCase) setActive(userID string, active bool) error {
+	user, err := uc.GetUser(userID)
 	if err != nil {
 		return err
 	}
+	if user.Active == active {
+		return nil
+	}
 
-	// Update follower in repository
-	if err := uc.userRepository.Update(follower); err != nil {
-		slog.ErrorContext(ctx, "Failed to update follower repository after follow", "followerID", followerID, "followedID", followedID, "error", err)
-		return fmt.Errorf("failed to update follower %s after follow: %w", followerID, err)
+	// Only the flag is written, a full update could undo follows stored since the user was read
+	if err := uc.userRepository.SetActive(userID, active); err != nil {
+		return err
 	}
-	slog.InfoContext(ctx, "User followed another user", "followerID", followerID, "followedID", followedID)
+	slog.Info("User account active state changed", "userID", userID, "active", active)
+	return nil
+}
 
-	// Invalidate follower's timeline cache
-	if uc.timelineCache != nil {
-		if err := uc.timelineCache.InvalidateTimeline(ctx, followerID); err != nil {
-			// Use structured logging for the warning
-			slog.WarnContext(ctx, "Failed to invalidate timeline cache after follow", "followerID", followerID, "followedID", followedID, "error", err)
-		}
-	} else {
-		// Use structured logging for the warning
-		slog.WarnContext(ctx, "Timeline cache is nil in UserUseCase, skipping invalidation on FollowUser")
+// Result of following or unfollowing one user as part of a batch
+type FollowResult struct {
+	FollowedID string
+	Err        error
+}
+
+// Makes a user follow another user
+// Returns ErrBlocked if either user blocks the other
+func (uc *UserUseCase) FollowUser(followerID, followedID string) error {
+	ctx := context.Background()
+	// Check if follower exists
+	if err := uc.checkUserExists(followerID); err != nil {
+		return err
 	}
 
+	if err := uc.follow(ctx, followerID, followedID); err != nil {
+		return err
+	}
+
+	// Invalidate follower's timeline cache, then rebuild it in the background
+	uc.invalidateTimelines(ctx, "FollowUser", followerID)
+	uc.warmTimeline(followerID)
 	return nil
 }
 
@@ -106,54 +215,333 @@ func (uc *UserUseCase) FollowUser(followerID, followedID string) error {
 func (uc *UserUseCase) UnfollowUser(followerID, followedID string) error {
 	ctx := context.Background()
 	// Check if follower exists
-	follower, err := uc.userRepository.FindByID(followerID)
+	if err := uc.checkUserExists(followerID); err != nil {
+		return err
+	}
+
+	if err := uc.unfollow(ctx, followerID, followedID); err != nil {
+		return err
+	}
+
+	// Invalidate follower's timeline cache
+	uc.invalidateTimelines(ctx, "UnfollowUser", followerID)
+	return nil
+}
+
+// Removes the follows between two users in both directions, as when one blocks the other
+// Missing follows are skipped; the timelines of the users who stopped following are invalidated once
+func (uc *UserUseCase) RemoveFollowsBetween(userID, otherID string) error {
+	ctx := context.Background()
+	var unfollowers []string
+	var err error
+	for _, pair := range [][2]string{{userID, otherID}, {otherID, userID}} {
+		err = uc.unfollow(ctx, pair[0], pair[1])
+		if err == entity.ErrNotFollowing {
+			err = nil
+			continue
+		}
+		if err != nil {
+			break
+		}
+		unfollowers = append(unfollowers, pair[0])
+	}
+
+	if len(unfollowers) > 0 {
+		uc.invalidateTimelines(ctx, "RemoveFollowsBetween", unfollowers...)
+	}
+	return err
+}
+
+// Makes a user follow several users, returning one result per ID in the same order
+// Failures such as self-follows or unknown users are reported per item without stopping the batch.
+// The follower's timeline cache is invalidated once if any follow succeeded.
+func (uc *UserUseCase) FollowMany(followerID string, followedIDs []string) ([]FollowResult, error) {
+	ctx := context.Background()
+	// Check if follower exists
+	if err := uc.checkUserExists(followerID); err != nil {
+		return nil, err
+	}
+
+	results := make([]FollowResult, len(followedIDs))
+	changed := false
+	for i, followedID := range followedIDs {
+		err := uc.follow(ctx, followerID, followedID)
+		results[i] = FollowResult{FollowedID: followedID, Err: err}
+		changed = changed || err == nil
+	}
+
+	if changed {
+		uc.invalidateTimelines(ctx, "FollowMany", followerID)
+		uc.warmTimeline(followerID)
+	}
+	return results, nil
+}
+
+// Makes a user unfollow several users, returning one result per ID in the same order
+// Failures such as unknown users are reported per item without stopping the batch.
+// The follower's timeline cache is invalidated once if any unfollow succeeded.
+func (uc *UserUseCase) UnfollowMany(followerID string, followedIDs []string) ([]FollowResult, error) {
+	ctx := context.Background()
+	// Check if follower exists
+	if err := uc.checkUserExists(followerID); err != nil {
+		return nil, err
+	}
+
+	results := make([]FollowResult, len(followedIDs))
+	changed := false
+	for i, followedID := range followedIDs {
+		err := uc.checkFollowTarget(followerID, followedID)
+		if err == nil {
+			err = uc.unfollow(ctx, followerID, followedID)
+		}
+		results[i] = FollowResult{FollowedID: followedID, Err: err}
+		changed = changed || err == nil
+	}
+
+	if changed {
+		uc.invalidateTimelines(ctx, "UnfollowMany", followerID)
+	}
+	return results, nil
+}
+
+// Retrieves the profile of the user with a username, in any case, as seen by the viewer
+func (uc *UserUseCase) GetProfileByUsername(viewerID, username string) (*entity.User, error) {
+	user, err := uc.userRepository.FindByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || (!user.Active && viewerID != user.ID) {
+		return nil, entity.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// Returns ErrUserNotFound if the user does not exist
+func (uc *UserUseCase) checkUserExists(userID string) error {
+	user, err := uc.userRepository.FindByID(userID)
 	if err != nil {
 		return err
 	}
-	if follower == nil {
+	if user == nil {
 		return entity.ErrUserNotFound
 	}
+	return nil
+}
 
-	// Make follower unfollow followed
-	follower.Unfollow(followedID)
+// Checks that the followed user exists and differs from the follower
+func (uc *UserUseCase) checkFollowTarget(followerID, followedID string) error {
+	if err := uc.checkUserExists(followedID); err != nil {
+		return err
+	}
 
-	// Update follower in repository
-	if err := uc.userRepository.Update(follower); err != nil {
-		slog.ErrorContext(ctx, "Failed to update follower repository after unfollow", "followerID", followerID, "followedID", followedID, "error", err)
-		return fmt.Errorf("failed to update follower %s after unfollow: %w", followerID, err)
+	// Validate the follow against the domain rules
+	if followerID == followedID {
+		return entity.ErrCannotFollowSelf
 	}
-	slog.InfoContext(ctx, "User unfollowed another user", "followerID", followerID, "followedID", followedID)
+	return nil
+}
 
-	// Invalidate follower's timeline cache
-	if uc.timelineCache != nil {
-		if err := uc.timelineCache.InvalidateTimeline(ctx, followerID); err != nil {
-			// Use structured logging for the warning
-			slog.WarnContext(ctx, "Failed to invalidate timeline cache after unfollow", "followerID", followerID, "followedID", followedID, "error", err)
+// Returns ErrBlocked if either user blocks the other
+func (uc *UserUseCase) checkNotBlocked(userID, otherID string) error {
+	if uc.blockRepository == nil {
+		return nil
+	}
+	for _, pair := range [][2]string{{userID, otherID}, {otherID, userID}} {
+		blocked, err := uc.blockRepository.IsBlocked(pair[0], pair[1])
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return entity.ErrBlocked
 		}
-	} else {
-		// Use structured logging for the warning
-		slog.WarnContext(ctx, "Timeline cache is nil in UserUseCase, skipping invalidation on UnfollowUser")
 	}
+	return nil
+}
 
+// Validates and stores a follow relation, without invalidating any cache
+func (uc *UserUseCase) follow(ctx context.Context, followerID, followedID string) error {
+	if err := uc.checkFollowTarget(followerID, followedID); err != nil {
+		return err
+	}
+	if err := uc.checkNotBlocked(followerID, followedID); err != nil {
+		return err
+	}
+
+	// Store the follow relation
+	err := uc.userRepository.Follow(followerID, followedID)
+	if err == entity.ErrAlreadyFollowing {
+		return err
+	}
+	if err == entity.ErrConcurrentUpdate {
+		slog.WarnContext(ctx, "Follow kept conflicting with concurrent updates", "followerID", followerID, "followedID", followedID)
+		return err
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to store follow relation", "followerID", followerID, "followedID", followedID, "error", err)
+		return fmt.Errorf("failed to store follow of %s by %s: %w", followedID, followerID, err)
+	}
+	slog.InfoContext(ctx, "User followed another user", "followerID", followerID, "followedID", followedID)
+	uc.notifier.notify(ctx, followedID, entity.NotificationFollow, followerID, "")
 	return nil
 }
 
-// Retrieves all users that follow a specific user
-func (uc *UserUseCase) GetFollowers(userID string) ([]*entity.User, error) {
+// Removes a follow relation, without invalidating any cache
+func (uc *UserUseCase) unfollow(ctx context.Context, followerID, followedID string) error {
+	// Remove the follow relation
+	err := uc.userRepository.Unfollow(followerID, followedID)
+	if err == entity.ErrNotFollowing {
+		return err
+	}
+	if err == entity.ErrConcurrentUpdate {
+		slog.WarnContext(ctx, "Unfollow kept conflicting with concurrent updates", "followerID", followerID, "followedID", followedID)
+		return err
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to remove follow relation", "followerID", followerID, "followedID", followedID, "error", err)
+		return fmt.Errorf("failed to remove follow of %s by %s: %w", followedID, followerID, err)
+	}
+	slog.InfoContext(ctx, "User unfollowed another user", "followerID", followerID, "followedID", followedID)
+	return nil
+}
+
+// Invalidates every cached timeline variant of the users after their follows changed, in a single cache call
+func (uc *UserUseCase) invalidateTimelines(ctx context.Context, operation string, userIDs ...string) {
+	if uc.timelineCache == nil {
+		// Use structured logging for the warning
+		slog.WarnContext(ctx, "Timeline cache is nil in UserUseCase, skipping invalidation", "operation", operation)
+		return
+	}
+	var keys []string
+	for _, userID := range userIDs {
+		keys = append(keys, repository.TimelineCacheKeys(userID)...)
+	}
+	if err := uc.timelineCache.InvalidateTimelines(ctx, keys); err != nil {
+		// Use structured logging for the warning
+		slog.WarnContext(ctx, "Failed to invalidate timeline cache after follow change", "users", len(userIDs), "operation", operation, "error", err)
+	}
+}
+
+// Rebuilds the timeline of a user in the background, when warming is enabled
+func (uc *UserUseCase) warmTimeline(userID string) {
+	if uc.timelineWarmer != nil {
+		uc.timelineWarmer.warm(userID)
+	}
+}
+
+// Numbers of followers and followed users of a user
+type FollowCounts struct {
+	Followers int
+	Following int
+}
+
+// Returns how many users follow a user and how many the user follows
+// The counts come from the repository without loading the users themselves
+func (uc *UserUseCase) GetFollowCounts(userID string) (*FollowCounts, error) {
+	followers, err := uc.userRepository.CountFollowers(userID)
+	if err != nil {
+		return nil, err
+	}
+	following, err := uc.userRepository.CountFollowing(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &FollowCounts{Followers: followers, Following: following}, nil
+}
+
+// Page of users ordered by ID
+// NextCursor fetches the following page and is empty on the last one
+type UserPage struct {
+	Users      []*entity.User
+	NextCursor string
+}
+
+// Retrieves a page of up to limit users that follow a specific user, ordered by ID
+// An empty cursor starts from the first page; a non-positive limit returns all remaining followers
+func (uc *UserUseCase) GetFollowers(userID string, limit int, cursor string) (*UserPage, error) {
 	// Check if user exists
-	user, err := uc.userRepository.FindByID(userID)
+	if err := uc.checkUserExists(userID); err != nil {
+		return nil, err
+	}
+
+	return fetchUserPage(limit, cursor, func(afterID string, limit int) ([]*entity.User, error) {
+		return uc.userRepository.FindFollowersPage(userID, afterID, limit)
+	})
+}
+
+// Retrieves a page of up to limit users that a specific user follows, ordered by ID
+// An empty cursor starts from the first page; a non-positive limit returns all remaining followed users
+func (uc *UserUseCase) GetFollowing(userID string, limit int, cursor string) (*UserPage, error) {
+	// Check if user exists
+	if err := uc.checkUserExists(userID); err != nil {
+		return nil, err
+	}
+
+	return fetchUserPage(limit, cursor, func(afterID string, limit int) ([]*entity.User, error) {
+		return uc.userRepository.FindFollowingPage(userID, afterID, limit)
+	})
+}
+
+// Fetches one page of users through find, which returns users ordered by ID after afterID
+// One extra user is requested to know whether a next page exists without an extra call
+func fetchUserPage(limit int, cursor string, find func(afterID string, limit int) ([]*entity.User, error)) (*UserPage, error) {
+	afterID, err := decodeCursor(cursor)
 	if err != nil {
 		return nil, err
 	}
-	if user == nil {
-		return nil, entity.ErrUserNotFound
+
+	if limit <= 0 {
+		users, err := find(afterID, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &UserPage{Users: users}, nil
+	}
+
+	users, err := find(afterID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &UserPage{Users: users}
+	if len(users) > limit {
+		page.Users = users[:limit]
+		page.NextCursor = encodeCursor(page.Users[limit-1].ID)
+	}
+	return page, nil
+}
+
+// Encodes the ID of the last returned item as an opaque cursor
+func encodeCursor(lastID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(lastID))
+}
+
+// Decodes a cursor into the ID of the last returned item; an empty cursor starts from the beginning
+func decodeCursor(cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
 	}
+	lastID, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(lastID) == 0 {
+		return "", entity.ErrInvalidCursor
+	}
+	return string(lastID), nil
+}
 
-	return uc.userRepository.FindFollowers(userID)
+// Retrieves all users from the repository
+func (uc *UserUseCase) GetAllUsers() ([]*entity.User, error) {
+	return uc.userRepository.FindAll()
 }
 
-// Retrieves all users that a specific user follows
-func (uc *UserUseCase) GetFollowing(userID string) ([]*entity.User, error) {
+// Retrieves a page of up to limit users, in the repository's listing order
+// An empty cursor starts from the first page; a non-positive limit returns all remaining users
+func (uc *UserUseCase) GetUsers(limit int, cursor string) (*UserPage, error) {
+	return fetchUserPage(limit, cursor, uc.userRepository.FindAllPage)
+}
+
+// Suggests up to n users for a user to follow
+// Candidates followed by the people the user follows (friends-of-friends) rank first, by how many
+// of them follow the candidate; remaining slots are filled with the most-followed users.
+// The user, the users they already follow and the users blocked in either direction are never suggested.
+func (uc *UserUseCase) SuggestFollows(userID string, n int) ([]*entity.User, error) {
 	// Check if user exists
 	user, err := uc.userRepository.FindByID(userID)
 	if err != nil {
@@ -162,11 +550,230 @@ func (uc *UserUseCase) GetFollowing(userID string) ([]*entity.User, error) {
 	if user == nil {
 		return nil, entity.ErrUserNotFound
 	}
+	if n <= 0 {
+		return []*entity.User{}, nil
+	}
+
+	// Users that must not be suggested
+	excluded := map[string]bool{userID: true}
+	for _, followedID := range user.GetFollowing() {
+		excluded[followedID] = true
+	}
+
+	// Count how many of the followed users follow each candidate
+	following, err := uc.userRepository.FindFollowing(userID)
+	if err != nil {
+		return nil, err
+	}
+	mutualCounts := make(map[string]int)
+	for _, followed := range following {
+		for _, candidateID := range followed.GetFollowing() {
+			if !excluded[candidateID] {
+				mutualCounts[candidateID]++
+			}
+		}
+	}
+
+	// Count followers of every user, used to break ties and as the fallback ranking
+	allUsers, err := uc.userRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	followerCounts := make(map[string]int)
+	for _, u := range allUsers {
+		for _, followedID := range u.GetFollowing() {
+			followerCounts[followedID]++
+		}
+	}
+
+	candidates := make([]*entity.User, 0, len(allUsers))
+	for _, u := range allUsers {
+		if !excluded[u.ID] {
+			candidates = append(candidates, u)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if mutualCounts[a.ID] != mutualCounts[b.ID] {
+			return mutualCounts[a.ID] > mutualCounts[b.ID]
+		}
+		if followerCounts[a.ID] != followerCounts[b.ID] {
+			return followerCounts[a.ID] > followerCounts[b.ID]
+		}
+		return a.ID < b.ID
+	})
+
+	// Skip users blocked in either direction, checking blocks only until n suggestions are found
+	suggestions := make([]*entity.User, 0, min(n, len(candidates)))
+	for _, candidate := range candidates {
+		if len(suggestions) == n {
+			break
+		}
+		err := uc.checkNotBlocked(userID, candidate.ID)
+		if err == entity.ErrBlocked {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, candidate)
+	}
+	return suggestions, nil
+}
+
+// Retrieves the users that follow both of the given users, ordered by ID
+func (uc *UserUseCase) MutualFollowers(userID, otherID string) ([]*entity.User, error) {
+	// Check if both users exist
+	for _, id := range []string{userID, otherID} {
+		user, err := uc.userRepository.FindByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, entity.ErrUserNotFound
+		}
+	}
+
+	followers, err := uc.userRepository.FindFollowers(userID)
+	if err != nil {
+		return nil, err
+	}
+	otherFollowers, err := uc.userRepository.FindFollowers(otherID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Intersect both follower sets
+	followerIDs := make(map[string]bool, len(followers))
+	for _, follower := range followers {
+		followerIDs[follower.ID] = true
+	}
+	mutuals := make([]*entity.User, 0)
+	for _, follower := range otherFollowers {
+		if followerIDs[follower.ID] {
+			mutuals = append(mutuals, follower)
+		}
+	}
+	sort.Slice(mutuals, func(i, j int) bool {
+		return mutuals[i].ID < mutuals[j].ID
+	})
+
+	return mutuals, nil
+}
+
+// Retrieves the users followed by the viewer who also follow the target, as social proof for the target
+// Returns at most limit users, ordered by ID, and the number of such users in total; a non-positive limit returns none
+func (uc *UserUseCase) FollowedByFriends(viewerID, targetID string, limit int) ([]*entity.User, int, error) {
+	// Check if both users exist
+	for _, id := range []string{viewerID, targetID} {
+		if err := uc.checkUserExists(id); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	following, err := uc.userRepository.FindFollowing(viewerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	followers, err := uc.userRepository.FindFollowers(targetID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Intersect the viewer's followees with the target's followers
+	followingIDs := make(map[string]bool, len(following))
+	for _, followed := range following {
+		followingIDs[followed.ID] = true
+	}
+	friends := make([]*entity.User, 0)
+	for _, follower := range followers {
+		if followingIDs[follower.ID] {
+			friends = append(friends, follower)
+		}
+	}
+	sort.Slice(friends, func(i, j int) bool {
+		return friends[i].ID < friends[j].ID
+	})
+
+	total := len(friends)
+	if limit <= 0 {
+		return []*entity.User{}, total, nil
+	}
+	if len(friends) > limit {
+		friends = friends[:limit]
+	}
+	return friends, total, nil
+}
+
+// Finds the shortest chain of follows leading from one user to another
+// The returned path starts with the from user and ends with the to user; its length in hops is len(path)-1.
+// The search explores at most maxDepth hops and returns ErrPathNotFound if the to user is not reached.
+func (uc *UserUseCase) ShortestPath(fromID, toID string, maxDepth int) ([]*entity.User, error) {
+	// Check if both users exist
+	from, err := uc.userRepository.FindByID(fromID)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil {
+		return nil, entity.ErrUserNotFound
+	}
+	to, err := uc.userRepository.FindByID(toID)
+	if err != nil {
+		return nil, err
+	}
+	if to == nil {
+		return nil, entity.ErrUserNotFound
+	}
 
-	return uc.userRepository.FindFollowing(userID)
+	if fromID == toID {
+		return []*entity.User{from}, nil
+	}
+
+	// Breadth-first search over follow relations; visited users are never expanded twice,
+	// which also guards against cycles
+	users := map[string]*entity.User{fromID: from}
+	parents := map[string]string{}
+	visited := map[string]bool{fromID: true}
+	frontier := []string{fromID}
+
+	for depth := 0; depth < maxDepth && len(frontier) > 0; depth++ {
+		var next []string
+		for _, userID := range frontier {
+			following, err := uc.userRepository.FindFollowing(userID)
+			if err != nil {
+				return nil, err
+			}
+			for _, followed := range following {
+				if visited[followed.ID] {
+					continue
+				}
+				visited[followed.ID] = true
+				users[followed.ID] = followed
+				parents[followed.ID] = userID
+
+				if followed.ID == toID {
+					return buildPath(users, parents, fromID, toID), nil
+				}
+				next = append(next, followed.ID)
+			}
+		}
+		frontier = next
+	}
+
+	return nil, entity.ErrPathNotFound
 }
 
-// Retrieves all users from the repository
-func (uc *UserUseCase) GetAllUsers() ([]*entity.User, error) {
-	return uc.userRepository.FindAll()
+// Rebuilds the path from the BFS parent links, from the first user to the last
+func buildPath(users map[string]*entity.User, parents map[string]string, fromID, toID string) []*entity.User {
+	path := []*entity.User{users[toID]}
+	for userID := toID; userID != fromID; {
+		userID = parents[userID]
+		path = append(path, users[userID])
+	}
+
+	// Reverse to start from the first user
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
 }
@@ -2,11 +2,15 @@ package usecase_test
 
 import (
 	"context"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/develpudu/go-challenge/application/usecase"
 	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
 	"github.com/develpudu/go-challenge/infrastructure/cache"
+	"github.com/develpudu/go-challenge/infrastructure/repository/memory"
 )
 
 // Mock implementation of the UserRepository interface
@@ -36,6 +40,27 @@ func (r *MockUserRepository) FindByID(id string) (*entity.User, error) {
 	return user, nil
 }
 
+// Retrieves the users with the given IDs, keyed by ID
+func (r *MockUserRepository) FindByIDs(ids []string) (map[string]*entity.User, error) {
+	users := make(map[string]*entity.User, len(ids))
+	for _, id := range ids {
+		if user, exists := r.users[id]; exists {
+			users[id] = user
+		}
+	}
+	return users, nil
+}
+
+// Retrieves a user by their username in any case
+func (r *MockUserRepository) FindByUsername(username string) (*entity.User, error) {
+	for _, user := range r.users {
+		if entity.NormalizeUsername(user.Username) == entity.NormalizeUsername(username) {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
 // Retrieves all users
 func (r *MockUserRepository) FindAll() ([]*entity.User, error) {
 	users := make([]*entity.User, 0, len(r.users))
@@ -51,6 +76,50 @@ func (r *MockUserRepository) Update(user *entity.User) error {
 	return nil
 }
 
+// Stores whether a user's account is active
+func (r *MockUserRepository) SetActive(userID string, active bool) error {
+	user, exists := r.users[userID]
+	if !exists {
+		return entity.ErrUserNotFound
+	}
+	user.Active = active
+	return nil
+}
+
+// Returns the number of users in the repository
+func (r *MockUserRepository) Count() (int, error) {
+	return len(r.users), nil
+}
+
+// Returns the number of follow relations in the repository
+func (r *MockUserRepository) CountFollows() (int, error) {
+	follows := 0
+	for _, user := range r.users {
+		follows += user.FollowingCount()
+	}
+	return follows, nil
+}
+
+// Returns the number of users that follow a specific user
+func (r *MockUserRepository) CountFollowers(userID string) (int, error) {
+	followers := 0
+	for _, user := range r.users {
+		if user.IsFollowing(userID) {
+			followers++
+		}
+	}
+	return followers, nil
+}
+
+// Returns the number of users a specific user follows
+func (r *MockUserRepository) CountFollowing(userID string) (int, error) {
+	user, exists := r.users[userID]
+	if !exists {
+		return 0, entity.ErrUserNotFound
+	}
+	return user.FollowingCount(), nil
+}
+
 // Removes a user from the repository
 func (r *MockUserRepository) Delete(id string) error {
 	delete(r.users, id)
@@ -85,6 +154,54 @@ func (r *MockUserRepository) FindFollowing(userID string) ([]*entity.User, error
 	return following, nil
 }
 
+// Retrieves a page of users ordered by ID
+func (r *MockUserRepository) FindAllPage(afterID string, limit int) ([]*entity.User, error) {
+	users, _ := r.FindAll()
+	return mockPage(users, afterID, limit), nil
+}
+
+// Retrieves a page of followers ordered by ID
+func (r *MockUserRepository) FindFollowersPage(userID, afterID string, limit int) ([]*entity.User, error) {
+	followers, _ := r.FindFollowers(userID)
+	return mockPage(followers, afterID, limit), nil
+}
+
+// Retrieves a page of followed users ordered by ID
+func (r *MockUserRepository) FindFollowingPage(userID, afterID string, limit int) ([]*entity.User, error) {
+	following, _ := r.FindFollowing(userID)
+	return mockPage(following, afterID, limit), nil
+}
+
+// Sorts users by ID and returns up to limit of them after afterID
+func mockPage(users []*entity.User, afterID string, limit int) []*entity.User {
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	page := make([]*entity.User, 0)
+	for _, user := range users {
+		if user.ID > afterID && (limit <= 0 || len(page) < limit) {
+			page = append(page, user)
+		}
+	}
+	return page
+}
+
+// Records that a user follows another user
+func (r *MockUserRepository) Follow(followerID, followedID string) error {
+	follower, exists := r.users[followerID]
+	if !exists {
+		return entity.ErrUserNotFound
+	}
+	return follower.Follow(followedID)
+}
+
+// Removes the follow relation between two users
+func (r *MockUserRepository) Unfollow(followerID, followedID string) error {
+	follower, exists := r.users[followerID]
+	if !exists {
+		return entity.ErrUserNotFound
+	}
+	return follower.Unfollow(followedID)
+}
+
 // Mock implementation of TimelineCache interface
 type MockTimelineCache struct{}
 
@@ -97,6 +214,9 @@ func (m *MockTimelineCache) SetTimeline(ctx context.Context, userID string, time
 func (m *MockTimelineCache) InvalidateTimeline(ctx context.Context, userID string) error {
 	return nil // Do nothing
 }
+func (m *MockTimelineCache) InvalidateTimelines(ctx context.Context, userIDs []string) error {
+	return nil // Do nothing
+}
 
 // Compile-time check
 var _ cache.TimelineCache = (*MockTimelineCache)(nil)
@@ -228,6 +348,93 @@ func TestFollowUserSelf(t *testing.T) {
 	}
 }
 
+func TestFollowUserAlreadyFollowing(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+
+	follower := entity.NewUser("follower", "followerUser")
+	followed := entity.NewUser("followed", "followedUser")
+	follower.Follow(followed.ID)
+	repo.Save(follower)
+	repo.Save(followed)
+
+	// Act
+	err := useCase.FollowUser(follower.ID, followed.ID)
+
+	// Assert
+	if err != entity.ErrAlreadyFollowing {
+		t.Errorf("Expected ErrAlreadyFollowing, got %v", err)
+	}
+}
+
+func TestFollowUserBlockedUserFollowsBlocker(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	blocks := memory.NewBlockRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{}, usecase.WithBlocks(blocks))
+
+	blocker := entity.NewUser("blocker", "blockerUser")
+	blocked := entity.NewUser("blocked", "blockedUser")
+	repo.Save(blocker)
+	repo.Save(blocked)
+	blocks.Block(blocker.ID, blocked.ID)
+
+	// Act
+	err := useCase.FollowUser(blocked.ID, blocker.ID)
+
+	// Assert
+	if err != entity.ErrBlocked {
+		t.Errorf("Expected ErrBlocked, got %v", err)
+	}
+	if blocked.IsFollowing(blocker.ID) {
+		t.Error("Expected the follow not to be stored")
+	}
+}
+
+func TestFollowUserBlockerFollowsBlockedUser(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	blocks := memory.NewBlockRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{}, usecase.WithBlocks(blocks))
+
+	blocker := entity.NewUser("blocker", "blockerUser")
+	blocked := entity.NewUser("blocked", "blockedUser")
+	repo.Save(blocker)
+	repo.Save(blocked)
+	blocks.Block(blocker.ID, blocked.ID)
+
+	// Act
+	err := useCase.FollowUser(blocker.ID, blocked.ID)
+
+	// Assert
+	if err != entity.ErrBlocked {
+		t.Errorf("Expected ErrBlocked, got %v", err)
+	}
+	if blocker.IsFollowing(blocked.ID) {
+		t.Error("Expected the follow not to be stored")
+	}
+}
+
+func TestUnfollowUserNotFollowing(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+
+	follower := entity.NewUser("follower", "followerUser")
+	followed := entity.NewUser("followed", "followedUser")
+	repo.Save(follower)
+	repo.Save(followed)
+
+	// Act
+	err := useCase.UnfollowUser(follower.ID, followed.ID)
+
+	// Assert
+	if err != entity.ErrNotFollowing {
+		t.Errorf("Expected ErrNotFollowing, got %v", err)
+	}
+}
+
 func TestUnfollowUser(t *testing.T) {
 	// Arrange
 	repo := NewMockUserRepository()
@@ -282,7 +489,8 @@ func TestGetFollowers(t *testing.T) {
 	repo.Save(nonFollower)
 
 	// Act
-	followers, err := useCase.GetFollowers(user.ID)
+	page, err := useCase.GetFollowers(user.ID, 0, "")
+	followers := page.Users
 
 	// Assert
 	if err != nil {
@@ -335,7 +543,8 @@ func TestGetFollowing(t *testing.T) {
 	repo.Save(notFollowed)
 
 	// Act
-	following, err := useCase.GetFollowing(user.ID)
+	page, err := useCase.GetFollowing(user.ID, 0, "")
+	following := page.Users
 
 	// Assert
 	if err != nil {
@@ -364,3 +573,820 @@ func TestGetFollowing(t *testing.T) {
 		t.Errorf("Expected notFollowed to not be in the following list, but it was found")
 	}
 }
+
+func TestGetFollowersPagesWithoutDuplicates(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+	ids := []string{"star", "f1", "f2", "f3", "f4", "f5", "f6", "f7"}
+	follows := make(map[string][]string)
+	for _, id := range ids[1:] {
+		follows[id] = []string{"star"}
+	}
+	setupFollowGraph(repo, follows, ids...)
+
+	// Act: walk every page of 3 followers
+	seen := make(map[string]bool)
+	pages := 0
+	cursor := ""
+	for {
+		page, err := useCase.GetFollowers("star", 3, cursor)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		pages++
+		for _, user := range page.Users {
+			if seen[user.ID] {
+				t.Errorf("Follower %s returned twice", user.ID)
+			}
+			seen[user.ID] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	// Assert
+	if len(seen) != 7 {
+		t.Errorf("Expected all 7 followers across pages, got %d", len(seen))
+	}
+	if pages != 3 {
+		t.Errorf("Expected 3 pages, got %d", pages)
+	}
+}
+
+func TestGetFollowersInvalidCursor(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+	setupFollowGraph(repo, nil, "star")
+
+	// Act
+	_, err := useCase.GetFollowers("star", 3, "not a cursor!")
+
+	// Assert
+	if err != entity.ErrInvalidCursor {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestCreateUserUsesIDGenerator(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	idGenerator := usecase.NewSequentialIDGenerator("user")
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{}, usecase.WithIDGenerator(idGenerator))
+
+	// Act
+	first, _ := useCase.CreateUser("first")
+	second, _ := useCase.CreateUser("second")
+
+	// Assert
+	if first.ID != "user-1" {
+		t.Errorf("Expected first user ID to be user-1, got %s", first.ID)
+	}
+
+	if second.ID != "user-2" {
+		t.Errorf("Expected second user ID to be user-2, got %s", second.ID)
+	}
+}
+
+// Saves users with the given IDs and follow relations in the repository
+func setupFollowGraph(repo *MockUserRepository, follows map[string][]string, ids ...string) {
+	for _, id := range ids {
+		repo.Save(entity.NewUser(id, id))
+	}
+	for followerID, followedIDs := range follows {
+		for _, followedID := range followedIDs {
+			repo.Follow(followerID, followedID)
+		}
+	}
+}
+
+func TestSuggestFollowsRanksFriendsOfFriends(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+	setupFollowGraph(repo, map[string][]string{
+		"me":    {"alice", "bob"},
+		"alice": {"carol", "dave", "me"},
+		"bob":   {"carol"},
+		"eve":   {"frank"},
+		"gina":  {"frank"},
+	}, "me", "alice", "bob", "carol", "dave", "eve", "frank", "gina")
+
+	// Act
+	suggestions, err := useCase.SuggestFollows("me", 3)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ids := make([]string, len(suggestions))
+	for i, user := range suggestions {
+		ids[i] = user.ID
+	}
+	// carol is followed by two friends, dave by one, then frank is the most-followed user
+	expected := []string{"carol", "dave", "frank"}
+	if len(ids) != len(expected) {
+		t.Fatalf("Expected suggestions %v, got %v", expected, ids)
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Errorf("Expected suggestions %v, got %v", expected, ids)
+			break
+		}
+	}
+}
+
+func TestSuggestFollowsExcludesSelfAndFollowed(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+	setupFollowGraph(repo, map[string][]string{
+		"me":    {"alice"},
+		"alice": {"me", "bob"},
+		"bob":   {"alice"},
+	}, "me", "alice", "bob")
+
+	// Act
+	suggestions, err := useCase.SuggestFollows("me", 10)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].ID != "bob" {
+		t.Errorf("Expected only bob to be suggested, got %d suggestions", len(suggestions))
+	}
+}
+
+func TestSuggestFollowsExcludesBlockedUsers(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	blocks := memory.NewBlockRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{}, usecase.WithBlocks(blocks))
+	setupFollowGraph(repo, map[string][]string{
+		"me":    {"alice"},
+		"alice": {"bob", "carol", "dave"},
+	}, "me", "alice", "bob", "carol", "dave")
+	blocks.Block("me", "bob")
+	blocks.Block("carol", "me")
+
+	// Act
+	suggestions, err := useCase.SuggestFollows("me", 10)
+
+	// Assert: neither the user blocked by me nor the user blocking me is suggested
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].ID != "dave" {
+		ids := make([]string, len(suggestions))
+		for i, user := range suggestions {
+			ids[i] = user.ID
+		}
+		t.Errorf("Expected only dave to be suggested, got %v", ids)
+	}
+}
+
+func TestSuggestFollowsUserNotFound(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+
+	// Act
+	_, err := useCase.SuggestFollows("nonexistent", 5)
+
+	// Assert
+	if err != entity.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestMutualFollowersOverlapping(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+	setupFollowGraph(repo, map[string][]string{
+		"carol": {"alice", "bob"},
+		"dave":  {"alice", "bob"},
+		"eve":   {"alice"},
+		"frank": {"bob"},
+	}, "alice", "bob", "carol", "dave", "eve", "frank")
+
+	// Act
+	mutuals, err := useCase.MutualFollowers("alice", "bob")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(mutuals) != 2 || mutuals[0].ID != "carol" || mutuals[1].ID != "dave" {
+		t.Errorf("Expected carol and dave as mutual followers, got %d users", len(mutuals))
+	}
+}
+
+func TestMutualFollowersDisjoint(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+	setupFollowGraph(repo, map[string][]string{
+		"eve":   {"alice"},
+		"frank": {"bob"},
+	}, "alice", "bob", "eve", "frank")
+
+	// Act
+	mutuals, err := useCase.MutualFollowers("alice", "bob")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(mutuals) != 0 {
+		t.Errorf("Expected no mutual followers, got %d", len(mutuals))
+	}
+}
+
+func TestMutualFollowersUserNotFound(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+	repo.Save(entity.NewUser("alice", "alice"))
+
+	// Act
+	_, err := useCase.MutualFollowers("alice", "nonexistent")
+
+	// Assert
+	if err != entity.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestFollowedByFriendsOverlapping(t *testing.T) {
+	// Arrange: alice follows carol, dave, eve and frank; all but frank follow bob
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+	setupFollowGraph(repo, map[string][]string{
+		"alice": {"eve", "carol", "dave", "frank"},
+		"carol": {"bob"},
+		"dave":  {"bob"},
+		"eve":   {"bob"},
+		"gina":  {"bob"},
+	}, "alice", "bob", "carol", "dave", "eve", "frank", "gina")
+
+	// Act
+	friends, total, err := useCase.FollowedByFriends("alice", "bob", 2)
+
+	// Assert: the first two by ID out of three
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ids := userIDs(friends); len(ids) != 2 || ids[0] != "carol" || ids[1] != "dave" || total != 3 {
+		t.Errorf("Expected carol and dave out of 3, got %v out of %d", userIDs(friends), total)
+	}
+}
+
+func TestFollowedByFriendsNonPositiveLimit(t *testing.T) {
+	// Arrange: carol follows bob and is followed by alice
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+	setupFollowGraph(repo, map[string][]string{
+		"alice": {"carol"},
+		"carol": {"bob"},
+	}, "alice", "bob", "carol")
+
+	for _, limit := range []int{0, -1} {
+		// Act
+		friends, total, err := useCase.FollowedByFriends("alice", "bob", limit)
+
+		// Assert: no users, but still the total
+		if err != nil {
+			t.Fatalf("Expected no error for limit %d, got %v", limit, err)
+		}
+		if len(friends) != 0 || total != 1 {
+			t.Errorf("Expected no users out of 1 for limit %d, got %v out of %d", limit, userIDs(friends), total)
+		}
+	}
+}
+
+func TestFollowedByFriendsEmpty(t *testing.T) {
+	// Arrange: bob's only follower is not followed by alice
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+	setupFollowGraph(repo, map[string][]string{
+		"alice": {"carol"},
+		"dave":  {"bob"},
+	}, "alice", "bob", "carol", "dave")
+
+	// Act
+	friends, total, err := useCase.FollowedByFriends("alice", "bob", 10)
+	_, _, missingErr := useCase.FollowedByFriends("alice", "nonexistent", 10)
+
+	// Assert
+	if err != nil || len(friends) != 0 || total != 0 {
+		t.Errorf("Expected no friends following bob, got %v out of %d and %v", userIDs(friends), total, err)
+	}
+	if missingErr != entity.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", missingErr)
+	}
+}
+
+// Returns the IDs of the given users, in order
+func userIDs(users []*entity.User) []string {
+	ids := make([]string, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids
+}
+
+func TestShortestPathDirect(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+	setupFollowGraph(repo, map[string][]string{
+		"alice": {"bob"},
+	}, "alice", "bob")
+
+	// Act
+	path, err := useCase.ShortestPath("alice", "bob", 6)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ids := userIDs(path); len(ids) != 2 || ids[0] != "alice" || ids[1] != "bob" {
+		t.Errorf("Expected path [alice bob], got %v", ids)
+	}
+}
+
+func TestShortestPathMultiHop(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+	// alice -> bob -> carol -> dave is longer than alice -> eve -> dave; bob and alice follow each other
+	setupFollowGraph(repo, map[string][]string{
+		"alice": {"bob", "eve"},
+		"bob":   {"alice", "carol"},
+		"carol": {"dave"},
+		"eve":   {"dave"},
+	}, "alice", "bob", "carol", "dave", "eve")
+
+	// Act
+	path, err := useCase.ShortestPath("alice", "dave", 6)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ids := userIDs(path); len(ids) != 3 || ids[0] != "alice" || ids[1] != "eve" || ids[2] != "dave" {
+		t.Errorf("Expected path [alice eve dave], got %v", ids)
+	}
+}
+
+func TestShortestPathUnreachable(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+	// A cycle not leading to dave, and dave following back without being followed
+	setupFollowGraph(repo, map[string][]string{
+		"alice": {"bob"},
+		"bob":   {"carol"},
+		"carol": {"alice"},
+		"dave":  {"alice"},
+	}, "alice", "bob", "carol", "dave")
+
+	// Act
+	_, err := useCase.ShortestPath("alice", "dave", 6)
+
+	// Assert
+	if err != entity.ErrPathNotFound {
+		t.Errorf("Expected ErrPathNotFound, got %v", err)
+	}
+}
+
+func TestShortestPathBeyondMaxDepth(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+	setupFollowGraph(repo, map[string][]string{
+		"alice": {"bob"},
+		"bob":   {"carol"},
+		"carol": {"dave"},
+	}, "alice", "bob", "carol", "dave")
+
+	// Act
+	_, err := useCase.ShortestPath("alice", "dave", 2)
+
+	// Assert
+	if err != entity.ErrPathNotFound {
+		t.Errorf("Expected ErrPathNotFound beyond the maximum depth, got %v", err)
+	}
+}
+
+// Timeline cache counting invalidations per user
+type CountingTimelineCache struct {
+	MockTimelineCache
+	invalidations map[string]int
+	// Number of InvalidateTimelines calls
+	batches int
+}
+
+func (c *CountingTimelineCache) InvalidateTimeline(ctx context.Context, userID string) error {
+	if c.invalidations == nil {
+		c.invalidations = make(map[string]int)
+	}
+	c.invalidations[userID]++
+	return nil
+}
+
+func (c *CountingTimelineCache) InvalidateTimelines(ctx context.Context, userIDs []string) error {
+	c.batches++
+	for _, userID := range userIDs {
+		c.InvalidateTimeline(ctx, userID)
+	}
+	return nil
+}
+
+func TestFollowManyPartialSuccess(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	timelineCache := &CountingTimelineCache{}
+	useCase := usecase.NewUserUseCase(repo, timelineCache)
+	setupFollowGraph(repo, nil, "me", "alice", "bob")
+
+	// Act
+	results, err := useCase.FollowMany("me", []string{"alice", "me", "nonexistent", "bob"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results))
+	}
+
+	expected := []error{nil, entity.ErrCannotFollowSelf, entity.ErrUserNotFound, nil}
+	for i, result := range results {
+		if result.Err != expected[i] {
+			t.Errorf("Expected result %d (%s) to be %v, got %v", i, result.FollowedID, expected[i], result.Err)
+		}
+	}
+
+	me, _ := repo.FindByID("me")
+	if !me.IsFollowing("alice") || !me.IsFollowing("bob") {
+		t.Error("Expected the valid follows to be applied")
+	}
+	if timelineCache.invalidations["me"] != 1 {
+		t.Errorf("Expected the timeline to be invalidated once, got %d", timelineCache.invalidations["me"])
+	}
+}
+
+func TestFollowManyNothingApplied(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	timelineCache := &CountingTimelineCache{}
+	useCase := usecase.NewUserUseCase(repo, timelineCache)
+	setupFollowGraph(repo, nil, "me")
+
+	// Act
+	results, err := useCase.FollowMany("me", []string{"me", "nonexistent"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, result := range results {
+		if result.Err == nil {
+			t.Errorf("Expected an error for %s", result.FollowedID)
+		}
+	}
+	if timelineCache.invalidations["me"] != 0 {
+		t.Error("Expected no invalidation when nothing changed")
+	}
+}
+
+func TestFollowManyFollowerNotFound(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, &MockTimelineCache{})
+
+	// Act
+	_, err := useCase.FollowMany("nonexistent", []string{"alice"})
+
+	// Assert
+	if err != entity.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestUnfollowManyPartialSuccess(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	timelineCache := &CountingTimelineCache{}
+	useCase := usecase.NewUserUseCase(repo, timelineCache)
+	setupFollowGraph(repo, map[string][]string{
+		"me": {"alice", "bob"},
+	}, "me", "alice", "bob")
+
+	// Act
+	results, err := useCase.UnfollowMany("me", []string{"alice", "nonexistent"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if results[0].Err != nil || results[1].Err != entity.ErrUserNotFound {
+		t.Errorf("Expected [nil ErrUserNotFound], got [%v %v]", results[0].Err, results[1].Err)
+	}
+
+	me, _ := repo.FindByID("me")
+	if me.IsFollowing("alice") || !me.IsFollowing("bob") {
+		t.Error("Expected only alice to be unfollowed")
+	}
+	if timelineCache.invalidations["me"] != 1 {
+		t.Errorf("Expected the timeline to be invalidated once, got %d", timelineCache.invalidations["me"])
+	}
+}
+
+// Mock implementation of the UserCache interface backed by a set
+type MapUserCache struct {
+	missing map[string]bool
+}
+
+func (c *MapUserCache) IsUserMissing(ctx context.Context, userID string) (bool, error) {
+	return c.missing[userID], nil
+}
+
+func (c *MapUserCache) MarkUserMissing(ctx context.Context, userID string) error {
+	if c.missing == nil {
+		c.missing = make(map[string]bool)
+	}
+	c.missing[userID] = true
+	return nil
+}
+
+func (c *MapUserCache) ClearUserMissing(ctx context.Context, userID string) error {
+	delete(c.missing, userID)
+	return nil
+}
+
+// User repository counting the lookups that reach it
+type CountingUserRepository struct {
+	*MockUserRepository
+	finds int
+}
+
+func (r *CountingUserRepository) FindByID(id string) (*entity.User, error) {
+	r.finds++
+	return r.MockUserRepository.FindByID(id)
+}
+
+func TestGetMissingUserServedFromCache(t *testing.T) {
+	// Arrange
+	repo := &CountingUserRepository{MockUserRepository: NewMockUserRepository()}
+	useCase := usecase.NewUserUseCase(repo, nil, usecase.WithUserCache(&MapUserCache{}))
+
+	// Act
+	_, firstErr := useCase.GetUser("ghost")
+	_, secondErr := useCase.GetUser("ghost")
+
+	// Assert
+	if firstErr != entity.ErrUserNotFound || secondErr != entity.ErrUserNotFound {
+		t.Fatalf("Expected ErrUserNotFound twice, got %v and %v", firstErr, secondErr)
+	}
+	if repo.finds != 1 {
+		t.Errorf("Expected the repository to be queried once, got %d", repo.finds)
+	}
+}
+
+func TestCreateUserClearsMissingMarker(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, nil,
+		usecase.WithUserCache(&MapUserCache{}),
+		usecase.WithIDGenerator(usecase.NewSequentialIDGenerator("user")))
+	if _, err := useCase.GetUser("user-1"); err != entity.ErrUserNotFound {
+		t.Fatalf("Expected ErrUserNotFound before creation, got %v", err)
+	}
+
+	// Act
+	created, err := useCase.CreateUser("alice")
+	if err != nil {
+		t.Fatalf("Expected no error creating user, got %v", err)
+	}
+	user, err := useCase.GetUser(created.ID)
+
+	// Assert
+	if err != nil || user == nil || user.ID != "user-1" {
+		t.Errorf("Expected the created user, got %v and %v", user, err)
+	}
+}
+
+func TestDeactivationHidesTweetsUntilReactivation(t *testing.T) {
+	// Arrange
+	userRepo := memory.NewUserRepository()
+	tweetRepo := memory.NewTweetRepository(userRepo)
+	userUseCase := usecase.NewUserUseCase(userRepo, nil)
+	tweetUseCase := usecase.NewTweetUseCase(tweetRepo, userRepo)
+	alice := entity.NewUser("alice", "alice")
+	alice.Follow("bob")
+	userRepo.Save(alice)
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	tweet, _ := entity.NewTweet("tweet1", "bob", "Hello")
+	tweetRepo.Save(tweet)
+	ctx := context.Background()
+
+	// Act
+	if err := userUseCase.DeactivateUser("bob"); err != nil {
+		t.Fatalf("Failed to deactivate: %v", err)
+	}
+	hidden, _ := tweetUseCase.GetTimeline(ctx, "alice", repository.DefaultTimelineOptions())
+	_, profileErr := userUseCase.GetProfile("alice", "bob")
+	ownProfile, ownErr := userUseCase.GetProfile("bob", "bob")
+	if err := userUseCase.ReactivateUser("bob"); err != nil {
+		t.Fatalf("Failed to reactivate: %v", err)
+	}
+	restored, _ := tweetUseCase.GetTimeline(ctx, "alice", repository.DefaultTimelineOptions())
+	profile, err := userUseCase.GetProfile("alice", "bob")
+
+	// Assert
+	if len(hidden) != 0 {
+		t.Errorf("Expected bob's tweets to be hidden while deactivated, got %v", hidden)
+	}
+	if profileErr != entity.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound for others, got %v", profileErr)
+	}
+	if ownErr != nil || ownProfile.Active {
+		t.Errorf("Expected bob to see his own inactive profile, got %+v and %v", ownProfile, ownErr)
+	}
+	if len(restored) != 1 || restored[0].ID != "tweet1" {
+		t.Errorf("Expected bob's tweet back after reactivation, got %v", restored)
+	}
+	if err != nil || !profile.Active {
+		t.Errorf("Expected the profile to be restored, got %+v and %v", profile, err)
+	}
+}
+
+func TestCreateUserRejectsReservedUsernames(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	useCase := usecase.NewUserUseCase(repo, nil, usecase.WithReservedUsernames(entity.NewReservedUsernames([]string{"admin"})))
+
+	// Act
+	_, reservedErr := useCase.CreateUser("Admin")
+	user, allowedErr := useCase.CreateUser("alice")
+
+	// Assert
+	if reservedErr != entity.ErrUsernameReserved {
+		t.Errorf("Expected ErrUsernameReserved, got %v", reservedErr)
+	}
+	if allowedErr != nil || user.Username != "alice" {
+		t.Errorf("Expected alice to be created, got %v and %v", user, allowedErr)
+	}
+	if len(repo.users) != 1 {
+		t.Errorf("Expected only the allowed user to be stored, got %d users", len(repo.users))
+	}
+}
+
+// Timeline builder that caches what it builds, like the DynamoDB repository does on a miss
+type CachingTimelineBuilder struct {
+	tweets repository.TweetRepository
+	cache  cache.TimelineCache
+}
+
+func (b *CachingTimelineBuilder) GetTimeline(ctx context.Context, userID string, opts repository.TimelineOptions) ([]*entity.Tweet, error) {
+	timeline, err := b.tweets.GetTimeline(ctx, userID, opts)
+	if err != nil {
+		return nil, err
+	}
+	return timeline, b.cache.SetTimeline(ctx, opts.CacheKey(userID), timeline)
+}
+
+func TestFollowUserWarmsFollowerTimeline(t *testing.T) {
+	// Arrange
+	userRepo := memory.NewUserRepository()
+	tweetRepo := memory.NewTweetRepository(userRepo)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	tweet, _ := entity.NewTweet("tweet1", "bob", "Hello")
+	tweetRepo.Save(tweet)
+	timelineCache := NewMapTimelineCache()
+	builder := &CachingTimelineBuilder{tweets: tweetRepo, cache: timelineCache}
+	useCase := usecase.NewUserUseCase(userRepo, timelineCache, usecase.WithTimelineWarming(builder))
+	key := repository.DefaultTimelineOptions().CacheKey("alice")
+
+	// Act
+	if err := useCase.FollowUser("alice", "bob"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Assert: the timeline is cached shortly after the follow returns
+	deadline := time.Now().Add(time.Second)
+	for {
+		timeline, found, _ := timelineCache.GetTimeline(context.Background(), key)
+		if found {
+			if len(timeline) != 1 || timeline[0].ID != "tweet1" {
+				t.Errorf("Expected the warmed timeline to hold bob's tweet, got %v", timeline)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the follower's timeline to be cached after the follow")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDeleteUserRemovesFollowsAndInvalidatesFollowerTimelines(t *testing.T) {
+	// Arrange: alice follows bob, who follows carol; alice's timeline is cached
+	userRepo := memory.NewUserRepository()
+	for _, id := range []string{"alice", "bob", "carol"} {
+		userRepo.Save(entity.NewUser(id, id))
+	}
+	userRepo.Follow("alice", "bob")
+	userRepo.Follow("bob", "carol")
+	timelineCache := NewMapTimelineCache()
+	key := repository.DefaultTimelineOptions().CacheKey("alice")
+	timelineCache.SetTimeline(context.Background(), key, []*entity.Tweet{{ID: "tweet1", UserID: "bob"}})
+	useCase := usecase.NewUserUseCase(userRepo, timelineCache)
+
+	// Act
+	if err := useCase.DeleteUser("bob"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Assert
+	if user, _ := userRepo.FindByID("bob"); user != nil {
+		t.Error("Expected bob to be deleted")
+	}
+	if alice, _ := userRepo.FindByID("alice"); alice.IsFollowing("bob") {
+		t.Error("Expected alice to no longer follow bob")
+	}
+	if followers, _ := userRepo.FindFollowers("carol"); len(followers) != 0 {
+		t.Errorf("Expected bob's follows to be removed, got followers %v", followers)
+	}
+	if _, found, _ := timelineCache.GetTimeline(context.Background(), key); found {
+		t.Error("Expected alice's cached timeline to be invalidated")
+	}
+	if err := useCase.DeleteUser("bob"); err != entity.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound deleting bob again, got %v", err)
+	}
+}
+
+func TestDeleteUserInvalidatesFollowerTimelinesInOneCall(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	timelineCache := &CountingTimelineCache{}
+	useCase := usecase.NewUserUseCase(repo, timelineCache)
+	setupFollowGraph(repo, map[string][]string{
+		"alice": {"bob"},
+		"carol": {"bob"},
+		"dave":  {"bob"},
+	}, "alice", "bob", "carol", "dave")
+
+	// Act
+	if err := useCase.DeleteUser("bob"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Assert: one call for the followers, one for bob
+	if timelineCache.batches != 2 {
+		t.Errorf("Expected 2 invalidation calls, got %d", timelineCache.batches)
+	}
+	for _, id := range []string{"alice", "carol", "dave", "bob"} {
+		if timelineCache.invalidations[repository.DefaultTimelineOptions().CacheKey(id)] != 1 {
+			t.Errorf("Expected the timeline of %s to be invalidated once, got %v", id, timelineCache.invalidations)
+		}
+	}
+}
+
+func TestRepeatedFollowChangesInvalidateOnce(t *testing.T) {
+	// Arrange
+	repo := NewMockUserRepository()
+	timelineCache := &CountingTimelineCache{}
+	useCase := usecase.NewUserUseCase(repo, timelineCache)
+	setupFollowGraph(repo, nil, "alice", "bob")
+	key := repository.DefaultTimelineOptions().CacheKey("alice")
+
+	// Act & Assert: each change invalidates alice's timeline, repeating it does not
+	steps := []struct {
+		change func(followerID, followedID string) error
+		err    error
+		want   int
+	}{
+		{useCase.FollowUser, nil, 1},
+		{useCase.FollowUser, entity.ErrAlreadyFollowing, 1},
+		{useCase.UnfollowUser, nil, 2},
+		{useCase.UnfollowUser, entity.ErrNotFollowing, 2},
+	}
+	for i, step := range steps {
+		if err := step.change("alice", "bob"); err != step.err {
+			t.Fatalf("Step %d: expected %v, got %v", i+1, step.err, err)
+		}
+		if got := timelineCache.invalidations[key]; got != step.want {
+			t.Errorf("Step %d: expected %d invalidations, got %d", i+1, step.want, got)
+		}
+	}
+}
@@ -5,51 +5,166 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
+	"github.com/develpudu/go-challenge/application/seed"
 	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/entity"
 	"github.com/develpudu/go-challenge/domain/repository"
 	"github.com/develpudu/go-challenge/infrastructure/api/handler"
+	"github.com/develpudu/go-challenge/infrastructure/api/server"
 	cacheRepo "github.com/develpudu/go-challenge/infrastructure/cache"
+	eventPublisher "github.com/develpudu/go-challenge/infrastructure/event"
+	"github.com/develpudu/go-challenge/infrastructure/linkpreview"
+	"github.com/develpudu/go-challenge/infrastructure/logging"
 	dynamodbRepo "github.com/develpudu/go-challenge/infrastructure/repository/dynamodb"
 	memoryRepo "github.com/develpudu/go-challenge/infrastructure/repository/memory"
+	"github.com/develpudu/go-challenge/infrastructure/tracing"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 // Use the correct type name
 var httpAdapter *httpadapter.HandlerAdapter
 
+// Tracer provider exporting spans over OTLP, nil when tracing is disabled
+var tracerProvider *sdktrace.TracerProvider
+
+// Build information reported by GET /version, set at build time with
+// -ldflags "-X main.gitCommit=$(git rev-parse HEAD) -X main.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	gitCommit = "unknown"
+	buildTime = "unknown"
+)
+
 // Main function - Entry point of the application
 func main() {
-	// Setup structured logging
+	// Check command-line arguments to decide which repository implementation to use
+	runMode := "local"
+	if len(os.Args) > 1 && os.Args[1] == "aws" {
+		runMode = "lambda"
+	}
+
+	// Setup structured logging; LOG_LEVEL is one of debug, info, warn or error
 	logLevel := slog.LevelInfo // Default level
-	if os.Getenv("LOG_LEVEL") == "debug" {
-		logLevel = slog.LevelDebug
+	var invalidLogLevel string
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		if err := logLevel.UnmarshalText([]byte(value)); err != nil {
+			logLevel = slog.LevelInfo
+			invalidLogLevel = value
+		}
+	}
+	// LOG_FORMAT is json or text; JSON suits CloudWatch, text is easier to read locally
+	logFormat := logging.FormatText
+	if runMode == "lambda" {
+		logFormat = logging.FormatJSON
+	}
+	logHandler, logFormatErr := logging.NewHandlerFromEnv(os.Stdout, logFormat, &slog.HandlerOptions{Level: logLevel})
+	slog.SetDefault(slog.New(logHandler)) // Set as default logger
+	if invalidLogLevel != "" {
+		slog.Warn("Invalid LOG_LEVEL, using default", "value", invalidLogLevel)
+	}
+	if logFormatErr != nil {
+		slog.Warn("Invalid LOG_FORMAT, using default", "value", os.Getenv("LOG_FORMAT"), "default", logFormat)
 	}
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel}))
-	slog.SetDefault(logger) // Set as default logger
 
 	slog.Info("Starting Microblogging Platform...")
 
 	var userRepository repository.UserRepository
 	var tweetRepository repository.TweetRepository
+	var bookmarkRepository repository.BookmarkRepository
+	var listRepository repository.ListRepository
+	var reportRepository repository.ReportRepository
+	var notificationRepository repository.NotificationRepository
+	var messageRepository repository.MessageRepository
+	var blockRepository repository.BlockRepository
 	var timelineCache cacheRepo.TimelineCache
+	var listTimelineCache cacheRepo.TimelineCache
+	var tweetOptions []usecase.Option
+	var cacheHealth handler.CacheHealth
+	var userOptions []usecase.Option
+	// Interval at which impressions counted in Redis are flushed to the tweets, zero when they are not counted
+	var impressionFlushInterval time.Duration
 
-	// Check command-line arguments to decide which repository implementation to use
-	runMode := "local"
-	if len(os.Args) > 1 && os.Args[1] == "aws" {
-		runMode = "lambda"
-	}
 	slog.Info("Determined run mode", "mode", runMode)
 
+	// Spans are exported over OTLP when OTEL_EXPORTER_OTLP_ENDPOINT is set
+	var err error
+	tracerProvider, err = tracing.Setup(context.Background(), "microblog-api")
+	if err != nil {
+		slog.Warn("Failed to initialize tracing. Proceeding without it.", "error", err)
+	} else if tracerProvider != nil {
+		slog.Info("OpenTelemetry tracing enabled")
+		defer tracerProvider.Shutdown(context.Background())
+	}
+
 	if runMode == "lambda" {
 		slog.Info("Initializing DynamoDB repositories and Redis cache...")
 		ctx := context.Background()
 
-		// Initialize Redis Cache
-		redisCache, err := cacheRepo.NewRedisTimelineCache(ctx)
+		// Per-call deadline for Redis operations, e.g. REDIS_TIMEOUT=100ms
+		var cacheOptions []cacheRepo.Option
+		if value := os.Getenv("REDIS_TIMEOUT"); value != "" {
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				slog.Warn("Invalid REDIS_TIMEOUT, using default", "value", value, "error", err)
+			} else {
+				cacheOptions = append(cacheOptions, cacheRepo.WithCallTimeout(timeout))
+			}
+		}
+
+		// Fraction of the timeline TTL randomly spread per entry, e.g. REDIS_TTL_JITTER=0.2; 0 disables
+		if value := os.Getenv("REDIS_TTL_JITTER"); value != "" {
+			jitter, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				slog.Warn("Invalid REDIS_TTL_JITTER, using default", "value", value, "error", err)
+			} else {
+				cacheOptions = append(cacheOptions, cacheRepo.WithTTLJitter(jitter))
+			}
+		}
+
+		// Age after which cached timelines are served stale while rebuilt in the background,
+		// e.g. REDIS_SOFT_TTL=1m; unset keeps them fresh until they expire
+		if value := os.Getenv("REDIS_SOFT_TTL"); value != "" {
+			softTTL, err := time.ParseDuration(value)
+			if err != nil {
+				slog.Warn("Invalid REDIS_SOFT_TTL, ignoring", "value", value, "error", err)
+			} else {
+				cacheOptions = append(cacheOptions, cacheRepo.WithSoftTTL(softTTL))
+			}
+		}
+
+		// Consecutive Redis failures that bypass the cache, and for how long
+		var breakerThreshold int
+		var breakerCooldown time.Duration
+		if value := os.Getenv("REDIS_BREAKER_THRESHOLD"); value != "" {
+			threshold, err := strconv.Atoi(value)
+			if err != nil {
+				slog.Warn("Invalid REDIS_BREAKER_THRESHOLD, using default", "value", value, "error", err)
+			} else {
+				breakerThreshold = threshold
+			}
+		}
+		if value := os.Getenv("REDIS_BREAKER_COOLDOWN"); value != "" {
+			cooldown, err := time.ParseDuration(value)
+			if err != nil {
+				slog.Warn("Invalid REDIS_BREAKER_COOLDOWN, using default", "value", value, "error", err)
+			} else {
+				breakerCooldown = cooldown
+			}
+		}
+		cacheOptions = append(cacheOptions, cacheRepo.WithCircuitBreaker(breakerThreshold, breakerCooldown))
+
+		var timelineLocker cacheRepo.Locker
+
+		// Initialize Redis Cache; REDIS_MODE selects a single server, a cluster or Sentinel
+		redisCache, err := cacheRepo.NewRedisTimelineCache(ctx, cacheOptions...)
 		if err != nil {
 			// Use structured logging for warnings
 			slog.Warn("Failed to initialize Redis timeline cache. Proceeding without cache.", "error", err)
@@ -57,6 +172,61 @@ func main() {
 		} else {
 			slog.Info("Redis timeline cache initialized.")
 			timelineCache = redisCache
+			listTimelineCache = redisCache.ListTimelines()
+
+			// Redis is pinged every REDIS_HEALTH_INTERVAL, e.g. 30s, to report its reachability
+			healthInterval := cacheRepo.DefaultHealthInterval
+			if value := os.Getenv("REDIS_HEALTH_INTERVAL"); value != "" {
+				interval, err := time.ParseDuration(value)
+				if err != nil || interval <= 0 {
+					slog.Warn("Invalid REDIS_HEALTH_INTERVAL, using default", "value", value, "error", err)
+				} else {
+					healthInterval = interval
+				}
+			}
+			monitor := cacheRepo.NewHealthMonitor(redisCache, healthInterval)
+			monitor.Start(ctx)
+			cacheHealth = monitor
+
+			// Lookups of missing users are cached for USER_NOT_FOUND_TTL, e.g. 10s
+			var missingUserTTL time.Duration
+			if value := os.Getenv("USER_NOT_FOUND_TTL"); value != "" {
+				ttl, err := time.ParseDuration(value)
+				if err != nil {
+					slog.Warn("Invalid USER_NOT_FOUND_TTL, using default", "value", value, "error", err)
+				} else {
+					missingUserTTL = ttl
+				}
+			}
+			userOptions = append(userOptions, usecase.WithUserCache(redisCache.Users(missingUserTTL)))
+
+			// Views of a tweet by the same viewer count once per IMPRESSION_WINDOW, e.g. 1h
+			var impressionWindow time.Duration
+			if value := os.Getenv("IMPRESSION_WINDOW"); value != "" {
+				window, err := time.ParseDuration(value)
+				if err != nil {
+					slog.Warn("Invalid IMPRESSION_WINDOW, using default", "value", value, "error", err)
+				} else {
+					impressionWindow = window
+				}
+			}
+			tweetOptions = append(tweetOptions, usecase.WithImpressionCounter(redisCache.Impressions(impressionWindow)))
+
+			// Counted impressions are added to the tweets in DynamoDB every IMPRESSION_FLUSH_INTERVAL, e.g. 5m
+			impressionFlushInterval = usecase.DefaultImpressionFlushInterval
+			if value := os.Getenv("IMPRESSION_FLUSH_INTERVAL"); value != "" {
+				interval, err := time.ParseDuration(value)
+				if err != nil || interval <= 0 {
+					slog.Warn("Invalid IMPRESSION_FLUSH_INTERVAL, using default", "value", value, "error", err)
+				} else {
+					impressionFlushInterval = interval
+				}
+			}
+
+			// TIMELINE_REBUILD_LOCK=true lets a single instance rebuild each timeline missing from the cache
+			if os.Getenv("TIMELINE_REBUILD_LOCK") == "true" {
+				timelineLocker = redisCache.TimelineLocks()
+			}
 		}
 
 		// Load AWS configuration
@@ -67,15 +237,121 @@ func main() {
 			os.Exit(1)
 		}
 
+		// DYNAMODB_ENDPOINT points the repositories at another endpoint, e.g. DynamoDB Local
+		if endpoint := os.Getenv(dynamodbRepo.EndpointEnv); endpoint != "" {
+			slog.Info("Using custom DynamoDB endpoint", "endpoint", endpoint)
+		}
+
 		// Use hardcoded table names
 		usersTableName := "users"
+		followsTableName := "follows"
 		tweetsTableName := "tweets"
-		slog.Info("Using DynamoDB tables", "usersTable", usersTableName, "tweetsTable", tweetsTableName)
+		outboxTableName := "outbox"
+		bookmarksTableName := "bookmarks"
+		listsTableName := "lists"
+		reportsTableName := "reports"
+		usernamesTableName := "usernames"
+		timelinesTableName := "timelines"
+		notificationsTableName := "notifications"
+		messagesTableName := "messages"
+		blocksTableName := "blocks"
+		slog.Info("Using DynamoDB tables", "usersTable", usersTableName, "followsTable", followsTableName, "tweetsTable", tweetsTableName, "outboxTable", outboxTableName, "bookmarksTable", bookmarksTableName, "listsTable", listsTableName, "reportsTable", reportsTableName, "usernamesTable", usernamesTableName, "timelinesTable", timelinesTableName, "notificationsTable", notificationsTableName, "messagesTable", messagesTableName, "blocksTable", blocksTableName)
+
+		// Per-call deadline for DynamoDB operations, e.g. DYNAMODB_TIMEOUT=3s
+		var ddbOptions []dynamodbRepo.Option
+		if value := os.Getenv("DYNAMODB_TIMEOUT"); value != "" {
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				slog.Warn("Invalid DYNAMODB_TIMEOUT, using default", "value", value, "error", err)
+			} else {
+				ddbOptions = append(ddbOptions, dynamodbRepo.WithTimeout(timeout))
+			}
+		}
+
+		// Attempts for throttled DynamoDB calls, including the first one
+		if value := os.Getenv("DYNAMODB_MAX_ATTEMPTS"); value != "" {
+			maxAttempts, err := strconv.Atoi(value)
+			if err != nil {
+				slog.Warn("Invalid DYNAMODB_MAX_ATTEMPTS, using default", "value", value, "error", err)
+			} else {
+				ddbOptions = append(ddbOptions, dynamodbRepo.WithMaxAttempts(maxAttempts))
+			}
+		}
+
+		// Tweet events go through the outbox table when EVENTS_OUTBOX=true, written in the same
+		// transaction as each tweet and published by the relay ("main aws outbox-relay").
+		// Otherwise they are published directly to EVENTS_TOPIC_ARN, if set.
+		topicARN := os.Getenv("EVENTS_TOPIC_ARN")
+		if os.Getenv("EVENTS_OUTBOX") == "true" {
+			slog.Info("Writing tweet events to the outbox", "outboxTable", outboxTableName)
+			ddbOptions = append(ddbOptions, dynamodbRepo.WithOutboxTable(outboxTableName))
+		} else if topicARN != "" {
+			slog.Info("Publishing tweet events to SNS", "topicARN", topicARN)
+			tweetOptions = append(tweetOptions, usecase.WithEventPublisher(eventPublisher.NewSNSEventPublisher(cfg, topicARN)))
+		}
+
+		if len(os.Args) > 2 && os.Args[2] == "outbox-relay" {
+			if topicARN == "" {
+				slog.Error("EVENTS_TOPIC_ARN must be set to run the outbox relay")
+				os.Exit(1)
+			}
+			relay := dynamodbRepo.NewOutboxRelay(cfg, outboxTableName, eventPublisher.NewSNSEventPublisher(cfg, topicARN), ddbOptions...)
+			slog.Info("Starting outbox relay Lambda handler")
+			lambda.Start(func(ctx context.Context) error {
+				published, err := relay.RelayOnce(ctx)
+				slog.InfoContext(ctx, "Outbox relay run finished", "published", published)
+				return err
+			})
+			return
+		}
 
 		// Initialize DynamoDB repositories
-		ddbUserRepo := dynamodbRepo.NewDynamoDBUserRepository(cfg, usersTableName)
-		userRepository = ddbUserRepo
-		tweetRepository = dynamodbRepo.NewDynamoDBTweetRepository(cfg, tweetsTableName, ddbUserRepo, timelineCache)
+		// Usernames are claimed in their own table, keeping them unique regardless of case
+		ddbUserRepo := dynamodbRepo.NewDynamoDBUserRepository(cfg, usersTableName, followsTableName, append(ddbOptions, dynamodbRepo.WithUsernamesTable(usernamesTableName))...)
+
+		// "main aws backfill-follows" copies the follows recorded before the follows table existed
+		// into it; run it once after upgrading, follower lists and counts miss those follows until then
+		if len(os.Args) > 2 && os.Args[2] == "backfill-follows" {
+			if _, err := ddbUserRepo.BackfillFollows(ctx); err != nil {
+				slog.Error("Failed to backfill follows table", "error", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		// TIMELINE_MODE=materialized keeps each user's timeline as tweet IDs in the timelines
+		// table, filled on follow and on followees' tweets, instead of querying every followee
+		var tweetRepoOptions []dynamodbRepo.Option
+		switch mode := os.Getenv("TIMELINE_MODE"); mode {
+		case "", "query":
+		case "materialized":
+			slog.Info("Using materialized timelines", "timelinesTable", timelinesTableName)
+			tweetRepoOptions = append(tweetRepoOptions, dynamodbRepo.WithTimelineTable(timelinesTableName))
+		default:
+			slog.Warn("Invalid TIMELINE_MODE, using default", "value", mode)
+		}
+		// Instances that find a timeline locked wait up to TIMELINE_REBUILD_LOCK_WAIT, e.g. 1s, before rebuilding it too
+		if timelineLocker != nil {
+			var lockWait time.Duration
+			if value := os.Getenv("TIMELINE_REBUILD_LOCK_WAIT"); value != "" {
+				wait, err := time.ParseDuration(value)
+				if err != nil {
+					slog.Warn("Invalid TIMELINE_REBUILD_LOCK_WAIT, using default", "value", value, "error", err)
+				} else {
+					lockWait = wait
+				}
+			}
+			tweetRepoOptions = append(tweetRepoOptions, dynamodbRepo.WithTimelineLock(timelineLocker, lockWait))
+		}
+		ddbTweetRepo := dynamodbRepo.NewDynamoDBTweetRepository(cfg, tweetsTableName, ddbUserRepo, timelineCache, append(ddbOptions, tweetRepoOptions...)...)
+		userRepository = ddbTweetRepo.MaterializeFollows(ddbUserRepo)
+		tweetRepository = ddbTweetRepo
+		bookmarkRepository = dynamodbRepo.NewDynamoDBBookmarkRepository(cfg, bookmarksTableName, ddbOptions...)
+		listRepository = dynamodbRepo.NewDynamoDBListRepository(cfg, listsTableName, ddbOptions...)
+		reportRepository = dynamodbRepo.NewDynamoDBReportRepository(cfg, reportsTableName, ddbOptions...)
+		notificationRepository = dynamodbRepo.NewDynamoDBNotificationRepository(cfg, notificationsTableName, ddbOptions...)
+		messageRepository = dynamodbRepo.NewDynamoDBMessageRepository(cfg, messagesTableName, ddbOptions...)
+		blockRepository = dynamodbRepo.NewDynamoDBBlockRepository(cfg, blocksTableName, ddbOptions...)
 
 	} else {
 		slog.Info("Initializing in-memory repositories...")
@@ -84,32 +360,227 @@ func main() {
 		memUserRepo := memoryRepo.NewUserRepository()
 		userRepository = memUserRepo
 		tweetRepository = memoryRepo.NewTweetRepository(memUserRepo)
+		bookmarkRepository = memoryRepo.NewBookmarkRepository()
+		listRepository = memoryRepo.NewListRepository()
+		notificationRepository = memoryRepo.NewNotificationRepository()
+		messageRepository = memoryRepo.NewMessageRepository()
+		blockRepository = memoryRepo.NewBlockRepository()
+		reportRepository = memoryRepo.NewReportRepository()
 	}
 
 	slog.Info("Initializing use cases...")
 	// Initialize use cases (inject cache into UserUseCase)
-	userUseCase := usecase.NewUserUseCase(userRepository, timelineCache)
-	tweetUseCase := usecase.NewTweetUseCase(tweetRepository, userRepository)
+	// RESERVED_USERNAMES=a,b,c replaces the default list of usernames nobody can register
+	if value := os.Getenv("RESERVED_USERNAMES"); value != "" {
+		userOptions = append(userOptions, usecase.WithReservedUsernames(entity.NewReservedUsernames(strings.Split(value, ","))))
+	}
+	// Tweet IDs are random UUIDs unless TWEET_ID_FORMAT=ulid selects time-sortable ULIDs
+	if os.Getenv("TWEET_ID_FORMAT") == "ulid" {
+		slog.Info("Using ULID tweet IDs")
+		tweetOptions = append(tweetOptions, usecase.WithIDGenerator(usecase.NewULIDGenerator(usecase.SystemClock{})))
+	}
+	// Identical back-to-back tweets within TWEET_DUPLICATE_WINDOW, e.g. 1m, are rejected; unset or 0 disables the check
+	if value := os.Getenv("TWEET_DUPLICATE_WINDOW"); value != "" {
+		window, err := time.ParseDuration(value)
+		if err != nil {
+			slog.Warn("Invalid TWEET_DUPLICATE_WINDOW, not checking duplicates", "value", value, "error", err)
+		} else {
+			tweetOptions = append(tweetOptions, usecase.WithDuplicateWindow(window))
+		}
+	}
+	// Each user can post at most TWEET_RATE_LIMIT tweets within TWEET_RATE_WINDOW (1h by default); unset or 0 disables the limit
+	if value := os.Getenv("TWEET_RATE_LIMIT"); value != "" {
+		limit, err := strconv.Atoi(value)
+		window := time.Hour
+		if windowValue := os.Getenv("TWEET_RATE_WINDOW"); windowValue != "" && err == nil {
+			window, err = time.ParseDuration(windowValue)
+		}
+		if err != nil || limit < 0 || window <= 0 {
+			slog.Warn("Invalid TWEET_RATE_LIMIT or TWEET_RATE_WINDOW, not limiting tweets", "limit", value, "window", os.Getenv("TWEET_RATE_WINDOW"), "error", err)
+		} else {
+			tweetOptions = append(tweetOptions, usecase.WithTweetRateLimit(limit, window))
+		}
+	}
+	// Tweets can be edited for TWEET_EDIT_WINDOW after creation, e.g. TWEET_EDIT_WINDOW=10m; 0 removes the limit
+	if value := os.Getenv("TWEET_EDIT_WINDOW"); value != "" {
+		window, err := time.ParseDuration(value)
+		if err != nil {
+			slog.Warn("Invalid TWEET_EDIT_WINDOW, using default", "value", value, "error", err)
+		} else {
+			tweetOptions = append(tweetOptions, usecase.WithEditWindow(window))
+		}
+	}
+	// Maximum tweet length, e.g. MAX_TWEET_LENGTH=500; defaults to 280 characters
+	if value := os.Getenv("MAX_TWEET_LENGTH"); value != "" {
+		maxLength, err := strconv.Atoi(value)
+		if err != nil || maxLength <= 0 {
+			slog.Warn("Invalid MAX_TWEET_LENGTH, using default", "value", value, "error", err)
+		} else {
+			tweetOptions = append(tweetOptions, usecase.WithMaxTweetLength(maxLength))
+		}
+	}
+	// Default timeline ranking, e.g. TIMELINE_RANKING=engagement; defaults to chronological
+	if value := os.Getenv("TIMELINE_RANKING"); value != "" {
+		if !slices.Contains(repository.TimelineRankings, value) {
+			slog.Warn("Invalid TIMELINE_RANKING, using default", "value", value)
+		} else {
+			tweetOptions = append(tweetOptions, usecase.WithTimelineRanking(value))
+		}
+	}
+	// PROFANITY_FILTER=reject rejects tweets with profane words and PROFANITY_FILTER=mask masks them;
+	// PROFANITY_WORDS=a,b,c replaces the default list of words
+	if mode := os.Getenv("PROFANITY_FILTER"); mode != "" {
+		if mode != entity.ProfanityReject && mode != entity.ProfanityMask {
+			slog.Warn("Invalid PROFANITY_FILTER, leaving profanity unfiltered", "value", mode)
+		} else {
+			words := entity.DefaultProfanityList()
+			if value := os.Getenv("PROFANITY_WORDS"); value != "" {
+				words = entity.NewProfanityList(strings.Split(value, ","))
+			}
+			tweetOptions = append(tweetOptions, usecase.WithProfanityFilter(words, mode))
+		}
+	}
+	// LINK_PREVIEWS=true fetches the Open Graph preview of the first link of each tweet in the
+	// background, giving up on a page after LINK_PREVIEW_TIMEOUT (default 3s)
+	if os.Getenv("LINK_PREVIEWS") == "true" {
+		timeout := linkpreview.DefaultTimeout
+		if value := os.Getenv("LINK_PREVIEW_TIMEOUT"); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil || parsed <= 0 {
+				slog.Warn("Invalid LINK_PREVIEW_TIMEOUT, using default", "value", value, "error", err)
+			} else {
+				timeout = parsed
+			}
+		}
+		tweetOptions = append(tweetOptions, usecase.WithLinkPreviews(linkpreview.NewFetcher(linkpreview.PublicClient(timeout))))
+	}
+	// Follows, replies and mentions are stored so their recipients see them in GET /users/notifications
+	tweetOptions = append(tweetOptions, usecase.WithNotifications(notificationRepository))
+	userOptions = append(userOptions, usecase.WithNotifications(notificationRepository))
+	// Users who block each other cannot follow one another
+	userOptions = append(userOptions, usecase.WithBlocks(blockRepository))
+	// Cached timelines are invalidated when reading them removes follows of deleted users
+	if timelineCache != nil {
+		tweetOptions = append(tweetOptions, usecase.WithTimelineCache(timelineCache))
+	}
+	tweetUseCase := usecase.NewTweetUseCase(tweetRepository, userRepository, tweetOptions...)
+	if impressionFlushInterval > 0 {
+		go tweetUseCase.RunImpressionFlush(context.Background(), impressionFlushInterval)
+	}
+	// WARM_TIMELINE_ON_FOLLOW=true rebuilds a follower's timeline in the background after each
+	// follow, so their next read hits the cache; it only helps when the timeline cache is enabled
+	if os.Getenv("WARM_TIMELINE_ON_FOLLOW") == "true" {
+		if timelineCache == nil {
+			slog.Warn("WARM_TIMELINE_ON_FOLLOW has no effect without the timeline cache")
+		} else {
+			userOptions = append(userOptions, usecase.WithTimelineWarming(tweetUseCase))
+		}
+	}
+	userUseCase := usecase.NewUserUseCase(userRepository, timelineCache, userOptions...)
+	bookmarkUseCase := usecase.NewBookmarkUseCase(bookmarkRepository, tweetRepository, userRepository)
+	listUseCase := usecase.NewListUseCase(listRepository, tweetRepository, userRepository, listTimelineCache)
+	reportUseCase := usecase.NewReportUseCase(reportRepository, tweetRepository)
+	notificationUseCase := usecase.NewNotificationUseCase(notificationRepository, userRepository)
+	// Blocking a user removes the follows between both users
+	messageUseCase := usecase.NewMessageUseCase(messageRepository, blockRepository, userRepository, usecase.WithFollowRemoval(userUseCase))
+
+	// SEED_DATA=true or the -seed argument fills the in-memory repositories with sample data
+	if os.Getenv("SEED_DATA") == "true" || slices.Contains(os.Args[1:], "-seed") {
+		if runMode == "lambda" {
+			slog.Warn("Seed data is only loaded in local mode, ignoring")
+		} else if users, err := seed.Load(userUseCase, tweetUseCase); err != nil {
+			slog.Error("Failed to load seed data", "error", err)
+			os.Exit(1)
+		} else {
+			slog.Info("Loaded seed data", "users", len(users))
+		}
+	}
 
-	// Initialize API handlers
-	userHandler := handler.NewUserHandler(userUseCase)
-	tweetHandler := handler.NewTweetHandler(tweetUseCase)
+	// Initialize API handlers; write routes resolve the User-ID header through the identity middleware
+	identity := handler.NewIdentityMiddleware(userUseCase)
+	userHandler := handler.NewUserHandler(userUseCase, identity)
+	tweetHandler := handler.NewTweetHandler(tweetUseCase, identity)
+	bookmarkHandler := handler.NewBookmarkHandler(bookmarkUseCase, identity)
+	listHandler := handler.NewListHandler(listUseCase, identity)
+	notificationHandler := handler.NewNotificationHandler(notificationUseCase, identity)
+	messageHandler := handler.NewMessageHandler(messageUseCase, identity)
+	versionHandler := handler.NewVersionHandler(gitCommit, buildTime, runMode)
+	healthHandler := handler.NewHealthHandler(cacheHealth)
+	// Moderation routes require the ADMIN_TOKEN in the X-Admin-Token header; unset disables them
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken == "" {
+		slog.Warn("ADMIN_TOKEN not set, admin routes are disabled")
+	}
+	admin := handler.NewAdminMiddleware(adminToken)
+	adminHandler := handler.NewAdminHandler(tweetUseCase, usecase.NewStatsUseCase(userRepository, tweetRepository), admin)
+	reportHandler := handler.NewReportHandler(reportUseCase, identity, admin)
 
 	slog.Info("Initializing API handlers and registering routes...")
+	// Start from an empty mux: importing net/http/pprof registers its routes on the default one
+	http.DefaultServeMux = new(http.ServeMux)
 	// Register routes
 	userHandler.RegisterRoutes()
 	tweetHandler.RegisterRoutes()
+	bookmarkHandler.RegisterRoutes()
+	listHandler.RegisterRoutes()
+	notificationHandler.RegisterRoutes()
+	messageHandler.RegisterRoutes()
+	versionHandler.RegisterRoutes()
+	healthHandler.RegisterRoutes()
+	adminHandler.RegisterRoutes()
+	reportHandler.RegisterRoutes()
+	// Profiles are served under /debug/pprof/ when PPROF_ENABLED=true, never in Lambda
+	if os.Getenv("PPROF_ENABLED") == "true" {
+		if runMode == "lambda" {
+			slog.Warn("pprof is only served in local mode, ignoring PPROF_ENABLED")
+		} else {
+			slog.Warn("pprof endpoints enabled under /debug/pprof/")
+			handler.NewPprofHandler().RegisterRoutes()
+		}
+	}
 
 	// Run based on the determined mode
 	if runMode == "lambda" {
 		slog.Info("Starting Lambda handler")
 		// Use httpadapter to wrap the existing http.Handler (DefaultServeMux)
-		httpAdapter = httpadapter.New(http.DefaultServeMux)
+		httpAdapter = httpadapter.New(tracing.Middleware(handler.AccessLogMiddleware(handler.GzipMiddleware(handler.MethodsMiddleware(http.DefaultServeMux)))))
 		lambda.Start(LambdaHandler)
 	} else {
-		slog.Info("Starting HTTP server", "port", 8080)
+		// Listen on PORT, e.g. PORT=3000; defaults to 8080
+		port, err := server.ResolvePort(os.Getenv("PORT"))
+		if err != nil {
+			slog.Warn("Invalid PORT, using default", "value", os.Getenv("PORT"), "error", err)
+			port = server.DefaultPort
+		}
+		// Server timeouts, e.g. SERVER_WRITE_TIMEOUT=2m; unset or non-positive values keep the defaults
+		var serverOptions []server.Option
+		for name, option := range map[string]func(time.Duration) server.Option{
+			"SERVER_READ_TIMEOUT":        server.WithReadTimeout,
+			"SERVER_READ_HEADER_TIMEOUT": server.WithReadHeaderTimeout,
+			"SERVER_WRITE_TIMEOUT":       server.WithWriteTimeout,
+			"SERVER_IDLE_TIMEOUT":        server.WithIdleTimeout,
+		} {
+			value := os.Getenv(name)
+			if value == "" {
+				continue
+			}
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				slog.Warn("Invalid "+name+", using default", "value", value, "error", err)
+				continue
+			}
+			serverOptions = append(serverOptions, option(timeout))
+		}
+		// HTTPS is served when TLS_CERT_FILE and TLS_KEY_FILE are set; plaintext otherwise
+		tlsFiles := server.TLSFiles{CertFile: os.Getenv("TLS_CERT_FILE"), KeyFile: os.Getenv("TLS_KEY_FILE")}
+		if err := tlsFiles.Validate(); err != nil {
+			slog.Error("Invalid TLS configuration, set both TLS_CERT_FILE and TLS_KEY_FILE or neither", "error", err)
+			os.Exit(1)
+		}
+		srv := server.New(server.Addr(port), tracing.Middleware(handler.AccessLogMiddleware(handler.GzipMiddleware(handler.MethodsMiddleware(http.DefaultServeMux)))), serverOptions...)
+		slog.Info("Starting HTTP server", "port", port, "tls", tlsFiles.Enabled(), "readTimeout", srv.ReadTimeout, "writeTimeout", srv.WriteTimeout, "idleTimeout", srv.IdleTimeout)
 		// Start HTTP server
-		if err := http.ListenAndServe(":8080", nil); err != nil {
+		if err := server.ListenAndServe(srv, tlsFiles); err != nil {
 			slog.Error("HTTP server failed", "error", err)
 			os.Exit(1)
 		}
@@ -118,12 +589,19 @@ func main() {
 
 // LambdaHandler proxies requests to the httpAdapter
 func LambdaHandler(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	// Add basic request logging
+	// Add basic request logging; headers and body are left out, as they may carry credentials
+	// (the access log middleware logs them redacted at debug level)
 	slog.InfoContext(ctx, "Received Lambda request", "method", req.HTTPMethod, "path", req.Path, "requestID", req.RequestContext.RequestID)
-	// Note: Consider adding more details like User-Agent, source IP if needed
 
 	response, err := httpAdapter.ProxyWithContext(ctx, req)
 
+	// Export the spans of this invocation before Lambda freezes the environment
+	if tracerProvider != nil {
+		if err := tracerProvider.ForceFlush(ctx); err != nil {
+			slog.WarnContext(ctx, "Failed to flush traces", "error", err)
+		}
+	}
+
 	// Log response status
 	if err != nil {
 		slog.ErrorContext(ctx, "Lambda handler error", "error", err)
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// Private bookmark of a tweet by a user
+type Bookmark struct {
+	UserID    string
+	TweetID   string
+	CreatedAt time.Time
+}
+
+// Creates a new bookmark of a tweet by a user at the given time
+func NewBookmark(userID, tweetID string, createdAt time.Time) *Bookmark {
+	return &Bookmark{
+		UserID:    userID,
+		TweetID:   tweetID,
+		CreatedAt: createdAt,
+	}
+}
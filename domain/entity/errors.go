@@ -1,6 +1,9 @@
 package entity
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 // Domain errors
 var (
@@ -10,9 +13,146 @@ var (
 	// Returned when a tweet exceeds the character limit
 	ErrTweetTooLong = errors.New("tweet exceeds character limit")
 
+	// Returned when a tweet has no content besides whitespace
+	ErrEmptyTweet = errors.New("tweet content is empty")
+
+	// Returned when a tweet contains a disallowed control or formatting character
+	ErrInvalidContent = errors.New("tweet contains disallowed control characters")
+
+	// Returned when a user picks a reserved username
+	ErrUsernameReserved = errors.New("username is reserved")
+
+	// Returned when another user already has the username, in any case
+	ErrUsernameTaken = errors.New("username is already taken")
+
 	// Returned when a user is not found
 	ErrUserNotFound = errors.New("user not found")
 
 	// Returned when a tweet is not found
 	ErrTweetNotFound = errors.New("tweet not found")
+
+	// Returned when a tweet is given an expiration that is not in the future
+	ErrInvalidExpiration = errors.New("tweet expiration must be positive")
+
+	// Returned when a tweet contains a profane word and the profanity filter rejects it
+	ErrProfanity = errors.New("tweet contains profanity")
+
+	// Returned when a tweet is given a language that is not an ISO 639-1 code
+	ErrInvalidLang = errors.New("tweet language must be an ISO 639-1 code")
+
+	// Returned when a tweet repeats the content of the author's latest tweet
+	ErrDuplicateTweet = errors.New("tweet duplicates the latest tweet")
+
+	// Returned when a user has posted as many tweets as allowed within the rate window, wrapped in a ThrottledError
+	ErrTweetRateLimited = errors.New("too many tweets posted recently")
+
+	// Returned when a user tries to modify a tweet they did not write
+	ErrNotTweetAuthor = errors.New("user is not the author of this tweet")
+
+	// Returned when a tweet is edited after the edit window has closed
+	ErrEditWindowExpired = errors.New("tweet can no longer be edited")
+
+	// Returned when a user tries to follow someone they blocked or who blocked them
+	ErrBlocked = errors.New("user is blocked")
+
+	// Returned when a user tries to follow someone they already follow
+	ErrAlreadyFollowing = errors.New("user is already following this user")
+
+	// Returned when a user tries to unfollow someone they do not follow
+	ErrNotFollowing = errors.New("user is not following this user")
+
+	// Returned when no follow path connects two users within the allowed depth
+	ErrPathNotFound = errors.New("no follow path between users")
+
+	// Returned when a list is not found
+	ErrListNotFound = errors.New("list not found")
+
+	// Returned when a list is created without a name
+	ErrListNameRequired = errors.New("list name is required")
+
+	// Returned when a list name exceeds the character limit
+	ErrListNameTooLong = errors.New("list name exceeds character limit")
+
+	// Returned when a user tries to modify a list they do not own
+	ErrNotListOwner = errors.New("user does not own this list")
+
+	// Returned when a tweet is reported without a reason
+	ErrReportReasonRequired = errors.New("report reason is required")
+
+	// Returned when a report reason exceeds the character limit
+	ErrReportReasonTooLong = errors.New("report reason exceeds character limit")
+
+	// Returned when a user reports a tweet they already reported
+	ErrAlreadyReported = errors.New("user already reported this tweet")
+
+	// Returned when an ID does not have the shape of the IDs given to entities
+	ErrInvalidID = errors.New("invalid ID")
+
+	// Returned when a pagination cursor cannot be decoded
+	ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+	// Returned when an imported tweet has no creation time or one in the future
+	ErrInvalidCreatedAt = errors.New("tweet creation time must be set and not in the future")
+
+	// Returned when an import has more tweets than allowed at once
+	ErrImportTooLarge = errors.New("too many tweets to import at once")
+
+	// Returned when a timeline ranking is not one of the known rankings
+	ErrUnknownRanking = errors.New("unknown timeline ranking")
+
+	// Returned when a time range is missing a bound or does not end after it starts
+	ErrInvalidTimeRange = errors.New("time range must end after it starts")
+
+	// Returned when a time range spans more buckets than allowed at once
+	ErrTimeRangeTooLarge = errors.New("time range spans too many buckets")
+
+	// Returned when a histogram bucket is not one of the known bucket sizes
+	ErrUnknownBucket = errors.New("unknown histogram bucket")
+
+	// Returned when a user tries to message themselves
+	ErrCannotMessageSelf = errors.New("user cannot message themselves")
+
+	// Returned when a message has no content besides whitespace
+	ErrEmptyMessage = errors.New("message content is empty")
+
+	// Returned when a message exceeds the character limit
+	ErrMessageTooLong = errors.New("message exceeds character limit")
+
+	// Returned when a user messages someone they blocked or who blocked them
+	ErrMessagingBlocked = errors.New("messaging between these users is blocked")
+
+	// Returned when a user tries to block themselves
+	ErrCannotBlockSelf = errors.New("user cannot block themselves")
+
+	// Returned when an update kept conflicting with concurrent updates of the same data after retrying
+	// The message tells clients how to recover, as it is shown to them
+	ErrConcurrentUpdate = errors.New("the resource was modified concurrently, fetch it again and retry")
+
+	// Returned when a request is throttled, by a rate limit or by the storage; see ThrottledError
+	ErrThrottled = errors.New("too many requests, try again later")
 )
+
+// Reports that an operation was throttled and how long to wait before retrying
+// It matches ErrThrottled with errors.Is, and unwraps to the cause when there is one
+type ThrottledError struct {
+	// Time after which the operation may succeed, zero when unknown
+	RetryAfter time.Duration
+	// Cause of the throttling, nil when there is none
+	Err error
+}
+
+// Returns the message of ErrThrottled, followed by the cause when there is one
+func (e *ThrottledError) Error() string {
+	if e.Err == nil {
+		return ErrThrottled.Error()
+	}
+	return ErrThrottled.Error() + ": " + e.Err.Error()
+}
+
+// Returns ErrThrottled and the cause, so both match with errors.Is
+func (e *ThrottledError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{ErrThrottled}
+	}
+	return []error{ErrThrottled, e.Err}
+}
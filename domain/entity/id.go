@@ -0,0 +1,21 @@
+package entity
+
+// Defines the maximum number of characters of an entity ID
+const MaxIDLength = 64
+
+// Checks if the ID has the shape of the IDs given to entities: between 1 and MaxIDLength
+// ASCII letters, digits, hyphens or underscores, as in UUIDs, ULIDs and sequential IDs
+func IsValidID(id string) bool {
+	if id == "" || len(id) > MaxIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Preview card of a web page linked from a tweet, built from its Open Graph metadata
+type LinkPreview struct {
+	// URL the preview was fetched from, as written in the tweet
+	URL         string
+	Title       string
+	Description string
+	// Absolute URL of the page's preview image, empty when it has none
+	ImageURL string
+}
+
+// Returns the first http or https URL in the content, or an empty string if there is none
+// URLs end at the first whitespace, and trailing punctuation such as a closing period is left out
+func FirstURL(content string) string {
+	for _, field := range strings.Fields(content) {
+		start := strings.Index(field, "http://")
+		if https := strings.Index(field, "https://"); https >= 0 && (start < 0 || https < start) {
+			start = https
+		}
+		if start < 0 {
+			continue
+		}
+		candidate := strings.TrimRight(field[start:], `.,;:!?)]}"'`)
+		if parsed, err := url.Parse(candidate); err == nil && parsed.Host != "" {
+			return candidate
+		}
+	}
+	return ""
+}
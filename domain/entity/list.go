@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Defines the maximum number of characters allowed in a list name
+const MaxListNameLength = 50
+
+// Named list of users whose tweets form a separate timeline
+type List struct {
+	ID        string
+	OwnerID   string
+	Name      string
+	Members   map[string]bool // Map of member user IDs
+	CreatedAt time.Time
+}
+
+// Creates a new empty list owned by a user
+// Returns an error if the name is empty or exceeds the character limit
+func NewList(id, ownerID, name string, createdAt time.Time) (*List, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrListNameRequired
+	}
+	if len(name) > MaxListNameLength {
+		return nil, ErrListNameTooLong
+	}
+
+	return &List{
+		ID:        id,
+		OwnerID:   ownerID,
+		Name:      name,
+		Members:   make(map[string]bool),
+		CreatedAt: createdAt,
+	}, nil
+}
+
+// Adds a user to the list; adding an existing member has no effect
+func (l *List) AddMember(userID string) {
+	if l.Members == nil {
+		l.Members = make(map[string]bool)
+	}
+	l.Members[userID] = true
+}
+
+// Removes a user from the list; removing a non-member has no effect
+func (l *List) RemoveMember(userID string) {
+	delete(l.Members, userID)
+}
+
+// Checks if a user is a member of the list
+func (l *List) HasMember(userID string) bool {
+	return l.Members[userID]
+}
+
+// Returns the member user IDs sorted by ID
+func (l *List) GetMembers() []string {
+	members := make([]string, 0, len(l.Members))
+	for id := range l.Members {
+		members = append(members, id)
+	}
+	sort.Strings(members)
+	return members
+}
+
+// Returns a copy of the list, including the member set
+func (l *List) Clone() *List {
+	members := make(map[string]bool, len(l.Members))
+	for id, v := range l.Members {
+		members[id] = v
+	}
+	return &List{
+		ID:        l.ID,
+		OwnerID:   l.OwnerID,
+		Name:      l.Name,
+		Members:   members,
+		CreatedAt: l.CreatedAt,
+	}
+}
@@ -0,0 +1,59 @@
+package entity
+
+import "time"
+
+// Defines the maximum number of characters allowed in a direct message
+const MaxMessageLength = 1000
+
+// Private message sent by one user to another
+type Message struct {
+	ID string
+	// Shared by every message between the same two users, see ConversationIDFor
+	ConversationID string
+	SenderID       string
+	RecipientID    string
+	Content        string
+	CreatedAt      time.Time
+}
+
+// Returns the ID of the conversation between two users, the same whichever of them sends
+func ConversationIDFor(userID, otherID string) string {
+	if otherID < userID {
+		userID, otherID = otherID, userID
+	}
+	// IDs never contain '#', so the pair cannot be read in another way
+	return userID + "#" + otherID
+}
+
+// Creates a new message from the sender to the recipient at the given time
+// The content is normalized like tweet content; returns an error if the users are the same,
+// or if the content is empty, has disallowed characters or exceeds MaxMessageLength
+func NewMessage(id, senderID, recipientID, content string, createdAt time.Time) (*Message, error) {
+	if senderID == recipientID {
+		return nil, ErrCannotMessageSelf
+	}
+	content = NormalizeContent(content)
+	if content == "" {
+		return nil, ErrEmptyMessage
+	}
+	if err := CheckTweetCharacters(content); err != nil {
+		return nil, err
+	}
+	if ContentLength(content) > MaxMessageLength {
+		return nil, ErrMessageTooLong
+	}
+
+	return &Message{
+		ID:             id,
+		ConversationID: ConversationIDFor(senderID, recipientID),
+		SenderID:       senderID,
+		RecipientID:    recipientID,
+		Content:        content,
+		CreatedAt:      createdAt,
+	}, nil
+}
+
+// Checks if the user is the sender or the recipient of the message
+func (m *Message) HasParticipant(userID string) bool {
+	return m.SenderID == userID || m.RecipientID == userID
+}
@@ -0,0 +1,46 @@
+package entity
+
+import "time"
+
+// Kind of action a notification tells its recipient about
+type NotificationType string
+
+const (
+	// The actor started following the recipient
+	NotificationFollow NotificationType = "follow"
+	// The actor replied to a tweet of the recipient
+	NotificationReply NotificationType = "reply"
+	// The actor mentioned the recipient by username in a tweet
+	NotificationMention NotificationType = "mention"
+)
+
+// Notification of an action by a user on another, stored for the recipient
+type Notification struct {
+	// Unique among the recipient's notifications; IDs sort in creation order
+	ID          string
+	RecipientID string
+	Type        NotificationType
+	ActorID     string
+	// Tweet the action was taken with, empty for follows
+	TweetID   string
+	CreatedAt time.Time
+	// Whether the recipient has marked the notification as read
+	Read bool
+}
+
+// Creates a new unread notification for the recipient of an action by the actor at the given time
+func NewNotification(recipientID string, notificationType NotificationType, actorID, tweetID string, createdAt time.Time) *Notification {
+	// Fixed-width UTC timestamps sort lexicographically in time order
+	id := createdAt.UTC().Format("2006-01-02T15:04:05.000000000Z") + "#" + string(notificationType) + "#" + actorID
+	if tweetID != "" {
+		id += "#" + tweetID
+	}
+	return &Notification{
+		ID:          id,
+		RecipientID: recipientID,
+		Type:        notificationType,
+		ActorID:     actorID,
+		TweetID:     tweetID,
+		CreatedAt:   createdAt,
+	}
+}
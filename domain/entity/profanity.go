@@ -0,0 +1,100 @@
+package entity
+
+import (
+	_ "embed"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Ways of handling profanity in tweet content
+const (
+	// Rejects content containing profanity with ErrProfanity
+	ProfanityReject = "reject"
+	// Replaces every character of each profane word with an asterisk
+	ProfanityMask = "mask"
+)
+
+// Default profane words, one per line with # comments
+//
+//go:embed profanity.txt
+var defaultProfanity string
+
+// Set of profane words, matched case-insensitively against whole words only,
+// so "Scunthorpe" or "classic" never match "cunt" or "ass"
+type ProfanityList map[string]bool
+
+// Creates a set of profane words from the given words
+// Words are trimmed and blank ones are skipped
+func NewProfanityList(words []string) ProfanityList {
+	list := make(ProfanityList, len(words))
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if word != "" {
+			list[normalizeWord(word)] = true
+		}
+	}
+	return list
+}
+
+// Returns the profane words shipped with the platform, in English and Spanish
+func DefaultProfanityList() ProfanityList {
+	var words []string
+	for _, line := range strings.Split(defaultProfanity, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			words = append(words, line)
+		}
+	}
+	return NewProfanityList(words)
+}
+
+// Reports whether the text contains a profane word
+func (p ProfanityList) Contains(text string) bool {
+	found := false
+	eachWord(text, func(start, end int) {
+		found = found || p[normalizeWord(text[start:end])]
+	})
+	return found
+}
+
+// Returns the text with every character of each profane word replaced with an asterisk
+// The masked text has as many characters as the original, so it counts the same against the tweet limit
+func (p ProfanityList) Mask(text string) string {
+	var masked strings.Builder
+	last := 0
+	eachWord(text, func(start, end int) {
+		if word := text[start:end]; p[normalizeWord(word)] {
+			masked.WriteString(text[last:start])
+			masked.WriteString(strings.Repeat("*", utf8.RuneCountInString(word)))
+			last = end
+		}
+	})
+	masked.WriteString(text[last:])
+	return masked.String()
+}
+
+// Calls fn with the byte offsets of each word of the text in order: each maximal run
+// of letters, digits and combining marks, so punctuation, spaces and emoji separate words
+func eachWord(text string, fn func(start, end int)) {
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			fn(start, i)
+			start = -1
+		}
+	}
+	if start >= 0 {
+		fn(start, len(text))
+	}
+}
+
+// Returns the form in which words are compared: lowercase and in Unicode NFC form
+func normalizeWord(word string) string {
+	return strings.ToLower(norm.NFC.String(word))
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// Defines the maximum number of characters allowed in a report reason
+const MaxReportReasonLength = 500
+
+// Report of a tweet flagged by a user for moderators to review
+type Report struct {
+	ReporterID string
+	TweetID    string
+	Reason     string
+	CreatedAt  time.Time
+}
+
+// Creates a new report of a tweet by a user at the given time
+// Returns an error if the reason is empty or exceeds the character limit
+func NewReport(reporterID, tweetID, reason string, createdAt time.Time) (*Report, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrReportReasonRequired
+	}
+	if ContentLength(reason) > MaxReportReasonLength {
+		return nil, ErrReportReasonTooLong
+	}
+
+	return &Report{
+		ReporterID: reporterID,
+		TweetID:    tweetID,
+		Reason:     reason,
+		CreatedAt:  createdAt,
+	}, nil
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	_ "embed"
+	"strings"
+)
+
+// Default reserved usernames, one per line with # comments
+//
+//go:embed reserved_usernames.txt
+var defaultReservedUsernames string
+
+// Set of usernames nobody can register, compared case-insensitively
+type ReservedUsernames map[string]bool
+
+// Creates a set of reserved usernames from the given names
+// Names are trimmed and blank ones are skipped
+func NewReservedUsernames(names []string) ReservedUsernames {
+	reserved := make(ReservedUsernames, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			reserved[NormalizeUsername(name)] = true
+		}
+	}
+	return reserved
+}
+
+// Returns the reserved usernames shipped with the platform: administrative
+// names such as admin or support, and profanity
+func DefaultReservedUsernames() ReservedUsernames {
+	var names []string
+	for _, line := range strings.Split(defaultReservedUsernames, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			names = append(names, line)
+		}
+	}
+	return NewReservedUsernames(names)
+}
+
+// Reports whether the username is reserved, ignoring case and surrounding whitespace
+func (r ReservedUsernames) Contains(username string) bool {
+	return r[NormalizeUsername(username)]
+}
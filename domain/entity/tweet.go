@@ -1,37 +1,338 @@
 package entity
 
 import (
+	"fmt"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
 )
 
-// Defines the maximum number of characters allowed in a tweet
+// Defines the default maximum number of characters allowed in a tweet
 const MaxTweetLength = 280
 
+// Returns the number of characters in the content as counted against the tweet limit
+// Characters are Unicode code points, so multibyte characters count once
+func ContentLength(content string) int {
+	return utf8.RuneCountInString(content)
+}
+
+// Returns an error matching ErrTweetTooLong, reporting the limit, if the content exceeds maxLength
+func CheckTweetLength(content string, maxLength int) error {
+	if ContentLength(content) > maxLength {
+		return fmt.Errorf("%w of %d", ErrTweetTooLong, maxLength)
+	}
+	return nil
+}
+
 // Tweet in the microblogging platform
 type Tweet struct {
 	ID        string
 	UserID    string
 	Content   string
 	CreatedAt time.Time
+	// Time of the last change to the tweet, equal to CreatedAt until it is edited
+	UpdatedAt time.Time
+	// ID of the tweet this one replies to, empty for root tweets
+	InReplyToID string
+	// ID of the root tweet of the thread, shared by every tweet in it
+	ConversationID string
+	// ID of the tweet quoted by this one, empty for tweets that quote nothing
+	QuotedTweetID string
+	// Time after which the tweet is no longer visible, zero for tweets that never expire
+	ExpiresAt time.Time
+	// ISO 639-1 code of the language of the content, empty when unknown
+	Lang string
+	// Preview of the first URL in the content, nil until it is fetched or when there is none
+	LinkPreview *LinkPreview
+	// Impressions flushed to storage, without the ones still pending in the counter
+	Impressions int64
+}
+
+// Control characters allowed in tweet content: line breaks and tabs
+// Every other control character (Unicode category Cc, e.g. NUL or ESC) is rejected,
+// as is every bidirectional formatting character (Unicode Bidi_Control, e.g. the
+// U+202E right-to-left override), since they break rendering and logs
+var allowedControlChars = map[rune]bool{
+	'\n': true,
+	'\r': true,
+	'\t': true,
 }
 
-// Creates a new tweet with the given parameters
+// Returns ErrInvalidContent if the content contains a disallowed control or bidirectional formatting character
+func CheckTweetCharacters(content string) error {
+	for _, r := range content {
+		if unicode.Is(unicode.Cc, r) && !allowedControlChars[r] {
+			return ErrInvalidContent
+		}
+		if unicode.Is(unicode.Bidi_Control, r) {
+			return ErrInvalidContent
+		}
+	}
+	return nil
+}
+
+// Returns the content as stored in a tweet: trimmed of surrounding whitespace
+// and in Unicode NFC form, so equal texts are stored and counted the same way
+func NormalizeContent(content string) string {
+	return norm.NFC.String(strings.TrimSpace(content))
+}
+
+// Returns the language code as stored in a tweet: trimmed and lowercased
+// Returns ErrInvalidLang unless it is a two-letter ISO 639-1 code; an empty code is kept as unknown
+func NormalizeLang(lang string) (string, error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" {
+		return "", nil
+	}
+	if len(lang) != 2 {
+		return "", ErrInvalidLang
+	}
+	base, err := language.ParseBase(lang)
+	if err != nil || base.String() != lang {
+		return "", ErrInvalidLang
+	}
+	return lang, nil
+}
+
+// Creates a new tweet with the given parameters, timestamped with the current time
 // Returns an error if the content exceeds the character limit
 func NewTweet(id, userID, content string) (*Tweet, error) {
-	// Validate tweet length
-	if len(content) > MaxTweetLength {
-		return nil, ErrTweetTooLong
+	return NewTweetAt(id, userID, content, time.Now())
+}
+
+// Creates a new tweet with the given parameters and creation time
+// Returns an error if the content exceeds the character limit
+func NewTweetAt(id, userID, content string, createdAt time.Time) (*Tweet, error) {
+	return NewTweetWithMaxLength(id, userID, content, createdAt, MaxTweetLength)
+}
+
+// Creates a new tweet with the given parameters and creation time
+// The content is normalized first; returns an error if it is empty, has disallowed characters or exceeds maxLength characters
+func NewTweetWithMaxLength(id, userID, content string, createdAt time.Time, maxLength int) (*Tweet, error) {
+	content, err := PrepareContent(content, maxLength)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Tweet{
-		ID:        id,
-		UserID:    userID,
-		Content:   content,
-		CreatedAt: time.Now(),
+		ID:             id,
+		UserID:         userID,
+		Content:        content,
+		CreatedAt:      createdAt,
+		UpdatedAt:      createdAt,
+		ConversationID: id,
 	}, nil
 }
 
+// Creates a new reply to the parent tweet with the given creation time
+// The reply joins the parent's conversation
+func NewReplyAt(id, userID, content string, parent *Tweet, createdAt time.Time) (*Tweet, error) {
+	return NewReplyWithMaxLength(id, userID, content, parent, createdAt, MaxTweetLength)
+}
+
+// Creates a new reply to the parent tweet with the given creation time
+// Returns an error if the content exceeds maxLength characters
+func NewReplyWithMaxLength(id, userID, content string, parent *Tweet, createdAt time.Time, maxLength int) (*Tweet, error) {
+	reply, err := NewTweetWithMaxLength(id, userID, content, createdAt, maxLength)
+	if err != nil {
+		return nil, err
+	}
+	reply.InReplyToID = parent.ID
+	reply.ConversationID = parent.RootID()
+	return reply, nil
+}
+
+// Creates a new tweet quoting another one with the given creation time
+// A quote starts its own conversation; returns an error if the content exceeds maxLength characters
+func NewQuoteWithMaxLength(id, userID, content string, quoted *Tweet, createdAt time.Time, maxLength int) (*Tweet, error) {
+	quote, err := NewTweetWithMaxLength(id, userID, content, createdAt, maxLength)
+	if err != nil {
+		return nil, err
+	}
+	quote.QuotedTweetID = quoted.ID
+	return quote, nil
+}
+
+// Returns the ID of the root tweet of the conversation
+// Tweets stored before conversations were tracked are their own root
+func (t *Tweet) RootID() string {
+	if t.ConversationID == "" {
+		return t.ID
+	}
+	return t.ConversationID
+}
+
+// Checks if the tweet was created after the other one
+// Tweets created at the same time are ordered by ID, the greater ID being newer, so sorting is deterministic
+func (t *Tweet) IsNewerThan(other *Tweet) bool {
+	if !t.CreatedAt.Equal(other.CreatedAt) {
+		return t.CreatedAt.After(other.CreatedAt)
+	}
+	return t.ID > other.ID
+}
+
+// Returns the usernames mentioned in the content as @username, normalized and in order of first mention
+// A mention starts after a character that is not part of a username, so e-mail addresses are not mentions
+func (t *Tweet) Mentions() []string {
+	var mentions []string
+	seen := make(map[string]bool)
+	runes := []rune(t.Content)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '@' || (i > 0 && isUsernameChar(runes[i-1])) {
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && isUsernameChar(runes[end]) {
+			end++
+		}
+		if end-i-1 >= 1 && end-i-1 <= MaxUsernameLength {
+			if username := NormalizeUsername(string(runes[i+1 : end])); !seen[username] {
+				seen[username] = true
+				mentions = append(mentions, username)
+			}
+		}
+		i = end - 1
+	}
+	return mentions
+}
+
+// Reports whether a character can be part of a mentioned username
+func isUsernameChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Checks if the tweet has expired at the given time
+func (t *Tweet) IsExpired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+// Replaces the content of the tweet, timestamping the change with the current time
+// Returns an error if the new content exceeds the character limit
+func (t *Tweet) Edit(content string) error {
+	return t.EditWithMaxLength(content, MaxTweetLength, time.Now())
+}
+
+// Replaces the content of the tweet and sets UpdatedAt to the given edit time
+// The content is normalized first; returns an error if it is empty, has disallowed characters or exceeds maxLength characters
+// The link preview is dropped when the edit changes the first URL of the content
+func (t *Tweet) EditWithMaxLength(content string, maxLength int, editedAt time.Time) error {
+	content, err := PrepareContent(content, maxLength)
+	if err != nil {
+		return err
+	}
+	t.Content = content
+	t.UpdatedAt = editedAt
+	if t.LinkPreview != nil && t.LinkPreview.URL != FirstURL(content) {
+		t.LinkPreview = nil
+	}
+	return nil
+}
+
+// Normalizes the content and checks it is not empty, has only allowed characters and fits within maxLength
+// Returns the normalized content as it would be stored in a tweet
+func PrepareContent(content string, maxLength int) (string, error) {
+	content = NormalizeContent(content)
+	if content == "" {
+		return "", ErrEmptyTweet
+	}
+	if err := CheckTweetCharacters(content); err != nil {
+		return "", err
+	}
+	if err := CheckTweetLength(content, maxLength); err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
 // Checks if the tweet is valid (within character limit)
 func (t *Tweet) IsValid() bool {
-	return len(t.Content) <= MaxTweetLength
+	return ContentLength(t.Content) <= MaxTweetLength
+}
+
+// Removes repeated tweets, keeping the first occurrence of each ID
+// The relative order of the remaining tweets is preserved
+func UniqueTweets(tweets []*Tweet) []*Tweet {
+	seen := make(map[string]bool, len(tweets))
+	unique := make([]*Tweet, 0, len(tweets))
+	for _, tweet := range tweets {
+		if seen[tweet.ID] {
+			continue
+		}
+		seen[tweet.ID] = true
+		unique = append(unique, tweet)
+	}
+	return unique
+}
+
+// Ellipsis appended to truncated content
+const truncationEllipsis = "…"
+
+// Returns the content shortened to at most n characters followed by an ellipsis,
+// or the content unchanged if it already fits. Characters that render as one
+// symbol are never split: a base character stays with its combining marks,
+// variation selectors and skin tone modifiers, emoji joined by zero-width
+// joiners stay together, and so do the two halves of a flag.
+func TruncateContent(content string, n int) string {
+	if ContentLength(content) <= n {
+		return content
+	}
+
+	var b strings.Builder
+	count := 0
+	for _, cluster := range splitClusters(content) {
+		length := ContentLength(cluster)
+		if count+length > n {
+			break
+		}
+		b.WriteString(cluster)
+		count += length
+	}
+	return strings.TrimRightFunc(b.String(), unicode.IsSpace) + truncationEllipsis
+}
+
+// Splits content into the sequences of characters that render as a single symbol
+func splitClusters(content string) []string {
+	var clusters []string
+	start := 0
+	var prev rune
+	regionalIndicators := 0
+	for i, r := range content {
+		if i > 0 && !continuesCluster(prev, r, regionalIndicators) {
+			clusters = append(clusters, content[start:i])
+			start = i
+			regionalIndicators = 0
+		}
+		if isRegionalIndicator(r) {
+			regionalIndicators++
+		}
+		prev = r
+	}
+	if start < len(content) {
+		clusters = append(clusters, content[start:])
+	}
+	return clusters
+}
+
+// Reports whether r belongs to the same symbol as the preceding character prev
+// regionalIndicators counts the flag halves already in the current symbol
+func continuesCluster(prev, r rune, regionalIndicators int) bool {
+	const zeroWidthJoiner = '‍'
+	switch {
+	case unicode.Is(unicode.M, r), r == zeroWidthJoiner, prev == zeroWidthJoiner:
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // Emoji skin tone modifiers
+		return true
+	case isRegionalIndicator(r):
+		return regionalIndicators == 1 && isRegionalIndicator(prev)
+	}
+	return false
+}
+
+// Reports whether r is one of the letters that pair up to form a flag emoji
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
 }
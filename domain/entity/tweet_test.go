@@ -1,6 +1,8 @@
 package entity_test
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -53,7 +55,7 @@ func TestNewTweetTooLong(t *testing.T) {
 	tweet, err := entity.NewTweet(id, userID, content)
 
 	// Assert
-	if err != entity.ErrTweetTooLong {
+	if !errors.Is(err, entity.ErrTweetTooLong) {
 		t.Errorf("Expected ErrTweetTooLong, got %v", err)
 	}
 
@@ -111,3 +113,216 @@ func TestTweetIsValid(t *testing.T) {
 		})
 	}
 }
+
+func TestNewTweetAt(t *testing.T) {
+	// Arrange
+	createdAt := time.Date(2024, time.January, 2, 15, 4, 5, 0, time.UTC)
+
+	// Act
+	tweet, err := entity.NewTweetAt("tweet123", "user456", "This is a test tweet", createdAt)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !tweet.CreatedAt.Equal(createdAt) {
+		t.Errorf("Expected CreatedAt to be %v, got %v", createdAt, tweet.CreatedAt)
+	}
+}
+
+func TestNewTweetTrimsWhitespace(t *testing.T) {
+	// Act
+	tweet, err := entity.NewTweet("tweet1", "user1", "  \thello world\n ")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tweet.Content != "hello world" {
+		t.Errorf("Expected trimmed content, got %q", tweet.Content)
+	}
+}
+
+func TestNewTweetRejectsBlankContent(t *testing.T) {
+	// Act
+	_, err := entity.NewTweet("tweet1", "user1", " \n\t ")
+
+	// Assert
+	if err != entity.ErrEmptyTweet {
+		t.Errorf("Expected ErrEmptyTweet, got %v", err)
+	}
+}
+
+func TestNewTweetNormalizesToNFC(t *testing.T) {
+	// Arrange: "é" written as "e" followed by a combining acute accent
+	decomposed := "café"
+
+	// Act
+	tweet, err := entity.NewTweet("tweet1", "user1", decomposed)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tweet.Content != "café" {
+		t.Errorf("Expected precomposed content %q, got %q", "café", tweet.Content)
+	}
+}
+
+func TestNewTweetRejectsControlCharacters(t *testing.T) {
+	for name, content := range map[string]string{
+		"NUL":                    "hello\x00world",
+		"escape":                 "hello\x1b[31mworld",
+		"right-to-left override": "hello ‮dlrow",
+		"isolate":                "hello ⁦world⁩",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := entity.NewTweet("tweet1", "user1", content); err != entity.ErrInvalidContent {
+				t.Errorf("Expected ErrInvalidContent, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewTweetAllowsLineBreaksTabsAndEmoji(t *testing.T) {
+	// Act: the family emoji joins its parts with zero-width joiners
+	_, err := entity.NewTweet("tweet1", "user1", "line one\r\nline\ttwo \U0001F468‍\U0001F469‍\U0001F467")
+
+	// Assert
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestTweetLengthCountsCharactersNotBytes(t *testing.T) {
+	// Arrange: each "é" takes two bytes
+	content := strings.Repeat("é", entity.MaxTweetLength)
+
+	// Act
+	_, err := entity.NewTweet("tweet1", "user1", content)
+
+	// Assert
+	if err != nil {
+		t.Errorf("Expected %d multibyte characters to fit, got %v", entity.MaxTweetLength, err)
+	}
+}
+
+func TestTruncateContent(t *testing.T) {
+	for name, tc := range map[string]struct {
+		content  string
+		n        int
+		expected string
+	}{
+		"fits":               {"hello", 5, "hello"},
+		"ascii":              {"hello world", 5, "hello…"},
+		"trailing space":     {"hello world", 6, "hello…"},
+		"multibyte":          {"ñañañaña", 3, "ñañ…"},
+		"combining accent":   {"café noir", 4, "caf…"},
+		"combining fits":     {"café noir", 5, "café…"},
+		"skin tone emoji":    {"hi \U0001F44B\U0001F3FD there", 3, "hi…"},
+		"zero-width joiners": {"ok \U0001F468‍\U0001F469‍\U0001F467 family", 6, "ok…"},
+		"whole joined emoji": {"ok \U0001F468‍\U0001F469‍\U0001F467 family", 8, "ok \U0001F468‍\U0001F469‍\U0001F467…"},
+		"flags":              {"\U0001F1E6\U0001F1F7\U0001F1FA\U0001F1F8", 3, "\U0001F1E6\U0001F1F7…"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := entity.TruncateContent(tc.content, tc.n); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestTweetMentions(t *testing.T) {
+	for name, tc := range map[string]struct {
+		content  string
+		expected string
+	}{
+		"none":         {"hello world", "[]"},
+		"several":      {"@alice and @Bob_2, meet", "[alice bob_2]"},
+		"repeated":     {"@alice @ALICE @alice!", "[alice]"},
+		"email":        {"write to me@example.com", "[]"},
+		"bare at sign": {"meet @ 5", "[]"},
+		"too long":     {"@abcdefghijklmnop hi", "[]"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tweet, _ := entity.NewTweet("1", "user", tc.content)
+			if got := fmt.Sprint(tweet.Mentions()); got != tc.expected {
+				t.Errorf("Expected mentions %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeLang(t *testing.T) {
+	for input, tc := range map[string]struct {
+		expected string
+		err      error
+	}{
+		"":       {"", nil},
+		"es":     {"es", nil},
+		" EN ":   {"en", nil},
+		"xx":     {"", entity.ErrInvalidLang},
+		"spa":    {"", entity.ErrInvalidLang},
+		"es-AR":  {"", entity.ErrInvalidLang},
+		"e1":     {"", entity.ErrInvalidLang},
+		"inglés": {"", entity.ErrInvalidLang},
+	} {
+		lang, err := entity.NormalizeLang(input)
+		if lang != tc.expected || err != tc.err {
+			t.Errorf("NormalizeLang(%q): expected %q and %v, got %q and %v", input, tc.expected, tc.err, lang, err)
+		}
+	}
+}
+
+func TestProfanityListMatchesWholeWordsOnly(t *testing.T) {
+	profanity := entity.DefaultProfanityList()
+	for _, text := range []string{"what the FUCK", "¡Mierda!", "pendejo…", "vaya cabrón", "fuck👋"} {
+		if !profanity.Contains(text) {
+			t.Errorf("Expected %q to contain profanity", text)
+		}
+	}
+	// Profane words inside longer words are not matched
+	for _, text := range []string{"Greetings from Scunthorpe", "a classic assassin", "computadora", "shitake", "#"} {
+		if profanity.Contains(text) {
+			t.Errorf("Expected %q to be clean", text)
+		}
+	}
+
+	// A custom list replaces the default one
+	custom := entity.NewProfanityList([]string{" Darn", ""})
+	if !custom.Contains("darn it") || custom.Contains("fuck") || custom.Contains("") {
+		t.Errorf("Expected only darn to be profane, got %v", custom)
+	}
+}
+
+func TestProfanityListMask(t *testing.T) {
+	profanity := entity.DefaultProfanityList()
+	for text, expected := range map[string]string{
+		"what the FUCK, Scunthorpe": "what the ****, Scunthorpe",
+		"¡Cabrón! puta puta":        "¡******! **** ****",
+		"clean text":                "clean text",
+	} {
+		masked := profanity.Mask(text)
+		if masked != expected {
+			t.Errorf("Mask(%q): expected %q, got %q", text, expected, masked)
+		}
+		if entity.ContentLength(masked) != entity.ContentLength(text) {
+			t.Errorf("Mask(%q): expected the length to be kept, got %q", text, masked)
+		}
+	}
+}
+
+func TestFirstURL(t *testing.T) {
+	for content, expected := range map[string]string{
+		"no links here": "",
+		"see https://go.dev/doc, then http://a.b/c":   "https://go.dev/doc",
+		"(http://example.com/path?q=1).":              "http://example.com/path?q=1",
+		"ftp://example.com and https:// alone":        "",
+		"prefix:https://example.com/x! and more text": "https://example.com/x",
+	} {
+		if got := entity.FirstURL(content); got != expected {
+			t.Errorf("FirstURL(%q): expected %q, got %q", content, expected, got)
+		}
+	}
+}
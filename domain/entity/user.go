@@ -1,10 +1,29 @@
 package entity
 
+import (
+	"strings"
+	"sync"
+)
+
+// Defines the maximum number of characters allowed in a username
+const MaxUsernameLength = 15
+
 // User in the microblogging platform
+// The following set is guarded by an internal lock, so the methods below
+// are safe to call from concurrent requests for the same user
 type User struct {
 	ID        string
 	Username  string
 	Following map[string]bool // Map of user IDs that this user follows
+	// False while the user has deactivated their account, hiding their profile and tweets
+	Active bool
+	mutex  sync.RWMutex
+}
+
+// Returns the form of a username used to look users up and keep usernames unique,
+// so Alice and alice are the same user; the original casing is kept for display
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
 }
 
 // Creates a new user with the given ID and username
@@ -13,6 +32,7 @@ func NewUser(id, username string) *User {
 		ID:        id,
 		Username:  username,
 		Following: make(map[string]bool),
+		Active:    true,
 	}
 }
 
@@ -23,26 +43,73 @@ func (u *User) Follow(userID string) error {
 		return ErrCannotFollowSelf
 	}
 
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if u.Following == nil {
+		u.Following = make(map[string]bool)
+	}
+	if u.Following[userID] {
+		return ErrAlreadyFollowing
+	}
 	u.Following[userID] = true
 	return nil
 }
 
 // Makes the user unfollow another user
-func (u *User) Unfollow(userID string) {
+func (u *User) Unfollow(userID string) error {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if !u.Following[userID] {
+		return ErrNotFollowing
+	}
 	delete(u.Following, userID)
+	return nil
 }
 
 // Checks if the user is following another user
 func (u *User) IsFollowing(userID string) bool {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+
 	_, following := u.Following[userID]
 	return following
 }
 
 // Returns a slice of user IDs that this user follows
 func (u *User) GetFollowing() []string {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+
 	following := make([]string, 0, len(u.Following))
 	for id := range u.Following {
 		following = append(following, id)
 	}
 	return following
 }
+
+// Returns the number of users this user follows
+func (u *User) FollowingCount() int {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+
+	return len(u.Following)
+}
+
+// Returns a deep copy of the user, including the following set
+func (u *User) Clone() *User {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+
+	following := make(map[string]bool, len(u.Following))
+	for id, v := range u.Following {
+		following[id] = v
+	}
+	return &User{
+		ID:        u.ID,
+		Username:  u.Username,
+		Following: following,
+		Active:    u.Active,
+	}
+}
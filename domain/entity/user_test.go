@@ -1,6 +1,8 @@
 package entity_test
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/develpudu/go-challenge/domain/entity"
@@ -71,14 +73,49 @@ func TestUserUnfollow(t *testing.T) {
 	_ = user.Follow(otherUserID) // First follow the user
 
 	// Act
-	user.Unfollow(otherUserID)
+	err := user.Unfollow(otherUserID)
 
 	// Assert
+	if err != nil {
+		t.Errorf("Expected no error when unfollowing a followed user, got %v", err)
+	}
+
 	if user.IsFollowing(otherUserID) {
 		t.Errorf("Expected user not to be following %s after unfollowing, but IsFollowing returned true", otherUserID)
 	}
 }
 
+func TestUserFollowAlreadyFollowing(t *testing.T) {
+	// Arrange
+	user := entity.NewUser("user1", "testuser1")
+	_ = user.Follow("user2")
+
+	// Act
+	err := user.Follow("user2")
+
+	// Assert
+	if err != entity.ErrAlreadyFollowing {
+		t.Errorf("Expected ErrAlreadyFollowing when following twice, got %v", err)
+	}
+
+	if !user.IsFollowing("user2") {
+		t.Error("Expected user to still be following user2")
+	}
+}
+
+func TestUserUnfollowNotFollowing(t *testing.T) {
+	// Arrange
+	user := entity.NewUser("user1", "testuser1")
+
+	// Act
+	err := user.Unfollow("user2")
+
+	// Assert
+	if err != entity.ErrNotFollowing {
+		t.Errorf("Expected ErrNotFollowing when unfollowing a user not followed, got %v", err)
+	}
+}
+
 func TestGetFollowing(t *testing.T) {
 	// Arrange
 	user := entity.NewUser("user1", "testuser1")
@@ -111,3 +148,85 @@ func TestGetFollowing(t *testing.T) {
 		}
 	}
 }
+
+func TestUserClone(t *testing.T) {
+	// Arrange
+	user := entity.NewUser("user1", "testuser1")
+	_ = user.Follow("user2")
+
+	// Act
+	clone := user.Clone()
+	_ = clone.Follow("user3")
+
+	// Assert
+	if !clone.IsFollowing("user2") {
+		t.Error("Expected clone to keep the original following set")
+	}
+
+	if user.IsFollowing("user3") {
+		t.Error("Expected original user to be unaffected by changes to the clone")
+	}
+}
+
+func TestUserConcurrentFollowUnfollow(t *testing.T) {
+	// Arrange
+	user := entity.NewUser("user1", "testuser1")
+	const n = 100
+
+	// Act: follow n users concurrently while reading, then unfollow the even ones
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(2)
+		go func(id string) {
+			defer wg.Done()
+			_ = user.Follow(id)
+		}(fmt.Sprintf("user-%d", i))
+		go func(id string) {
+			defer wg.Done()
+			_ = user.IsFollowing(id)
+			_ = user.GetFollowing()
+		}(fmt.Sprintf("user-%d", i))
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i += 2 {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			_ = user.Unfollow(id)
+		}(fmt.Sprintf("user-%d", i))
+	}
+	wg.Wait()
+
+	// Assert
+	if count := user.FollowingCount(); count != n/2 {
+		t.Errorf("Expected %d followings, got %d", n/2, count)
+	}
+
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("user-%d", i)
+		if user.IsFollowing(id) != (i%2 == 1) {
+			t.Errorf("Unexpected following state for %s", id)
+		}
+	}
+}
+
+func TestReservedUsernames(t *testing.T) {
+	reserved := entity.DefaultReservedUsernames()
+	for _, name := range []string{"admin", "ADMIN", "Root", " support "} {
+		if !reserved.Contains(name) {
+			t.Errorf("Expected %q to be reserved", name)
+		}
+	}
+	for _, name := range []string{"alice", "administrators", "admin1", "#"} {
+		if reserved.Contains(name) {
+			t.Errorf("Expected %q to be allowed", name)
+		}
+	}
+
+	// A custom list replaces the default one
+	custom := entity.NewReservedUsernames([]string{"Staff ", "", "owner"})
+	if !custom.Contains("staff") || !custom.Contains("OWNER") || custom.Contains("admin") || custom.Contains("") {
+		t.Errorf("Expected only staff and owner to be reserved, got %v", custom)
+	}
+}
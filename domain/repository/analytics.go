@@ -0,0 +1,61 @@
+package repository
+
+import "time"
+
+// Bucket sizes of tweet histograms
+const (
+	HourBucket = time.Hour
+	DayBucket  = 24 * time.Hour
+)
+
+// Number of tweets created in each of a series of consecutive time buckets
+// Buckets are aligned to multiples of their size since the zero time, so hours and days start on UTC boundaries
+type TweetHistogram struct {
+	// Start of the first bucket
+	Start time.Time
+	// Length of every bucket
+	BucketSize time.Duration
+	// Counts[i] is the number of tweets created in [Start + i*BucketSize, Start + (i+1)*BucketSize)
+	Counts []int
+}
+
+// Creates an empty histogram whose buckets cover the range [from, to), widened to bucket boundaries
+func NewTweetHistogram(from, to time.Time, bucketSize time.Duration) *TweetHistogram {
+	start := from.UTC().Truncate(bucketSize)
+	end := to.UTC().Truncate(bucketSize)
+	if end.Before(to) {
+		end = end.Add(bucketSize)
+	}
+	buckets := 0
+	if end.After(start) {
+		buckets = int(end.Sub(start) / bucketSize)
+	}
+	return &TweetHistogram{Start: start, BucketSize: bucketSize, Counts: make([]int, buckets)}
+}
+
+// Returns the end of the last bucket, the first instant the histogram does not cover
+func (h *TweetHistogram) End() time.Time {
+	return h.Start.Add(time.Duration(len(h.Counts)) * h.BucketSize)
+}
+
+// Returns the start of the i-th bucket
+func (h *TweetHistogram) BucketStart(i int) time.Time {
+	return h.Start.Add(time.Duration(i) * h.BucketSize)
+}
+
+// Counts a tweet created at createdAt in its bucket, ignoring times the histogram does not cover
+func (h *TweetHistogram) Add(createdAt time.Time) {
+	if createdAt.Before(h.Start) || !createdAt.Before(h.End()) {
+		return
+	}
+	h.Counts[createdAt.Sub(h.Start)/h.BucketSize]++
+}
+
+// Returns the number of tweets counted in every bucket
+func (h *TweetHistogram) Total() int {
+	total := 0
+	for _, count := range h.Counts {
+		total += count
+	}
+	return total
+}
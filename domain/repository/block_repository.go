@@ -0,0 +1,13 @@
+package repository
+
+// Defines the interface for the blocks between users
+type BlockRepository interface {
+	// Records that a user blocks another; blocking an already blocked user is not an error
+	Block(blockerID, blockedID string) error
+
+	// Removes a block; removing a missing block is not an error
+	Unblock(blockerID, blockedID string) error
+
+	// Checks if a user blocks another; blocks are one-way, so the reverse is checked separately
+	IsBlocked(blockerID, blockedID string) (bool, error)
+}
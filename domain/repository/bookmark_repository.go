@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Defines the interface for bookmark data operations
+type BookmarkRepository interface {
+	// Stores a bookmark; storing an existing bookmark keeps the original one
+	Save(bookmark *entity.Bookmark) error
+
+	// Removes a user's bookmark of a tweet; removing a missing bookmark is not an error
+	Delete(userID, tweetID string) error
+
+	// Retrieves all bookmarks of a user, newest first
+	FindByUserID(userID string) ([]*entity.Bookmark, error)
+}
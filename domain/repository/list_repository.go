@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Defines the interface for list data operations
+type ListRepository interface {
+	// Stores a list in the repository
+	SaveList(list *entity.List) error
+
+	// Retrieves a list by its ID
+	FindListByID(id string) (*entity.List, error)
+
+	// Retrieves all lists owned by a user, oldest first
+	FindListsByOwner(ownerID string) ([]*entity.List, error)
+
+	// Adds a user to a list; adding an existing member is not an error
+	// Returns ErrListNotFound if the list does not exist
+	AddMember(listID, userID string) error
+
+	// Removes a user from a list; removing a non-member is not an error
+	// Returns ErrListNotFound if the list does not exist
+	RemoveMember(listID, userID string) error
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Defines the interface for direct message data operations
+// Messages are stored by conversation, see entity.ConversationIDFor
+type MessageRepository interface {
+	// Stores a message in its conversation
+	Save(message *entity.Message) error
+
+	// Retrieves up to limit of the latest messages of a conversation, oldest first
+	// A non-positive limit returns every message of the conversation
+	FindByConversationID(conversationID string, limit int) ([]*entity.Message, error)
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+const (
+	// Time notifications are kept after they are created
+	NotificationRetention = 30 * 24 * time.Hour
+	// Maximum number of notifications kept per recipient, and counted as unread
+	MaxNotificationsPerUser = 100
+)
+
+// Defines the interface for notification repository operations
+// Notifications are kept for NotificationRetention, and at most MaxNotificationsPerUser per recipient
+type NotificationRepository interface {
+	// Saves a notification for its recipient
+	Save(notification *entity.Notification) error
+
+	// Retrieves up to limit of the notifications of a recipient, newest first, with their read state
+	// Only notifications older than the one with ID afterID are returned, every one when afterID is empty
+	// A non-positive limit returns every kept notification
+	FindByRecipientID(recipientID, afterID string, limit int) ([]*entity.Notification, error)
+
+	// Counts the notifications of a recipient not yet marked as read
+	CountUnread(recipientID string) (int, error)
+
+	// Marks every notification of a recipient created up to readAt as read
+	MarkAllRead(recipientID string, readAt time.Time) error
+}
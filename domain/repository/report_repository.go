@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Defines the interface for report data operations
+type ReportRepository interface {
+	// Stores a report; returns ErrAlreadyReported if the reporter already reported the tweet
+	Save(report *entity.Report) error
+
+	// Retrieves all reports, newest first
+	FindAll() ([]*entity.Report, error)
+}
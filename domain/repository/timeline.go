@@ -0,0 +1,96 @@
+package repository
+
+import "github.com/develpudu/go-challenge/domain/entity"
+
+// Name of the default timeline ranking, newest tweets first
+const ChronologicalRanking = "chronological"
+
+// Name of the timeline ranking that boosts tweets with more replies
+const EngagementRanking = "engagement"
+
+// Names of every timeline ranking, each cached under its own key
+var TimelineRankings = []string{ChronologicalRanking, EngagementRanking}
+
+// Orders the tweets of a timeline for the user viewing it
+type TimelineRanker interface {
+	// Returns the name of the ranking, which is part of the cache key of the timelines it orders
+	Name() string
+
+	// Returns the tweets in the order they are shown to the viewer
+	Rank(tweets []*entity.Tweet, viewer *entity.User) []*entity.Tweet
+}
+
+// Selects which tweets a user's timeline includes
+type TimelineOptions struct {
+	// Includes the user's own tweets besides those of the users they follow
+	IncludeSelf bool
+	// Includes replies to other tweets, which are left out by default
+	IncludeReplies bool
+	// Orders the timeline; nil keeps it reverse-chronological
+	Ranker TimelineRanker
+}
+
+// Returns the options of the default timeline, which includes the user's own tweets but no replies
+func DefaultTimelineOptions() TimelineOptions {
+	return TimelineOptions{IncludeSelf: true}
+}
+
+// Returns the key under which the timeline of a user is cached for these options
+// The default timeline is keyed by the user ID alone, so each variant is cached separately
+func (o TimelineOptions) CacheKey(userID string) string {
+	ranking := ChronologicalRanking
+	if o.Ranker != nil {
+		ranking = o.Ranker.Name()
+	}
+	return timelineCacheKey(userID, o.IncludeSelf, o.IncludeReplies, ranking)
+}
+
+// Builds the cache key of a timeline variant
+func timelineCacheKey(userID string, includeSelf, includeReplies bool, ranking string) string {
+	key := userID
+	if !includeSelf {
+		key += ":following"
+	}
+	if includeReplies {
+		key += ":replies"
+	}
+	if ranking != ChronologicalRanking {
+		key += ":" + ranking
+	}
+	return key
+}
+
+// Removes the tweets these options leave out of a timeline, keeping the order of the rest
+func (o TimelineOptions) Filter(tweets []*entity.Tweet) []*entity.Tweet {
+	if o.IncludeReplies {
+		return tweets
+	}
+	filtered := make([]*entity.Tweet, 0, len(tweets))
+	for _, tweet := range tweets {
+		if tweet.InReplyToID == "" {
+			filtered = append(filtered, tweet)
+		}
+	}
+	return filtered
+}
+
+// Orders a reverse-chronological timeline with the ranker of these options, if any
+func (o TimelineOptions) Rank(tweets []*entity.Tweet, viewer *entity.User) []*entity.Tweet {
+	if o.Ranker == nil {
+		return tweets
+	}
+	return o.Ranker.Rank(tweets, viewer)
+}
+
+// Returns the cache keys of every timeline variant of a user, so they can be invalidated together
+func TimelineCacheKeys(userID string) []string {
+	keys := make([]string, 0, 4*len(TimelineRankings))
+	for _, ranking := range TimelineRankings {
+		for _, includeSelf := range []bool{true, false} {
+			for _, includeReplies := range []bool{false, true} {
+				keys = append(keys, timelineCacheKey(userID, includeSelf, includeReplies, ranking))
+			}
+		}
+	}
+	return keys
+}
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/develpudu/go-challenge/domain/entity"
 )
 
@@ -9,19 +12,45 @@ type TweetRepository interface {
 	// Stores a tweet in the repository
 	Save(tweet *entity.Tweet) error
 
+	// Stores several tweets at once, invalidating cached timelines once for the whole batch
+	SaveAll(tweets []*entity.Tweet) error
+
+	// Replaces an existing tweet, returns ErrTweetNotFound if it does not exist
+	Update(tweet *entity.Tweet) error
+
 	// Retrieves a tweet by its ID
 	FindByID(id string) (*entity.Tweet, error)
 
 	// Retrieves all tweets by a specific user
 	FindByUserID(userID string) ([]*entity.Tweet, error)
 
+	// Returns the number of tweets by a specific user that have not expired at now, without retrieving them
+	CountByUserID(userID string, now time.Time) (int, error)
+
+	// Returns the creation times of the tweets by a specific user created between from and to, both inclusive,
+	// in no particular order and without retrieving the tweets
+	FindCreatedAtByUserID(userID string, from, to time.Time) ([]time.Time, error)
+
 	// Retrieves all tweets
 	FindAll() ([]*entity.Tweet, error)
 
+	// Returns the number of stored tweets
+	Count() (int, error)
+
+	// Counts the stored tweets created in each bucket of the histogram
+	CountCreatedPerBucket(histogram *TweetHistogram) error
+
+	// Retrieves all tweets of a conversation, including its root,
+	// ordered by creation time (oldest first)
+	FindByConversationID(conversationID string) ([]*entity.Tweet, error)
+
 	// Removes a tweet from the repository
 	Delete(id string) error
 
-	// Retrieves tweets from users that a specific user follows
-	// ordered by creation time (newest first)
-	GetTimeline(userID string) ([]*entity.Tweet, error)
+	// Adds a number of impressions to a stored tweet, returns ErrTweetNotFound if it does not exist
+	AddImpressions(id string, count int64) error
+
+	// Retrieves tweets from users that a specific user follows, and their own tweets
+	// and replies when the options include them, ordered by creation time (newest first)
+	GetTimeline(ctx context.Context, userID string, opts TimelineOptions) ([]*entity.Tweet, error)
 }
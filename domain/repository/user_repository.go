@@ -7,17 +7,47 @@ import (
 // Defines the interface for user data operations
 type UserRepository interface {
 	// Stores a user in the repository
+	// Returns ErrUsernameTaken if another user has the same normalized username
 	Save(user *entity.User) error
 
 	// Retrieves a user by their ID
 	FindByID(id string) (*entity.User, error)
 
+	// Retrieves the users with the given IDs in a single batch, keyed by ID
+	// IDs of users that do not exist are absent from the map
+	FindByIDs(ids []string) (map[string]*entity.User, error)
+
+	// Retrieves a user by their username in any case, nil if there is none
+	FindByUsername(username string) (*entity.User, error)
+
 	// Retrieves all users
 	FindAll() ([]*entity.User, error)
 
+	// Retrieves up to limit users listed after the user with ID afterID, starting from the first user when afterID is empty
+	// Users are listed in an order of the repository's choosing that stays stable while paging,
+	// so the ID of the last user of a page resumes the listing. A non-positive limit returns all remaining users
+	FindAllPage(afterID string, limit int) ([]*entity.User, error)
+
+	// Returns the number of stored users
+	Count() (int, error)
+
+	// Returns the number of follow relations between users
+	CountFollows() (int, error)
+
+	// Returns the number of users that follow a specific user
+	CountFollowers(userID string) (int, error)
+
+	// Returns the number of users a specific user follows, ErrUserNotFound if the user does not exist
+	CountFollowing(userID string) (int, error)
+
 	// Udates an existing user
+	// Returns ErrUsernameTaken if another user has the same normalized username
 	Update(user *entity.User) error
 
+	// Stores whether a user's account is active without rewriting the rest of the user
+	// Returns ErrUserNotFound if the user does not exist
+	SetActive(userID string, active bool) error
+
 	// Removes a user from the repository
 	Delete(id string) error
 
@@ -26,4 +56,20 @@ type UserRepository interface {
 
 	// Retrieves all users that a specific user follows
 	FindFollowing(userID string) ([]*entity.User, error)
+
+	// Retrieves up to limit followers of a user with an ID greater than afterID, ordered by ID
+	// A non-positive limit returns all remaining followers
+	FindFollowersPage(userID, afterID string, limit int) ([]*entity.User, error)
+
+	// Retrieves up to limit users followed by a user with an ID greater than afterID, ordered by ID
+	// A non-positive limit returns all remaining followed users
+	FindFollowingPage(userID, afterID string, limit int) ([]*entity.User, error)
+
+	// Records that a user follows another user
+	// Both sides of the relation must be stored atomically
+	Follow(followerID, followedID string) error
+
+	// Removes the follow relation between two users
+	// Both sides of the relation must be removed atomically
+	Unfollow(followerID, followedID string) error
 }
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/develpudu/go-challenge/infrastructure/tracing"
+)
+
+// Maximum number of request body bytes included in debug logs
+const maxLoggedBodySize = 512
+
+// Value logged in place of sensitive header values
+const redactedValue = "[REDACTED]"
+
+// Headers whose values never appear in logs, as they carry credentials
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Admin-Token"}
+
+// Wraps a handler so every request is logged at info level once it completes,
+// with its method, path, status, response size, latency and request ID
+// At debug level the request headers and the start of the body are logged too, see logRequestDetails
+// It must run inside tracing.Middleware for the request ID to be known
+func AccessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		if slog.Default().Enabled(r.Context(), slog.LevelDebug) {
+			logRequestDetails(r)
+		}
+		lw := &accessLogWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(lw, r)
+
+		slog.LogAttrs(r.Context(), slog.LevelInfo, "HTTP request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", lw.status),
+			slog.Int("bytes", lw.bytes),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("requestID", tracing.RequestIDFromContext(r.Context())),
+		)
+	})
+}
+
+// Logs the headers and the start of the body of a request at debug level
+// Sensitive headers are redacted and the body is truncated to maxLoggedBodySize bytes;
+// the bytes read are put back so the handler still receives the whole body
+func logRequestDetails(r *http.Request) {
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		body, _ = io.ReadAll(io.LimitReader(r.Body, maxLoggedBodySize+1))
+		r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+	}
+
+	slog.LogAttrs(r.Context(), slog.LevelDebug, "HTTP request details",
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.Any("headers", redactHeaders(r.Header)),
+		slog.String("body", truncateBody(body)),
+	)
+}
+
+// Returns a copy of the headers with the values of sensitive headers replaced, safe to log
+func redactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range sensitiveHeaders {
+		if _, ok := redacted[http.CanonicalHeaderKey(name)]; ok {
+			redacted.Set(name, redactedValue)
+		}
+	}
+	return redacted
+}
+
+// Returns the body as a string, cut to maxLoggedBodySize bytes and marked when cut
+func truncateBody(body []byte) string {
+	if len(body) <= maxLoggedBodySize {
+		return string(body)
+	}
+	return string(body[:maxLoggedBodySize]) + "...[truncated]"
+}
+
+// Reads from a reader and closes a separate closer, restoring a partly read body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Records the status code and the number of body bytes written by the wrapped handler
+type accessLogWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+// Records the first status code written, as the one sent to the client
+func (w *accessLogWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Counts the bytes written to the body
+func (w *accessLogWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += n
+	return n, err
+}
+
+// Flushes the wrapped writer, if it supports flushing, so streamed responses keep streaming
+func (w *accessLogWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+)
+
+// Header carrying the admin token on moderation requests
+const AdminTokenHeader = "X-Admin-Token"
+
+// Restricts handlers to moderators presenting the configured admin token
+type AdminMiddleware struct {
+	token string
+}
+
+// Creates a new admin middleware
+// An empty token disables the admin routes, rejecting every request
+func NewAdminMiddleware(token string) *AdminMiddleware {
+	return &AdminMiddleware{
+		token: token,
+	}
+}
+
+// Wraps a handler reserved to moderators
+// Responds 403 unless the X-Admin-Token header matches the configured token
+func (m *AdminMiddleware) RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(AdminTokenHeader)
+		if m.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(m.token)) != 1 {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": "admin token is missing or invalid"})
+			return
+		}
+		next(w, r)
+	}
+}
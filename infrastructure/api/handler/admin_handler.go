@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/infrastructure/tracing"
+)
+
+// Handles HTTP requests for moderation
+type AdminHandler struct {
+	tweetUseCase *usecase.TweetUseCase
+	statsUseCase *usecase.StatsUseCase
+	admin        *AdminMiddleware
+}
+
+// Creates a new admin handler
+func NewAdminHandler(tweetUseCase *usecase.TweetUseCase, statsUseCase *usecase.StatsUseCase, admin *AdminMiddleware) *AdminHandler {
+	return &AdminHandler{
+		tweetUseCase: tweetUseCase,
+		statsUseCase: statsUseCase,
+		admin:        admin,
+	}
+}
+
+// Represents the response body for platform stats
+type StatsResponse struct {
+	Users                int     `json:"users"`
+	Tweets               int     `json:"tweets"`
+	Follows              int     `json:"follows"`
+	AverageTweetsPerUser float64 `json:"average_tweets_per_user"`
+}
+
+// Represents the response body for tweet analytics
+type TweetHistogramResponse struct {
+	From    time.Time             `json:"from"`
+	To      time.Time             `json:"to"`
+	Bucket  string                `json:"bucket"`
+	Buckets []TweetBucketResponse `json:"buckets"`
+	Total   int                   `json:"total"`
+}
+
+// Represents the number of tweets created in one bucket
+type TweetBucketResponse struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// Registers the admin routes
+func (h *AdminHandler) RegisterRoutes() {
+	http.HandleFunc("DELETE /admin/tweets/{id}", h.admin.RequireAdmin(h.deleteTweet))
+	http.HandleFunc("GET /admin/stats", h.admin.RequireAdmin(h.getStats))
+	http.HandleFunc("GET /admin/analytics/tweets", h.admin.RequireAdmin(h.getTweetAnalytics))
+}
+
+// Deletes any tweet regardless of its author
+func (h *AdminHandler) deleteTweet(w http.ResponseWriter, r *http.Request) {
+	// Validate the tweet ID in the path
+	tweetID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	// Delete tweet
+	tweet, err := h.tweetUseCase.DeleteTweetAsModerator(tweetID)
+	if err != nil {
+		if err == entity.ErrTweetNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			writeErrorBody(w, r, err)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Record the moderator action
+	slog.InfoContext(r.Context(), "Moderator deleted tweet", "tweetID", tweet.ID, "authorID", tweet.UserID, "requestID", tracing.RequestIDFromContext(r.Context()))
+
+	// Return response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Returns the platform totals
+func (h *AdminHandler) getStats(w http.ResponseWriter, r *http.Request) {
+	// Get stats
+	stats, err := h.statsUseCase.GetStats()
+	if err != nil {
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(StatsResponse{
+		Users:                stats.Users,
+		Tweets:               stats.Tweets,
+		Follows:              stats.Follows,
+		AverageTweetsPerUser: stats.AverageTweetsPerUser,
+	})
+}
+
+// Returns the number of tweets created per hour or day in the range given by the from and to
+// query parameters, both RFC 3339 timestamps; bucket defaults to hour
+func (h *AdminHandler) getTweetAnalytics(w http.ResponseWriter, r *http.Request) {
+	// Parse range and bucket
+	query := r.URL.Query()
+	from, fromErr := time.Parse(time.RFC3339, query.Get("from"))
+	to, toErr := time.Parse(time.RFC3339, query.Get("to"))
+	if fromErr != nil || toErr != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeErrorBody(w, r, entity.ErrInvalidTimeRange)
+		return
+	}
+	bucket := query.Get("bucket")
+	if bucket == "" {
+		bucket = usecase.HourBucket
+	}
+
+	// Count tweets
+	histogram, err := h.statsUseCase.GetTweetHistogram(from, to, bucket)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidTimeRange) || errors.Is(err, entity.ErrTimeRangeTooLarge) || errors.Is(err, entity.ErrUnknownBucket) {
+			w.WriteHeader(http.StatusBadRequest)
+			writeErrorBody(w, r, err)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Build response
+	response := TweetHistogramResponse{
+		From:    histogram.Start,
+		To:      histogram.End(),
+		Bucket:  bucket,
+		Buckets: make([]TweetBucketResponse, len(histogram.Counts)),
+		Total:   histogram.Total(),
+	}
+	for i, count := range histogram.Counts {
+		response.Buckets[i] = TweetBucketResponse{Start: histogram.BucketStart(i), Count: count}
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Handles HTTP requests related to bookmarks
+// Every route acts on the bookmarks of the user in the User-ID header
+type BookmarkHandler struct {
+	bookmarkUseCase *usecase.BookmarkUseCase
+	identity        *IdentityMiddleware
+}
+
+// Creates a new bookmark handler
+func NewBookmarkHandler(bookmarkUseCase *usecase.BookmarkUseCase, identity *IdentityMiddleware) *BookmarkHandler {
+	return &BookmarkHandler{
+		bookmarkUseCase: bookmarkUseCase,
+		identity:        identity,
+	}
+}
+
+// Registers the bookmark routes
+func (h *BookmarkHandler) RegisterRoutes() {
+	http.HandleFunc("POST /tweets/{id}/bookmark", h.identity.RequireUser(h.addBookmark))
+	http.HandleFunc("DELETE /tweets/{id}/bookmark", h.identity.RequireUser(h.removeBookmark))
+	http.HandleFunc("GET /users/bookmarks", h.identity.RequireUser(h.listBookmarks))
+}
+
+// Bookmarks a tweet for the requesting user
+func (h *BookmarkHandler) addBookmark(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Validate the tweet ID in the path
+	tweetID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	// Add bookmark
+	err := h.bookmarkUseCase.AddBookmark(userID, tweetID)
+	if err != nil {
+		if err == entity.ErrUserNotFound || err == entity.ErrTweetNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			writeErrorBody(w, r, err)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Return success response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Removes a tweet from the requesting user's bookmarks
+func (h *BookmarkHandler) removeBookmark(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Validate the tweet ID in the path
+	tweetID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	// Remove bookmark
+	err := h.bookmarkUseCase.RemoveBookmark(userID, tweetID)
+	if err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Return success response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Returns the tweets bookmarked by the requesting user
+func (h *BookmarkHandler) listBookmarks(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Get bookmarked tweets
+	tweets, err := h.bookmarkUseCase.ListBookmarks(userID)
+	if err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Convert to response format, with authors
+	response := toTweetResponses(tweets)
+	embedAuthors(r, response, h.bookmarkUseCase.GetAuthors)
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
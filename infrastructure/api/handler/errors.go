@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	"golang.org/x/text/language"
+)
+
+// Delay suggested to throttled clients when the cause of the throttling gives none
+const defaultRetryAfter = time.Second
+
+// Represents the response body of a failed request
+// Code identifies domain errors independently of the language of the message
+type ErrorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
+}
+
+// Codes of the domain errors, matched with errors.Is so wrapped errors keep their code
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{entity.ErrCannotFollowSelf, "cannot_follow_self"},
+	{entity.ErrTweetTooLong, "tweet_too_long"},
+	{entity.ErrEmptyTweet, "empty_tweet"},
+	{entity.ErrInvalidContent, "invalid_content"},
+	{entity.ErrProfanity, "profanity"},
+	{entity.ErrUsernameReserved, "username_reserved"},
+	{entity.ErrUsernameTaken, "username_taken"},
+	{entity.ErrUserNotFound, "user_not_found"},
+	{entity.ErrTweetNotFound, "tweet_not_found"},
+	{entity.ErrInvalidExpiration, "invalid_expiration"},
+	{entity.ErrInvalidLang, "invalid_lang"},
+	{entity.ErrDuplicateTweet, "duplicate_tweet"},
+	{entity.ErrTweetRateLimited, "tweet_rate_limited"},
+	{entity.ErrNotTweetAuthor, "not_tweet_author"},
+	{entity.ErrEditWindowExpired, "edit_window_expired"},
+	{entity.ErrBlocked, "blocked"},
+	{entity.ErrAlreadyFollowing, "already_following"},
+	{entity.ErrNotFollowing, "not_following"},
+	{entity.ErrPathNotFound, "path_not_found"},
+	{entity.ErrListNotFound, "list_not_found"},
+	{entity.ErrListNameRequired, "list_name_required"},
+	{entity.ErrListNameTooLong, "list_name_too_long"},
+	{entity.ErrNotListOwner, "not_list_owner"},
+	{entity.ErrReportReasonRequired, "report_reason_required"},
+	{entity.ErrReportReasonTooLong, "report_reason_too_long"},
+	{entity.ErrAlreadyReported, "already_reported"},
+	{entity.ErrInvalidID, "invalid_id"},
+	{entity.ErrInvalidCursor, "invalid_cursor"},
+	{entity.ErrInvalidCreatedAt, "invalid_created_at"},
+	{entity.ErrImportTooLarge, "import_too_large"},
+	{entity.ErrUnknownRanking, "unknown_ranking"},
+	{entity.ErrInvalidTimeRange, "invalid_time_range"},
+	{entity.ErrTimeRangeTooLarge, "time_range_too_large"},
+	{entity.ErrUnknownBucket, "unknown_bucket"},
+	{entity.ErrCannotMessageSelf, "cannot_message_self"},
+	{entity.ErrEmptyMessage, "empty_message"},
+	{entity.ErrMessageTooLong, "message_too_long"},
+	{entity.ErrMessagingBlocked, "messaging_blocked"},
+	{entity.ErrCannotBlockSelf, "cannot_block_self"},
+	{entity.ErrConcurrentUpdate, "concurrent_update"},
+	{entity.ErrThrottled, "throttled"},
+}
+
+// Messages of the domain errors by language and code
+// English is the language of the domain errors themselves, so it needs no entry
+var errorMessages = map[language.Tag]map[string]string{
+	language.Spanish: {
+		"cannot_follow_self":     "un usuario no puede seguirse a sí mismo",
+		"tweet_too_long":         "el tweet supera el límite de caracteres",
+		"empty_tweet":            "el contenido del tweet está vacío",
+		"invalid_content":        "el tweet contiene caracteres de control no permitidos",
+		"profanity":              "el tweet contiene palabras no permitidas",
+		"username_reserved":      "el nombre de usuario está reservado",
+		"username_taken":         "el nombre de usuario ya está en uso",
+		"user_not_found":         "usuario no encontrado",
+		"tweet_not_found":        "tweet no encontrado",
+		"invalid_expiration":     "la expiración del tweet debe ser positiva",
+		"invalid_lang":           "el idioma del tweet debe ser un código ISO 639-1",
+		"duplicate_tweet":        "el tweet repite el último tweet publicado",
+		"tweet_rate_limited":     "demasiados tweets publicados recientemente",
+		"not_tweet_author":       "el usuario no es el autor de este tweet",
+		"edit_window_expired":    "el tweet ya no se puede editar",
+		"blocked":                "el usuario está bloqueado",
+		"already_following":      "el usuario ya sigue a este usuario",
+		"not_following":          "el usuario no sigue a este usuario",
+		"path_not_found":         "no hay un camino de seguidores entre los usuarios",
+		"list_not_found":         "lista no encontrada",
+		"list_name_required":     "el nombre de la lista es obligatorio",
+		"list_name_too_long":     "el nombre de la lista supera el límite de caracteres",
+		"not_list_owner":         "el usuario no es dueño de esta lista",
+		"report_reason_required": "el motivo del reporte es obligatorio",
+		"report_reason_too_long": "el motivo del reporte supera el límite de caracteres",
+		"already_reported":       "el usuario ya reportó este tweet",
+		"invalid_id":             "ID inválido",
+		"invalid_cursor":         "cursor de paginación inválido",
+		"invalid_created_at":     "la fecha de creación del tweet es obligatoria y no puede ser futura",
+		"import_too_large":       "demasiados tweets para importar de una vez",
+		"unknown_ranking":        "orden de timeline desconocido",
+		"invalid_time_range":     "el rango de tiempo debe terminar después de empezar",
+		"time_range_too_large":   "el rango de tiempo abarca demasiados intervalos",
+		"unknown_bucket":         "intervalo de histograma desconocido",
+		"cannot_message_self":    "un usuario no puede enviarse mensajes a sí mismo",
+		"empty_message":          "el contenido del mensaje está vacío",
+		"message_too_long":       "el mensaje supera el límite de caracteres",
+		"messaging_blocked":      "los mensajes entre estos usuarios están bloqueados",
+		"cannot_block_self":      "un usuario no puede bloquearse a sí mismo",
+		"concurrent_update":      "el recurso fue modificado por otra solicitud, vuelve a obtenerlo y reintenta",
+		"throttled":              "demasiadas solicitudes, intenta de nuevo más tarde",
+	},
+}
+
+// Languages of error messages, the first one being the default
+var errorLanguages = []language.Tag{language.English, language.Spanish}
+
+// Picks one of errorLanguages from the Accept-Language header
+var errorLanguageMatcher = language.NewMatcher(errorLanguages)
+
+// Returns the code of a domain error, empty for any other error
+func errorCode(err error) string {
+	for _, known := range errorCodes {
+		if errors.Is(err, known.err) {
+			return known.code
+		}
+	}
+	return ""
+}
+
+// Returns the error response for err in the language requested by the Accept-Language header
+// Errors without a translation keep their own message
+func localizeError(r *http.Request, err error) ErrorResponse {
+	response := ErrorResponse{Error: err.Error(), Code: errorCode(err)}
+	if response.Code == "" {
+		return response
+	}
+	tags, _, _ := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	_, index, _ := errorLanguageMatcher.Match(tags...)
+	if message, ok := errorMessages[errorLanguages[index]][response.Code]; ok {
+		response.Error = message
+	}
+	return response
+}
+
+// Writes the body of an error response, localized for the request
+// The status code must already be written
+func writeErrorBody(w http.ResponseWriter, r *http.Request, err error) {
+	json.NewEncoder(w).Encode(localizeError(r, err))
+}
+
+// Writes the status of an error the handler does not map to a status itself
+// Throttled errors get 429 with a Retry-After header, unresolved concurrent updates 409, blocks 403,
+// any other error 500
+func writeErrorStatus(w http.ResponseWriter, err error) {
+	var throttled *entity.ThrottledError
+	switch {
+	case errors.Is(err, entity.ErrBlocked):
+		w.WriteHeader(http.StatusForbidden)
+	case errors.Is(err, entity.ErrConcurrentUpdate):
+		w.WriteHeader(http.StatusConflict)
+	case errors.As(err, &throttled) && throttled.RetryAfter > 0:
+		writeTooManyRequests(w, throttled.RetryAfter)
+	case errors.Is(err, entity.ErrThrottled):
+		writeTooManyRequests(w, defaultRetryAfter)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// Writes a 429 status with a Retry-After header holding the delay in whole seconds, rounded up
+// Every throttled response goes through here, so clients always learn when to retry
+func writeTooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := max(int(math.Ceil(retryAfter.Seconds())), 1)
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	w.WriteHeader(http.StatusTooManyRequests)
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Returns a weak ETag for a response listing the tweets, in order
+// It changes when a tweet is added, removed, reordered or edited, and when its link preview is stored,
+// which leaves UpdatedAt untouched; counters such as impressions are left out
+func tweetsETag(tweets []*entity.Tweet) string {
+	hash := sha256.New()
+	for _, tweet := range tweets {
+		hash.Write([]byte(tweet.ID))
+		hash.Write([]byte{0})
+		hash.Write([]byte(tweet.UpdatedAt.UTC().Format(time.RFC3339Nano)))
+		hash.Write([]byte{0})
+		if preview := tweet.LinkPreview; preview != nil {
+			for _, field := range []string{preview.URL, preview.Title, preview.Description, preview.ImageURL} {
+				hash.Write([]byte(field))
+				hash.Write([]byte{0})
+			}
+		}
+		hash.Write([]byte{0})
+	}
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// Sets the ETag header and, when the If-None-Match header of the request matches it, writes a 304 response
+// Returns true if the response was written and the handler must not send a body
+func writeNotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// Checks if an If-None-Match header lists the ETag, using the weak comparison
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Column headers of the CSV tweet export, in the order of csvRecord
+var csvExportHeader = []string{"id", "user_id", "content", "created_at", "in_reply_to_id", "conversation_id", "expires_at"}
+
+// Number of exported tweets after which the response is flushed to the client
+const exportFlushEvery = 100
+
+// Writes the tweets as a JSON array, encoding and flushing them in batches
+// rather than building the whole document in memory
+func writeTweetsJSON(w http.ResponseWriter, tweets []*entity.Tweet) error {
+	if _, err := w.Write([]byte("[")); err != nil {
+		return err
+	}
+	for i, tweet := range tweets {
+		if i > 0 {
+			if _, err := w.Write([]byte(",")); err != nil {
+				return err
+			}
+		}
+		data, err := json.Marshal(toTweetResponse(tweet))
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		flushEvery(w, i)
+	}
+	_, err := w.Write([]byte("]\n"))
+	return err
+}
+
+// Writes the tweets as CSV with a header row, flushing them in batches
+func writeTweetsCSV(w http.ResponseWriter, tweets []*entity.Tweet) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvExportHeader); err != nil {
+		return err
+	}
+	for i, tweet := range tweets {
+		if err := writer.Write(csvRecord(toTweetResponse(tweet))); err != nil {
+			return err
+		}
+		if (i+1)%exportFlushEvery == 0 {
+			writer.Flush()
+			flushEvery(w, i)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Returns the CSV fields of a tweet, in the order of csvExportHeader
+func csvRecord(tweet TweetResponse) []string {
+	return []string{tweet.ID, tweet.UserID, tweet.Content, tweet.CreatedAt, tweet.InReplyToID, tweet.ConversationID, tweet.ExpiresAt}
+}
+
+// Flushes the response after every exportFlushEvery tweets, if the writer supports it
+func flushEvery(w http.ResponseWriter, i int) {
+	if (i+1)%exportFlushEvery != 0 {
+		return
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
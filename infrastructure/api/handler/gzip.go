@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+)
+
+// Responses smaller than this are sent uncompressed, as gzip would barely shrink them
+const gzipMinSize = 1024
+
+// Wraps a handler so responses are gzip-compressed for clients that accept it
+// Small responses and responses that already set a Content-Encoding are left untouched
+func GzipMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		defer gw.finish()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// Checks if the Accept-Encoding header of the request allows gzip
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.EqualFold(strings.TrimSpace(name), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+// Buffers the start of a response until it is known whether it is worth compressing
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	buffer      []byte
+	gz          *gzip.Writer
+	passthrough bool
+}
+
+// Records the status code, sent once the encoding has been decided
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+// Buffers the body until it reaches gzipMinSize, then compresses or passes it through
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	switch {
+	case w.gz != nil:
+		return w.gz.Write(p)
+	case w.passthrough:
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.buffer = append(w.buffer, p...)
+	if len(w.buffer) < gzipMinSize {
+		return len(p), nil
+	}
+
+	if w.Header().Get("Content-Encoding") != "" {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(w.status)
+		_, err := w.ResponseWriter.Write(w.buffer)
+		return len(p), err
+	}
+
+	w.Header().Set("Content-Encoding", "gzip")
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	w.gz = gzip.NewWriter(w.ResponseWriter)
+	_, err := w.gz.Write(w.buffer)
+	return len(p), err
+}
+
+// Flushes the compressed stream, or sends a small response as is
+func (w *gzipResponseWriter) finish() {
+	if w.gz != nil {
+		w.gz.Close()
+		return
+	}
+	if !w.passthrough {
+		w.ResponseWriter.WriteHeader(w.status)
+		w.ResponseWriter.Write(w.buffer)
+	}
+}
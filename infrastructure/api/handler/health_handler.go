@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Cache states reported by GET /ready
+const (
+	cacheUp       = "up"
+	cacheDown     = "down"
+	cacheDisabled = "disabled"
+)
+
+// Reports whether the cache answered its last health check, like cache.HealthMonitor
+type CacheHealth interface {
+	Reachable() bool
+}
+
+// Represents the response body of the readiness check
+type ReadyResponse struct {
+	Status string `json:"status"`
+	Cache  string `json:"cache"`
+}
+
+// Handles HTTP requests for the readiness check
+type HealthHandler struct {
+	cache CacheHealth
+}
+
+// Creates a new health handler; a nil cache health reports the cache as disabled
+func NewHealthHandler(cache CacheHealth) *HealthHandler {
+	return &HealthHandler{cache: cache}
+}
+
+// Registers the readiness route
+func (h *HealthHandler) RegisterRoutes() {
+	http.HandleFunc("GET /ready", h.getReady)
+}
+
+// Returns the readiness of the service and the state of the cache
+// The cache state comes from the last background check, so Redis is never pinged here.
+// The service works without the cache, so a cache outage is reported but keeps the status 200
+func (h *HealthHandler) getReady(w http.ResponseWriter, r *http.Request) {
+	response := ReadyResponse{Status: "ready", Cache: cacheDisabled}
+	if h.cache != nil {
+		response.Cache = cacheDown
+		if h.cache.Reachable() {
+			response.Cache = cacheUp
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
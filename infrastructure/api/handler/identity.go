@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Key under which the resolved user is stored in the request context
+type userContextKey struct{}
+
+// Resolves the User-ID header to an existing user before running a handler
+type IdentityMiddleware struct {
+	userUseCase *usecase.UserUseCase
+}
+
+// Creates a new identity middleware
+func NewIdentityMiddleware(userUseCase *usecase.UserUseCase) *IdentityMiddleware {
+	return &IdentityMiddleware{
+		userUseCase: userUseCase,
+	}
+}
+
+// Wraps a handler that acts on behalf of the requesting user
+// Responds 401 if no identity is given and 404 if the user does not exist,
+// otherwise stores the user in the request context for currentUser
+func (m *IdentityMiddleware) RequireUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := requestUserID(r)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			writeErrorBody(w, r, err)
+			return
+		}
+
+		user, err := m.userUseCase.GetUser(userID)
+		if err != nil {
+			if err == entity.ErrUserNotFound {
+				w.WriteHeader(http.StatusNotFound)
+				writeErrorBody(w, r, err)
+				return
+			}
+			writeErrorStatus(w, err)
+			writeErrorBody(w, r, err)
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), userContextKey{}, user)))
+	}
+}
+
+// Returns the ID of the requesting user
+// An "Authorization: Bearer <userID>" header takes precedence over the legacy User-ID header
+func requestUserID(r *http.Request) (string, error) {
+	if authorization := r.Header.Get("Authorization"); authorization != "" {
+		scheme, token, found := strings.Cut(authorization, " ")
+		token = strings.TrimSpace(token)
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return "", errors.New("Authorization header must use the Bearer scheme")
+		}
+		return token, nil
+	}
+
+	if userID := r.Header.Get("User-ID"); userID != "" {
+		return userID, nil
+	}
+	return "", errors.New("Authorization or User-ID header is required")
+}
+
+// Returns the user resolved by RequireUser
+// Only valid inside handlers wrapped by the identity middleware
+func currentUser(r *http.Request) *entity.User {
+	user, _ := r.Context().Value(userContextKey{}).(*entity.User)
+	return user
+}
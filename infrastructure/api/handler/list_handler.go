@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Handles HTTP requests related to lists
+type ListHandler struct {
+	listUseCase *usecase.ListUseCase
+	identity    *IdentityMiddleware
+}
+
+// Represents the request body for creating a list
+type CreateListRequest struct {
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+}
+
+// Represents the request body for adding a member to a list
+type AddListMemberRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// Represents the response body for list-related operations
+type ListResponse struct {
+	ID        string   `json:"id"`
+	OwnerID   string   `json:"owner_id"`
+	Name      string   `json:"name"`
+	Members   []string `json:"members"`
+	CreatedAt string   `json:"created_at"`
+}
+
+// Creates a new list handler
+func NewListHandler(listUseCase *usecase.ListUseCase, identity *IdentityMiddleware) *ListHandler {
+	return &ListHandler{
+		listUseCase: listUseCase,
+		identity:    identity,
+	}
+}
+
+// Registers the list routes
+func (h *ListHandler) RegisterRoutes() {
+	http.HandleFunc("POST /lists", h.identity.RequireUser(h.createList))
+	http.HandleFunc("GET /lists", h.identity.RequireUser(h.getLists))
+	http.HandleFunc("POST /lists/{id}/members", h.identity.RequireUser(h.addMember))
+	http.HandleFunc("DELETE /lists/{id}/members/{userID}", h.identity.RequireUser(h.removeMember))
+	http.HandleFunc("GET /lists/{id}/timeline", h.getListTimeline)
+}
+
+// Creates a new list owned by the requesting user
+func (h *ListHandler) createList(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Parse request body
+	var req CreateListRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Create list
+	list, err := h.listUseCase.CreateList(userID, req.Name, req.Members)
+	if err != nil {
+		writeListError(w, r, err)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toListResponse(list))
+}
+
+// Returns the lists owned by the requesting user
+func (h *ListHandler) getLists(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Get lists
+	lists, err := h.listUseCase.GetListsByOwner(userID)
+	if err != nil {
+		writeListError(w, r, err)
+		return
+	}
+
+	// Convert to response format
+	response := make([]ListResponse, len(lists))
+	for i, list := range lists {
+		response[i] = toListResponse(list)
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Adds a member to a list owned by the requesting user
+func (h *ListHandler) addMember(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Validate the list ID in the path
+	listID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var req AddListMemberRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Validate request
+	if req.UserID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "user_id is required"})
+		return
+	}
+
+	// Add member
+	if err := h.listUseCase.AddMember(userID, listID, req.UserID); err != nil {
+		writeListError(w, r, err)
+		return
+	}
+
+	// Return success response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Removes a member from a list owned by the requesting user
+func (h *ListHandler) removeMember(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Validate the list ID in the path
+	listID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	// Remove member
+	if err := h.listUseCase.RemoveMember(userID, listID, r.PathValue("userID")); err != nil {
+		writeListError(w, r, err)
+		return
+	}
+
+	// Return success response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Returns the timeline of a list
+func (h *ListHandler) getListTimeline(w http.ResponseWriter, r *http.Request) {
+	// Validate the list ID in the path
+	listID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	// Get timeline
+	tweets, err := h.listUseCase.GetListTimeline(listID)
+	if err != nil {
+		writeListError(w, r, err)
+		return
+	}
+
+	// Convert to response format, with authors
+	response := toTweetResponses(tweets)
+	embedAuthors(r, response, h.listUseCase.GetAuthors)
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Converts a list to its response format
+func toListResponse(list *entity.List) ListResponse {
+	return ListResponse{
+		ID:        list.ID,
+		OwnerID:   list.OwnerID,
+		Name:      list.Name,
+		Members:   list.GetMembers(),
+		CreatedAt: list.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// Writes the status code and error body matching a list use case error
+func writeListError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case entity.ErrListNotFound, entity.ErrUserNotFound:
+		w.WriteHeader(http.StatusNotFound)
+	case entity.ErrNotListOwner:
+		w.WriteHeader(http.StatusForbidden)
+	case entity.ErrListNameRequired, entity.ErrListNameTooLong:
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		writeErrorStatus(w, err)
+	}
+	writeErrorBody(w, r, err)
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Default and maximum number of messages returned from a conversation
+const (
+	defaultConversationLimit = 50
+	maxConversationLimit     = 100
+)
+
+// Represents the request body for sending a direct message
+type SendMessageRequest struct {
+	RecipientID string `json:"recipient_id"`
+	Content     string `json:"content"`
+}
+
+// Represents a direct message in the response
+type MessageResponse struct {
+	ID          string `json:"id"`
+	SenderID    string `json:"sender_id"`
+	RecipientID string `json:"recipient_id"`
+	Content     string `json:"content"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// Converts a message to its response format
+func toMessageResponse(message *entity.Message) MessageResponse {
+	return MessageResponse{
+		ID:          message.ID,
+		SenderID:    message.SenderID,
+		RecipientID: message.RecipientID,
+		Content:     message.Content,
+		CreatedAt:   message.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// Handles HTTP requests related to direct messages and the blocks that stop them
+// Every route acts on behalf of the user in the User-ID header
+type MessageHandler struct {
+	messageUseCase *usecase.MessageUseCase
+	identity       *IdentityMiddleware
+}
+
+// Creates a new message handler
+func NewMessageHandler(messageUseCase *usecase.MessageUseCase, identity *IdentityMiddleware) *MessageHandler {
+	return &MessageHandler{
+		messageUseCase: messageUseCase,
+		identity:       identity,
+	}
+}
+
+// Registers the message routes
+func (h *MessageHandler) RegisterRoutes() {
+	http.HandleFunc("POST /messages", h.identity.RequireUser(h.sendMessage))
+	http.HandleFunc("GET /messages", h.identity.RequireUser(h.getConversation))
+	http.HandleFunc("POST /users/{id}/block", h.identity.RequireUser(h.blockUser))
+	http.HandleFunc("DELETE /users/{id}/block", h.identity.RequireUser(h.unblockUser))
+}
+
+// Sends a direct message from the requesting user
+func (h *MessageHandler) sendMessage(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Parse request body
+	var req SendMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Validate request
+	v := &validator{}
+	recipientID := strings.TrimSpace(req.RecipientID)
+	v.check(recipientID != "", "recipient_id", "is required")
+	v.check(entity.IsValidID(recipientID), "recipient_id", "is not a valid ID")
+	content := entity.NormalizeContent(req.Content)
+	v.check(content != "", "content", "is required")
+	v.check(entity.CheckTweetCharacters(content) == nil, "content", "must not contain control characters")
+	v.check(entity.ContentLength(content) <= entity.MaxMessageLength, "content", fmt.Sprintf("must be at most %d characters", entity.MaxMessageLength))
+	if !v.writeErrors(w) {
+		return
+	}
+
+	// Send message
+	message, err := h.messageUseCase.SendMessage(userID, recipientID, req.Content)
+	if err != nil {
+		switch err {
+		case entity.ErrUserNotFound:
+			w.WriteHeader(http.StatusNotFound)
+		case entity.ErrCannotMessageSelf, entity.ErrEmptyMessage, entity.ErrMessageTooLong, entity.ErrInvalidContent:
+			w.WriteHeader(http.StatusBadRequest)
+		case entity.ErrMessagingBlocked:
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			writeErrorStatus(w, err)
+		}
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toMessageResponse(message))
+}
+
+// Returns the latest messages between the requesting user and the user in the with parameter, oldest first
+func (h *MessageHandler) getConversation(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Validate request
+	otherID := r.URL.Query().Get("with")
+	if otherID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "with query parameter is required"})
+		return
+	}
+	if !entity.IsValidID(otherID) {
+		w.WriteHeader(http.StatusBadRequest)
+		writeErrorBody(w, r, entity.ErrInvalidID)
+		return
+	}
+
+	// Parse optional limit
+	limit := defaultConversationLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxConversationLimit {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "limit must be between 1 and " + strconv.Itoa(maxConversationLimit)})
+			return
+		}
+		limit = parsed
+	}
+
+	// Get conversation
+	messages, err := h.messageUseCase.GetConversation(userID, otherID, limit)
+	if err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			writeErrorBody(w, r, err)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Convert to response format
+	response := make([]MessageResponse, len(messages))
+	for i, message := range messages {
+		response[i] = toMessageResponse(message)
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Blocks the user in the path on behalf of the requesting user
+func (h *MessageHandler) blockUser(w http.ResponseWriter, r *http.Request) {
+	h.changeBlock(w, r, h.messageUseCase.BlockUser)
+}
+
+// Removes the requesting user's block of the user in the path
+func (h *MessageHandler) unblockUser(w http.ResponseWriter, r *http.Request) {
+	h.changeBlock(w, r, h.messageUseCase.UnblockUser)
+}
+
+// Applies change to the block of the user in the path by the requesting user
+func (h *MessageHandler) changeBlock(w http.ResponseWriter, r *http.Request, change func(blockerID, blockedID string) error) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Validate request
+	blockedID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	// Change block
+	if err := change(userID, blockedID); err != nil {
+		switch err {
+		case entity.ErrUserNotFound:
+			w.WriteHeader(http.StatusNotFound)
+		case entity.ErrCannotBlockSelf:
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			writeErrorStatus(w, err)
+		}
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Return success response
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Methods looked up in the mux to list the methods allowed for a path, in the order they are listed
+var routeMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// Wraps a mux so every route answers HEAD and OPTIONS requests
+// HEAD requests are served by the GET route of the path with the body discarded, so the status and headers match GET
+// OPTIONS requests get a 204 response whose Allow header lists the methods routed for the path, or a 404 when there are none
+// Other methods the path is not routed for keep the 405 response of the mux, which lists them in Allow too
+func MethodsMiddleware(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodOptions:
+			allowed := allowedMethods(mux, r)
+			if len(allowed) == 0 {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Allow", strings.Join(append(allowed, http.MethodOptions), ", "))
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodHead:
+			mux.ServeHTTP(headResponseWriter{ResponseWriter: w}, r)
+		default:
+			mux.ServeHTTP(w, r)
+		}
+	})
+}
+
+// Returns the methods the mux routes for the path of a request
+// The mux returns an empty pattern for paths it does not route and for methods it answers with 405
+func allowedMethods(mux *http.ServeMux, r *http.Request) []string {
+	allowed := make([]string, 0, len(routeMethods))
+	for _, method := range routeMethods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, pattern := mux.Handler(probe); pattern != "" {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// Discards the body written by the GET route serving a HEAD request, keeping its status and headers
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+// Reports the body as written without sending it
+func (w headResponseWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Default and maximum number of notifications per page
+const (
+	defaultNotificationsLimit = 20
+	maxNotificationsLimit     = 100
+)
+
+// Represents a notification in the response; the actor's username is left out once they are deleted
+type NotificationResponse struct {
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	ActorID       string `json:"actor_id"`
+	ActorUsername string `json:"actor_username,omitempty"`
+	TweetID       string `json:"tweet_id,omitempty"`
+	CreatedAt     string `json:"created_at"`
+	Read          bool   `json:"read"`
+}
+
+// Represents a page of notifications; NextCursor is omitted on the last page
+type NotificationPageResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	UnreadCount   int                    `json:"unread_count"`
+	NextCursor    string                 `json:"next_cursor,omitempty"`
+}
+
+// Handles HTTP requests related to notifications
+// Every route acts on the notifications of the user in the User-ID header
+type NotificationHandler struct {
+	notificationUseCase *usecase.NotificationUseCase
+	identity            *IdentityMiddleware
+}
+
+// Creates a new notification handler
+func NewNotificationHandler(notificationUseCase *usecase.NotificationUseCase, identity *IdentityMiddleware) *NotificationHandler {
+	return &NotificationHandler{
+		notificationUseCase: notificationUseCase,
+		identity:            identity,
+	}
+}
+
+// Registers the notification routes
+func (h *NotificationHandler) RegisterRoutes() {
+	http.HandleFunc("GET /users/notifications", h.identity.RequireUser(h.getNotifications))
+	http.HandleFunc("POST /users/notifications/read", h.identity.RequireUser(h.markAllRead))
+}
+
+// Returns a page of the requesting user's notifications, newest first, with their unread count
+func (h *NotificationHandler) getNotifications(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Parse optional limit
+	limit := defaultNotificationsLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxNotificationsLimit {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "limit must be between 1 and " + strconv.Itoa(maxNotificationsLimit)})
+			return
+		}
+		limit = parsed
+	}
+
+	// Get page
+	page, err := h.notificationUseCase.GetNotifications(userID, limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err == entity.ErrInvalidCursor {
+			w.WriteHeader(http.StatusBadRequest)
+			writeErrorBody(w, r, err)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+	setPaginationHeaders(w, r, pageInfo{NextCursor: page.NextCursor, Total: -1})
+
+	// Read the actors in a single batch; without them the notifications still name their IDs
+	actorIDs := make([]string, len(page.Notifications))
+	for i, notification := range page.Notifications {
+		actorIDs[i] = notification.ActorID
+	}
+	actors, err := h.notificationUseCase.GetActors(actorIDs)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Failed to read notification actors", "error", err)
+	}
+
+	// Convert to response format
+	response := NotificationPageResponse{
+		Notifications: make([]NotificationResponse, len(page.Notifications)),
+		UnreadCount:   page.UnreadCount,
+		NextCursor:    page.NextCursor,
+	}
+	for i, notification := range page.Notifications {
+		response.Notifications[i] = NotificationResponse{
+			ID:        notification.ID,
+			Type:      string(notification.Type),
+			ActorID:   notification.ActorID,
+			TweetID:   notification.TweetID,
+			CreatedAt: notification.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			Read:      notification.Read,
+		}
+		if actor, ok := actors[notification.ActorID]; ok {
+			response.Notifications[i].ActorUsername = actor.Username
+		}
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Marks every notification of the requesting user as read
+func (h *NotificationHandler) markAllRead(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Mark notifications
+	if err := h.notificationUseCase.MarkAllRead(userID); err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Return success response
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Default and maximum number of tweets returned per page of a tweet list
+const (
+	defaultTweetPageLimit = 20
+	maxTweetPageLimit     = 100
+)
+
+// Describes where a page sits in its list, sent in the pagination headers
+type pageInfo struct {
+	// Cursors of the following and preceding pages, empty when there is none
+	NextCursor string
+	PrevCursor string
+	// Whether a preceding page exists; its cursor is empty for the first page
+	HasPrev bool
+	// Total number of items in the list, negative when counting it would be expensive
+	Total int
+}
+
+// Sets the Link header with the next and prev page URLs and, when known, the X-Total-Count header
+// Page URLs keep the request path and query, replacing only the cursor
+func setPaginationHeaders(w http.ResponseWriter, r *http.Request, info pageInfo) {
+	var links []string
+	if info.NextCursor != "" {
+		links = append(links, pageLink(r, info.NextCursor, "next"))
+	}
+	if info.HasPrev {
+		links = append(links, pageLink(r, info.PrevCursor, "prev"))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+	if info.Total >= 0 {
+		w.Header().Set("X-Total-Count", strconv.Itoa(info.Total))
+	}
+}
+
+// Formats a Link header entry pointing to the page at cursor
+func pageLink(r *http.Request, cursor, rel string) string {
+	query := r.URL.Query()
+	if cursor == "" {
+		query.Del("cursor")
+	} else {
+		query.Set("cursor", cursor)
+	}
+	target := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf("<%s>; rel=%q", target.String(), rel)
+}
+
+// Returns the page of tweets selected by the limit and cursor query parameters and sets the pagination headers
+// Without either parameter all tweets are returned. Writes a 400 response and returns false on invalid parameters
+func pageTweets(w http.ResponseWriter, r *http.Request, tweets []*entity.Tweet) ([]*entity.Tweet, bool) {
+	query := r.URL.Query()
+	if !query.Has("limit") && !query.Has("cursor") {
+		setPaginationHeaders(w, r, pageInfo{Total: len(tweets)})
+		return tweets, true
+	}
+
+	// Parse optional limit
+	limit := defaultTweetPageLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxTweetPageLimit {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "limit must be between 1 and " + strconv.Itoa(maxTweetPageLimit)})
+			return nil, false
+		}
+		limit = parsed
+	}
+
+	// Parse optional cursor
+	cursor, err := decodeTweetCursor(query.Get("cursor"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeErrorBody(w, r, err)
+		return nil, false
+	}
+
+	start := cursor.position(tweets)
+	end := min(start+limit, len(tweets))
+	info := pageInfo{Total: len(tweets)}
+	if end < len(tweets) {
+		info.NextCursor = encodeTweetCursor(tweets[end-1])
+	}
+	if start > 0 {
+		info.HasPrev = true
+		if prev := max(start-limit, 0); prev > 0 {
+			info.PrevCursor = encodeTweetCursor(tweets[prev-1])
+		}
+	}
+	setPaginationHeaders(w, r, info)
+	return tweets[start:end], true
+}
+
+// Position in a tweet list, newest first, after which a page starts
+// The zero value is the beginning of the list
+type tweetCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Encodes the creation time and ID of the last tweet of a page as an opaque cursor
+func encodeTweetCursor(tweet *entity.Tweet) string {
+	raw := strconv.FormatInt(tweet.CreatedAt.UnixNano(), 10) + ":" + tweet.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Decodes a cursor into the tweet a page follows; an empty cursor starts from the beginning
+func decodeTweetCursor(cursor string) (tweetCursor, error) {
+	if cursor == "" {
+		return tweetCursor{}, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return tweetCursor{}, entity.ErrInvalidCursor
+	}
+	nanos, id, found := strings.Cut(string(raw), ":")
+	if !found || id == "" {
+		return tweetCursor{}, entity.ErrInvalidCursor
+	}
+	createdAt, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return tweetCursor{}, entity.ErrInvalidCursor
+	}
+	return tweetCursor{CreatedAt: time.Unix(0, createdAt), ID: id}, nil
+}
+
+// Returns the index of the first tweet after the cursor
+// Tweets are compared by creation time, then ID, so the page starts at the first tweet strictly
+// older than the cursor even when the tweet it points at was deleted meanwhile
+// In lists not ordered by time, e.g. ranked timelines, the page resumes after the cursor's tweet if still listed
+func (c tweetCursor) position(tweets []*entity.Tweet) int {
+	if c.ID == "" {
+		return 0
+	}
+	for i, tweet := range tweets {
+		if tweet.ID == c.ID {
+			return i + 1
+		}
+	}
+	for i, tweet := range tweets {
+		if c.after(tweet) {
+			return i
+		}
+	}
+	return len(tweets)
+}
+
+// Reports whether the tweet comes strictly after the cursor in a list newest first
+func (c tweetCursor) after(tweet *entity.Tweet) bool {
+	if !tweet.CreatedAt.Equal(c.CreatedAt) {
+		return tweet.CreatedAt.Before(c.CreatedAt)
+	}
+	return tweet.ID < c.ID
+}
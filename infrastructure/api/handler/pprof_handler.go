@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/pprof"
+)
+
+// Serves the runtime profiles of net/http/pprof under /debug/pprof/
+// Importing net/http/pprof also registers these routes on the DefaultServeMux the
+// package started with, so the server must route through a mux created afterwards
+type PprofHandler struct{}
+
+// Creates a new pprof handler
+func NewPprofHandler() *PprofHandler {
+	return &PprofHandler{}
+}
+
+// Registers the profiling routes
+func (h *PprofHandler) RegisterRoutes() {
+	http.HandleFunc("GET /debug/pprof/", pprof.Index)
+	http.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
+	http.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
+	http.HandleFunc("GET /debug/pprof/symbol", pprof.Symbol)
+	http.HandleFunc("POST /debug/pprof/symbol", pprof.Symbol)
+	http.HandleFunc("GET /debug/pprof/trace", pprof.Trace)
+}
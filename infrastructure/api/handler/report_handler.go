@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Handles HTTP requests related to tweet reports
+// Users report tweets; only moderators can list the reports
+type ReportHandler struct {
+	reportUseCase *usecase.ReportUseCase
+	identity      *IdentityMiddleware
+	admin         *AdminMiddleware
+}
+
+// Creates a new report handler
+func NewReportHandler(reportUseCase *usecase.ReportUseCase, identity *IdentityMiddleware, admin *AdminMiddleware) *ReportHandler {
+	return &ReportHandler{
+		reportUseCase: reportUseCase,
+		identity:      identity,
+		admin:         admin,
+	}
+}
+
+// Represents the request body for reporting a tweet
+type ReportTweetRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Represents the response body for a report
+type ReportResponse struct {
+	TweetID    string `json:"tweet_id"`
+	ReporterID string `json:"reporter_id"`
+	Reason     string `json:"reason"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// Converts a report to its response format
+func toReportResponse(report *entity.Report) ReportResponse {
+	return ReportResponse{
+		TweetID:    report.TweetID,
+		ReporterID: report.ReporterID,
+		Reason:     report.Reason,
+		CreatedAt:  report.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// Registers the report routes
+func (h *ReportHandler) RegisterRoutes() {
+	http.HandleFunc("POST /tweets/{id}/report", h.identity.RequireUser(h.reportTweet))
+	http.HandleFunc("GET /admin/reports", h.admin.RequireAdmin(h.listReports))
+}
+
+// Reports a tweet on behalf of the requesting user
+func (h *ReportHandler) reportTweet(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Validate the tweet ID in the path
+	tweetID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var req ReportTweetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Report tweet
+	report, err := h.reportUseCase.ReportTweet(userID, tweetID, req.Reason)
+	if err != nil {
+		switch err {
+		case entity.ErrTweetNotFound:
+			w.WriteHeader(http.StatusNotFound)
+		case entity.ErrReportReasonRequired, entity.ErrReportReasonTooLong:
+			w.WriteHeader(http.StatusBadRequest)
+		case entity.ErrAlreadyReported:
+			w.WriteHeader(http.StatusConflict)
+		default:
+			writeErrorStatus(w, err)
+		}
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toReportResponse(report))
+}
+
+// Returns every report, newest first
+func (h *ReportHandler) listReports(w http.ResponseWriter, r *http.Request) {
+	// Get reports
+	reports, err := h.reportUseCase.ListReports()
+	if err != nil {
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Convert to response format
+	response := make([]ReportResponse, len(reports))
+	for i, report := range reports {
+		response[i] = toReportResponse(report)
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
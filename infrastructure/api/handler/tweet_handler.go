@@ -2,103 +2,269 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/develpudu/go-challenge/application/usecase"
 	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
 )
 
 // Handles HTTP requests related to tweets
 type TweetHandler struct {
 	tweetUseCase *usecase.TweetUseCase
+	identity     *IdentityMiddleware
 }
 
 // Creates a new tweet handler
-func NewTweetHandler(tweetUseCase *usecase.TweetUseCase) *TweetHandler {
+func NewTweetHandler(tweetUseCase *usecase.TweetUseCase, identity *IdentityMiddleware) *TweetHandler {
 	return &TweetHandler{
 		tweetUseCase: tweetUseCase,
+		identity:     identity,
 	}
 }
 
 // Represents the request body for creating a tweet
 type CreateTweetRequest struct {
+	Content     string `json:"content"`
+	InReplyToID string `json:"in_reply_to_id"` // Optional, makes the tweet a reply
+	ExpiresIn   string `json:"expires_in"`     // Optional duration, e.g. "24h", makes the tweet ephemeral
+	Lang        string `json:"lang"`           // Optional ISO 639-1 code of the content's language, e.g. "es"
+}
+
+// Represents one entry of the request body for importing tweets
+type ImportTweetRequest struct {
+	Content   string `json:"content"`
+	CreatedAt string `json:"created_at"` // RFC 3339 time the tweet was originally posted
+}
+
+// Represents the outcome of importing one entry, at the same index as in the request
+type ImportTweetResult struct {
+	Index int            `json:"index"`
+	Tweet *TweetResponse `json:"tweet,omitempty"`
+	Error string         `json:"error,omitempty"`
+}
+
+// Represents the result of importing tweets
+type ImportTweetsResponse struct {
+	Imported int                 `json:"imported"`
+	Failed   int                 `json:"failed"`
+	Results  []ImportTweetResult `json:"results"`
+}
+
+// Represents the request body for validating tweet content
+type ValidateTweetRequest struct {
 	Content string `json:"content"`
 }
 
-// Represents the response body for tweet-related operations
-type TweetResponse struct {
+// Represents the result of validating tweet content
+// Length counts the characters of the normalized content, as the server does when posting
+type ValidateTweetResponse struct {
+	Length    int    `json:"length"`
+	MaxLength int    `json:"max_length"`
+	Valid     bool   `json:"valid"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Represents the request body for editing a tweet
+type UpdateTweetRequest struct {
+	Content string `json:"content"`
+}
+
+// Represents the request body for quoting a tweet
+type QuoteTweetRequest struct {
+	Content string `json:"content"`
+}
+
+// Maximum number of characters of the quoted tweet's content embedded in a quote
+const quotedSummaryLength = 100
+
+// Represents the summary of a quoted tweet embedded in the quote
+type QuotedTweetSummary struct {
 	ID        string `json:"id"`
 	UserID    string `json:"user_id"`
 	Content   string `json:"content"`
 	CreatedAt string `json:"created_at"`
 }
 
-// Registers the tweet routes
-func (h *TweetHandler) RegisterRoutes() {
-	http.HandleFunc("/tweets", h.handleTweets)
-	http.HandleFunc("/tweets/", h.handleTweetByID)
-	http.HandleFunc("/users/tweets", h.handleUserTweets)
-	http.HandleFunc("/timeline", h.handleTimeline)
-}
-
-// Handles requests to /tweets
-func (h *TweetHandler) handleTweets(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodPost:
-		h.createTweet(w, r)
-	case http.MethodGet:
-		h.getAllTweets(w, r)
-	default:
-		w.WriteHeader(http.StatusMethodNotAllowed)
+// Represents the response body for tweet-related operations
+type TweetResponse struct {
+	ID             string `json:"id"`
+	UserID         string `json:"user_id"`
+	Content        string `json:"content"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+	InReplyToID    string `json:"in_reply_to_id,omitempty"`
+	ConversationID string `json:"conversation_id"`
+	ExpiresAt      string `json:"expires_at,omitempty"`
+	// ISO 639-1 code of the content's language; left out when unknown
+	Lang string `json:"lang,omitempty"`
+	// Username of the author; left out when the author no longer exists
+	AuthorUsername string `json:"author_username,omitempty"`
+	// Content truncated to the length requested with the preview query parameter
+	Preview string `json:"preview,omitempty"`
+	// Number of times the tweet was viewed, only set when it is fetched by ID
+	Impressions int64 `json:"impressions,omitempty"`
+	// Tweet quoted by this one; the summary is left out once the quoted tweet is gone
+	QuotedTweetID string              `json:"quoted_tweet_id,omitempty"`
+	QuotedTweet   *QuotedTweetSummary `json:"quoted_tweet,omitempty"`
+	// Preview card of the first link in the content; left out until it is fetched
+	LinkPreview *LinkPreviewResponse `json:"link_preview,omitempty"`
+}
+
+// Represents the preview card of a link in the response
+type LinkPreviewResponse struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+}
+
+// Represents the number of tweets of a user
+type TweetCountResponse struct {
+	UserID     string `json:"user_id"`
+	TweetCount int    `json:"tweet_count"`
+}
+
+// Converts a tweet to its response format
+func toTweetResponse(tweet *entity.Tweet) TweetResponse {
+	response := TweetResponse{
+		ID:             tweet.ID,
+		UserID:         tweet.UserID,
+		Content:        tweet.Content,
+		CreatedAt:      tweet.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:      tweet.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		InReplyToID:    tweet.InReplyToID,
+		ConversationID: tweet.RootID(),
+		QuotedTweetID:  tweet.QuotedTweetID,
+		Lang:           tweet.Lang,
 	}
+	if !tweet.ExpiresAt.IsZero() {
+		response.ExpiresAt = tweet.ExpiresAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if preview := tweet.LinkPreview; preview != nil {
+		response.LinkPreview = &LinkPreviewResponse{
+			URL:         preview.URL,
+			Title:       preview.Title,
+			Description: preview.Description,
+			ImageURL:    preview.ImageURL,
+		}
+	}
+	return response
 }
 
-// Handles requests to /tweets/{id}
-func (h *TweetHandler) handleTweetByID(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
+// Converts tweets to their response format, keeping their order
+func toTweetResponses(tweets []*entity.Tweet) []TweetResponse {
+	response := make([]TweetResponse, len(tweets))
+	for i, tweet := range tweets {
+		response[i] = toTweetResponse(tweet)
 	}
+	return response
+}
 
-	// Extract tweet ID from URL path
-	tweetID := r.URL.Path[len("/tweets/"):]
-	if tweetID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		return
+// Converts a quoted tweet to the summary embedded in quotes
+func toQuotedTweetSummary(tweet *entity.Tweet) *QuotedTweetSummary {
+	return &QuotedTweetSummary{
+		ID:        tweet.ID,
+		UserID:    tweet.UserID,
+		Content:   entity.TruncateContent(tweet.Content, quotedSummaryLength),
+		CreatedAt: tweet.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
+}
 
-	h.getTweet(w, r, tweetID)
+// Embeds the summary of the quoted tweet in each response that quotes one
+// Quoted tweets that were deleted or have expired are left out
+func (h *TweetHandler) embedQuotedTweets(responses []TweetResponse) {
+	summaries := make(map[string]*QuotedTweetSummary)
+	for i := range responses {
+		quotedID := responses[i].QuotedTweetID
+		if quotedID == "" {
+			continue
+		}
+		summary, ok := summaries[quotedID]
+		if !ok {
+			if quoted, err := h.tweetUseCase.GetTweetByID(quotedID); err == nil {
+				summary = toQuotedTweetSummary(quoted)
+			}
+			summaries[quotedID] = summary
+		}
+		responses[i].QuotedTweet = summary
+	}
 }
 
-// Handles requests to /users/tweets
-func (h *TweetHandler) handleUserTweets(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+// Fills in the username of the author of each response, reading every author in a single batch with find
+// Authors that no longer exist leave the username out; if the lookup fails the tweets are still returned,
+// without usernames, as they were already read
+func embedAuthors(r *http.Request, responses []TweetResponse, find func(userIDs []string) (map[string]*entity.User, error)) {
+	if len(responses) == 0 {
 		return
 	}
-
-	h.getUserTweets(w, r)
+	userIDs := make([]string, len(responses))
+	for i := range responses {
+		userIDs[i] = responses[i].UserID
+	}
+	authors, err := find(userIDs)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Failed to read tweet authors", "error", err)
+		return
+	}
+	for i := range responses {
+		if author, ok := authors[responses[i].UserID]; ok {
+			responses[i].AuthorUsername = author.Username
+		}
+	}
 }
 
-// Handles requests to /timeline
-func (h *TweetHandler) handleTimeline(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
+// Fills in the preview of each response when the preview query parameter gives its length in characters
+// Writes a 400 response and returns false if the length is not a positive integer
+func addPreviews(w http.ResponseWriter, r *http.Request, responses []TweetResponse) bool {
+	value := r.URL.Query().Get("preview")
+	if value == "" {
+		return true
 	}
+	length, err := strconv.Atoi(value)
+	if err != nil || length <= 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "preview must be a positive integer"})
+		return false
+	}
+	for i := range responses {
+		responses[i].Preview = entity.TruncateContent(responses[i].Content, length)
+	}
+	return true
+}
 
-	h.getTimeline(w, r)
+// Registers the tweet routes
+func (h *TweetHandler) RegisterRoutes() {
+	http.HandleFunc("POST /tweets", h.identity.RequireUser(h.createTweet))
+	http.HandleFunc("GET /tweets", h.getAllTweets)
+	http.HandleFunc("GET /tweets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if tweetID, ok := pathID(w, r); ok {
+			h.getTweet(w, r, tweetID)
+		}
+	})
+	http.HandleFunc("POST /tweets/validate", h.validateTweet)
+	http.HandleFunc("PUT /tweets/{id}", h.identity.RequireUser(h.updateTweet))
+	http.HandleFunc("DELETE /tweets/{id}", h.identity.RequireUser(h.deleteTweet))
+	http.HandleFunc("GET /tweets/{id}/conversation", h.getConversation)
+	http.HandleFunc("POST /tweets/{id}/quote", h.identity.RequireUser(h.quoteTweet))
+	http.HandleFunc("GET /users/tweets", h.getUserTweets)
+	http.HandleFunc("GET /users/tweets/count", h.countUserTweets)
+	http.HandleFunc("GET /users/tweets/export", h.identity.RequireUser(h.exportTweets))
+	http.HandleFunc("POST /users/tweets/import", h.identity.RequireUser(h.importTweets))
+	http.HandleFunc("GET /timeline", h.identity.RequireUser(h.getTimeline))
 }
 
 // Creates a new tweet
 func (h *TweetHandler) createTweet(w http.ResponseWriter, r *http.Request) {
-	// Get user ID from header
-	userID := r.Header.Get("User-ID")
-	if userID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "User-ID header is required"})
-		return
-	}
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
 
 	// Parse request body
 	var req CreateTweetRequest
@@ -109,59 +275,245 @@ func (h *TweetHandler) createTweet(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate request
-	if req.Content == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "content is required"})
+	v := &validator{}
+	maxLength := h.tweetUseCase.MaxTweetLength()
+	content := entity.NormalizeContent(req.Content)
+	v.check(content != "", "content", "is required")
+	v.check(entity.CheckTweetCharacters(content) == nil, "content", "must not contain control characters")
+	v.check(entity.ContentLength(content) <= maxLength, "content", fmt.Sprintf("must be at most %d characters", maxLength))
+	var expiresIn time.Duration
+	if req.ExpiresIn != "" {
+		expiresIn, err = time.ParseDuration(req.ExpiresIn)
+		v.check(err == nil && expiresIn > 0, "expires_in", "must be a positive duration")
+		v.check(req.InReplyToID == "", "expires_in", "is not allowed on replies")
+	}
+	_, langErr := entity.NormalizeLang(req.Lang)
+	v.check(langErr == nil, "lang", "must be an ISO 639-1 language code")
+	if !v.writeErrors(w) {
 		return
 	}
 
-	// Create tweet
-	tweet, err := h.tweetUseCase.CreateTweet(userID, req.Content)
+	// Create tweet, or a reply when the replied tweet is given
+	var tweet *entity.Tweet
+	if req.InReplyToID != "" {
+		tweet, err = h.tweetUseCase.CreateReply(userID, req.InReplyToID, req.Content, req.Lang)
+	} else {
+		tweet, err = h.tweetUseCase.CreateExpiringTweet(userID, req.Content, req.Lang, expiresIn)
+	}
 	if err != nil {
 		if err == entity.ErrUserNotFound {
 			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(map[string]string{"error": "user not found"})
+			writeErrorBody(w, r, entity.ErrUserNotFound)
+			return
+		} else if err == entity.ErrTweetNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "replied tweet not found"})
 			return
-		} else if err == entity.ErrTweetTooLong {
+		} else if err == entity.ErrDuplicateTweet {
+			w.WriteHeader(http.StatusConflict)
+			writeErrorBody(w, r, err)
+			return
+		} else if errors.Is(err, entity.ErrTweetTooLong) || err == entity.ErrEmptyTweet || err == entity.ErrInvalidContent || err == entity.ErrInvalidLang || err == entity.ErrProfanity {
 			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]string{"error": "tweet exceeds character limit"})
+			writeErrorBody(w, r, err)
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
 		return
 	}
 
 	// Return response
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(TweetResponse{
-		ID:        tweet.ID,
-		UserID:    tweet.UserID,
-		Content:   tweet.Content,
-		CreatedAt: tweet.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	})
+	json.NewEncoder(w).Encode(toTweetResponse(tweet))
+}
+
+// Reports whether content can be posted and its length as counted by the server, without creating a tweet
+func (h *TweetHandler) validateTweet(w http.ResponseWriter, r *http.Request) {
+	// Parse request body
+	var req ValidateTweetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Validate content
+	length, err := h.tweetUseCase.ValidateContent(req.Content)
+	response := ValidateTweetResponse{
+		Length:    length,
+		MaxLength: h.tweetUseCase.MaxTweetLength(),
+		Valid:     err == nil,
+	}
+	if err != nil {
+		response.Error = err.Error()
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Creates a new tweet by the caller that quotes the tweet in the path
+func (h *TweetHandler) quoteTweet(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Validate the quoted tweet ID in the path
+	tweetID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var req QuoteTweetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Quote tweet
+	tweet, err := h.tweetUseCase.QuoteTweet(userID, tweetID, req.Content)
+	if err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			writeErrorBody(w, r, entity.ErrUserNotFound)
+			return
+		} else if err == entity.ErrTweetNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "quoted tweet not found"})
+			return
+		} else if err == entity.ErrDuplicateTweet {
+			w.WriteHeader(http.StatusConflict)
+			writeErrorBody(w, r, err)
+			return
+		} else if errors.Is(err, entity.ErrTweetTooLong) || err == entity.ErrEmptyTweet || err == entity.ErrInvalidContent || err == entity.ErrProfanity {
+			w.WriteHeader(http.StatusBadRequest)
+			writeErrorBody(w, r, err)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Return response, with the summary of the quoted tweet
+	response := []TweetResponse{toTweetResponse(tweet)}
+	h.embedQuotedTweets(response)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response[0])
+}
+
+// Edits the content of a tweet owned by the caller
+func (h *TweetHandler) updateTweet(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Validate the tweet ID in the path
+	tweetID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var req UpdateTweetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Validate request
+	if entity.NormalizeContent(req.Content) == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "content is required"})
+		return
+	}
+
+	// Update tweet
+	tweet, err := h.tweetUseCase.UpdateTweet(userID, tweetID, req.Content)
+	if err != nil {
+		switch {
+		case err == entity.ErrTweetNotFound:
+			w.WriteHeader(http.StatusNotFound)
+		case err == entity.ErrNotTweetAuthor, err == entity.ErrEditWindowExpired:
+			w.WriteHeader(http.StatusForbidden)
+		case errors.Is(err, entity.ErrTweetTooLong), err == entity.ErrEmptyTweet, err == entity.ErrInvalidContent, err == entity.ErrProfanity:
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			writeErrorStatus(w, err)
+		}
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toTweetResponse(tweet))
+}
+
+// Deletes a tweet owned by the caller
+func (h *TweetHandler) deleteTweet(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Validate the tweet ID in the path
+	tweetID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	// Delete tweet
+	if err := h.tweetUseCase.DeleteTweet(userID, tweetID); err != nil {
+		switch err {
+		case entity.ErrTweetNotFound:
+			w.WriteHeader(http.StatusNotFound)
+		case entity.ErrNotTweetAuthor:
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			writeErrorStatus(w, err)
+		}
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Return response
+	w.WriteHeader(http.StatusNoContent)
 }
 
 // Returns all tweets
 func (h *TweetHandler) getAllTweets(w http.ResponseWriter, r *http.Request) {
-	// Get all tweets
-	tweets, err := h.tweetUseCase.GetAllTweets()
+	// Get all tweets, only those in one language when the lang query parameter is given
+	var tweets []*entity.Tweet
+	var err error
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		tweets, err = h.tweetUseCase.GetAllTweetsInLang(lang)
+	} else {
+		tweets, err = h.tweetUseCase.GetAllTweets()
+	}
+	if err == entity.ErrInvalidLang {
+		w.WriteHeader(http.StatusBadRequest)
+		writeErrorBody(w, r, err)
+		return
+	}
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
 		return
 	}
 
-	// Convert to response format
-	response := make([]TweetResponse, len(tweets))
-	for i, tweet := range tweets {
-		response[i] = TweetResponse{
-			ID:        tweet.ID,
-			UserID:    tweet.UserID,
-			Content:   tweet.Content,
-			CreatedAt: tweet.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		}
+	// Select the requested page
+	tweets, ok := pageTweets(w, r, tweets)
+	if !ok {
+		return
+	}
+
+	// Convert to response format, with authors, quoted tweets and previews if requested
+	response := toTweetResponses(tweets)
+	embedAuthors(r, response, h.tweetUseCase.GetAuthors)
+	h.embedQuotedTweets(response)
+	if !addPreviews(w, r, response) {
+		return
 	}
 
 	// Return response
@@ -171,26 +523,48 @@ func (h *TweetHandler) getAllTweets(w http.ResponseWriter, r *http.Request) {
 
 // Returns a specific tweet
 func (h *TweetHandler) getTweet(w http.ResponseWriter, r *http.Request, tweetID string) {
-	// Get tweet
-	tweet, err := h.tweetUseCase.GetTweetByID(tweetID)
+	// Get tweet, counting the view once per viewer
+	tweet, impressions, err := h.tweetUseCase.ViewTweet(r.Context(), tweetID, viewerKey(r))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		if err == entity.ErrTweetNotFound {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			writeErrorStatus(w, err)
+		}
+		writeErrorBody(w, r, err)
 		return
 	}
-	if tweet == nil {
-		w.WriteHeader(http.StatusNotFound)
+
+	// Skip the body if the client already has this version of the tweet
+	if writeNotModified(w, r, tweetsETag([]*entity.Tweet{tweet})) {
 		return
 	}
 
-	// Return response
+	// Return response, with the author and the summary of the quoted tweet if any
+	responses := []TweetResponse{toTweetResponse(tweet)}
+	embedAuthors(r, responses, h.tweetUseCase.GetAuthors)
+	response := responses[0]
+	response.Impressions = impressions
+	if tweet.QuotedTweetID != "" {
+		if quoted, err := h.tweetUseCase.GetTweetByID(tweet.QuotedTweetID); err == nil {
+			response.QuotedTweet = toQuotedTweetSummary(quoted)
+		}
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(TweetResponse{
-		ID:        tweet.ID,
-		UserID:    tweet.UserID,
-		Content:   tweet.Content,
-		CreatedAt: tweet.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	})
+	json.NewEncoder(w).Encode(response)
+}
+
+// Returns the key identifying who views a tweet when counting impressions
+// Anonymous viewers are identified by their remote address
+func viewerKey(r *http.Request) string {
+	if userID, err := requestUserID(r); err == nil && userID != "" {
+		return "user:" + userID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
 }
 
 // Returns all tweets by a specific user
@@ -210,20 +584,23 @@ func (h *TweetHandler) getUserTweets(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
 		return
 	}
 
-	// Convert to response format
-	response := make([]TweetResponse, len(tweets))
-	for i, tweet := range tweets {
-		response[i] = TweetResponse{
-			ID:        tweet.ID,
-			UserID:    tweet.UserID,
-			Content:   tweet.Content,
-			CreatedAt: tweet.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		}
+	// Select the requested page
+	tweets, ok := pageTweets(w, r, tweets)
+	if !ok {
+		return
+	}
+
+	// Convert to response format, with authors, quoted tweets and previews if requested
+	response := toTweetResponses(tweets)
+	embedAuthors(r, response, h.tweetUseCase.GetAuthors)
+	h.embedQuotedTweets(response)
+	if !addPreviews(w, r, response) {
+		return
 	}
 
 	// Return response
@@ -231,39 +608,235 @@ func (h *TweetHandler) getUserTweets(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// Returns the timeline for a specific user
-func (h *TweetHandler) getTimeline(w http.ResponseWriter, r *http.Request) {
-	// Get user ID from header
-	userID := r.Header.Get("User-ID")
+// Returns the number of tweets of a user, without listing them
+func (h *TweetHandler) countUserTweets(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from query parameter
+	userID := r.URL.Query().Get("user_id")
 	if userID == "" {
 		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "User-ID header is required"})
+		json.NewEncoder(w).Encode(map[string]string{"error": "user_id query parameter is required"})
+		return
+	}
+
+	// Count tweets by user
+	count, err := h.tweetUseCase.CountTweetsByUser(userID)
+	if err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			writeErrorBody(w, r, err)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TweetCountResponse{UserID: userID, TweetCount: count})
+}
+
+// Downloads all tweets of the caller as JSON or CSV, oldest first
+func (h *TweetHandler) exportTweets(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Parse format, JSON by default
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	var contentType string
+	var write func(http.ResponseWriter, []*entity.Tweet) error
+	switch format {
+	case "json":
+		contentType, write = "application/json", writeTweetsJSON
+	case "csv":
+		contentType, write = "text/csv; charset=utf-8", writeTweetsCSV
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "format must be json or csv"})
 		return
 	}
 
+	// Get tweets
+	tweets, err := h.tweetUseCase.ExportTweets(userID)
+	if err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Stream the export as a download
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "tweets-" + userID + "." + format}))
+	if err := write(w, tweets); err != nil {
+		// The status is already sent, so the client sees a truncated file
+		slog.WarnContext(r.Context(), "Failed to write tweet export", "userID", userID, "format", format, "error", err)
+	}
+}
+
+// Creates tweets for the caller from a JSON array, keeping their original creation times
+// Entries are validated one by one, so the response reports each row on its own
+func (h *TweetHandler) importTweets(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Parse request body
+	var req []ImportTweetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "body must be a JSON array of tweets"})
+		return
+	}
+
+	// An unparseable creation time is left zero, which the use case rejects for that row
+	entries := make([]usecase.ImportedTweet, len(req))
+	for i, item := range req {
+		createdAt, _ := time.Parse(time.RFC3339, item.CreatedAt)
+		entries[i] = usecase.ImportedTweet{Content: item.Content, CreatedAt: createdAt}
+	}
+
+	// Import tweets
+	results, err := h.tweetUseCase.ImportTweets(userID, entries)
+	if err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			writeErrorBody(w, r, entity.ErrUserNotFound)
+			return
+		} else if err == entity.ErrImportTooLarge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("at most %d tweets can be imported at once", usecase.MaxImportTweets)})
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Return the result of every row
+	response := ImportTweetsResponse{Results: make([]ImportTweetResult, len(results))}
+	for i, result := range results {
+		response.Results[i].Index = i
+		if result.Err != nil {
+			response.Results[i].Error = result.Err.Error()
+			response.Failed++
+			continue
+		}
+		tweet := toTweetResponse(result.Tweet)
+		response.Results[i].Tweet = &tweet
+		response.Imported++
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Returns the timeline for a specific user
+func (h *TweetHandler) getTimeline(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Parse optional include_self, the user's own tweets are included by default
+	opts := repository.DefaultTimelineOptions()
+	if value := r.URL.Query().Get("include_self"); value != "" {
+		includeSelf, err := strconv.ParseBool(value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "include_self must be true or false"})
+			return
+		}
+		opts.IncludeSelf = includeSelf
+	}
+
+	// Parse optional include_replies, replies are left out by default
+	if value := r.URL.Query().Get("include_replies"); value != "" {
+		includeReplies, err := strconv.ParseBool(value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "include_replies must be true or false"})
+			return
+		}
+		opts.IncludeReplies = includeReplies
+	}
+
+	// Parse optional ranking, the configured default applies when absent
+	if value := r.URL.Query().Get("ranking"); value != "" {
+		ranker, err := h.tweetUseCase.TimelineRanker(value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "ranking must be chronological or engagement"})
+			return
+		}
+		opts.Ranker = ranker
+	}
+
 	// Get timeline
-	tweets, err := h.tweetUseCase.GetTimeline(userID)
+	tweets, err := h.tweetUseCase.GetTimeline(r.Context(), userID, opts)
 	if err != nil {
 		if err == entity.ErrUserNotFound {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
 		return
 	}
 
-	// Convert to response format
-	response := make([]TweetResponse, len(tweets))
-	for i, tweet := range tweets {
-		response[i] = TweetResponse{
-			ID:        tweet.ID,
-			UserID:    tweet.UserID,
-			Content:   tweet.Content,
-			CreatedAt: tweet.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	// Select the requested page
+	tweets, ok := pageTweets(w, r, tweets)
+	if !ok {
+		return
+	}
+
+	// Tell an empty timeline of a user who follows no one from one whose followed users have not tweeted
+	w.Header().Set("X-Following-Count", strconv.Itoa(currentUser(r).FollowingCount()))
+
+	// Skip the body if the client already has this page of the timeline
+	if writeNotModified(w, r, tweetsETag(tweets)) {
+		return
+	}
+
+	// Convert to response format, with authors, quoted tweets and previews if requested
+	response := toTweetResponses(tweets)
+	embedAuthors(r, response, h.tweetUseCase.GetAuthors)
+	h.embedQuotedTweets(response)
+	if !addPreviews(w, r, response) {
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Returns the whole conversation a tweet belongs to, oldest first
+func (h *TweetHandler) getConversation(w http.ResponseWriter, r *http.Request) {
+	// Validate the tweet ID in the path
+	tweetID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	// Get conversation
+	tweets, err := h.tweetUseCase.GetConversation(tweetID)
+	if err != nil {
+		if err == entity.ErrTweetNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
 		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
 	}
 
+	// Convert to response format, with authors
+	response := toTweetResponses(tweets)
+	embedAuthors(r, response, h.tweetUseCase.GetAuthors)
+
 	// Return response
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -2,7 +2,10 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/develpudu/go-challenge/application/usecase"
 	"github.com/develpudu/go-challenge/domain/entity"
@@ -11,12 +14,14 @@ import (
 // Handles HTTP requests related to users
 type UserHandler struct {
 	userUseCase *usecase.UserUseCase
+	identity    *IdentityMiddleware
 }
 
 // Creates a new user handler
-func NewUserHandler(userUseCase *usecase.UserUseCase) *UserHandler {
+func NewUserHandler(userUseCase *usecase.UserUseCase, identity *IdentityMiddleware) *UserHandler {
 	return &UserHandler{
 		userUseCase: userUseCase,
+		identity:    identity,
 	}
 }
 
@@ -31,66 +36,109 @@ type UserResponse struct {
 	Username string `json:"username"`
 }
 
-// Represents the request body for following a user
-type FollowRequest struct {
-	FollowedID string `json:"followed_id"`
+// Represents a user profile, with the number of followers and followed users
+type ProfileResponse struct {
+	UserResponse
+	FollowersCount int `json:"followers_count"`
+	FollowingCount int `json:"following_count"`
 }
 
-// Registers the user routes
-func (h *UserHandler) RegisterRoutes() {
-	http.HandleFunc("/users", h.handleUsers)
-	http.HandleFunc("/users/", h.handleUserByID)
-	http.HandleFunc("/users/follow", h.handleFollow)
-	http.HandleFunc("/users/unfollow", h.handleUnfollow)
-}
+// Default and maximum number of suggested users returned
+const (
+	defaultSuggestionsLimit = 10
+	maxSuggestionsLimit     = 50
+)
 
-// Handles requests to /users
-func (h *UserHandler) handleUsers(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodPost:
-		h.createUser(w, r)
-	case http.MethodGet:
-		h.getUsers(w, r)
-	default:
-		w.WriteHeader(http.StatusMethodNotAllowed)
-	}
+// Maximum number of hops explored when looking for a path between users
+const maxPathDepth = 6
+
+// Represents the response body for a path between users
+type PathResponse struct {
+	Path   []UserResponse `json:"path"`
+	Length int            `json:"length"`
 }
 
-// Handles requests to /users/{id}
-func (h *UserHandler) handleUserByID(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
+// Represents the request body for following a user
+type FollowRequest struct {
+	FollowedID string `json:"followed_id"`
+}
 
-	// Extract user ID from URL path
-	userID := r.URL.Path[len("/users/"):]
-	if userID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
+// Default and maximum number of users returned per users, followers or following page
+const (
+	defaultUserPageLimit = 20
+	maxUserPageLimit     = 100
+)
 
-	h.getUser(w, r, userID)
+// Represents a page of users; NextCursor is omitted on the last page
+type UserPageResponse struct {
+	Users      []UserResponse `json:"users"`
+	NextCursor string         `json:"next_cursor,omitempty"`
 }
 
-// Handles requests to /users/follow
-func (h *UserHandler) handleFollow(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
+// Maximum number of user IDs accepted by a batch follow or unfollow request
+const maxFollowBatchSize = 100
 
-	h.followUser(w, r)
+// Represents the outcome of one item of a batch follow or unfollow request
+type FollowBatchResult struct {
+	FollowedID string `json:"followed_id"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
 }
 
-// Handles requests to /users/unfollow
-func (h *UserHandler) handleUnfollow(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-
-	h.unfollowUser(w, r)
+// Registers the user routes
+func (h *UserHandler) RegisterRoutes() {
+	http.HandleFunc("POST /users", h.createUser)
+	http.HandleFunc("GET /users", h.getUsers)
+	http.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if userID, ok := pathID(w, r); ok {
+			h.getUser(w, r, userID)
+		}
+	})
+	http.HandleFunc("DELETE /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		// Malformed IDs are rejected before the requesting user is resolved
+		userID, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		h.identity.RequireUser(func(w http.ResponseWriter, r *http.Request) {
+			h.deleteUser(w, r, userID)
+		})(w, r)
+	})
+	http.HandleFunc("POST /users/follow", h.identity.RequireUser(h.followUser))
+	http.HandleFunc("POST /users/unfollow", h.identity.RequireUser(h.unfollowUser))
+	http.HandleFunc("GET /users/suggestions", h.identity.RequireUser(h.suggestFollows))
+	http.HandleFunc("GET /users/mutuals", h.getMutualFollowers)
+	http.HandleFunc("GET /users/path", h.getShortestPath)
+	http.HandleFunc("POST /users/follow/batch", h.identity.RequireUser(func(w http.ResponseWriter, r *http.Request) {
+		h.applyFollowBatch(w, r, h.userUseCase.FollowMany)
+	}))
+	http.HandleFunc("POST /users/unfollow/batch", h.identity.RequireUser(func(w http.ResponseWriter, r *http.Request) {
+		h.applyFollowBatch(w, r, h.userUseCase.UnfollowMany)
+	}))
+	http.HandleFunc("GET /users/lookup", h.getUserByUsername)
+	http.HandleFunc("POST /users/deactivate", h.identity.RequireUser(h.deactivateUser))
+	http.HandleFunc("POST /users/reactivate", h.identity.RequireUser(h.reactivateUser))
+	http.HandleFunc("GET /users/{id}/followers", func(w http.ResponseWriter, r *http.Request) {
+		if userID, ok := pathID(w, r); ok {
+			h.getUserPage(w, r, func(limit int, cursor string) (*usecase.UserPage, error) {
+				return h.userUseCase.GetFollowers(userID, limit, cursor)
+			})
+		}
+	})
+	http.HandleFunc("GET /users/{id}/following", func(w http.ResponseWriter, r *http.Request) {
+		if userID, ok := pathID(w, r); ok {
+			h.getUserPage(w, r, func(limit int, cursor string) (*usecase.UserPage, error) {
+				return h.userUseCase.GetFollowing(userID, limit, cursor)
+			})
+		}
+	})
+	http.HandleFunc("GET /users/{id}/followed-by-friends", h.identity.RequireUser(h.getFollowedByFriends))
+	http.HandleFunc("PUT /users/{id}/follow", h.identity.RequireUser(func(w http.ResponseWriter, r *http.Request) {
+		h.changeFollow(w, r, h.userUseCase.FollowUser)
+	}))
+	http.HandleFunc("DELETE /users/{id}/follow", h.identity.RequireUser(func(w http.ResponseWriter, r *http.Request) {
+		h.changeFollow(w, r, h.userUseCase.UnfollowUser)
+	}))
 }
 
 // Creates a new user
@@ -104,17 +152,23 @@ func (h *UserHandler) createUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate request
-	if req.Username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "username is required"})
+	v := &validator{}
+	v.check(req.Username != "", "username", "is required")
+	v.check(len(req.Username) <= entity.MaxUsernameLength, "username", fmt.Sprintf("must be at most %d characters", entity.MaxUsernameLength))
+	if !v.writeErrors(w) {
 		return
 	}
 
 	// Create user
 	user, err := h.userUseCase.CreateUser(req.Username)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		if err == entity.ErrUsernameReserved || err == entity.ErrUsernameTaken {
+			w.WriteHeader(http.StatusConflict)
+			writeErrorBody(w, r, err)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
 		return
 	}
 
@@ -129,14 +183,24 @@ func (h *UserHandler) createUser(w http.ResponseWriter, r *http.Request) {
 
 // Returns all users
 func (h *UserHandler) getUsers(w http.ResponseWriter, r *http.Request) {
+	// A limit or cursor asks for a single page instead of every user
+	query := r.URL.Query()
+	if query.Has("limit") || query.Has("cursor") {
+		h.getUserPage(w, r, h.userUseCase.GetUsers)
+		return
+	}
+
 	// Get all users
 	users, err := h.userUseCase.GetAllUsers()
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
 		return
 	}
 
+	// Every user is returned, so the total is already known
+	setPaginationHeaders(w, r, pageInfo{Total: len(users)})
+
 	// Convert to response format
 	response := make([]UserResponse, len(users))
 	for i, user := range users {
@@ -152,37 +216,132 @@ func (h *UserHandler) getUsers(w http.ResponseWriter, r *http.Request) {
 }
 
 // Returns a specific user
+// Deactivated users are only visible to themselves
 func (h *UserHandler) getUser(w http.ResponseWriter, r *http.Request, userID string) {
+	// Identify the viewer, if any; anonymous requests are allowed
+	viewerID, _ := requestUserID(r)
+
+	// Get user
+	user, err := h.userUseCase.GetProfile(viewerID, userID)
+	if err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	h.writeProfile(w, r, user)
+}
+
+// Returns a specific user looked up by the username query parameter, in any case
+func (h *UserHandler) getUserByUsername(w http.ResponseWriter, r *http.Request) {
+	// Identify the viewer, if any; anonymous requests are allowed
+	viewerID, _ := requestUserID(r)
+
 	// Get user
-	user, err := h.userUseCase.GetUser(userID)
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "username is required"})
+		return
+	}
+	user, err := h.userUseCase.GetProfileByUsername(viewerID, username)
 	if err != nil {
 		if err == entity.ErrUserNotFound {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	h.writeProfile(w, r, user)
+}
+
+// Writes the profile of a user with their follow counts
+func (h *UserHandler) writeProfile(w http.ResponseWriter, r *http.Request, user *entity.User) {
+	// Count follows without listing them
+	counts, err := h.userUseCase.GetFollowCounts(user.ID)
+	if err != nil {
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
 		return
 	}
 
 	// Return response
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(UserResponse{
-		ID:       user.ID,
-		Username: user.Username,
+	json.NewEncoder(w).Encode(ProfileResponse{
+		UserResponse: UserResponse{
+			ID:       user.ID,
+			Username: user.Username,
+		},
+		FollowersCount: counts.Followers,
+		FollowingCount: counts.Following,
 	})
 }
 
-// Makes a user follow another user
-func (h *UserHandler) followUser(w http.ResponseWriter, r *http.Request) {
-	// Get follower ID from header
-	followerID := r.Header.Get("User-ID")
-	if followerID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "User-ID header is required"})
+// Deactivates the requesting user's account
+func (h *UserHandler) deactivateUser(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, h.userUseCase.DeactivateUser)
+}
+
+// Reactivates the requesting user's account
+func (h *UserHandler) reactivateUser(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, h.userUseCase.ReactivateUser)
+}
+
+// Deletes the account of the user in the path; users can only delete their own
+func (h *UserHandler) deleteUser(w http.ResponseWriter, r *http.Request, userID string) {
+	// Compare with the user resolved by the identity middleware
+	if currentUser(r).ID != userID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "users can only delete their own account"})
+		return
+	}
+
+	// Delete user
+	if err := h.userUseCase.DeleteUser(userID); err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Return response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Applies a deactivation or reactivation to the requesting user's account
+func (h *UserHandler) setActive(w http.ResponseWriter, r *http.Request, apply func(userID string) error) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	if err := apply(userID); err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
 		return
 	}
 
+	// Return success response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Makes a user follow another user
+func (h *UserHandler) followUser(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	followerID := currentUser(r).ID
+
 	// Parse request body
 	var req FollowRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
@@ -191,26 +350,34 @@ func (h *UserHandler) followUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate request
-	if req.FollowedID == "" {
+	// Validate request; self-follows are rejected here, before reaching the use case
+	followed, ok := followedID(w, r, req)
+	if !ok {
+		return
+	}
+	if followed == followerID {
 		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "followed_id is required"})
+		writeErrorBody(w, r, entity.ErrCannotFollowSelf)
 		return
 	}
 
 	// Follow user
-	err = h.userUseCase.FollowUser(followerID, req.FollowedID)
+	err = h.userUseCase.FollowUser(followerID, followed)
 	if err != nil {
 		if err == entity.ErrUserNotFound {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		} else if err == entity.ErrCannotFollowSelf {
 			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			writeErrorBody(w, r, err)
+			return
+		} else if err == entity.ErrAlreadyFollowing {
+			w.WriteHeader(http.StatusConflict)
+			writeErrorBody(w, r, err)
 			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
 		return
 	}
 
@@ -221,13 +388,8 @@ func (h *UserHandler) followUser(w http.ResponseWriter, r *http.Request) {
 
 // Makes a user unfollow another user
 func (h *UserHandler) unfollowUser(w http.ResponseWriter, r *http.Request) {
-	// Get follower ID from header
-	followerID := r.Header.Get("User-ID")
-	if followerID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "User-ID header is required"})
-		return
-	}
+	// Get the user resolved by the identity middleware
+	followerID := currentUser(r).ID
 
 	// Parse request body
 	var req FollowRequest
@@ -238,21 +400,24 @@ func (h *UserHandler) unfollowUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate request
-	if req.FollowedID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "followed_id is required"})
+	followed, ok := followedID(w, r, req)
+	if !ok {
 		return
 	}
 
 	// Unfollow user
-	err = h.userUseCase.UnfollowUser(followerID, req.FollowedID)
+	err = h.userUseCase.UnfollowUser(followerID, followed)
 	if err != nil {
 		if err == entity.ErrUserNotFound {
 			w.WriteHeader(http.StatusNotFound)
 			return
+		} else if err == entity.ErrNotFollowing {
+			w.WriteHeader(http.StatusConflict)
+			writeErrorBody(w, r, err)
+			return
 		}
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
 		return
 	}
 
@@ -260,3 +425,325 @@ func (h *UserHandler) unfollowUser(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "User unfollowed successfully"})
 }
+
+// Applies change to the follow of the user in the path by the requesting user
+// The resource counterpart of POST /users/follow and /users/unfollow, answering 204 on success
+// Unlike those routes it is idempotent: following a followed user or unfollowing a user not followed
+// also answers 204, leaving the follow and the cached timelines untouched
+func (h *UserHandler) changeFollow(w http.ResponseWriter, r *http.Request, change func(followerID, followedID string) error) {
+	// Get the user resolved by the identity middleware
+	followerID := currentUser(r).ID
+
+	// Validate request; self-follows are rejected here, before reaching the use case
+	followedID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if followedID == followerID {
+		w.WriteHeader(http.StatusBadRequest)
+		writeErrorBody(w, r, entity.ErrCannotFollowSelf)
+		return
+	}
+
+	// Change follow; a follow already in the requested state is not an error
+	err := change(followerID, followedID)
+	if err != nil && err != entity.ErrAlreadyFollowing && err != entity.ErrNotFollowing {
+		switch err {
+		case entity.ErrUserNotFound:
+			w.WriteHeader(http.StatusNotFound)
+		case entity.ErrCannotFollowSelf:
+			w.WriteHeader(http.StatusBadRequest)
+		case entity.ErrBlocked:
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			writeErrorStatus(w, err)
+		}
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Return success response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Returns users suggested for the requesting user to follow
+func (h *UserHandler) suggestFollows(w http.ResponseWriter, r *http.Request) {
+	// Get the user resolved by the identity middleware
+	userID := currentUser(r).ID
+
+	// Parse optional limit
+	limit := defaultSuggestionsLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxSuggestionsLimit {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "limit must be between 1 and " + strconv.Itoa(maxSuggestionsLimit)})
+			return
+		}
+		limit = parsed
+	}
+
+	// Get suggestions
+	users, err := h.userUseCase.SuggestFollows(userID, limit)
+	if err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Every user is returned, so the total is already known
+	setPaginationHeaders(w, r, pageInfo{Total: len(users)})
+
+	// Convert to response format
+	response := make([]UserResponse, len(users))
+	for i, user := range users {
+		response[i] = UserResponse{
+			ID:       user.ID,
+			Username: user.Username,
+		}
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Returns the users that follow both given users
+func (h *UserHandler) getMutualFollowers(w http.ResponseWriter, r *http.Request) {
+	// Validate request
+	userID := r.URL.Query().Get("user_id")
+	otherID := r.URL.Query().Get("other")
+	if userID == "" || otherID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "user_id and other query parameters are required"})
+		return
+	}
+
+	// Get mutual followers
+	users, err := h.userUseCase.MutualFollowers(userID, otherID)
+	if err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Every user is returned, so the total is already known
+	setPaginationHeaders(w, r, pageInfo{Total: len(users)})
+
+	// Convert to response format
+	response := make([]UserResponse, len(users))
+	for i, user := range users {
+		response[i] = UserResponse{
+			ID:       user.ID,
+			Username: user.Username,
+		}
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Returns the users followed by the caller who also follow the user in the path
+// At most limit users are returned, ordered by ID; X-Total-Count holds how many there are in total
+func (h *UserHandler) getFollowedByFriends(w http.ResponseWriter, r *http.Request) {
+	// Validate the user ID in the path
+	targetID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse optional limit
+	limit := defaultUserPageLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxUserPageLimit {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "limit must be between 1 and " + strconv.Itoa(maxUserPageLimit)})
+			return
+		}
+		limit = parsed
+	}
+
+	// Get the caller's followees who follow the user
+	users, total, err := h.userUseCase.FollowedByFriends(currentUser(r).ID, targetID, limit)
+	if err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			writeErrorBody(w, r, err)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+	setPaginationHeaders(w, r, pageInfo{Total: total})
+
+	// Convert to response format
+	response := make([]UserResponse, len(users))
+	for i, user := range users {
+		response[i] = UserResponse{
+			ID:       user.ID,
+			Username: user.Username,
+		}
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Returns the shortest follow path between two users
+func (h *UserHandler) getShortestPath(w http.ResponseWriter, r *http.Request) {
+	// Validate request
+	fromID := r.URL.Query().Get("from")
+	toID := r.URL.Query().Get("to")
+	if fromID == "" || toID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "from and to query parameters are required"})
+		return
+	}
+
+	// Find path
+	path, err := h.userUseCase.ShortestPath(fromID, toID, maxPathDepth)
+	if err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		} else if err == entity.ErrPathNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			writeErrorBody(w, r, err)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Convert to response format
+	response := PathResponse{
+		Path:   make([]UserResponse, len(path)),
+		Length: len(path) - 1,
+	}
+	for i, user := range path {
+		response.Path[i] = UserResponse{
+			ID:       user.ID,
+			Username: user.Username,
+		}
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Follows or unfollows a list of users, returning a result per user ID
+func (h *UserHandler) applyFollowBatch(w http.ResponseWriter, r *http.Request, apply func(followerID string, followedIDs []string) ([]usecase.FollowResult, error)) {
+	// Get the user resolved by the identity middleware
+	followerID := currentUser(r).ID
+
+	// Parse request body
+	var followedIDs []string
+	err := json.NewDecoder(r.Body).Decode(&followedIDs)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Validate request
+	if len(followedIDs) == 0 || len(followedIDs) > maxFollowBatchSize {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "between 1 and " + strconv.Itoa(maxFollowBatchSize) + " user IDs are required"})
+		return
+	}
+	for i, id := range followedIDs {
+		followedIDs[i] = strings.TrimSpace(id)
+	}
+
+	// Apply the batch
+	results, err := apply(followerID, followedIDs)
+	if err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Convert to response format
+	response := make([]FollowBatchResult, len(results))
+	for i, result := range results {
+		response[i] = FollowBatchResult{
+			FollowedID: result.FollowedID,
+			Success:    result.Err == nil,
+		}
+		if result.Err != nil {
+			response[i].Error = result.Err.Error()
+		}
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Returns a page of users as fetched by get, such as the followers of a user
+func (h *UserHandler) getUserPage(w http.ResponseWriter, r *http.Request, get func(limit int, cursor string) (*usecase.UserPage, error)) {
+	// Parse optional limit
+	limit := defaultUserPageLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxUserPageLimit {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "limit must be between 1 and " + strconv.Itoa(maxUserPageLimit)})
+			return
+		}
+		limit = parsed
+	}
+
+	// Get page
+	page, err := get(limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		if err == entity.ErrUserNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err == entity.ErrInvalidCursor {
+			w.WriteHeader(http.StatusBadRequest)
+			writeErrorBody(w, r, err)
+			return
+		}
+		writeErrorStatus(w, err)
+		writeErrorBody(w, r, err)
+		return
+	}
+
+	// Counting every user of the listing would need a full read, so only the next page is linked
+	setPaginationHeaders(w, r, pageInfo{NextCursor: page.NextCursor, Total: -1})
+
+	// Convert to response format
+	response := UserPageResponse{
+		Users:      make([]UserResponse, len(page.Users)),
+		NextCursor: page.NextCursor,
+	}
+	for i, user := range page.Users {
+		response.Users[i] = UserResponse{
+			ID:       user.ID,
+			Username: user.Username,
+		}
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Describes why a single field of a request is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Represents the response body for requests that fail validation
+type ValidationErrorResponse struct {
+	Errors []FieldError `json:"errors"`
+}
+
+// Collects every field error of a request instead of stopping at the first one
+type validator struct {
+	errors []FieldError
+}
+
+// Records a field error when the condition does not hold
+// Only the first error of each field is kept, so later checks can assume earlier ones passed
+func (v *validator) check(ok bool, field, message string) {
+	if ok || v.hasError(field) {
+		return
+	}
+	v.errors = append(v.errors, FieldError{Field: field, Message: message})
+}
+
+// Checks if an error was already recorded for the field
+func (v *validator) hasError(field string) bool {
+	for _, fieldError := range v.errors {
+		if fieldError.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Writes the collected field errors with status 422 if there are any
+// Returns true if the request was valid and nothing was written
+func (v *validator) writeErrors(w http.ResponseWriter) bool {
+	if len(v.errors) == 0 {
+		return true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(ValidationErrorResponse{Errors: v.errors})
+	return false
+}
+
+// Returns the {id} wildcard of the URL path of a request
+// Writes a 400 response for a malformed ID and returns false; sub-paths never reach the handler
+func pathID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := r.PathValue("id")
+	if !entity.IsValidID(id) {
+		w.WriteHeader(http.StatusBadRequest)
+		writeErrorBody(w, r, entity.ErrInvalidID)
+		return "", false
+	}
+	return id, true
+}
+
+// Returns the followed_id of a follow or unfollow request, trimmed of surrounding whitespace
+// Writes a 400 response for a missing or malformed ID and returns false
+func followedID(w http.ResponseWriter, r *http.Request, req FollowRequest) (string, bool) {
+	id := strings.TrimSpace(req.FollowedID)
+	if id == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "followed_id is required"})
+		return "", false
+	}
+	if !entity.IsValidID(id) {
+		w.WriteHeader(http.StatusBadRequest)
+		writeErrorBody(w, r, entity.ErrInvalidID)
+		return "", false
+	}
+	return id, true
+}
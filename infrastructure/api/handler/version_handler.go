@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+)
+
+// Represents the response body describing the running build
+type VersionResponse struct {
+	GitCommit string `json:"git_commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+	RunMode   string `json:"run_mode"`
+}
+
+// Handles HTTP requests for build information
+type VersionHandler struct {
+	version VersionResponse
+}
+
+// Creates a new version handler for the given build and run mode (local or lambda)
+func NewVersionHandler(gitCommit, buildTime, runMode string) *VersionHandler {
+	return &VersionHandler{
+		version: VersionResponse{
+			GitCommit: gitCommit,
+			BuildTime: buildTime,
+			GoVersion: runtime.Version(),
+			RunMode:   runMode,
+		},
+	}
+}
+
+// Registers the version route
+func (h *VersionHandler) RegisterRoutes() {
+	http.HandleFunc("GET /version", h.getVersion)
+}
+
+// Returns the build information
+func (h *VersionHandler) getVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.version)
+}
@@ -0,0 +1,28 @@
+// Package server holds the settings of the HTTP server run in local mode.
+package server
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Port the HTTP server listens on when PORT is not set
+const DefaultPort = 8080
+
+// Returns the port to listen on from the value of the PORT variable, DefaultPort when empty
+// Returns an error if the value is not a port number between 1 and 65535
+func ResolvePort(value string) (int, error) {
+	if value == "" {
+		return DefaultPort, nil
+	}
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q", value)
+	}
+	return port, nil
+}
+
+// Returns the address to listen on for the port, on every interface
+func Addr(port int) string {
+	return ":" + strconv.Itoa(port)
+}
@@ -0,0 +1,39 @@
+package server_test
+
+import (
+	"testing"
+
+	"github.com/develpudu/go-challenge/infrastructure/api/server"
+)
+
+func TestResolvePort(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "", want: server.DefaultPort},
+		{value: "3000", want: 3000},
+		{value: "65535", want: 65535},
+		{value: "0", wantErr: true},
+		{value: "65536", wantErr: true},
+		{value: "http", wantErr: true},
+		{value: ":8080", wantErr: true},
+	}
+	for _, tt := range tests {
+		port, err := server.ResolvePort(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ResolvePort(%q): expected an error, got port %d", tt.value, port)
+			}
+			continue
+		}
+		if err != nil || port != tt.want {
+			t.Errorf("ResolvePort(%q) = %d, %v; want %d", tt.value, port, err, tt.want)
+		}
+	}
+
+	if addr := server.Addr(3000); addr != ":3000" {
+		t.Errorf("Expected address :3000, got %q", addr)
+	}
+}
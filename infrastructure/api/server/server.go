@@ -0,0 +1,96 @@
+package server
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// Default time allowed to read a whole request, body included
+	DefaultReadTimeout = 15 * time.Second
+	// Default time allowed to read the request headers, bounding slow-loris clients
+	DefaultReadHeaderTimeout = 5 * time.Second
+	// Default time allowed to write a response; long enough for streamed exports
+	// and the default 30-second CPU profile
+	DefaultWriteTimeout = 60 * time.Second
+	// Default time an idle keep-alive connection is kept open
+	DefaultIdleTimeout = 120 * time.Second
+)
+
+// Configures optional settings of the HTTP server
+type Option func(*options)
+
+// Holds the timeouts of the HTTP server
+type options struct {
+	readTimeout       time.Duration
+	readHeaderTimeout time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+}
+
+// Returns the options with defaults applied, overridden by opts
+func newOptions(opts []Option) options {
+	o := options{
+		readTimeout:       DefaultReadTimeout,
+		readHeaderTimeout: DefaultReadHeaderTimeout,
+		writeTimeout:      DefaultWriteTimeout,
+		idleTimeout:       DefaultIdleTimeout,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Sets the time allowed to read a whole request
+// Non-positive values keep the default
+func WithReadTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		if timeout > 0 {
+			o.readTimeout = timeout
+		}
+	}
+}
+
+// Sets the time allowed to read the request headers
+// Non-positive values keep the default
+func WithReadHeaderTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		if timeout > 0 {
+			o.readHeaderTimeout = timeout
+		}
+	}
+}
+
+// Sets the time allowed to write a response
+// Non-positive values keep the default
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		if timeout > 0 {
+			o.writeTimeout = timeout
+		}
+	}
+}
+
+// Sets how long an idle keep-alive connection is kept open
+// Non-positive values keep the default
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		if timeout > 0 {
+			o.idleTimeout = timeout
+		}
+	}
+}
+
+// Creates a new HTTP server listening on addr and serving handler, with the configured timeouts
+func New(addr string, handler http.Handler, opts ...Option) *http.Server {
+	o := newOptions(opts)
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       o.readTimeout,
+		ReadHeaderTimeout: o.readHeaderTimeout,
+		WriteTimeout:      o.writeTimeout,
+		IdleTimeout:       o.idleTimeout,
+	}
+}
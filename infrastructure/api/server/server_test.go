@@ -0,0 +1,43 @@
+package server_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/infrastructure/api/server"
+)
+
+func TestNewAppliesDefaultTimeouts(t *testing.T) {
+	srv := server.New(":8080", http.NotFoundHandler())
+
+	if srv.Addr != ":8080" || srv.Handler == nil {
+		t.Fatalf("Expected the address and handler to be set, got %q and %v", srv.Addr, srv.Handler)
+	}
+	if srv.ReadTimeout != server.DefaultReadTimeout || srv.ReadHeaderTimeout != server.DefaultReadHeaderTimeout ||
+		srv.WriteTimeout != server.DefaultWriteTimeout || srv.IdleTimeout != server.DefaultIdleTimeout {
+		t.Errorf("Expected the default timeouts, got read=%v header=%v write=%v idle=%v",
+			srv.ReadTimeout, srv.ReadHeaderTimeout, srv.WriteTimeout, srv.IdleTimeout)
+	}
+}
+
+func TestNewAppliesConfiguredTimeouts(t *testing.T) {
+	srv := server.New(":8080", http.NotFoundHandler(),
+		server.WithReadTimeout(time.Second),
+		server.WithReadHeaderTimeout(2*time.Second),
+		server.WithWriteTimeout(3*time.Second),
+		server.WithIdleTimeout(4*time.Second),
+	)
+
+	if srv.ReadTimeout != time.Second || srv.ReadHeaderTimeout != 2*time.Second ||
+		srv.WriteTimeout != 3*time.Second || srv.IdleTimeout != 4*time.Second {
+		t.Errorf("Expected the configured timeouts, got read=%v header=%v write=%v idle=%v",
+			srv.ReadTimeout, srv.ReadHeaderTimeout, srv.WriteTimeout, srv.IdleTimeout)
+	}
+
+	// Non-positive values keep the defaults
+	srv = server.New(":8080", http.NotFoundHandler(), server.WithWriteTimeout(0), server.WithIdleTimeout(-time.Second))
+	if srv.WriteTimeout != server.DefaultWriteTimeout || srv.IdleTimeout != server.DefaultIdleTimeout {
+		t.Errorf("Expected non-positive timeouts to keep the defaults, got write=%v idle=%v", srv.WriteTimeout, srv.IdleTimeout)
+	}
+}
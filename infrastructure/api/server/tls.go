@@ -0,0 +1,55 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"net/http"
+)
+
+// Returned when only one of the certificate and key files is set
+var ErrIncompleteTLS = errors.New("TLS needs both a certificate and a key file")
+
+// Certificate and private key files in PEM format that enable HTTPS
+// Both empty serves plaintext HTTP
+type TLSFiles struct {
+	CertFile string
+	KeyFile  string
+}
+
+// Reports whether HTTPS is configured
+func (f TLSFiles) Enabled() bool {
+	return f.CertFile != "" || f.KeyFile != ""
+}
+
+// Returns ErrIncompleteTLS if only one of the files is set
+func (f TLSFiles) Validate() error {
+	if (f.CertFile == "") != (f.KeyFile == "") {
+		return ErrIncompleteTLS
+	}
+	return nil
+}
+
+// Listens on the server address and serves HTTPS when files is enabled, plaintext otherwise
+func ListenAndServe(srv *http.Server, files TLSFiles) error {
+	addr := srv.Addr
+	if addr == "" {
+		addr = Addr(DefaultPort)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return Serve(srv, ln, files)
+}
+
+// Serves connections accepted on ln over HTTPS when files is enabled, plaintext otherwise
+func Serve(srv *http.Server, ln net.Listener, files TLSFiles) error {
+	if err := files.Validate(); err != nil {
+		ln.Close()
+		return err
+	}
+	if files.Enabled() {
+		return srv.ServeTLS(ln, files.CertFile, files.KeyFile)
+	}
+	return srv.Serve(ln)
+}
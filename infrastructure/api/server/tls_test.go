@@ -0,0 +1,114 @@
+package server_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/infrastructure/api/server"
+)
+
+// Writes a self-signed certificate for 127.0.0.1 and its key to a temporary directory
+// Returns the certificate and key file paths and the parsed certificate
+func writeTestCert(t *testing.T) (string, string, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certFile, keyFile, cert
+}
+
+// Starts a server answering "ok" on a free local port and returns its address, closing it when the test ends
+func startServer(t *testing.T, files server.TLSFiles) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	srv := server.New(ln.Addr().String(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	go server.Serve(srv, ln, files)
+	t.Cleanup(func() { srv.Close() })
+	return ln.Addr().String()
+}
+
+func TestServeHTTPSWhenConfigured(t *testing.T) {
+	certFile, keyFile, cert := writeTestCert(t)
+	addr := startServer(t, server.TLSFiles{CertFile: certFile, KeyFile: keyFile})
+
+	// Trust only the generated certificate
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+
+	resp, err := client.Get("https://" + addr)
+	if err != nil {
+		t.Fatalf("Expected an HTTPS response, got %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.TLS == nil {
+		t.Errorf("Expected status %v over TLS, got %v (TLS: %v)", http.StatusOK, resp.StatusCode, resp.TLS != nil)
+	}
+}
+
+func TestServePlaintextByDefault(t *testing.T) {
+	addr := startServer(t, server.TLSFiles{})
+
+	resp, err := http.Get("http://" + addr)
+	if err != nil {
+		t.Fatalf("Expected a plaintext response, got %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.TLS != nil {
+		t.Errorf("Expected status %v without TLS, got %v", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestServeRejectsIncompleteTLS(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	srv := server.New(ln.Addr().String(), http.NotFoundHandler())
+
+	err = server.Serve(srv, ln, server.TLSFiles{CertFile: "cert.pem"})
+	if !errors.Is(err, server.ErrIncompleteTLS) {
+		t.Errorf("Expected ErrIncompleteTLS, got %v", err)
+	}
+}
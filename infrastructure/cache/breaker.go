@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/develpudu/go-challenge/infrastructure/metrics"
+)
+
+// Circuit breaker states, also reported by the breaker state gauge.
+const (
+	breakerClosed   = 0
+	breakerOpen     = 1
+	breakerHalfOpen = 2
+)
+
+// Name of the gauge reporting the timeline cache circuit breaker state
+// (0 closed, 1 open, 2 half-open).
+const BreakerStateMetric = "cache.timeline.breaker_state"
+
+// ErrCircuitOpen is returned when the cache is bypassed because of repeated failures.
+var ErrCircuitOpen = errors.New("cache circuit breaker is open")
+
+// circuitBreaker stops calling a failing dependency for a cooldown period
+// after a number of consecutive failures. Once the cooldown passes, calls
+// are let through again (half-open); a success closes the breaker and a
+// failure opens it for another cooldown.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	gauge     *metrics.Gauge
+
+	mutex    sync.Mutex
+	state    int
+	failures int
+	openedAt time.Time
+}
+
+// newCircuitBreaker creates a closed breaker.
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	b := &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		gauge:     metrics.NewGauge(BreakerStateMetric),
+	}
+	b.gauge.Set(breakerClosed)
+	return b
+}
+
+// allow reports whether a call may be attempted.
+func (b *circuitBreaker) allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == breakerOpen {
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.setState(breakerHalfOpen)
+	}
+	return true
+}
+
+// success records a successful call, closing the breaker.
+func (b *circuitBreaker) success() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.failures = 0
+	if b.state != breakerClosed {
+		b.setState(breakerClosed)
+	}
+}
+
+// failure records a failed call, opening the breaker once the threshold is reached.
+func (b *circuitBreaker) failure() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.openedAt = b.now()
+		b.setState(breakerOpen)
+	}
+}
+
+// setState updates the state and its gauge. Must be called with the mutex held.
+func (b *circuitBreaker) setState(state int) {
+	if b.state != state {
+		slog.Warn("Timeline cache circuit breaker changed state", "from", b.state, "to", state, "failures", b.failures)
+	}
+	b.state = state
+	b.gauge.Set(int64(state))
+}
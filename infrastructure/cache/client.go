@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Redis deployment modes accepted by REDIS_MODE
+const (
+	// A single Redis server at REDIS_ENDPOINT
+	ModeSingle = "single"
+	// A Redis Cluster reached through the seed nodes in REDIS_ENDPOINT
+	ModeCluster = "cluster"
+	// A primary discovered through the Sentinels in REDIS_ENDPOINT
+	ModeSentinel = "sentinel"
+)
+
+// RedisConfig describes how to connect to Redis.
+type RedisConfig struct {
+	// Mode is one of ModeSingle, ModeCluster or ModeSentinel; empty means ModeSingle.
+	Mode string
+	// Addrs holds the server address in single mode, the cluster seed nodes in
+	// cluster mode and the Sentinel addresses in sentinel mode.
+	Addrs []string
+	// MasterName is the name of the primary monitored by the Sentinels.
+	MasterName string
+
+	// PoolSize is the maximum number of connections per server; zero keeps the
+	// go-redis default of 10 per CPU.
+	PoolSize int
+	// MinIdleConns is the number of idle connections kept open per server.
+	MinIdleConns int
+	// DialTimeout bounds establishing a connection; zero keeps the go-redis default of 5s.
+	DialTimeout time.Duration
+}
+
+// RedisConfigFromEnv reads the Redis configuration from REDIS_MODE (default single), REDIS_ENDPOINT
+// (comma-separated addresses in cluster and sentinel modes) and REDIS_MASTER_NAME, and the connection
+// pool settings from REDIS_POOL_SIZE, REDIS_MIN_IDLE_CONNS and REDIS_DIAL_TIMEOUT.
+// Invalid pool settings are logged and left at their defaults.
+func RedisConfigFromEnv() RedisConfig {
+	var addrs []string
+	for _, addr := range strings.Split(os.Getenv("REDIS_ENDPOINT"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	mode := os.Getenv("REDIS_MODE")
+	if mode == "" {
+		mode = ModeSingle
+	}
+	cfg := RedisConfig{
+		Mode:       mode,
+		Addrs:      addrs,
+		MasterName: os.Getenv("REDIS_MASTER_NAME"),
+	}
+	cfg.PoolSize = intFromEnv("REDIS_POOL_SIZE")
+	cfg.MinIdleConns = intFromEnv("REDIS_MIN_IDLE_CONNS")
+	if value := os.Getenv("REDIS_DIAL_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			slog.Warn("Invalid REDIS_DIAL_TIMEOUT, using default", "value", value, "error", err)
+		} else {
+			cfg.DialTimeout = timeout
+		}
+	}
+	return cfg
+}
+
+// intFromEnv returns the non-negative integer in the environment variable name,
+// or zero when it is unset or invalid.
+func intFromEnv(name string) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		slog.Warn("Invalid "+name+", using default", "value", value, "error", err)
+		return 0
+	}
+	return n
+}
+
+// NewRedisClient builds the client for cfg: a *redis.Client in single mode, a
+// *redis.ClusterClient in cluster mode and a failover *redis.Client in sentinel
+// mode. Clients connect lazily, so no connection is attempted here.
+func NewRedisClient(cfg RedisConfig) (redis.UniversalClient, error) {
+	if len(cfg.Addrs) == 0 {
+		return nil, errors.New("REDIS_ENDPOINT environment variable not set")
+	}
+
+	switch cfg.Mode {
+	case "", ModeSingle:
+		if len(cfg.Addrs) > 1 {
+			return nil, fmt.Errorf("redis mode %q takes a single address, got %d", ModeSingle, len(cfg.Addrs))
+		}
+		return redis.NewClient(&redis.Options{
+			Addr:         cfg.Addrs[0],
+			PoolSize:     cfg.PoolSize,
+			MinIdleConns: cfg.MinIdleConns,
+			DialTimeout:  cfg.DialTimeout,
+		}), nil
+	case ModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        cfg.Addrs,
+			PoolSize:     cfg.PoolSize,
+			MinIdleConns: cfg.MinIdleConns,
+			DialTimeout:  cfg.DialTimeout,
+		}), nil
+	case ModeSentinel:
+		if cfg.MasterName == "" {
+			return nil, errors.New("REDIS_MASTER_NAME must be set in sentinel mode")
+		}
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    cfg.MasterName,
+			SentinelAddrs: cfg.Addrs,
+			PoolSize:      cfg.PoolSize,
+			MinIdleConns:  cfg.MinIdleConns,
+			DialTimeout:   cfg.DialTimeout,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown redis mode %q", cfg.Mode)
+	}
+}
@@ -0,0 +1,119 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/infrastructure/cache"
+	"github.com/go-redis/redis/v8"
+)
+
+// Every client built by NewRedisClient can back the timeline cache
+var (
+	_ cache.RedisClient = (*redis.Client)(nil)
+	_ cache.RedisClient = (*redis.ClusterClient)(nil)
+)
+
+func TestRedisConfigFromEnvSplitsAddresses(t *testing.T) {
+	// Arrange
+	t.Setenv("REDIS_MODE", "")
+	t.Setenv("REDIS_ENDPOINT", "node1:6379, node2:6379,,node3:6379")
+	t.Setenv("REDIS_MASTER_NAME", "primary")
+
+	// Act
+	cfg := cache.RedisConfigFromEnv()
+
+	// Assert
+	if cfg.Mode != cache.ModeSingle {
+		t.Errorf("Expected mode to default to %q, got %q", cache.ModeSingle, cfg.Mode)
+	}
+	if len(cfg.Addrs) != 3 || cfg.Addrs[0] != "node1:6379" || cfg.Addrs[1] != "node2:6379" || cfg.Addrs[2] != "node3:6379" {
+		t.Errorf("Expected three trimmed addresses, got %q", cfg.Addrs)
+	}
+	if cfg.MasterName != "primary" {
+		t.Errorf("Expected master name primary, got %q", cfg.MasterName)
+	}
+}
+
+func TestNewRedisClientPerMode(t *testing.T) {
+	// Arrange
+	addrs := []string{"node1:6379", "node2:6379"}
+
+	// Act
+	single, singleErr := cache.NewRedisClient(cache.RedisConfig{Mode: cache.ModeSingle, Addrs: addrs[:1]})
+	cluster, clusterErr := cache.NewRedisClient(cache.RedisConfig{Mode: cache.ModeCluster, Addrs: addrs})
+	sentinel, sentinelErr := cache.NewRedisClient(cache.RedisConfig{Mode: cache.ModeSentinel, Addrs: addrs, MasterName: "primary"})
+	for _, client := range []redis.UniversalClient{single, cluster, sentinel} {
+		if client != nil {
+			defer client.Close()
+		}
+	}
+
+	// Assert
+	if singleErr != nil || clusterErr != nil || sentinelErr != nil {
+		t.Fatalf("Expected no errors, got %v, %v, %v", singleErr, clusterErr, sentinelErr)
+	}
+	if c, ok := single.(*redis.Client); !ok || c.Options().Addr != "node1:6379" {
+		t.Errorf("Expected a plain client for node1:6379 in single mode, got %T", single)
+	}
+	if c, ok := cluster.(*redis.ClusterClient); !ok || len(c.Options().Addrs) != 2 {
+		t.Errorf("Expected a cluster client seeded with both nodes in cluster mode, got %T", cluster)
+	}
+	// Failover clients are plain clients whose connections go through the Sentinels
+	if c, ok := sentinel.(*redis.Client); !ok || c.Options().Addr != "FailoverClient" {
+		t.Errorf("Expected a failover client in sentinel mode, got %T", sentinel)
+	}
+}
+
+func TestRedisConfigFromEnvReadsPoolSettings(t *testing.T) {
+	// Arrange
+	t.Setenv("REDIS_MODE", "")
+	t.Setenv("REDIS_ENDPOINT", "node1:6379")
+	t.Setenv("REDIS_POOL_SIZE", "5")
+	t.Setenv("REDIS_MIN_IDLE_CONNS", "1")
+	t.Setenv("REDIS_DIAL_TIMEOUT", "750ms")
+
+	// Act
+	cfg := cache.RedisConfigFromEnv()
+	client, err := cache.NewRedisClient(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer client.Close()
+
+	// Assert: the settings reach the client options
+	options := client.(*redis.Client).Options()
+	if options.PoolSize != 5 || options.MinIdleConns != 1 || options.DialTimeout != 750*time.Millisecond {
+		t.Errorf("Expected pool size 5, 1 idle connection and a 750ms dial timeout, got %d, %d and %v",
+			options.PoolSize, options.MinIdleConns, options.DialTimeout)
+	}
+}
+
+func TestRedisConfigFromEnvIgnoresInvalidPoolSettings(t *testing.T) {
+	// Arrange
+	t.Setenv("REDIS_POOL_SIZE", "many")
+	t.Setenv("REDIS_MIN_IDLE_CONNS", "-1")
+	t.Setenv("REDIS_DIAL_TIMEOUT", "soon")
+
+	// Act
+	cfg := cache.RedisConfigFromEnv()
+
+	// Assert
+	if cfg.PoolSize != 0 || cfg.MinIdleConns != 0 || cfg.DialTimeout != 0 {
+		t.Errorf("Expected invalid settings to keep the defaults, got %+v", cfg)
+	}
+}
+
+func TestNewRedisClientRejectsInvalidConfig(t *testing.T) {
+	configs := map[string]cache.RedisConfig{
+		"no address":            {Mode: cache.ModeSingle},
+		"several single":        {Mode: cache.ModeSingle, Addrs: []string{"a:6379", "b:6379"}},
+		"sentinel without name": {Mode: cache.ModeSentinel, Addrs: []string{"a:26379"}},
+		"unknown mode":          {Mode: "replicated", Addrs: []string{"a:6379"}},
+	}
+	for name, cfg := range configs {
+		if _, err := cache.NewRedisClient(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
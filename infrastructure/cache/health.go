@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/develpudu/go-challenge/infrastructure/metrics"
+)
+
+// Name of the gauge reporting whether Redis answered the last health check (1 reachable, 0 unreachable).
+const ReachableMetric = "cache.redis.reachable"
+
+// Default interval between Redis health checks.
+const DefaultHealthInterval = 15 * time.Second
+
+// Pinger checks that a cache server answers, like RedisTimelineCache.Ping.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// HealthMonitor pings the cache in the background and remembers whether it answered,
+// so health reports never wait on Redis from the request path.
+type HealthMonitor struct {
+	pinger    Pinger
+	interval  time.Duration
+	gauge     *metrics.Gauge
+	reachable atomic.Bool
+}
+
+// NewHealthMonitor creates a monitor pinging pinger every interval once started.
+// A non-positive interval uses DefaultHealthInterval.
+func NewHealthMonitor(pinger Pinger, interval time.Duration) *HealthMonitor {
+	if interval <= 0 {
+		interval = DefaultHealthInterval
+	}
+	return &HealthMonitor{
+		pinger:   pinger,
+		interval: interval,
+		gauge:    metrics.NewGauge(ReachableMetric),
+	}
+}
+
+// Start checks the cache once, then keeps checking it every interval until ctx is done.
+func (m *HealthMonitor) Start(ctx context.Context) {
+	m.Check(ctx)
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.Check(ctx)
+			}
+		}
+	}()
+}
+
+// Check pings the cache and records whether it answered, logging changes of state.
+func (m *HealthMonitor) Check(ctx context.Context) {
+	err := m.pinger.Ping(ctx)
+	reachable := err == nil
+	if previous := m.reachable.Swap(reachable); previous != reachable {
+		if reachable {
+			slog.InfoContext(ctx, "Redis cache is reachable")
+		} else {
+			slog.WarnContext(ctx, "Redis cache is unreachable", "error", err)
+		}
+	}
+	if reachable {
+		m.gauge.Set(1)
+	} else {
+		m.gauge.Set(0)
+	}
+}
+
+// Reachable reports whether the cache answered the last check.
+func (m *HealthMonitor) Reachable() bool {
+	return m.reachable.Load()
+}
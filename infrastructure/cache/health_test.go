@@ -0,0 +1,58 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/infrastructure/cache"
+	"github.com/develpudu/go-challenge/infrastructure/metrics"
+)
+
+func TestHealthMonitorTracksReachability(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	redisCache := cache.NewRedisTimelineCacheWithClient(client)
+	monitor := cache.NewHealthMonitor(redisCache, time.Minute)
+	gauge := metrics.NewGauge(cache.ReachableMetric)
+	ctx := context.Background()
+
+	// Act & Assert: reachable
+	monitor.Check(ctx)
+	if !monitor.Reachable() || gauge.Value() != 1 {
+		t.Errorf("Expected a reachable cache, got %v with gauge %d", monitor.Reachable(), gauge.Value())
+	}
+
+	// Act & Assert: unreachable
+	client.SetErr(errors.New("connection refused"))
+	monitor.Check(ctx)
+	if monitor.Reachable() || gauge.Value() != 0 {
+		t.Errorf("Expected an unreachable cache, got %v with gauge %d", monitor.Reachable(), gauge.Value())
+	}
+}
+
+func TestHealthMonitorChecksInBackground(t *testing.T) {
+	// Arrange: Redis is down when the monitor starts
+	client := NewMockRedisClient()
+	client.SetErr(errors.New("connection refused"))
+	monitor := cache.NewHealthMonitor(cache.NewRedisTimelineCacheWithClient(client), 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Act
+	monitor.Start(ctx)
+	if monitor.Reachable() {
+		t.Fatal("Expected the first check to find the cache unreachable")
+	}
+	client.SetErr(nil)
+
+	// Assert: a later check notices Redis is back
+	deadline := time.Now().Add(time.Second)
+	for !monitor.Reachable() {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the background check to find the cache reachable")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
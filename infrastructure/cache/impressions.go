@@ -0,0 +1,173 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// Default window during which repeated views of a tweet by the same viewer count once
+	defaultImpressionWindow = 30 * time.Minute
+	// Key prefix for impression counters in Redis, shared by the viewer markers and the pending set
+	impressionKeyPrefix = "impressions:"
+	// Key, after the key prefix, of the markers of viewers already counted within the window
+	impressionSeenKey = "seen:"
+	// Key, after the key prefix, of the set of tweets whose counters have not been taken yet
+	impressionPendingKey = "pending"
+	// Number of tweets popped from the pending set per call when taking counts
+	impressionTakeBatch = 100
+)
+
+// ImpressionCounter defines the interface for counting tweet impressions.
+// Counts accumulate until they are taken to be stored with the tweets.
+type ImpressionCounter interface {
+	// RecordImpression counts a view of the tweet by the viewer, unless the
+	// viewer was already counted within the window, and returns the number of
+	// impressions counted since the tweet's counts were last taken.
+	RecordImpression(ctx context.Context, tweetID, viewerID string) (int64, error)
+
+	// TakeImpressions removes and returns the pending counts by tweet ID. The
+	// counts taken before a failure are returned along with the error.
+	TakeImpressions(ctx context.Context) (map[string]int64, error)
+
+	// RestoreImpressions adds taken counts back, as when they could not be stored.
+	RestoreImpressions(ctx context.Context, counts map[string]int64) error
+}
+
+// RedisImpressionCounter implements ImpressionCounter using Redis. Each tweet
+// has a counter incremented atomically with INCR and listed in a pending set,
+// and each viewer leaves a marker set with SETNX that expires after the window,
+// so reloading a tweet does not inflate its count. Taking the counts pops the
+// pending set and reads each counter with GETDEL, so views recorded meanwhile
+// are kept for the next take.
+type RedisImpressionCounter struct {
+	cache  *RedisTimelineCache
+	window time.Duration
+}
+
+// Impressions returns an impression counter that counts a viewer once per
+// window. It shares the client and circuit breaker of c.
+// A non-positive window keeps the default.
+func (c *RedisTimelineCache) Impressions(window time.Duration) *RedisImpressionCounter {
+	impressions := *c
+	impressions.keyPrefix = impressionKeyPrefix
+	if window <= 0 {
+		window = defaultImpressionWindow
+	}
+	return &RedisImpressionCounter{cache: &impressions, window: window}
+}
+
+// RecordImpression counts a view of the tweet by the viewer and returns the
+// number of impressions of the tweet.
+func (c *RedisImpressionCounter) RecordImpression(ctx context.Context, tweetID, viewerID string) (int64, error) {
+	key := c.cache.generateKey(tweetID)
+	seenKey := c.cache.generateKey(impressionSeenKey + tweetID + ":" + viewerID)
+
+	var count int64
+	err := c.cache.call(ctx, func(ctx context.Context) error {
+		first, err := c.cache.client.SetNX(ctx, seenKey, []byte("1"), c.window).Result()
+		if err != nil {
+			return err
+		}
+		if first {
+			if count, err = c.cache.client.Incr(ctx, key).Result(); err != nil {
+				return err
+			}
+			return c.cache.client.SAdd(ctx, c.cache.generateKey(impressionPendingKey), tweetID).Err()
+		}
+		val, err := c.cache.client.Get(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		count, err = strconv.ParseInt(val, 10, 64)
+		return err
+	})
+	if err == redis.Nil {
+		// The viewer was counted but the counter is gone, taken since or evicted
+		return 0, nil
+	}
+	if err == ErrCircuitOpen {
+		return 0, err
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record impression in Redis", "tweetID", tweetID, "error", err)
+		return 0, fmt.Errorf("failed to record impression of tweet %s in Redis: %w", tweetID, err)
+	}
+	return count, nil
+}
+
+// TakeImpressions pops the pending tweets in batches and takes each counter with
+// GETDEL. A tweet whose counter is gone, e.g. evicted, is skipped.
+func (c *RedisImpressionCounter) TakeImpressions(ctx context.Context) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	pendingKey := c.cache.generateKey(impressionPendingKey)
+	for {
+		var tweetIDs []string
+		err := c.cache.call(ctx, func(ctx context.Context) error {
+			var err error
+			if tweetIDs, err = c.cache.client.SPopN(ctx, pendingKey, impressionTakeBatch).Result(); err != nil {
+				return err
+			}
+			for _, tweetID := range tweetIDs {
+				val, err := c.cache.client.GetDel(ctx, c.cache.generateKey(tweetID)).Result()
+				if err == redis.Nil {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				count, err := strconv.ParseInt(val, 10, 64)
+				if err != nil {
+					return err
+				}
+				counts[tweetID] = count
+			}
+			return nil
+		})
+		if err != nil && err != redis.Nil {
+			if err != ErrCircuitOpen {
+				slog.ErrorContext(ctx, "Failed to take impressions from Redis", "taken", len(counts), "error", err)
+			}
+			return counts, fmt.Errorf("failed to take impressions from Redis: %w", err)
+		}
+		if len(tweetIDs) < impressionTakeBatch {
+			return counts, nil
+		}
+	}
+}
+
+// RestoreImpressions adds counts back to the counters with INCRBY and lists
+// their tweets as pending again.
+func (c *RedisImpressionCounter) RestoreImpressions(ctx context.Context, counts map[string]int64) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	pendingKey := c.cache.generateKey(impressionPendingKey)
+	err := c.cache.call(ctx, func(ctx context.Context) error {
+		for tweetID, count := range counts {
+			if err := c.cache.client.IncrBy(ctx, c.cache.generateKey(tweetID), count).Err(); err != nil {
+				return err
+			}
+			if err := c.cache.client.SAdd(ctx, pendingKey, tweetID).Err(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == ErrCircuitOpen {
+		return err
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to restore impressions in Redis", "tweets", len(counts), "error", err)
+		return fmt.Errorf("failed to restore impressions of %d tweets in Redis: %w", len(counts), err)
+	}
+	return nil
+}
+
+// Compile-time check to ensure RedisImpressionCounter implements ImpressionCounter
+var _ ImpressionCounter = (*RedisImpressionCounter)(nil)
@@ -0,0 +1,134 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/infrastructure/cache"
+)
+
+func TestRecordImpressionIncrements(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	counter := cache.NewRedisTimelineCacheWithClient(client).Impressions(time.Minute)
+	ctx := context.Background()
+
+	// Act
+	first, err1 := counter.RecordImpression(ctx, "tweet1", "user1")
+	second, err2 := counter.RecordImpression(ctx, "tweet1", "user2")
+	other, err3 := counter.RecordImpression(ctx, "tweet2", "user1")
+
+	// Assert
+	if err1 != nil || err2 != nil || err3 != nil {
+		t.Fatalf("Expected no errors, got %v, %v and %v", err1, err2, err3)
+	}
+	if first != 1 || second != 2 {
+		t.Errorf("Expected counts 1 and 2, got %d and %d", first, second)
+	}
+	if other != 1 {
+		t.Errorf("Expected each tweet to be counted separately, got %d", other)
+	}
+}
+
+func TestRecordImpressionDedupsViewerWithinWindow(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	counter := cache.NewRedisTimelineCacheWithClient(client).Impressions(time.Minute)
+	ctx := context.Background()
+	counter.RecordImpression(ctx, "tweet1", "user1")
+
+	// Act
+	repeated, err := counter.RecordImpression(ctx, "tweet1", "user1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if repeated != 1 {
+		t.Errorf("Expected a repeated view to keep the count at 1, got %d", repeated)
+	}
+	if len(client.Expirations) != 1 || client.Expirations[0] != time.Minute {
+		t.Errorf("Expected the viewer marker to expire after the window, got %v", client.Expirations)
+	}
+
+	// Once the marker expires the viewer counts again
+	client.Del(ctx, "impressions:seen:tweet1:user1")
+	again, _ := counter.RecordImpression(ctx, "tweet1", "user1")
+	if again != 2 {
+		t.Errorf("Expected a view after the window to be counted, got %d", again)
+	}
+}
+
+func TestRecordImpressionRedisFailure(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	client.SetErr(errors.New("connection refused"))
+	counter := cache.NewRedisTimelineCacheWithClient(client).Impressions(0)
+
+	// Act
+	count, err := counter.RecordImpression(context.Background(), "tweet1", "user1")
+
+	// Assert
+	if err == nil {
+		t.Error("Expected an error when Redis fails")
+	}
+	if count != 0 {
+		t.Errorf("Expected no count when Redis fails, got %d", count)
+	}
+}
+
+func TestTakeImpressionsResetsCounters(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	counter := cache.NewRedisTimelineCacheWithClient(client).Impressions(time.Minute)
+	ctx := context.Background()
+	counter.RecordImpression(ctx, "tweet1", "user1")
+	counter.RecordImpression(ctx, "tweet1", "user2")
+	counter.RecordImpression(ctx, "tweet2", "user1")
+
+	// Act
+	taken, err := counter.TakeImpressions(ctx)
+	again, againErr := counter.TakeImpressions(ctx)
+
+	// Assert
+	if err != nil || againErr != nil {
+		t.Fatalf("Expected no errors, got %v and %v", err, againErr)
+	}
+	if len(taken) != 2 || taken["tweet1"] != 2 || taken["tweet2"] != 1 {
+		t.Errorf("Expected 2 impressions of tweet1 and 1 of tweet2, got %v", taken)
+	}
+	if len(again) != 0 {
+		t.Errorf("Expected nothing left to take, got %v", again)
+	}
+
+	// Views after the take count from zero, the seen viewers still count once
+	if count, _ := counter.RecordImpression(ctx, "tweet1", "user3"); count != 1 {
+		t.Errorf("Expected a new view to start a new count, got %d", count)
+	}
+	if count, _ := counter.RecordImpression(ctx, "tweet2", "user1"); count != 0 {
+		t.Errorf("Expected a repeated view after the take to count nothing, got %d", count)
+	}
+}
+
+func TestRestoreImpressionsAddsCountsBack(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	counter := cache.NewRedisTimelineCacheWithClient(client).Impressions(time.Minute)
+	ctx := context.Background()
+	counter.RecordImpression(ctx, "tweet1", "user1")
+	taken, _ := counter.TakeImpressions(ctx)
+	counter.RecordImpression(ctx, "tweet1", "user2")
+
+	// Act
+	err := counter.RestoreImpressions(ctx, taken)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if again, _ := counter.TakeImpressions(ctx); again["tweet1"] != 2 {
+		t.Errorf("Expected the restored and the new impression to be taken together, got %v", again)
+	}
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Key prefix for the locks taken while rebuilding a timeline
+const timelineLockKeyPrefix = "timeline_lock:"
+
+// unlockScript deletes a lock only if it still holds the caller's token, so a
+// holder whose lock expired never releases the lock of the next holder.
+const unlockScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`
+
+// Locker hands out short-lived locks shared by every instance of the service.
+type Locker interface {
+	// TryLock takes the lock on key for at most ttl without waiting for it.
+	// It returns whether the lock was taken and, if so, a function that releases it.
+	TryLock(ctx context.Context, key string, ttl time.Duration) (release func(), acquired bool, err error)
+}
+
+// RedisLocker implements Locker using Redis. A lock is a key set with SET NX
+// and a TTL, holding a random token; the TTL frees locks whose holder died.
+type RedisLocker struct {
+	cache *RedisTimelineCache
+}
+
+// TimelineLocks returns a locker for timeline rebuilds, keyed under a separate
+// key prefix. It shares the client and circuit breaker of c.
+func (c *RedisTimelineCache) TimelineLocks() *RedisLocker {
+	locks := *c
+	locks.keyPrefix = timelineLockKeyPrefix
+	return &RedisLocker{cache: &locks}
+}
+
+// TryLock takes the lock on key for at most ttl, returning false without
+// waiting when another holder has it.
+func (l *RedisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (func(), bool, error) {
+	lockKey := l.cache.generateKey(key)
+	token, err := newLockToken()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var acquired bool
+	err = l.cache.call(ctx, func(ctx context.Context) error {
+		var err error
+		acquired, err = l.cache.client.SetNX(ctx, lockKey, []byte(token), ttl).Result()
+		return err
+	})
+	if err == ErrCircuitOpen {
+		return nil, false, err
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to take lock in Redis", "key", lockKey, "error", err)
+		return nil, false, fmt.Errorf("failed to take lock %s in Redis: %w", lockKey, err)
+	}
+	if !acquired {
+		return nil, false, nil
+	}
+
+	release := func() {
+		// Released on a fresh context, so the lock is freed even after the request's context is done
+		ctx := context.Background()
+		err := l.cache.call(ctx, func(ctx context.Context) error {
+			return l.cache.client.Eval(ctx, unlockScript, []string{lockKey}, token).Err()
+		})
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to release lock in Redis, leaving it to expire", "key", lockKey, "error", err)
+		}
+	}
+	return release, true, nil
+}
+
+// newLockToken returns a random token identifying one holder of a lock.
+func newLockToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// Compile-time check to ensure RedisLocker implements Locker
+var _ Locker = (*RedisLocker)(nil)
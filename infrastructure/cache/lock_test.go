@@ -0,0 +1,69 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/infrastructure/cache"
+)
+
+func TestTimelineLockIsHeldByOneCaller(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	locks := cache.NewRedisTimelineCacheWithClient(client).TimelineLocks()
+	ctx := context.Background()
+
+	// Act
+	release, first, firstErr := locks.TryLock(ctx, "alice", 10*time.Second)
+	_, second, secondErr := locks.TryLock(ctx, "alice", 10*time.Second)
+	release()
+	_, third, thirdErr := locks.TryLock(ctx, "alice", 10*time.Second)
+
+	// Assert
+	if firstErr != nil || secondErr != nil || thirdErr != nil {
+		t.Fatalf("Expected no errors, got %v, %v and %v", firstErr, secondErr, thirdErr)
+	}
+	if !first || second || !third {
+		t.Errorf("Expected the lock to be taken, refused while held and taken again once released, got %v, %v and %v", first, second, third)
+	}
+	if client.Expirations[0] != 10*time.Second {
+		t.Errorf("Expected the lock to expire after 10s, got %v", client.Expirations[0])
+	}
+}
+
+func TestTimelineLockReleaseKeepsTheNextHoldersLock(t *testing.T) {
+	// Arrange: the first holder's lock expires and a second caller takes it
+	client := NewMockRedisClient()
+	timelineCache := cache.NewRedisTimelineCacheWithClient(client)
+	locks := timelineCache.TimelineLocks()
+	ctx := context.Background()
+	staleRelease, _, _ := locks.TryLock(ctx, "alice", time.Second)
+	client.Del(ctx, "timeline_lock:alice")
+	locks.TryLock(ctx, "alice", time.Second)
+
+	// Act
+	staleRelease()
+	_, acquired, _ := locks.TryLock(ctx, "alice", time.Second)
+
+	// Assert
+	if acquired {
+		t.Error("Expected the stale release to leave the second holder's lock in place")
+	}
+}
+
+func TestTimelineLockFailsWhenRedisIsDown(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	client.SetErr(errors.New("connection refused"))
+	locks := cache.NewRedisTimelineCacheWithClient(client).TimelineLocks()
+
+	// Act
+	release, acquired, err := locks.TryLock(context.Background(), "alice", time.Second)
+
+	// Assert: the caller learns it has no lock and can rebuild without it
+	if err == nil || acquired || release != nil {
+		t.Errorf("Expected an error and no lock, got %v, %v", acquired, err)
+	}
+}
@@ -0,0 +1,99 @@
+package cache
+
+import "time"
+
+const (
+	// Default deadline applied to each Redis call
+	defaultCallTimeout = 200 * time.Millisecond
+	// Default number of consecutive failures that open the circuit breaker
+	defaultBreakerThreshold = 5
+	// Default time the cache is bypassed once the circuit breaker opens
+	defaultBreakerCooldown = 30 * time.Second
+	// Default fraction of the TTL randomly added to or removed from each entry
+	defaultTTLJitter = 0.1
+)
+
+// Option configures optional settings of the Redis timeline cache.
+type Option func(*options)
+
+// options holds the settings of the Redis timeline cache.
+type options struct {
+	ttl              time.Duration
+	softTTL          time.Duration
+	ttlJitter        float64
+	callTimeout      time.Duration
+	breakerThreshold int
+	breakerCooldown  time.Duration
+}
+
+// newOptions returns the options with defaults applied, overridden by opts.
+func newOptions(opts []Option) options {
+	o := options{
+		ttl:              defaultTimelineTTL,
+		ttlJitter:        defaultTTLJitter,
+		callTimeout:      defaultCallTimeout,
+		breakerThreshold: defaultBreakerThreshold,
+		breakerCooldown:  defaultBreakerCooldown,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.softTTL < 0 || o.softTTL >= o.ttl {
+		o.softTTL = 0
+	}
+	return o
+}
+
+// WithTTL sets how long cached timelines are kept, their hard TTL.
+// Non-positive values keep the default.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		if ttl > 0 {
+			o.ttl = ttl
+		}
+	}
+}
+
+// WithSoftTTL sets the age after which cached timelines are stale: they are still
+// served until the hard TTL, while callers rebuild them in the background.
+// Zero, the default, keeps timelines fresh until they expire. A soft TTL that is
+// not below the hard TTL is ignored.
+func WithSoftTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.softTTL = ttl
+	}
+}
+
+// WithTTLJitter sets the fraction of the TTL randomly added to or removed from
+// each cached timeline, so entries written together do not expire together.
+// Zero disables jitter; values outside [0, 1) keep the default.
+func WithTTLJitter(fraction float64) Option {
+	return func(o *options) {
+		if fraction >= 0 && fraction < 1 {
+			o.ttlJitter = fraction
+		}
+	}
+}
+
+// WithCallTimeout sets the deadline applied to each Redis call.
+// Non-positive values keep the default.
+func WithCallTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		if timeout > 0 {
+			o.callTimeout = timeout
+		}
+	}
+}
+
+// WithCircuitBreaker sets how many consecutive failures open the circuit breaker
+// and how long the cache is bypassed afterwards. Non-positive values keep the defaults.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(o *options) {
+		if threshold > 0 {
+			o.breakerThreshold = threshold
+		}
+		if cooldown > 0 {
+			o.breakerCooldown = cooldown
+		}
+	}
+}
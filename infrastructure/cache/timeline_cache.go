@@ -3,10 +3,10 @@ package cache
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log/slog"
-	"os"
+	"math/rand/v2"
+	"strings"
 	"time"
 
 	"github.com/develpudu/go-challenge/domain/entity"
@@ -18,6 +18,8 @@ const (
 	defaultTimelineTTL = 5 * time.Minute
 	// Key prefix for timeline cache entries in Redis
 	timelineKeyPrefix = "timeline:"
+	// Key prefix for list timeline cache entries in Redis
+	listTimelineKeyPrefix = "list_timeline:"
 )
 
 // TimelineCache defines the interface for caching user timelines.
@@ -31,98 +33,227 @@ type TimelineCache interface {
 
 	// InvalidateTimeline removes a cached timeline for a user.
 	InvalidateTimeline(ctx context.Context, userID string) error
+
+	// InvalidateTimelines removes the cached timelines of several users at once.
+	// An empty list is a no-op.
+	InvalidateTimelines(ctx context.Context, userIDs []string) error
+}
+
+// StaleTimelineCache is a TimelineCache that keeps serving timelines past their
+// soft TTL, until their hard TTL, so callers can rebuild them in the background.
+type StaleTimelineCache interface {
+	TimelineCache
+
+	// GetStaleTimeline retrieves a cached timeline like GetTimeline, also reporting
+	// whether it is past its soft TTL and should be rebuilt.
+	GetStaleTimeline(ctx context.Context, userID string) (timeline []*entity.Tweet, found, stale bool, err error)
+}
+
+// RedisClient is the subset of the Redis client used by the timeline cache.
+type RedisClient interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd
+	Incr(ctx context.Context, key string) *redis.IntCmd
+	IncrBy(ctx context.Context, key string, value int64) *redis.IntCmd
+	GetDel(ctx context.Context, key string) *redis.StringCmd
+	SAdd(ctx context.Context, key string, members ...interface{}) *redis.IntCmd
+	SPopN(ctx context.Context, key string, count int64) *redis.StringSliceCmd
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd
+	Ping(ctx context.Context) *redis.StatusCmd
+	Close() error
 }
 
-// RedisTimelineCache implements TimelineCache using Redis.
+// RedisTimelineCache implements StaleTimelineCache using Redis.
+// Every call is bounded by a short timeout and guarded by a circuit breaker,
+// so a slow or unavailable Redis is bypassed instead of delaying requests.
+// Entries expire from Redis after the hard TTL; with a soft TTL they are
+// reported stale once it passes, see GetStaleTimeline.
 type RedisTimelineCache struct {
-	client *redis.Client
-	ttl    time.Duration
+	client      RedisClient
+	keyPrefix   string
+	hardTTL     time.Duration
+	softTTL     time.Duration
+	ttlJitter   float64
+	callTimeout time.Duration
+	breaker     *circuitBreaker
 }
 
 // NewRedisTimelineCache creates a new Redis timeline cache client.
-// It reads the Redis endpoint from the REDIS_ENDPOINT environment variable.
-func NewRedisTimelineCache(ctx context.Context) (*RedisTimelineCache, error) {
-	redisEndpoint := os.Getenv("REDIS_ENDPOINT")
-	if redisEndpoint == "" {
-		return nil, errors.New("REDIS_ENDPOINT environment variable not set")
+// It connects as described by the REDIS_MODE, REDIS_ENDPOINT and
+// REDIS_MASTER_NAME environment variables (see RedisConfigFromEnv).
+func NewRedisTimelineCache(ctx context.Context, opts ...Option) (*RedisTimelineCache, error) {
+	cfg := RedisConfigFromEnv()
+	client, err := NewRedisClient(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	client := redis.NewClient(&redis.Options{
-		Addr: redisEndpoint,
-		// Add other options like Password, DB if needed
-	})
-
 	// Ping the server to ensure connectivity
 	if err := client.Ping(ctx).Err(); err != nil {
 		client.Close() // Close client if ping fails
-		return nil, fmt.Errorf("failed to connect to Redis at %s: %w", redisEndpoint, err)
+		return nil, fmt.Errorf("failed to connect to Redis at %s: %w", strings.Join(cfg.Addrs, ","), err)
 	}
 
 	// Use slog for info message
-	slog.InfoContext(ctx, "Connected to Redis", "endpoint", redisEndpoint)
+	slog.InfoContext(ctx, "Connected to Redis", "mode", cfg.Mode, "endpoints", cfg.Addrs)
+	return NewRedisTimelineCacheWithClient(client, opts...), nil
+}
+
+// NewRedisTimelineCacheWithClient creates a Redis timeline cache using the given client.
+func NewRedisTimelineCacheWithClient(client RedisClient, opts ...Option) *RedisTimelineCache {
+	o := newOptions(opts)
 	return &RedisTimelineCache{
-		client: client,
-		ttl:    defaultTimelineTTL,
-	}, nil
+		client:      client,
+		keyPrefix:   timelineKeyPrefix,
+		hardTTL:     o.ttl,
+		softTTL:     o.softTTL,
+		ttlJitter:   o.ttlJitter,
+		callTimeout: o.callTimeout,
+		breaker:     newCircuitBreaker(o.breakerThreshold, o.breakerCooldown),
+	}
+}
+
+// ListTimelines returns a cache for list timelines, keyed by list ID under a
+// separate key prefix. It shares the client and circuit breaker of c.
+func (c *RedisTimelineCache) ListTimelines() *RedisTimelineCache {
+	lists := *c
+	lists.keyPrefix = listTimelineKeyPrefix
+	return &lists
+}
+
+// call runs a Redis operation bounded by the per-call timeout, recording its
+// outcome in the circuit breaker. It returns ErrCircuitOpen without calling
+// Redis while the breaker is open. A missing key is not a failure.
+func (c *RedisTimelineCache) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !c.breaker.allow() {
+		return ErrCircuitOpen
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, c.callTimeout)
+	defer cancel()
+
+	err := fn(callCtx)
+	if err != nil && err != redis.Nil {
+		c.breaker.failure()
+		return err
+	}
+	c.breaker.success()
+	return err
 }
 
 // generateKey creates the Redis key for a user's timeline.
 func (c *RedisTimelineCache) generateKey(userID string) string {
-	return timelineKeyPrefix + userID
+	return c.keyPrefix + userID
+}
+
+// jitteredTTL returns the hard TTL randomly spread by up to the jitter fraction
+// in either direction.
+func (c *RedisTimelineCache) jitteredTTL() time.Duration {
+	spread := time.Duration(float64(c.hardTTL) * c.ttlJitter)
+	if spread <= 0 {
+		return c.hardTTL
+	}
+	return c.hardTTL - spread + rand.N(2*spread+1)
+}
+
+// cachedTimeline is the value stored in Redis for a timeline.
+type cachedTimeline struct {
+	// StaleAt is the Unix time in milliseconds after which the timeline is stale,
+	// or zero when it stays fresh until it expires.
+	StaleAt int64           `json:"stale_at,omitempty"`
+	Tweets  []*entity.Tweet `json:"tweets"`
 }
 
 // GetTimeline retrieves a cached timeline for a user from Redis.
+// Stale timelines are returned like fresh ones until they expire.
 func (c *RedisTimelineCache) GetTimeline(ctx context.Context, userID string) ([]*entity.Tweet, bool, error) {
+	timeline, found, _, err := c.GetStaleTimeline(ctx, userID)
+	return timeline, found, err
+}
+
+// GetStaleTimeline retrieves a cached timeline for a user from Redis, reporting
+// whether it is past its soft TTL. Entries written without a soft TTL are never stale.
+func (c *RedisTimelineCache) GetStaleTimeline(ctx context.Context, userID string) ([]*entity.Tweet, bool, bool, error) {
 	key := c.generateKey(userID)
-	val, err := c.client.Get(ctx, key).Result()
+	var val string
+	err := c.call(ctx, func(ctx context.Context) error {
+		var err error
+		val, err = c.client.Get(ctx, key).Result()
+		return err
+	})
 
 	if err == redis.Nil {
 		slog.DebugContext(ctx, "Timeline cache miss", "userID", userID)
-		return nil, false, nil // Cache miss
+		return nil, false, false, nil // Cache miss
+	}
+	if err == ErrCircuitOpen {
+		slog.DebugContext(ctx, "Timeline cache bypassed, circuit breaker open", "userID", userID)
+		return nil, false, false, err
 	}
 	if err != nil {
 		// Log the error but return it so the caller can potentially fetch from DB
 		slog.ErrorContext(ctx, "Failed to get timeline from Redis", "userID", userID, "error", err)
-		return nil, false, fmt.Errorf("failed to get timeline for user %s from Redis: %w", userID, err)
+		return nil, false, false, fmt.Errorf("failed to get timeline for user %s from Redis: %w", userID, err)
 	}
 
 	// Deserialize the timeline from JSON
-	var timeline []*entity.Tweet
-	if err := json.Unmarshal([]byte(val), &timeline); err != nil {
+	var entry cachedTimeline
+	if err := json.Unmarshal([]byte(val), &entry); err != nil {
 		// Use slog for warning
 		slog.WarnContext(ctx, "Failed to unmarshal cached timeline, invalidating entry", "userID", userID, "error", err)
 		_ = c.InvalidateTimeline(ctx, userID)
-		return nil, false, fmt.Errorf("failed to unmarshal cached timeline for user %s: %w", userID, err)
+		return nil, false, false, fmt.Errorf("failed to unmarshal cached timeline for user %s: %w", userID, err)
 	}
 
-	slog.DebugContext(ctx, "Timeline cache hit", "userID", userID)
-	return timeline, true, nil // Cache hit
+	stale := entry.StaleAt != 0 && time.Now().UnixMilli() >= entry.StaleAt
+	slog.DebugContext(ctx, "Timeline cache hit", "userID", userID, "stale", stale)
+	return entry.Tweets, true, stale, nil // Cache hit
 }
 
-// SetTimeline caches a timeline for a user in Redis.
+// SetTimeline caches a timeline for a user in Redis until the hard TTL.
+// With a soft TTL, the timeline is marked stale once it passes; both TTLs are
+// jittered by the same factor.
 func (c *RedisTimelineCache) SetTimeline(ctx context.Context, userID string, timeline []*entity.Tweet) error {
 	key := c.generateKey(userID)
+	ttl := c.jitteredTTL()
+	entry := cachedTimeline{Tweets: timeline}
+	if c.softTTL > 0 {
+		softTTL := time.Duration(float64(c.softTTL) * float64(ttl) / float64(c.hardTTL))
+		entry.StaleAt = time.Now().Add(softTTL).UnixMilli()
+	}
 
 	// Serialize the timeline to JSON
-	val, err := json.Marshal(timeline)
+	val, err := json.Marshal(entry)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to marshal timeline for caching", "userID", userID, "error", err)
 		return fmt.Errorf("failed to marshal timeline for caching for user %s: %w", userID, err)
 	}
 
-	err = c.client.Set(ctx, key, val, c.ttl).Err()
+	err = c.call(ctx, func(ctx context.Context) error {
+		return c.client.Set(ctx, key, val, ttl).Err()
+	})
+	if err == ErrCircuitOpen {
+		return err
+	}
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to set timeline cache in Redis", "userID", userID, "error", err)
 		return fmt.Errorf("failed to set timeline cache for user %s in Redis: %w", userID, err)
 	}
-	slog.DebugContext(ctx, "Successfully set timeline cache", "userID", userID, "ttl", c.ttl)
+	slog.DebugContext(ctx, "Successfully set timeline cache", "userID", userID, "ttl", ttl)
 	return nil
 }
 
 // InvalidateTimeline removes a cached timeline for a user from Redis.
 func (c *RedisTimelineCache) InvalidateTimeline(ctx context.Context, userID string) error {
 	key := c.generateKey(userID)
-	err := c.client.Del(ctx, key).Err()
+	err := c.call(ctx, func(ctx context.Context) error {
+		return c.client.Del(ctx, key).Err()
+	})
+	if err == ErrCircuitOpen {
+		return err
+	}
 	if err != nil && err != redis.Nil { // Ignore error if key didn't exist
 		slog.ErrorContext(ctx, "Failed to invalidate timeline cache in Redis", "userID", userID, "error", err)
 		return fmt.Errorf("failed to invalidate timeline cache for user %s in Redis: %w", userID, err)
@@ -131,6 +262,38 @@ func (c *RedisTimelineCache) InvalidateTimeline(ctx context.Context, userID stri
 	return nil
 }
 
+// InvalidateTimelines removes the cached timelines of several users from Redis
+// with a single variadic DEL. An empty list does not call Redis.
+func (c *RedisTimelineCache) InvalidateTimelines(ctx context.Context, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	keys := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		keys[i] = c.generateKey(userID)
+	}
+	err := c.call(ctx, func(ctx context.Context) error {
+		return c.client.Del(ctx, keys...).Err()
+	})
+	if err == ErrCircuitOpen {
+		return err
+	}
+	if err != nil && err != redis.Nil {
+		slog.ErrorContext(ctx, "Failed to invalidate timeline caches in Redis", "count", len(userIDs), "error", err)
+		return fmt.Errorf("failed to invalidate %d timeline caches in Redis: %w", len(userIDs), err)
+	}
+	slog.DebugContext(ctx, "Invalidated timeline caches", "count", len(userIDs))
+	return nil
+}
+
+// Ping checks that Redis answers within the per-call timeout.
+// It bypasses the circuit breaker, so it reports whether Redis is back while requests still skip it.
+func (c *RedisTimelineCache) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.callTimeout)
+	defer cancel()
+	return c.client.Ping(ctx).Err()
+}
+
 // Close closes the Redis client connection.
 func (c *RedisTimelineCache) Close() error {
 	if c.client != nil {
@@ -0,0 +1,527 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/infrastructure/cache"
+	"github.com/develpudu/go-challenge/infrastructure/metrics"
+	"github.com/go-redis/redis/v8"
+)
+
+// Mock implementation of the RedisClient interface backed by a map
+type MockRedisClient struct {
+	mutex  sync.Mutex
+	values map[string]string
+	sets   map[string]map[string]bool
+	// Error returned by every call while set
+	Err error
+	// Delay applied to every call, honoring the context deadline
+	Delay time.Duration
+	// Number of calls that reached the client
+	Calls int
+	// Expiration of every successful Set call, in order
+	Expirations []time.Duration
+}
+
+// Creates a new mock Redis client
+func NewMockRedisClient() *MockRedisClient {
+	return &MockRedisClient{values: make(map[string]string), sets: make(map[string]map[string]bool)}
+}
+
+// Records a call and returns the error it should fail with, if any
+func (c *MockRedisClient) begin(ctx context.Context) error {
+	c.mutex.Lock()
+	c.Calls++
+	err, delay := c.Err, c.Delay
+	c.mutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Sets the error returned by every call
+func (c *MockRedisClient) SetErr(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.Err = err
+}
+
+func (c *MockRedisClient) Ping(ctx context.Context) *redis.StatusCmd {
+	if err := c.begin(ctx); err != nil {
+		return redis.NewStatusResult("", err)
+	}
+	return redis.NewStatusResult("PONG", nil)
+}
+
+func (c *MockRedisClient) Get(ctx context.Context, key string) *redis.StringCmd {
+	if err := c.begin(ctx); err != nil {
+		return redis.NewStringResult("", err)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	val, ok := c.values[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(val, nil)
+}
+
+func (c *MockRedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	if err := c.begin(ctx); err != nil {
+		return redis.NewStatusResult("", err)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.values[key] = string(value.([]byte))
+	c.Expirations = append(c.Expirations, expiration)
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (c *MockRedisClient) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	if err := c.begin(ctx); err != nil {
+		return redis.NewIntResult(0, err)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, key := range keys {
+		delete(c.values, key)
+	}
+	return redis.NewIntResult(int64(len(keys)), nil)
+}
+
+func (c *MockRedisClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
+	if err := c.begin(ctx); err != nil {
+		return redis.NewBoolResult(false, err)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.values[key]; ok {
+		return redis.NewBoolResult(false, nil)
+	}
+	c.values[key] = string(value.([]byte))
+	c.Expirations = append(c.Expirations, expiration)
+	return redis.NewBoolResult(true, nil)
+}
+
+func (c *MockRedisClient) Incr(ctx context.Context, key string) *redis.IntCmd {
+	return c.IncrBy(ctx, key, 1)
+}
+
+func (c *MockRedisClient) IncrBy(ctx context.Context, key string, value int64) *redis.IntCmd {
+	if err := c.begin(ctx); err != nil {
+		return redis.NewIntResult(0, err)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	count, _ := strconv.ParseInt(c.values[key], 10, 64)
+	count += value
+	c.values[key] = strconv.FormatInt(count, 10)
+	return redis.NewIntResult(count, nil)
+}
+
+func (c *MockRedisClient) GetDel(ctx context.Context, key string) *redis.StringCmd {
+	if err := c.begin(ctx); err != nil {
+		return redis.NewStringResult("", err)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	val, ok := c.values[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	delete(c.values, key)
+	return redis.NewStringResult(val, nil)
+}
+
+func (c *MockRedisClient) SAdd(ctx context.Context, key string, members ...interface{}) *redis.IntCmd {
+	if err := c.begin(ctx); err != nil {
+		return redis.NewIntResult(0, err)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.sets[key] == nil {
+		c.sets[key] = make(map[string]bool)
+	}
+	added := 0
+	for _, member := range members {
+		if !c.sets[key][member.(string)] {
+			c.sets[key][member.(string)] = true
+			added++
+		}
+	}
+	return redis.NewIntResult(int64(added), nil)
+}
+
+func (c *MockRedisClient) SPopN(ctx context.Context, key string, count int64) *redis.StringSliceCmd {
+	if err := c.begin(ctx); err != nil {
+		return redis.NewStringSliceResult(nil, err)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	popped := make([]string, 0, count)
+	for member := range c.sets[key] {
+		if int64(len(popped)) == count {
+			break
+		}
+		delete(c.sets[key], member)
+		popped = append(popped, member)
+	}
+	return redis.NewStringSliceResult(popped, nil)
+}
+
+// Runs the unlock script only: deletes the key in KEYS[1] if it holds the token in ARGV[1]
+func (c *MockRedisClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+	if err := c.begin(ctx); err != nil {
+		return redis.NewCmdResult(nil, err)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if val, ok := c.values[keys[0]]; ok && val == args[0] {
+		delete(c.values, keys[0])
+		return redis.NewCmdResult(int64(1), nil)
+	}
+	return redis.NewCmdResult(int64(0), nil)
+}
+
+func (c *MockRedisClient) Close() error {
+	return nil
+}
+
+func TestTimelineCacheRoundTrip(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	timelineCache := cache.NewRedisTimelineCacheWithClient(client)
+	ctx := context.Background()
+	tweet, _ := entity.NewTweet("tweet1", "user1", "Hello")
+
+	// Act
+	err := timelineCache.SetTimeline(ctx, "user1", []*entity.Tweet{tweet})
+	timeline, found, getErr := timelineCache.GetTimeline(ctx, "user1")
+
+	// Assert
+	if err != nil || getErr != nil {
+		t.Fatalf("Expected no errors, got %v and %v", err, getErr)
+	}
+	if !found || len(timeline) != 1 || timeline[0].ID != "tweet1" {
+		t.Errorf("Expected the cached timeline, got found=%v timeline=%v", found, timeline)
+	}
+}
+
+func TestSetTimelineJittersTTL(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	ttl := 10 * time.Minute
+	timelineCache := cache.NewRedisTimelineCacheWithClient(client, cache.WithTTL(ttl), cache.WithTTLJitter(0.2))
+	ctx := context.Background()
+
+	// Act
+	for i := 0; i < 500; i++ {
+		timelineCache.SetTimeline(ctx, "user1", []*entity.Tweet{})
+	}
+
+	// Assert: every TTL within ±20%, and not all identical
+	minTTL, maxTTL := 8*time.Minute, 12*time.Minute
+	distinct := make(map[time.Duration]bool)
+	for _, expiration := range client.Expirations {
+		if expiration < minTTL || expiration > maxTTL {
+			t.Fatalf("Expected TTL within [%v, %v], got %v", minTTL, maxTTL, expiration)
+		}
+		distinct[expiration] = true
+	}
+	if len(distinct) < 2 {
+		t.Error("Expected jittered TTLs to vary")
+	}
+}
+
+func TestSetTimelineWithoutJitterUsesFixedTTL(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	timelineCache := cache.NewRedisTimelineCacheWithClient(client, cache.WithTTL(time.Minute), cache.WithTTLJitter(0))
+
+	// Act
+	timelineCache.SetTimeline(context.Background(), "user1", []*entity.Tweet{})
+
+	// Assert
+	if len(client.Expirations) != 1 || client.Expirations[0] != time.Minute {
+		t.Errorf("Expected a fixed TTL of 1m, got %v", client.Expirations)
+	}
+}
+
+func TestGetStaleTimelineAfterSoftTTL(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	timelineCache := cache.NewRedisTimelineCacheWithClient(client,
+		cache.WithTTL(time.Minute), cache.WithSoftTTL(30*time.Millisecond), cache.WithTTLJitter(0))
+	ctx := context.Background()
+	tweet, _ := entity.NewTweet("tweet1", "user1", "Hello")
+	timelineCache.SetTimeline(ctx, "user1", []*entity.Tweet{tweet})
+
+	// Act
+	_, freshFound, freshStale, _ := timelineCache.GetStaleTimeline(ctx, "user1")
+	time.Sleep(50 * time.Millisecond)
+	timeline, staleFound, stale, err := timelineCache.GetStaleTimeline(ctx, "user1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !freshFound || freshStale {
+		t.Errorf("Expected a fresh timeline before the soft TTL, got found=%v stale=%v", freshFound, freshStale)
+	}
+	if !staleFound || !stale || len(timeline) != 1 {
+		t.Errorf("Expected the stale timeline to be served after the soft TTL, got found=%v stale=%v timeline=%v", staleFound, stale, timeline)
+	}
+	// Redis drops the entry after the hard TTL
+	if len(client.Expirations) != 1 || client.Expirations[0] != time.Minute {
+		t.Errorf("Expected the entry to expire after the hard TTL of 1m, got %v", client.Expirations)
+	}
+}
+
+func TestGetStaleTimelineOnceExpired(t *testing.T) {
+	// Arrange: an expired entry is gone from Redis
+	client := NewMockRedisClient()
+	timelineCache := cache.NewRedisTimelineCacheWithClient(client, cache.WithSoftTTL(time.Minute))
+
+	// Act
+	timeline, found, stale, err := timelineCache.GetStaleTimeline(context.Background(), "user1")
+
+	// Assert
+	if err != nil || found || stale || timeline != nil {
+		t.Errorf("Expected a plain miss, got timeline=%v found=%v stale=%v err=%v", timeline, found, stale, err)
+	}
+}
+
+func TestSoftTTLNotBelowHardTTLIsIgnored(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	timelineCache := cache.NewRedisTimelineCacheWithClient(client,
+		cache.WithTTL(20*time.Millisecond), cache.WithSoftTTL(time.Minute), cache.WithTTLJitter(0))
+	ctx := context.Background()
+	timelineCache.SetTimeline(ctx, "user1", []*entity.Tweet{})
+
+	// Act
+	time.Sleep(30 * time.Millisecond)
+	_, found, stale, _ := timelineCache.GetStaleTimeline(ctx, "user1")
+
+	// Assert: the mock never expires entries, so only the soft TTL could mark it stale
+	if !found || stale {
+		t.Errorf("Expected the timeline to never be stale, got found=%v stale=%v", found, stale)
+	}
+}
+
+func TestListTimelinesUseSeparateKeys(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	timelineCache := cache.NewRedisTimelineCacheWithClient(client)
+	listCache := timelineCache.ListTimelines()
+	ctx := context.Background()
+	tweet, _ := entity.NewTweet("tweet1", "user1", "Hello")
+
+	// Act: a list sharing its ID with a user must not see the user's timeline
+	timelineCache.SetTimeline(ctx, "id1", []*entity.Tweet{tweet})
+	_, listFound, _ := listCache.GetTimeline(ctx, "id1")
+	listCache.SetTimeline(ctx, "id1", []*entity.Tweet{})
+	listCache.InvalidateTimeline(ctx, "id1")
+	_, userFound, _ := timelineCache.GetTimeline(ctx, "id1")
+
+	// Assert
+	if listFound {
+		t.Error("Expected list timeline cache miss for a cached user timeline")
+	}
+	if !userFound {
+		t.Error("Expected invalidating a list timeline to keep the user timeline")
+	}
+}
+
+func TestInvalidateTimelinesDeletesAllKeysInOneCall(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	timelineCache := cache.NewRedisTimelineCacheWithClient(client)
+	ctx := context.Background()
+	userIDs := []string{"user1", "user2", "user3"}
+	for _, userID := range userIDs {
+		timelineCache.SetTimeline(ctx, userID, []*entity.Tweet{})
+	}
+	callsBefore := client.Calls
+
+	// Act
+	err := timelineCache.InvalidateTimelines(ctx, userIDs)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if calls := client.Calls - callsBefore; calls != 1 {
+		t.Errorf("Expected a single Redis call, got %d", calls)
+	}
+	for _, userID := range userIDs {
+		if _, found, _ := timelineCache.GetTimeline(ctx, userID); found {
+			t.Errorf("Expected the timeline of %s to be removed", userID)
+		}
+	}
+}
+
+func TestInvalidateTimelinesWithoutUsersIsNoOp(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	timelineCache := cache.NewRedisTimelineCacheWithClient(client)
+
+	// Act
+	err := timelineCache.InvalidateTimelines(context.Background(), nil)
+
+	// Assert
+	if err != nil || client.Calls != 0 {
+		t.Errorf("Expected no error and no Redis call, got %v and %d calls", err, client.Calls)
+	}
+}
+
+func TestTimelineCacheCallTimesOut(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	client.Delay = time.Second
+	timelineCache := cache.NewRedisTimelineCacheWithClient(client, cache.WithCallTimeout(20*time.Millisecond))
+
+	// Act
+	start := time.Now()
+	_, found, err := timelineCache.GetTimeline(context.Background(), "user1")
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline exceeded error, got %v", err)
+	}
+	if found {
+		t.Error("Expected no timeline to be found")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the call to be cut short by the timeout, took %v", elapsed)
+	}
+}
+
+func TestCircuitBreakerOpensAndCloses(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	client.SetErr(errors.New("connection refused"))
+	cooldown := 50 * time.Millisecond
+	timelineCache := cache.NewRedisTimelineCacheWithClient(client, cache.WithCircuitBreaker(3, cooldown))
+	ctx := context.Background()
+	state := metrics.NewGauge(cache.BreakerStateMetric)
+
+	// Act: consecutive failures open the breaker
+	for i := 0; i < 3; i++ {
+		if _, _, err := timelineCache.GetTimeline(ctx, "user1"); err == nil {
+			t.Fatalf("Expected call %d to fail", i+1)
+		}
+	}
+
+	// Assert: calls are no longer sent to Redis
+	if state.Value() != 1 {
+		t.Errorf("Expected breaker state gauge to be 1 (open), got %d", state.Value())
+	}
+	callsWhenOpened := client.Calls
+	if _, _, err := timelineCache.GetTimeline(ctx, "user1"); err != cache.ErrCircuitOpen {
+		t.Errorf("Expected ErrCircuitOpen while the breaker is open, got %v", err)
+	}
+	if err := timelineCache.InvalidateTimeline(ctx, "user1"); err != cache.ErrCircuitOpen {
+		t.Errorf("Expected ErrCircuitOpen while the breaker is open, got %v", err)
+	}
+	if client.Calls != callsWhenOpened {
+		t.Errorf("Expected Redis to be bypassed while the breaker is open, got %d extra calls", client.Calls-callsWhenOpened)
+	}
+
+	// Act: Redis recovers and the cooldown passes
+	client.SetErr(nil)
+	time.Sleep(cooldown + 10*time.Millisecond)
+	_, _, err := timelineCache.GetTimeline(ctx, "user1")
+
+	// Assert: the probe succeeds and the breaker closes
+	if err != nil {
+		t.Fatalf("Expected the probe call to succeed after the cooldown, got %v", err)
+	}
+	if state.Value() != 0 {
+		t.Errorf("Expected breaker state gauge to be 0 (closed), got %d", state.Value())
+	}
+	if err := timelineCache.InvalidateTimeline(ctx, "user1"); err != nil {
+		t.Errorf("Expected calls to reach Redis once the breaker closes, got %v", err)
+	}
+}
+
+func TestCircuitBreakerReopensOnFailedProbe(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	client.SetErr(errors.New("connection refused"))
+	cooldown := 50 * time.Millisecond
+	timelineCache := cache.NewRedisTimelineCacheWithClient(client, cache.WithCircuitBreaker(1, cooldown))
+	ctx := context.Background()
+	_, _, _ = timelineCache.GetTimeline(ctx, "user1")
+
+	// Act: the probe after the cooldown fails again
+	time.Sleep(cooldown + 10*time.Millisecond)
+	_, _, probeErr := timelineCache.GetTimeline(ctx, "user1")
+	_, _, err := timelineCache.GetTimeline(ctx, "user1")
+
+	// Assert
+	if probeErr == nil || probeErr == cache.ErrCircuitOpen {
+		t.Errorf("Expected the probe to reach Redis and fail, got %v", probeErr)
+	}
+	if err != cache.ErrCircuitOpen {
+		t.Errorf("Expected the breaker to reopen after a failed probe, got %v", err)
+	}
+}
+
+func TestCacheMissDoesNotOpenBreaker(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	timelineCache := cache.NewRedisTimelineCacheWithClient(client, cache.WithCircuitBreaker(1, time.Minute))
+	ctx := context.Background()
+
+	// Act
+	_, _, _ = timelineCache.GetTimeline(ctx, "missing")
+	_, found, err := timelineCache.GetTimeline(ctx, "missing")
+
+	// Assert
+	if err != nil || found {
+		t.Errorf("Expected a plain cache miss, got found=%v err=%v", found, err)
+	}
+}
+
+func TestMissingUserMarkerRoundTrip(t *testing.T) {
+	// Arrange
+	client := NewMockRedisClient()
+	timelineCache := cache.NewRedisTimelineCacheWithClient(client)
+	userCache := timelineCache.Users(time.Second)
+	ctx := context.Background()
+
+	// Act
+	before, _ := userCache.IsUserMissing(ctx, "ghost")
+	markErr := userCache.MarkUserMissing(ctx, "ghost")
+	marked, _ := userCache.IsUserMissing(ctx, "ghost")
+	_, timelineFound, _ := timelineCache.GetTimeline(ctx, "ghost")
+	clearErr := userCache.ClearUserMissing(ctx, "ghost")
+	after, _ := userCache.IsUserMissing(ctx, "ghost")
+
+	// Assert
+	if markErr != nil || clearErr != nil {
+		t.Fatalf("Expected no errors, got %v and %v", markErr, clearErr)
+	}
+	if before || !marked || after {
+		t.Errorf("Expected missing marker only between mark and clear, got %v %v %v", before, marked, after)
+	}
+	if timelineFound {
+		t.Error("Expected the missing marker not to be read as a timeline")
+	}
+}
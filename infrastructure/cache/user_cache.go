@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// Default TTL for markers of users that were not found
+	defaultMissingUserTTL = 30 * time.Second
+	// Key prefix for missing user markers in Redis
+	missingUserKeyPrefix = "user_missing:"
+)
+
+// UserCache defines the interface for caching user lookups.
+type UserCache interface {
+	// IsUserMissing reports whether a recent lookup of the user found nothing.
+	IsUserMissing(ctx context.Context, userID string) (bool, error)
+
+	// MarkUserMissing records that the user was not found, for a short TTL.
+	MarkUserMissing(ctx context.Context, userID string) error
+
+	// ClearUserMissing removes the missing marker of a user, e.g. once it is created.
+	ClearUserMissing(ctx context.Context, userID string) error
+}
+
+// RedisUserCache implements UserCache using Redis. It records short-lived
+// "not found" markers so repeated lookups of a missing user skip the database.
+type RedisUserCache struct {
+	cache *RedisTimelineCache
+	ttl   time.Duration
+}
+
+// Users returns a cache of missing user markers kept for ttl, under a separate
+// key prefix. It shares the client and circuit breaker of c.
+// A non-positive ttl keeps the default.
+func (c *RedisTimelineCache) Users(ttl time.Duration) *RedisUserCache {
+	users := *c
+	users.keyPrefix = missingUserKeyPrefix
+	if ttl <= 0 {
+		ttl = defaultMissingUserTTL
+	}
+	return &RedisUserCache{cache: &users, ttl: ttl}
+}
+
+// IsUserMissing reports whether a missing marker is cached for the user.
+func (c *RedisUserCache) IsUserMissing(ctx context.Context, userID string) (bool, error) {
+	key := c.cache.generateKey(userID)
+	err := c.cache.call(ctx, func(ctx context.Context) error {
+		return c.cache.client.Get(ctx, key).Err()
+	})
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err == ErrCircuitOpen {
+		return false, err
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get missing user marker from Redis", "userID", userID, "error", err)
+		return false, fmt.Errorf("failed to get missing user marker for user %s from Redis: %w", userID, err)
+	}
+	slog.DebugContext(ctx, "Missing user cache hit", "userID", userID)
+	return true, nil
+}
+
+// MarkUserMissing caches a missing marker for the user.
+func (c *RedisUserCache) MarkUserMissing(ctx context.Context, userID string) error {
+	key := c.cache.generateKey(userID)
+	err := c.cache.call(ctx, func(ctx context.Context) error {
+		return c.cache.client.Set(ctx, key, []byte("1"), c.ttl).Err()
+	})
+	if err == ErrCircuitOpen {
+		return err
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to set missing user marker in Redis", "userID", userID, "error", err)
+		return fmt.Errorf("failed to set missing user marker for user %s in Redis: %w", userID, err)
+	}
+	return nil
+}
+
+// ClearUserMissing removes the missing marker of the user.
+func (c *RedisUserCache) ClearUserMissing(ctx context.Context, userID string) error {
+	key := c.cache.generateKey(userID)
+	err := c.cache.call(ctx, func(ctx context.Context) error {
+		return c.cache.client.Del(ctx, key).Err()
+	})
+	if err == ErrCircuitOpen {
+		return err
+	}
+	if err != nil && err != redis.Nil {
+		slog.ErrorContext(ctx, "Failed to clear missing user marker in Redis", "userID", userID, "error", err)
+		return fmt.Errorf("failed to clear missing user marker for user %s in Redis: %w", userID, err)
+	}
+	return nil
+}
+
+// Compile-time check to ensure RedisUserCache implements UserCache
+var _ UserCache = (*RedisUserCache)(nil)
@@ -0,0 +1,85 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/develpudu/go-challenge/application/usecase"
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Event type of the messages published when a tweet is created
+const TweetCreatedEvent = "TweetCreated"
+
+// SNSAPI is the subset of the SNS client used by the publisher.
+type SNSAPI interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+// SNSEventPublisher implements usecase.EventPublisher by publishing to an SNS topic.
+type SNSEventPublisher struct {
+	client   SNSAPI
+	topicARN string
+}
+
+// TweetCreatedMessage is the JSON body of a tweet created event.
+type TweetCreatedMessage struct {
+	Type       string    `json:"type"`
+	TweetID    string    `json:"tweetId"`
+	UserID     string    `json:"userId"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"createdAt"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// NewSNSEventPublisher creates a new SNS event publisher for the given topic.
+func NewSNSEventPublisher(cfg aws.Config, topicARN string) *SNSEventPublisher {
+	return NewSNSEventPublisherWithClient(sns.NewFromConfig(cfg), topicARN)
+}
+
+// NewSNSEventPublisherWithClient creates a new SNS event publisher using the given client.
+func NewSNSEventPublisherWithClient(client SNSAPI, topicARN string) *SNSEventPublisher {
+	return &SNSEventPublisher{
+		client:   client,
+		topicARN: topicARN,
+	}
+}
+
+// TweetCreated publishes a tweet created event to the topic.
+// The event type is also set as a message attribute so subscribers can filter on it.
+func (p *SNSEventPublisher) TweetCreated(ctx context.Context, tweet *entity.Tweet) error {
+	body, err := json.Marshal(TweetCreatedMessage{
+		Type:       TweetCreatedEvent,
+		TweetID:    tweet.ID,
+		UserID:     tweet.UserID,
+		Content:    tweet.Content,
+		CreatedAt:  tweet.CreatedAt,
+		OccurredAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal tweet created event for tweet %s: %w", tweet.ID, err)
+	}
+
+	_, err = p.client.Publish(ctx, &sns.PublishInput{
+		TopicArn: aws.String(p.topicARN),
+		Message:  aws.String(string(body)),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			"eventType": {
+				DataType:    aws.String("String"),
+				StringValue: aws.String(TweetCreatedEvent),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish tweet created event for tweet %s: %w", tweet.ID, err)
+	}
+	return nil
+}
+
+// Compile-time check to ensure SNSEventPublisher implements EventPublisher
+var _ usecase.EventPublisher = (*SNSEventPublisher)(nil)
@@ -0,0 +1,77 @@
+package event_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/infrastructure/event"
+)
+
+// Mock implementation of the SNSAPI interface
+type MockSNSClient struct {
+	inputs []*sns.PublishInput
+	err    error
+}
+
+func (c *MockSNSClient) Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	c.inputs = append(c.inputs, params)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &sns.PublishOutput{MessageId: aws.String("message-1")}, nil
+}
+
+func TestSNSPublishesTweetCreated(t *testing.T) {
+	// Arrange
+	client := &MockSNSClient{}
+	publisher := event.NewSNSEventPublisherWithClient(client, "arn:aws:sns:us-east-1:123456789012:tweets")
+	tweet, _ := entity.NewTweet("tweet1", "user1", "Hello")
+
+	// Act
+	err := publisher.TweetCreated(context.Background(), tweet)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(client.inputs) != 1 {
+		t.Fatalf("Expected 1 publish call, got %d", len(client.inputs))
+	}
+
+	input := client.inputs[0]
+	if aws.ToString(input.TopicArn) != "arn:aws:sns:us-east-1:123456789012:tweets" {
+		t.Errorf("Expected the configured topic, got %s", aws.ToString(input.TopicArn))
+	}
+	if aws.ToString(input.MessageAttributes["eventType"].StringValue) != event.TweetCreatedEvent {
+		t.Error("Expected the eventType message attribute to be set")
+	}
+
+	var message event.TweetCreatedMessage
+	if err := json.Unmarshal([]byte(aws.ToString(input.Message)), &message); err != nil {
+		t.Fatalf("Expected a JSON message, got %v", err)
+	}
+	if message.TweetID != "tweet1" || message.UserID != "user1" || message.Content != "Hello" {
+		t.Errorf("Expected the message to describe the tweet, got %+v", message)
+	}
+}
+
+func TestSNSPublishFailure(t *testing.T) {
+	// Arrange
+	publishErr := errors.New("sns unavailable")
+	client := &MockSNSClient{err: publishErr}
+	publisher := event.NewSNSEventPublisherWithClient(client, "topic")
+	tweet, _ := entity.NewTweet("tweet1", "user1", "Hello")
+
+	// Act
+	err := publisher.TweetCreated(context.Background(), tweet)
+
+	// Assert
+	if !errors.Is(err, publishErr) {
+		t.Errorf("Expected the publish error to be returned, got %v", err)
+	}
+}
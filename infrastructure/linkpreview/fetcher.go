@@ -0,0 +1,209 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	"golang.org/x/net/html"
+)
+
+// DefaultTimeout bounds fetching a page with the client NewFetcher builds when given none.
+const DefaultTimeout = 3 * time.Second
+
+const (
+	// maxPageSize is the number of bytes of a page read looking for its metadata.
+	maxPageSize = 512 << 10
+	// maxRedirects is the number of redirects followed before giving up on a page.
+	maxRedirects = 5
+	// maxTitleLength and maxDescriptionLength bound the characters kept of each field.
+	maxTitleLength       = 200
+	maxDescriptionLength = 300
+	// userAgent identifies the fetcher to the sites it visits.
+	userAgent = "go-challenge-linkpreview/1.0"
+)
+
+// ErrNoMetadata is returned when a page has neither Open Graph tags nor a title.
+var ErrNoMetadata = errors.New("page has no preview metadata")
+
+// Fetcher implements usecase.LinkPreviewFetcher by reading the Open Graph tags of web pages.
+type Fetcher struct {
+	client *http.Client
+}
+
+// NewFetcher creates a fetcher that requests pages with client.
+// A nil client uses PublicClient(DefaultTimeout).
+func NewFetcher(client *http.Client) *Fetcher {
+	if client == nil {
+		client = PublicClient(DefaultTimeout)
+	}
+	return &Fetcher{client: client}
+}
+
+// PublicClient returns an HTTP client that gives up after timeout and refuses to connect
+// to loopback, private and link-local addresses, even after a redirect, so links in
+// tweets cannot make the server reach internal services.
+func PublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: refuseNonPublicAddress}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		},
+	}
+}
+
+// refuseNonPublicAddress fails dials to addresses that are not public unicast addresses.
+func refuseNonPublicAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return fmt.Errorf("refusing to connect to non-public address %s", ip)
+	}
+	return nil
+}
+
+// Fetch returns the preview of the page at pageURL: its Open Graph title, description
+// and image, falling back to the page's title and meta description.
+// It returns ErrNoMetadata for pages with nothing to show.
+func (f *Fetcher) Fetch(ctx context.Context, pageURL string) (*entity.LinkPreview, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: unexpected status %d", pageURL, resp.StatusCode)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("fetching %s: unexpected content type %q", pageURL, mediaType)
+	}
+
+	meta := parseMetadata(io.LimitReader(resp.Body, maxPageSize))
+	preview := &entity.LinkPreview{
+		URL:         pageURL,
+		Title:       entity.TruncateContent(firstNonEmpty(meta.properties["og:title"], meta.title), maxTitleLength),
+		Description: entity.TruncateContent(firstNonEmpty(meta.properties["og:description"], meta.properties["description"]), maxDescriptionLength),
+		// Relative images are resolved against the page, after any redirect
+		ImageURL: resolveImageURL(resp.Request.URL, meta.properties["og:image"]),
+	}
+	if preview.Title == "" && preview.Description == "" && preview.ImageURL == "" {
+		return nil, ErrNoMetadata
+	}
+	return preview, nil
+}
+
+// metadata holds the parts of a page's head used in previews.
+type metadata struct {
+	// properties maps the lowercased property or name of each meta tag to its content.
+	properties map[string]string
+	title      string
+}
+
+// parseMetadata reads the meta tags and title of the page's head, stopping at its end.
+// The first tag wins when a property is repeated, and whitespace in values is collapsed.
+func parseMetadata(r io.Reader) metadata {
+	meta := metadata{properties: make(map[string]string)}
+	tokenizer := html.NewTokenizer(r)
+	inTitle := false
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return meta
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			switch string(name) {
+			case "meta":
+				attrs := make(map[string]string)
+				for hasAttr {
+					var key, value []byte
+					key, value, hasAttr = tokenizer.TagAttr()
+					attrs[string(key)] = string(value)
+				}
+				property := strings.ToLower(firstNonEmpty(attrs["property"], attrs["name"]))
+				if _, seen := meta.properties[property]; property != "" && !seen {
+					meta.properties[property] = collapseSpaces(attrs["content"])
+				}
+			case "title":
+				inTitle = meta.title == ""
+			case "body":
+				return meta
+			}
+		case html.TextToken:
+			if inTitle {
+				meta.title = collapseSpaces(string(tokenizer.Text()))
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				return meta
+			}
+		}
+	}
+}
+
+// resolveImageURL returns the absolute http or https URL of image relative to page,
+// or an empty string when image is empty or has another scheme.
+func resolveImageURL(page *url.URL, image string) string {
+	if image == "" {
+		return ""
+	}
+	ref, err := url.Parse(image)
+	if err != nil {
+		return ""
+	}
+	resolved := page.ResolveReference(ref)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return ""
+	}
+	return resolved.String()
+}
+
+// collapseSpaces trims s and replaces each run of whitespace inside it with a single space.
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// firstNonEmpty returns the first of values that is not empty.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
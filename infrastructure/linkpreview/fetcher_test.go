@@ -0,0 +1,110 @@
+package linkpreview_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/develpudu/go-challenge/infrastructure/linkpreview"
+)
+
+// Serves pages with and without Open Graph tags, and pages that cannot be previewed
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<!DOCTYPE html><html><head>
+			<title>Fallback title</title>
+			<meta property="og:title" content="  Go   1.23 released ">
+			<meta property="og:description" content="What's new">
+			<meta property="og:image" content="/images/cover.png">
+			<meta property="og:title" content="Ignored duplicate">
+			</head><body><meta property="og:description" content="Not in the head"></body></html>`))
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Just a title</title><meta name="description" content="Meta description"></head></html>`))
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body>Nothing to preview</body></html>`))
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/data.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchReadsOpenGraphTags(t *testing.T) {
+	// Arrange
+	server := newTestServer(t)
+	fetcher := linkpreview.NewFetcher(server.Client())
+
+	// Act
+	preview, err := fetcher.Fetch(context.Background(), server.URL+"/article")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if preview.URL != server.URL+"/article" || preview.Title != "Go 1.23 released" || preview.Description != "What's new" {
+		t.Errorf("Expected the first Open Graph title and description, got %+v", preview)
+	}
+	if preview.ImageURL != server.URL+"/images/cover.png" {
+		t.Errorf("Expected the image to be resolved against the page, got %q", preview.ImageURL)
+	}
+}
+
+func TestFetchFallsBackToTitleAndDescription(t *testing.T) {
+	// Arrange
+	server := newTestServer(t)
+	fetcher := linkpreview.NewFetcher(server.Client())
+
+	// Act
+	preview, err := fetcher.Fetch(context.Background(), server.URL+"/plain")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if preview.Title != "Just a title" || preview.Description != "Meta description" || preview.ImageURL != "" {
+		t.Errorf("Expected the page title and meta description, got %+v", preview)
+	}
+}
+
+func TestFetchFailures(t *testing.T) {
+	// Arrange
+	server := newTestServer(t)
+	fetcher := linkpreview.NewFetcher(server.Client())
+
+	// Act & Assert
+	for _, path := range []string{"/empty", "/broken", "/data.json", "/missing"} {
+		if preview, err := fetcher.Fetch(context.Background(), server.URL+path); err == nil {
+			t.Errorf("%s: expected an error, got %+v", path, preview)
+		}
+	}
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/empty"); err != linkpreview.ErrNoMetadata {
+		t.Errorf("Expected ErrNoMetadata for a page without metadata, got %v", err)
+	}
+}
+
+func TestPublicClientRefusesLoopback(t *testing.T) {
+	// Arrange: the test server listens on a loopback address
+	server := newTestServer(t)
+	fetcher := linkpreview.NewFetcher(linkpreview.PublicClient(linkpreview.DefaultTimeout))
+
+	// Act
+	_, err := fetcher.Fetch(context.Background(), server.URL+"/article")
+
+	// Assert
+	if err == nil {
+		t.Error("Expected fetching a loopback address to be refused")
+	}
+}
@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Log output formats accepted by LOG_FORMAT
+const (
+	// One JSON object per line, for log collectors such as CloudWatch
+	FormatJSON = "json"
+	// key=value pairs, easier to read in a terminal
+	FormatText = "text"
+)
+
+// NewHandler returns a slog handler writing to w in format, FormatJSON or FormatText.
+func NewHandler(w io.Writer, format string, opts *slog.HandlerOptions) (slog.Handler, error) {
+	switch format {
+	case FormatJSON:
+		return slog.NewJSONHandler(w, opts), nil
+	case FormatText:
+		return slog.NewTextHandler(w, opts), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+}
+
+// NewHandlerFromEnv returns a slog handler writing to w in the format set by LOG_FORMAT,
+// or in defaultFormat when it is not set. An unknown LOG_FORMAT falls back to defaultFormat
+// and is reported in the returned error, so the caller can log it with the handler it got.
+func NewHandlerFromEnv(w io.Writer, defaultFormat string, opts *slog.HandlerOptions) (slog.Handler, error) {
+	format := os.Getenv("LOG_FORMAT")
+	if format == "" {
+		return NewHandler(w, defaultFormat, opts)
+	}
+
+	handler, err := NewHandler(w, format, opts)
+	if err != nil {
+		fallback, fallbackErr := NewHandler(w, defaultFormat, opts)
+		if fallbackErr != nil {
+			return nil, fallbackErr
+		}
+		return fallback, err
+	}
+	return handler, nil
+}
@@ -0,0 +1,48 @@
+package logging_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/develpudu/go-challenge/infrastructure/logging"
+)
+
+func TestNewHandlerFromEnvSelectsFormat(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           string
+		defaultFormat string
+		wantJSON      bool
+		wantErr       bool
+	}{
+		{name: "json", env: "json", defaultFormat: logging.FormatText, wantJSON: true},
+		{name: "text", env: "text", defaultFormat: logging.FormatJSON, wantJSON: false},
+		{name: "unset uses JSON default", env: "", defaultFormat: logging.FormatJSON, wantJSON: true},
+		{name: "unset uses text default", env: "", defaultFormat: logging.FormatText, wantJSON: false},
+		{name: "unknown falls back", env: "xml", defaultFormat: logging.FormatJSON, wantJSON: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			t.Setenv("LOG_FORMAT", tt.env)
+
+			// Act
+			handler, err := logging.NewHandlerFromEnv(io.Discard, tt.defaultFormat, &slog.HandlerOptions{Level: slog.LevelDebug})
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			_, isJSON := handler.(*slog.JSONHandler)
+			_, isText := handler.(*slog.TextHandler)
+			if isJSON != tt.wantJSON || isText == tt.wantJSON {
+				t.Errorf("Expected a JSON handler %v, got %T", tt.wantJSON, handler)
+			}
+			if !handler.Enabled(context.Background(), slog.LevelDebug) {
+				t.Error("Expected the handler to keep the given level")
+			}
+		})
+	}
+}
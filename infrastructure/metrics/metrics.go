@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Gauge holds a single numeric value that can go up and down.
+type Gauge struct {
+	value atomic.Int64
+}
+
+// Set stores the current value of the gauge.
+func (g *Gauge) Set(value int64) {
+	g.value.Store(value)
+}
+
+// Value returns the current value of the gauge.
+func (g *Gauge) Value() int64 {
+	return g.value.Load()
+}
+
+var (
+	registryMutex sync.Mutex
+	registry      = make(map[string]*Gauge)
+)
+
+// NewGauge returns the process-wide gauge registered under name, creating it if needed.
+func NewGauge(name string) *Gauge {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if gauge, exists := registry[name]; exists {
+		return gauge
+	}
+	gauge := &Gauge{}
+	registry[name] = gauge
+	return gauge
+}
+
+// Snapshot returns the current value of every registered gauge.
+func Snapshot() map[string]int64 {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	values := make(map[string]int64, len(registry))
+	for name, gauge := range registry {
+		values[name] = gauge.Value()
+	}
+	return values
+}
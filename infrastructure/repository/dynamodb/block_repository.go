@@ -0,0 +1,89 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDBBlockRepository implements the BlockRepository interface using AWS DynamoDB.
+// The table is keyed by BlockerID (hash) and BlockedID (range), so checking a block is a single GetItem.
+type DynamoDBBlockRepository struct {
+	client    DynamoDBAPI
+	tableName string
+	opts      options
+}
+
+// NewDynamoDBBlockRepository creates a new DynamoDB block repository.
+func NewDynamoDBBlockRepository(cfg aws.Config, tableName string, opts ...Option) *DynamoDBBlockRepository {
+	return NewDynamoDBBlockRepositoryWithClient(NewClient(cfg), tableName, opts...)
+}
+
+// NewDynamoDBBlockRepositoryWithClient creates a new DynamoDB block repository using the given client.
+func NewDynamoDBBlockRepositoryWithClient(client DynamoDBAPI, tableName string, opts ...Option) *DynamoDBBlockRepository {
+	return &DynamoDBBlockRepository{
+		client:    client,
+		tableName: tableName,
+		opts:      newOptions(opts),
+	}
+}
+
+// blockKey returns the primary key of the block of blockedID by blockerID.
+func blockKey(blockerID, blockedID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"BlockerID": &types.AttributeValueMemberS{Value: blockerID},
+		"BlockedID": &types.AttributeValueMemberS{Value: blockedID},
+	}
+}
+
+// Block records that a user blocks another. Putting the same key again leaves a single block.
+func (r *DynamoDBBlockRepository) Block(blockerID, blockedID string) error {
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      blockKey(blockerID, blockedID),
+	}
+	err := r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.PutItem(ctx, input)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save block of %s by %s: %w", blockedID, blockerID, err)
+	}
+	return nil
+}
+
+// Unblock removes a block. Removing a missing block is not an error.
+func (r *DynamoDBBlockRepository) Unblock(blockerID, blockedID string) error {
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       blockKey(blockerID, blockedID),
+	}
+	err := r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.DeleteItem(ctx, input)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete block of %s by %s: %w", blockedID, blockerID, err)
+	}
+	return nil
+}
+
+// IsBlocked checks if a user blocks another.
+func (r *DynamoDBBlockRepository) IsBlocked(blockerID, blockedID string) (bool, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       blockKey(blockerID, blockedID),
+	}
+	var result *dynamodb.GetItemOutput
+	err := r.opts.call(context.Background(), func(ctx context.Context) (err error) {
+		result, err = r.client.GetItem(ctx, input)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get block of %s by %s: %w", blockedID, blockerID, err)
+	}
+	return result.Item != nil, nil
+}
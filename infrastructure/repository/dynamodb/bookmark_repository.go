@@ -0,0 +1,148 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+)
+
+// DynamoDBBookmarkRepository implements the BookmarkRepository interface using AWS DynamoDB.
+// The table is keyed by UserID (hash) and TweetID (range), so a user's bookmarks are read with a single Query.
+type DynamoDBBookmarkRepository struct {
+	client    DynamoDBAPI
+	tableName string
+	opts      options
+}
+
+// dynamoDBBookmark is a helper struct for marshalling/unmarshalling Bookmark data.
+type dynamoDBBookmark struct {
+	UserID    string `dynamodbav:"UserID"`
+	TweetID   string `dynamodbav:"TweetID"`
+	CreatedAt string `dynamodbav:"CreatedAt"` // Store as ISO 8601 string
+}
+
+// NewDynamoDBBookmarkRepository creates a new DynamoDB bookmark repository.
+func NewDynamoDBBookmarkRepository(cfg aws.Config, tableName string, opts ...Option) *DynamoDBBookmarkRepository {
+	return NewDynamoDBBookmarkRepositoryWithClient(NewClient(cfg), tableName, opts...)
+}
+
+// NewDynamoDBBookmarkRepositoryWithClient creates a new DynamoDB bookmark repository using the given client.
+func NewDynamoDBBookmarkRepositoryWithClient(client DynamoDBAPI, tableName string, opts ...Option) *DynamoDBBookmarkRepository {
+	return &DynamoDBBookmarkRepository{
+		client:    client,
+		tableName: tableName,
+		opts:      newOptions(opts),
+	}
+}
+
+// Save stores a bookmark. An existing bookmark of the same tweet is kept unchanged.
+func (r *DynamoDBBookmarkRepository) Save(bookmark *entity.Bookmark) error {
+	av, err := attributevalue.MarshalMap(dynamoDBBookmark{
+		UserID:    bookmark.UserID,
+		TweetID:   bookmark.TweetID,
+		CreatedAt: bookmark.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal bookmark: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_not_exists(TweetID)"),
+	}
+	err = r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.PutItem(ctx, input)
+		return err
+	})
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil // Already bookmarked
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save bookmark of tweet %s for user %s: %w", bookmark.TweetID, bookmark.UserID, err)
+	}
+	return nil
+}
+
+// Delete removes a user's bookmark of a tweet. Deleting a missing bookmark is not an error.
+func (r *DynamoDBBookmarkRepository) Delete(userID, tweetID string) error {
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"UserID":  &types.AttributeValueMemberS{Value: userID},
+			"TweetID": &types.AttributeValueMemberS{Value: tweetID},
+		},
+	}
+	err := r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.DeleteItem(ctx, input)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete bookmark of tweet %s for user %s: %w", tweetID, userID, err)
+	}
+	return nil
+}
+
+// FindByUserID retrieves all bookmarks of a user, newest first.
+func (r *DynamoDBBookmarkRepository) FindByUserID(userID string) ([]*entity.Bookmark, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("UserID = :userID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":userID": &types.AttributeValueMemberS{Value: userID},
+		},
+	}
+
+	bookmarks := make([]*entity.Bookmark, 0)
+	for {
+		var result *dynamodb.QueryOutput
+		err := r.opts.call(context.Background(), func(ctx context.Context) error {
+			var err error
+			result, err = r.client.Query(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query bookmarks for user %s: %w", userID, err)
+		}
+
+		var items []dynamoDBBookmark
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal bookmarks: %w", err)
+		}
+		for _, item := range items {
+			createdAt, err := time.Parse(time.RFC3339Nano, item.CreatedAt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse CreatedAt timestamp '%s': %w", item.CreatedAt, err)
+			}
+			bookmarks = append(bookmarks, entity.NewBookmark(item.UserID, item.TweetID, createdAt))
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+
+	// The range key orders by tweet, so sort by bookmark time
+	sort.Slice(bookmarks, func(i, j int) bool {
+		if !bookmarks[i].CreatedAt.Equal(bookmarks[j].CreatedAt) {
+			return bookmarks[i].CreatedAt.After(bookmarks[j].CreatedAt)
+		}
+		return bookmarks[i].TweetID > bookmarks[j].TweetID
+	})
+	return bookmarks, nil
+}
+
+// Compile-time check to ensure DynamoDBBookmarkRepository implements BookmarkRepository
+var _ repository.BookmarkRepository = (*DynamoDBBookmarkRepository)(nil)
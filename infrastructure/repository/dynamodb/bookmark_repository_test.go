@@ -0,0 +1,83 @@
+package dynamodb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	dynamodbRepo "github.com/develpudu/go-challenge/infrastructure/repository/dynamodb"
+)
+
+func TestBookmarkSaveIsIdempotent(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBBookmarkRepositoryWithClient(client, "bookmarks")
+	first := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Act
+	err1 := repo.Save(entity.NewBookmark("user1", "tweet1", first))
+	err2 := repo.Save(entity.NewBookmark("user1", "tweet1", first.Add(time.Hour)))
+
+	// Assert
+	if err1 != nil || err2 != nil {
+		t.Fatalf("Expected no errors, got %v and %v", err1, err2)
+	}
+
+	bookmarks, err := repo.FindByUserID("user1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(bookmarks) != 1 {
+		t.Fatalf("Expected 1 bookmark, got %d", len(bookmarks))
+	}
+	if !bookmarks[0].CreatedAt.Equal(first) {
+		t.Errorf("Expected the original bookmark time to be kept, got %v", bookmarks[0].CreatedAt)
+	}
+}
+
+func TestBookmarkFindByUserIDNewestFirst(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBBookmarkRepositoryWithClient(client, "bookmarks")
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo.Save(entity.NewBookmark("user1", "tweetA", base.Add(2*time.Minute)))
+	repo.Save(entity.NewBookmark("user1", "tweetB", base))
+	repo.Save(entity.NewBookmark("user1", "tweetC", base.Add(time.Minute)))
+	repo.Save(entity.NewBookmark("user2", "tweetD", base))
+
+	// Act
+	bookmarks, err := repo.FindByUserID("user1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []string{"tweetA", "tweetC", "tweetB"}
+	if len(bookmarks) != len(expected) {
+		t.Fatalf("Expected %d bookmarks, got %d", len(expected), len(bookmarks))
+	}
+	for i, bookmark := range bookmarks {
+		if bookmark.TweetID != expected[i] {
+			t.Errorf("Expected bookmark %d to be %s, got %s", i, expected[i], bookmark.TweetID)
+		}
+	}
+}
+
+func TestBookmarkDelete(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBBookmarkRepositoryWithClient(client, "bookmarks")
+	repo.Save(entity.NewBookmark("user1", "tweet1", time.Now()))
+
+	// Act
+	err1 := repo.Delete("user1", "tweet1")
+	err2 := repo.Delete("user1", "tweet1")
+
+	// Assert
+	if err1 != nil || err2 != nil {
+		t.Fatalf("Expected deletes to succeed, got %v and %v", err1, err2)
+	}
+	if bookmarks, _ := repo.FindByUserID("user1"); len(bookmarks) != 0 {
+		t.Errorf("Expected no bookmarks, got %d", len(bookmarks))
+	}
+}
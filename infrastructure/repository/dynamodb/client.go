@@ -0,0 +1,23 @@
+package dynamodb
+
+import (
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// EndpointEnv names the environment variable that overrides the DynamoDB endpoint,
+// e.g. http://localhost:8000 for DynamoDB Local.
+const EndpointEnv = "DYNAMODB_ENDPOINT"
+
+// NewClient creates a DynamoDB client from cfg. When DYNAMODB_ENDPOINT is set,
+// requests go to that endpoint instead of the regional AWS one.
+func NewClient(cfg aws.Config) *dynamodb.Client {
+	endpoint := os.Getenv(EndpointEnv)
+	return dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// countItems returns the number of items in a table. It scans with Select=COUNT,
+// so DynamoDB returns only counts, page by page, instead of the items themselves.
+func countItems(ctx context.Context, client DynamoDBAPI, opts options, tableName string) (int, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(tableName),
+		Select:    types.SelectCount,
+	}
+
+	count := 0
+	for {
+		var result *dynamodb.ScanOutput
+		err := opts.call(ctx, func(ctx context.Context) error {
+			var err error
+			result, err = client.Scan(ctx, input)
+			return err
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to count items in table %s: %w", tableName, err)
+		}
+		count += int(result.Count)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return count, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// countQuery returns the number of items matching a query. It sets Select=COUNT,
+// so DynamoDB returns only counts, page by page, instead of the items themselves.
+func countQuery(ctx context.Context, client DynamoDBAPI, opts options, input *dynamodb.QueryInput) (int, error) {
+	input.Select = types.SelectCount
+
+	count := 0
+	for {
+		var result *dynamodb.QueryOutput
+		err := opts.call(ctx, func(ctx context.Context) error {
+			var err error
+			result, err = client.Query(ctx, input)
+			return err
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to count items in table %s: %w", aws.ToString(input.TableName), err)
+		}
+		count += int(result.Count)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return count, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
@@ -0,0 +1,88 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// BackfillFollows writes to the follows table the edge of every follow stored in the
+// users' Following sets, for follows recorded before the table existed. Follower lists,
+// follower counts and timeline cache invalidation read only the follows table, so they
+// miss those follows until the backfill has run.
+// Edges already in the table are kept, so the backfill can be run again safely.
+// It returns the number of edges written.
+func (r *DynamoDBUserRepository) BackfillFollows(ctx context.Context) (int, error) {
+	input := &dynamodb.ScanInput{
+		TableName:            aws.String(r.tableName),
+		ProjectionExpression: aws.String("ID, Following"),
+	}
+	paginator := dynamodb.NewScanPaginator(r.client, input)
+
+	// Edges of the backfilled follows are dated when the backfill runs
+	createdAt := time.Now().UTC().Format(time.RFC3339Nano)
+	written := 0
+	for paginator.HasMorePages() {
+		var page *dynamodb.ScanOutput
+		err := r.opts.call(ctx, func(ctx context.Context) (err error) {
+			page, err = paginator.NextPage(ctx)
+			return err
+		})
+		if err != nil {
+			return written, fmt.Errorf("failed to scan users page from DynamoDB: %w", err)
+		}
+
+		var pageUsers []dynamoDBUser
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &pageUsers); err != nil {
+			return written, fmt.Errorf("failed to unmarshal users page from DynamoDB: %w", err)
+		}
+		for _, user := range pageUsers {
+			for _, followedID := range user.Following {
+				created, err := r.putFollowEdge(ctx, dynamoDBFollow{FollowedID: followedID, FollowerID: user.ID, CreatedAt: createdAt})
+				if err != nil {
+					return written, err
+				}
+				if created {
+					written++
+				}
+			}
+		}
+	}
+
+	slog.InfoContext(ctx, "Backfilled follows table", "written", written)
+	return written, nil
+}
+
+// putFollowEdge writes a follow edge unless the follows table already holds it,
+// reporting whether it was written.
+func (r *DynamoDBUserRepository) putFollowEdge(ctx context.Context, edge dynamoDBFollow) (bool, error) {
+	item, err := attributevalue.MarshalMap(edge)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal follow edge: %w", err)
+	}
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(r.followsTableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(FollowerID)"),
+	}
+	err = r.opts.call(ctx, func(ctx context.Context) error {
+		_, err := r.client.PutItem(ctx, input)
+		return err
+	})
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to write follow edge of %s to %s: %w", edge.FollowerID, edge.FollowedID, err)
+	}
+	return true, nil
+}
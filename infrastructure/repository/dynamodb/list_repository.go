@@ -0,0 +1,217 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+)
+
+// Name of the GSI used to query lists by owner
+const listOwnerIndexName = "OwnerIDIndex"
+
+// DynamoDBListRepository implements the ListRepository interface using AWS DynamoDB.
+// Members are kept in a string set on the list item and changed with atomic ADD/DELETE updates.
+type DynamoDBListRepository struct {
+	client    DynamoDBAPI
+	tableName string
+	opts      options
+}
+
+// dynamoDBList is a helper struct for marshalling/unmarshalling List data.
+type dynamoDBList struct {
+	ID        string   `dynamodbav:"ID"`
+	OwnerID   string   `dynamodbav:"OwnerID"`
+	Name      string   `dynamodbav:"Name"`
+	MemberIDs []string `dynamodbav:"MemberIDs,stringset,omitempty"`
+	CreatedAt string   `dynamodbav:"CreatedAt"` // Store as ISO 8601 string
+}
+
+// NewDynamoDBListRepository creates a new DynamoDB list repository.
+func NewDynamoDBListRepository(cfg aws.Config, tableName string, opts ...Option) *DynamoDBListRepository {
+	return NewDynamoDBListRepositoryWithClient(NewClient(cfg), tableName, opts...)
+}
+
+// NewDynamoDBListRepositoryWithClient creates a new DynamoDB list repository using the given client.
+func NewDynamoDBListRepositoryWithClient(client DynamoDBAPI, tableName string, opts ...Option) *DynamoDBListRepository {
+	return &DynamoDBListRepository{
+		client:    client,
+		tableName: tableName,
+		opts:      newOptions(opts),
+	}
+}
+
+// fromDynamoDBList converts the stored representation to a domain list.
+func fromDynamoDBList(item *dynamoDBList) (*entity.List, error) {
+	createdAt, err := time.Parse(time.RFC3339Nano, item.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CreatedAt timestamp '%s': %w", item.CreatedAt, err)
+	}
+	list := &entity.List{
+		ID:        item.ID,
+		OwnerID:   item.OwnerID,
+		Name:      item.Name,
+		Members:   make(map[string]bool, len(item.MemberIDs)),
+		CreatedAt: createdAt,
+	}
+	for _, id := range item.MemberIDs {
+		list.Members[id] = true
+	}
+	return list, nil
+}
+
+// SaveList stores a list, replacing any existing list with the same ID.
+func (r *DynamoDBListRepository) SaveList(list *entity.List) error {
+	av, err := attributevalue.MarshalMap(dynamoDBList{
+		ID:        list.ID,
+		OwnerID:   list.OwnerID,
+		Name:      list.Name,
+		MemberIDs: list.GetMembers(),
+		CreatedAt: list.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal list: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	}
+	err = r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.PutItem(ctx, input)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save list %s: %w", list.ID, err)
+	}
+	return nil
+}
+
+// FindListByID retrieves a list by its ID. Returns nil if the list does not exist.
+func (r *DynamoDBListRepository) FindListByID(id string) (*entity.List, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"ID": &types.AttributeValueMemberS{Value: id},
+		},
+	}
+
+	var result *dynamodb.GetItemOutput
+	err := r.opts.call(context.Background(), func(ctx context.Context) error {
+		var err error
+		result, err = r.client.GetItem(ctx, input)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list %s: %w", id, err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var item dynamoDBList
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal list %s: %w", id, err)
+	}
+	return fromDynamoDBList(&item)
+}
+
+// FindListsByOwner retrieves all lists owned by a user through the OwnerIDIndex GSI, oldest first.
+func (r *DynamoDBListRepository) FindListsByOwner(ownerID string) ([]*entity.List, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String(listOwnerIndexName),
+		KeyConditionExpression: aws.String("OwnerID = :ownerID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":ownerID": &types.AttributeValueMemberS{Value: ownerID},
+		},
+	}
+
+	lists := make([]*entity.List, 0)
+	for {
+		var result *dynamodb.QueryOutput
+		err := r.opts.call(context.Background(), func(ctx context.Context) error {
+			var err error
+			result, err = r.client.Query(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query lists for owner %s: %w", ownerID, err)
+		}
+
+		var items []dynamoDBList
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal lists: %w", err)
+		}
+		for i := range items {
+			list, err := fromDynamoDBList(&items[i])
+			if err != nil {
+				return nil, err
+			}
+			lists = append(lists, list)
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+
+	sort.Slice(lists, func(i, j int) bool {
+		if !lists[i].CreatedAt.Equal(lists[j].CreatedAt) {
+			return lists[i].CreatedAt.Before(lists[j].CreatedAt)
+		}
+		return lists[i].ID < lists[j].ID
+	})
+	return lists, nil
+}
+
+// AddMember adds a user to the member set of a list.
+func (r *DynamoDBListRepository) AddMember(listID, userID string) error {
+	return r.updateMembers(listID, userID, "ADD")
+}
+
+// RemoveMember removes a user from the member set of a list.
+func (r *DynamoDBListRepository) RemoveMember(listID, userID string) error {
+	return r.updateMembers(listID, userID, "DELETE")
+}
+
+// updateMembers applies an ADD or DELETE string set update to the members of an existing list.
+// The condition on the list ID keeps the update from creating a list that does not exist.
+func (r *DynamoDBListRepository) updateMembers(listID, userID, action string) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"ID": &types.AttributeValueMemberS{Value: listID},
+		},
+		UpdateExpression:    aws.String(action + " MemberIDs :members"),
+		ConditionExpression: aws.String("attribute_exists(ID)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":members": &types.AttributeValueMemberSS{Value: []string{userID}},
+		},
+	}
+	err := r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.UpdateItem(ctx, input)
+		return err
+	})
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return entity.ErrListNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update members of list %s: %w", listID, err)
+	}
+	return nil
+}
+
+// Compile-time check to ensure DynamoDBListRepository implements ListRepository
+var _ repository.ListRepository = (*DynamoDBListRepository)(nil)
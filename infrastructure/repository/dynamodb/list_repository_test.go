@@ -0,0 +1,66 @@
+package dynamodb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	dynamodbRepo "github.com/develpudu/go-challenge/infrastructure/repository/dynamodb"
+)
+
+func TestListMembershipUpdates(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBListRepositoryWithClient(client, "lists")
+	list, _ := entity.NewList("list1", "owner", "Friends", time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	list.AddMember("alice")
+	if err := repo.SaveList(list); err != nil {
+		t.Fatalf("Failed to save list: %v", err)
+	}
+
+	// Act
+	addErr := repo.AddMember("list1", "bob")
+	removeErr := repo.RemoveMember("list1", "alice")
+	missingErr := repo.AddMember("missing", "bob")
+
+	// Assert
+	if addErr != nil || removeErr != nil {
+		t.Fatalf("Expected no errors, got %v and %v", addErr, removeErr)
+	}
+	if missingErr != entity.ErrListNotFound {
+		t.Errorf("Expected ErrListNotFound for an unknown list, got %v", missingErr)
+	}
+
+	stored, err := repo.FindListByID("list1")
+	if err != nil || stored == nil {
+		t.Fatalf("Expected the stored list, got %v (err %v)", stored, err)
+	}
+	members := stored.GetMembers()
+	if len(members) != 1 || members[0] != "bob" {
+		t.Errorf("Expected members [bob], got %v", members)
+	}
+}
+
+func TestFindListsByOwnerOldestFirst(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBListRepositoryWithClient(client, "lists")
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	newer, _ := entity.NewList("listA", "owner", "Newer", base.Add(time.Minute))
+	older, _ := entity.NewList("listB", "owner", "Older", base)
+	other, _ := entity.NewList("listC", "someone", "Other", base)
+	repo.SaveList(newer)
+	repo.SaveList(older)
+	repo.SaveList(other)
+
+	// Act
+	lists, err := repo.FindListsByOwner("owner")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(lists) != 2 || lists[0].ID != "listB" || lists[1].ID != "listA" {
+		t.Errorf("Expected the owner's lists oldest first, got %v", lists)
+	}
+}
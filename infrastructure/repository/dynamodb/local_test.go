@@ -0,0 +1,88 @@
+package dynamodb_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/develpudu/go-challenge/domain/entity"
+	dynamodbRepo "github.com/develpudu/go-challenge/infrastructure/repository/dynamodb"
+)
+
+// Returns a client for the DynamoDB Local endpoint in DYNAMODB_ENDPOINT, skipping the test when unset
+func setupLocalClient(t *testing.T) (aws.Config, *dynamodb.Client) {
+	if os.Getenv(dynamodbRepo.EndpointEnv) == "" {
+		t.Skip("DYNAMODB_ENDPOINT not set, skipping DynamoDB Local test")
+	}
+	// DynamoDB Local accepts any credentials
+	cfg := aws.Config{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "local", SecretAccessKey: "local"}, nil
+		}),
+	}
+	return cfg, dynamodbRepo.NewClient(cfg)
+}
+
+// Creates a table with a string hash key and optional string range key, deleted when the test ends
+func createLocalTable(t *testing.T, client *dynamodb.Client, name, hashKey, rangeKey string) {
+	ctx := context.Background()
+	input := &dynamodb.CreateTableInput{
+		TableName:   aws.String(name),
+		BillingMode: types.BillingModePayPerRequest,
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String(hashKey), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String(hashKey), KeyType: types.KeyTypeHash},
+		},
+	}
+	if rangeKey != "" {
+		input.AttributeDefinitions = append(input.AttributeDefinitions, types.AttributeDefinition{AttributeName: aws.String(rangeKey), AttributeType: types.ScalarAttributeTypeS})
+		input.KeySchema = append(input.KeySchema, types.KeySchemaElement{AttributeName: aws.String(rangeKey), KeyType: types.KeyTypeRange})
+	}
+	if _, err := client.CreateTable(ctx, input); err != nil {
+		t.Fatalf("Failed to create table %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		client.DeleteTable(context.Background(), &dynamodb.DeleteTableInput{TableName: aws.String(name)})
+	})
+}
+
+func TestUserRepositoryAgainstDynamoDBLocal(t *testing.T) {
+	cfg, client := setupLocalClient(t)
+	suffix := time.Now().UnixNano()
+	usersTable := fmt.Sprintf("users-%d", suffix)
+	followsTable := fmt.Sprintf("follows-%d", suffix)
+	createLocalTable(t, client, usersTable, "ID", "")
+	createLocalTable(t, client, followsTable, "FollowedID", "FollowerID")
+	repo := dynamodbRepo.NewDynamoDBUserRepository(cfg, usersTable, followsTable)
+
+	// Save two users and a follow between them
+	for _, user := range []*entity.User{entity.NewUser("alice", "alice"), entity.NewUser("bob", "bob")} {
+		if err := repo.Save(user); err != nil {
+			t.Fatalf("Failed to save user %s: %v", user.ID, err)
+		}
+	}
+	if err := repo.Follow("alice", "bob"); err != nil {
+		t.Fatalf("Failed to follow: %v", err)
+	}
+
+	// Read them back
+	alice, err := repo.FindByID("alice")
+	if err != nil || alice == nil {
+		t.Fatalf("Expected to find alice, got %v and %v", alice, err)
+	}
+	if !alice.IsFollowing("bob") {
+		t.Error("Expected alice to follow bob")
+	}
+	followers, err := repo.FindFollowers("bob")
+	if err != nil || len(followers) != 1 || followers[0].ID != "alice" {
+		t.Errorf("Expected alice as bob's only follower, got %v and %v", followers, err)
+	}
+}
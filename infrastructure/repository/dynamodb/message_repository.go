@@ -0,0 +1,135 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// DynamoDBMessageRepository implements the MessageRepository interface using AWS DynamoDB.
+// The table is keyed by ConversationID (hash) and MessageKey (range), the send time followed
+// by the message ID, so the latest messages of a conversation are read with a single descending Query.
+type DynamoDBMessageRepository struct {
+	client    DynamoDBAPI
+	tableName string
+	opts      options
+}
+
+// dynamoDBMessage is a helper struct for marshalling/unmarshalling Message data.
+type dynamoDBMessage struct {
+	ConversationID string `dynamodbav:"ConversationID"`
+	MessageKey     string `dynamodbav:"MessageKey"` // CreatedAt#ID, ordering messages by time
+	ID             string `dynamodbav:"ID"`
+	SenderID       string `dynamodbav:"SenderID"`
+	RecipientID    string `dynamodbav:"RecipientID"`
+	Content        string `dynamodbav:"Content"`
+	CreatedAt      string `dynamodbav:"CreatedAt"` // Store as ISO 8601 string
+}
+
+// NewDynamoDBMessageRepository creates a new DynamoDB message repository.
+func NewDynamoDBMessageRepository(cfg aws.Config, tableName string, opts ...Option) *DynamoDBMessageRepository {
+	return NewDynamoDBMessageRepositoryWithClient(NewClient(cfg), tableName, opts...)
+}
+
+// NewDynamoDBMessageRepositoryWithClient creates a new DynamoDB message repository using the given client.
+func NewDynamoDBMessageRepositoryWithClient(client DynamoDBAPI, tableName string, opts ...Option) *DynamoDBMessageRepository {
+	return &DynamoDBMessageRepository{
+		client:    client,
+		tableName: tableName,
+		opts:      newOptions(opts),
+	}
+}
+
+// Save stores a message in its conversation.
+func (r *DynamoDBMessageRepository) Save(message *entity.Message) error {
+	// Fixed-width UTC timestamps sort lexicographically in time order
+	sentAt := message.CreatedAt.UTC().Format("2006-01-02T15:04:05.000000000Z")
+	av, err := attributevalue.MarshalMap(dynamoDBMessage{
+		ConversationID: message.ConversationID,
+		MessageKey:     sentAt + "#" + message.ID,
+		ID:             message.ID,
+		SenderID:       message.SenderID,
+		RecipientID:    message.RecipientID,
+		Content:        message.Content,
+		CreatedAt:      message.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	}
+	err = r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.PutItem(ctx, input)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save message %s: %w", message.ID, err)
+	}
+	return nil
+}
+
+// FindByConversationID retrieves up to limit of the latest messages of a conversation, oldest first.
+// A non-positive limit reads the whole conversation, following LastEvaluatedKey.
+func (r *DynamoDBMessageRepository) FindByConversationID(conversationID string, limit int) ([]*entity.Message, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("ConversationID = :conversationID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":conversationID": &types.AttributeValueMemberS{Value: conversationID},
+		},
+		ScanIndexForward: aws.Bool(false),
+	}
+	if limit > 0 {
+		input.Limit = aws.Int32(int32(limit))
+	}
+
+	messages := make([]*entity.Message, 0)
+	for {
+		var result *dynamodb.QueryOutput
+		err := r.opts.call(context.Background(), func(ctx context.Context) (err error) {
+			result, err = r.client.Query(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query messages of conversation %s: %w", conversationID, err)
+		}
+
+		var items []dynamoDBMessage
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal messages: %w", err)
+		}
+		for _, item := range items {
+			createdAt, err := time.Parse(time.RFC3339Nano, item.CreatedAt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse CreatedAt timestamp '%s': %w", item.CreatedAt, err)
+			}
+			messages = append(messages, &entity.Message{
+				ID:             item.ID,
+				ConversationID: item.ConversationID,
+				SenderID:       item.SenderID,
+				RecipientID:    item.RecipientID,
+				Content:        item.Content,
+				CreatedAt:      createdAt,
+			})
+		}
+
+		if limit > 0 || len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+
+	// Read newest first to honor the limit, returned oldest first
+	slices.Reverse(messages)
+	return messages, nil
+}
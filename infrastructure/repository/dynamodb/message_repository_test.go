@@ -0,0 +1,77 @@
+package dynamodb_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	dynamodbRepo "github.com/develpudu/go-challenge/infrastructure/repository/dynamodb"
+)
+
+func TestMessagesReadLatestOldestFirst(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBMessageRepositoryWithClient(client, "messages")
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		sender, recipient := "alice", "bob"
+		if i%2 == 1 {
+			sender, recipient = recipient, sender
+		}
+		message, _ := entity.NewMessage(fmt.Sprintf("m%d", i), sender, recipient, "Hi", base.Add(time.Duration(i)*time.Minute))
+		if err := repo.Save(message); err != nil {
+			t.Fatalf("Failed to save message: %v", err)
+		}
+	}
+	other, _ := entity.NewMessage("other", "alice", "carol", "Hi", base)
+	repo.Save(other)
+
+	// Act
+	all, err := repo.FindByConversationID(entity.ConversationIDFor("alice", "bob"), 0)
+	latest, latestErr := repo.FindByConversationID(entity.ConversationIDFor("bob", "alice"), 2)
+
+	// Assert
+	if err != nil || latestErr != nil {
+		t.Fatalf("Expected no errors, got %v and %v", err, latestErr)
+	}
+	if fmt.Sprint(messageIDs(all)) != "[m0 m1 m2 m3 m4]" {
+		t.Errorf("Expected the whole conversation oldest first, got %v", messageIDs(all))
+	}
+	if fmt.Sprint(messageIDs(latest)) != "[m3 m4]" {
+		t.Errorf("Expected the 2 latest messages oldest first, got %v", messageIDs(latest))
+	}
+	if !all[1].CreatedAt.Equal(base.Add(time.Minute)) || all[1].SenderID != "bob" || all[1].RecipientID != "alice" {
+		t.Errorf("Expected the stored fields back, got %+v", all[1])
+	}
+}
+
+func TestBlocksAreOneWay(t *testing.T) {
+	// Arrange
+	repo := dynamodbRepo.NewDynamoDBBlockRepositoryWithClient(NewMockDynamoDBClient(), "blocks")
+
+	// Act
+	repo.Block("alice", "bob")
+	repo.Block("alice", "bob")
+	blocked, err := repo.IsBlocked("alice", "bob")
+	reverse, _ := repo.IsBlocked("bob", "alice")
+	repo.Unblock("alice", "bob")
+	unblocked, _ := repo.IsBlocked("alice", "bob")
+
+	// Assert
+	if err != nil || !blocked || reverse {
+		t.Errorf("Expected only alice to block bob, got %v, %v (%v)", blocked, reverse, err)
+	}
+	if unblocked {
+		t.Error("Expected the block to be removed")
+	}
+}
+
+// Returns the IDs of the messages in order
+func messageIDs(messages []*entity.Message) []string {
+	ids := make([]string, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+	return ids
+}
@@ -0,0 +1,539 @@
+package dynamodb_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	dynamodbRepo "github.com/develpudu/go-challenge/infrastructure/repository/dynamodb"
+)
+
+// Error returned by mock operations that the tests do not exercise
+var errNotImplemented = errors.New("mock operation not implemented")
+
+// Mock implementation of the DynamoDBAPI interface backed by in-memory tables
+type MockDynamoDBClient struct {
+	mutex sync.Mutex
+	// Map of table name to the attribute names forming its primary key
+	keys map[string][]string
+	// Map of table name to items, indexed by their encoded primary key
+	tables map[string]map[string]map[string]types.AttributeValue
+
+	// Error returned by TransactWriteItems without applying any operation
+	TransactErr error
+
+	// Delay applied to every call, honoring the context deadline
+	Delay time.Duration
+
+	// Number of keys the next BatchGetItem call leaves unprocessed, as DynamoDB does under load
+	UnprocessedNext int
+
+	// Errors returned, in order, by the next calls of each operation
+	failures map[string][]error
+
+	// Number of calls per operation
+	Calls map[string]int
+}
+
+// Creates a new mock client with the users, follows, tweets, outbox, bookmarks, lists, reports, usernames, timelines, notifications, messages and blocks tables
+func NewMockDynamoDBClient() *MockDynamoDBClient {
+	return &MockDynamoDBClient{
+		keys: map[string][]string{
+			"users":         {"ID"},
+			"follows":       {"FollowedID", "FollowerID"},
+			"tweets":        {"ID"},
+			"outbox":        {"ID"},
+			"bookmarks":     {"UserID", "TweetID"},
+			"lists":         {"ID"},
+			"reports":       {"TweetID", "ReporterID"},
+			"usernames":     {"UsernameKey"},
+			"timelines":     {"UserID", "TweetID"},
+			"notifications": {"RecipientID", "NotificationKey"},
+			"messages":      {"ConversationID", "MessageKey"},
+			"blocks":        {"BlockerID", "BlockedID"},
+		},
+		tables:   make(map[string]map[string]map[string]types.AttributeValue),
+		failures: make(map[string][]error),
+		Calls:    make(map[string]int),
+	}
+}
+
+// Encodes the primary key of an item for the given table
+func (c *MockDynamoDBClient) encodeKey(table string, item map[string]types.AttributeValue) string {
+	parts := make([]string, 0, len(c.keys[table]))
+	for _, name := range c.keys[table] {
+		if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+			parts = append(parts, v.Value)
+		}
+	}
+	return strings.Join(parts, "#")
+}
+
+// Returns the items stored in a table
+func (c *MockDynamoDBClient) Items(table string) []map[string]types.AttributeValue {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	items := make([]map[string]types.AttributeValue, 0, len(c.tables[table]))
+	for _, item := range c.tables[table] {
+		items = append(items, item)
+	}
+	return items
+}
+
+// Makes the next calls of an operation fail with the given errors, in order
+func (c *MockDynamoDBClient) FailNext(operation string, errs ...error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.failures[operation] = append(c.failures[operation], errs...)
+}
+
+// Records a call to an operation and returns its next queued failure, if any
+func (c *MockDynamoDBClient) record(operation string) error {
+	c.Calls[operation]++
+	if queued := c.failures[operation]; len(queued) > 0 {
+		c.failures[operation] = queued[1:]
+		return queued[0]
+	}
+	return nil
+}
+
+// Waits for the configured delay, or returns the context error if it expires first
+func (c *MockDynamoDBClient) wait(ctx context.Context) error {
+	if c.Delay == 0 {
+		return nil
+	}
+	select {
+	case <-time.After(c.Delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *MockDynamoDBClient) put(table string, item map[string]types.AttributeValue) {
+	if c.tables[table] == nil {
+		c.tables[table] = make(map[string]map[string]types.AttributeValue)
+	}
+	c.tables[table][c.encodeKey(table, item)] = item
+}
+
+func (c *MockDynamoDBClient) get(table string, key map[string]types.AttributeValue) map[string]types.AttributeValue {
+	return c.tables[table][c.encodeKey(table, key)]
+}
+
+// Applies an "ADD attr :value" or "DELETE attr :value" string set update
+func (c *MockDynamoDBClient) update(table string, u *types.Update) error {
+	item := c.get(table, u.Key)
+	if item == nil {
+		return errors.New("item does not exist")
+	}
+	fields := strings.Fields(aws.ToString(u.UpdateExpression))
+	switch {
+	case len(fields) == 2 && fields[0] == "REMOVE":
+		delete(item, fields[1])
+		return nil
+	case len(fields) == 4 && fields[0] == "SET" && fields[2] == "=":
+		item[fields[1]] = u.ExpressionAttributeValues[fields[3]]
+		return nil
+	case len(fields) != 3:
+		return errNotImplemented
+	}
+	action, attr := fields[0], fields[1]
+	if n, ok := u.ExpressionAttributeValues[fields[2]].(*types.AttributeValueMemberN); ok && action == "ADD" {
+		// Number ADD: adds to the current value, zero when absent
+		current := int64(0)
+		if stored, ok := item[attr].(*types.AttributeValueMemberN); ok {
+			current, _ = strconv.ParseInt(stored.Value, 10, 64)
+		}
+		delta, _ := strconv.ParseInt(n.Value, 10, 64)
+		item[attr] = &types.AttributeValueMemberN{Value: strconv.FormatInt(current+delta, 10)}
+		return nil
+	}
+	values := u.ExpressionAttributeValues[fields[2]].(*types.AttributeValueMemberSS).Value
+
+	set := make(map[string]bool)
+	if current, ok := item[attr].(*types.AttributeValueMemberSS); ok {
+		for _, v := range current.Value {
+			set[v] = true
+		}
+	}
+	for _, v := range values {
+		if action == "ADD" {
+			set[v] = true
+		} else {
+			delete(set, v)
+		}
+	}
+	if len(set) == 0 {
+		delete(item, attr)
+		return nil
+	}
+	merged := make([]string, 0, len(set))
+	for v := range set {
+		merged = append(merged, v)
+	}
+	item[attr] = &types.AttributeValueMemberSS{Value: merged}
+	return nil
+}
+
+func (c *MockDynamoDBClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.record("GetItem"); err != nil {
+		return nil, err
+	}
+	return &dynamodb.GetItemOutput{Item: c.get(aws.ToString(params.TableName), params.Key)}, nil
+}
+
+func (c *MockDynamoDBClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.record("PutItem"); err != nil {
+		return nil, err
+	}
+	table := aws.ToString(params.TableName)
+	if strings.HasPrefix(aws.ToString(params.ConditionExpression), "attribute_not_exists") && c.get(table, params.Item) != nil {
+		return nil, &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	}
+	if strings.HasPrefix(aws.ToString(params.ConditionExpression), "attribute_exists") && c.get(table, params.Item) == nil {
+		return nil, &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	}
+	c.put(table, params.Item)
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (c *MockDynamoDBClient) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.record("UpdateItem"); err != nil {
+		return nil, err
+	}
+	table := aws.ToString(params.TableName)
+	if c.get(table, params.Key) == nil {
+		if strings.HasPrefix(aws.ToString(params.ConditionExpression), "attribute_exists") {
+			return nil, &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+		}
+		return nil, errNotImplemented
+	}
+	err := c.update(table, &types.Update{
+		Key:                       params.Key,
+		UpdateExpression:          params.UpdateExpression,
+		ExpressionAttributeValues: params.ExpressionAttributeValues,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func (c *MockDynamoDBClient) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.record("DeleteItem"); err != nil {
+		return nil, err
+	}
+	table := aws.ToString(params.TableName)
+	delete(c.tables[table], c.encodeKey(table, params.Key))
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func (c *MockDynamoDBClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.record("Query"); err != nil {
+		return nil, err
+	}
+
+	// Only "Attr = :value" key conditions are supported, optionally followed by "AND Range BETWEEN :a AND :b"
+	// on a number attribute; indexes are emulated by matching the attributes
+	fields := strings.Fields(aws.ToString(params.KeyConditionExpression))
+	between := len(fields) == 9 && fields[3] == "AND" && fields[5] == "BETWEEN" && fields[7] == "AND"
+	if (len(fields) != 3 && !between) || fields[1] != "=" {
+		return nil, errNotImplemented
+	}
+	table := aws.ToString(params.TableName)
+	attr := fields[0]
+	value := params.ExpressionAttributeValues[fields[2]].(*types.AttributeValueMemberS).Value
+	var rangeAttr string
+	var rangeFrom, rangeTo int64
+	if between {
+		rangeAttr = fields[4]
+		rangeFrom, _ = strconv.ParseInt(params.ExpressionAttributeValues[fields[6]].(*types.AttributeValueMemberN).Value, 10, 64)
+		rangeTo, _ = strconv.ParseInt(params.ExpressionAttributeValues[fields[8]].(*types.AttributeValueMemberN).Value, 10, 64)
+	}
+
+	// The only supported filter keeps items that have not expired at :now
+	var notExpiredAt int64
+	if params.FilterExpression != nil {
+		if aws.ToString(params.FilterExpression) != "attribute_not_exists(ExpiresAt) OR ExpiresAt > :now" {
+			return nil, errNotImplemented
+		}
+		notExpiredAt, _ = strconv.ParseInt(params.ExpressionAttributeValues[":now"].(*types.AttributeValueMemberN).Value, 10, 64)
+	}
+
+	// Collect matching items ordered by their encoded key, which follows the range key
+	matches := make([]map[string]types.AttributeValue, 0)
+	for _, item := range c.tables[table] {
+		if v, ok := item[attr].(*types.AttributeValueMemberS); !ok || v.Value != value {
+			continue
+		}
+		if between {
+			v, ok := item[rangeAttr].(*types.AttributeValueMemberN)
+			if !ok {
+				continue
+			}
+			if n, _ := strconv.ParseInt(v.Value, 10, 64); n < rangeFrom || n > rangeTo {
+				continue
+			}
+		}
+		if expiresAt, ok := item["ExpiresAt"].(*types.AttributeValueMemberN); ok && params.FilterExpression != nil {
+			if at, _ := strconv.ParseInt(expiresAt.Value, 10, 64); at <= notExpiredAt {
+				continue
+			}
+		}
+		matches = append(matches, item)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		less := c.encodeKey(table, matches[i]) < c.encodeKey(table, matches[j])
+		if params.ScanIndexForward != nil && !*params.ScanIndexForward {
+			return !less
+		}
+		return less
+	})
+
+	// Resume after the start key, which does not need to match a stored item
+	if params.ExclusiveStartKey != nil {
+		start := c.encodeKey(table, params.ExclusiveStartKey)
+		forward := params.ScanIndexForward == nil || *params.ScanIndexForward
+		remaining := matches[:0]
+		for _, item := range matches {
+			key := c.encodeKey(table, item)
+			if (forward && key > start) || (!forward && key < start) {
+				remaining = append(remaining, item)
+			}
+		}
+		matches = remaining
+	}
+
+	output := &dynamodb.QueryOutput{Items: matches}
+	if params.Limit != nil && int32(len(matches)) > *params.Limit {
+		output.Items = matches[:*params.Limit]
+		last := output.Items[len(output.Items)-1]
+		output.LastEvaluatedKey = make(map[string]types.AttributeValue)
+		for _, name := range c.keys[table] {
+			output.LastEvaluatedKey[name] = last[name]
+		}
+	}
+	output.Count = int32(len(output.Items))
+	return output, nil
+}
+
+func (c *MockDynamoDBClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.record("Scan"); err != nil {
+		return nil, err
+	}
+	if params.FilterExpression != nil {
+		return nil, errNotImplemented
+	}
+
+	// Unfiltered scans return up to Limit items of the table after ExclusiveStartKey.
+	// The scan order is the encoded primary key, standing in for DynamoDB's hash order
+	table := aws.ToString(params.TableName)
+	keys := make([]string, 0, len(c.tables[table]))
+	for key := range c.tables[table] {
+		if params.ExclusiveStartKey == nil || key > c.encodeKey(table, params.ExclusiveStartKey) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	output := &dynamodb.ScanOutput{Items: make([]map[string]types.AttributeValue, 0, len(keys))}
+	for _, key := range keys {
+		if params.Limit != nil && int32(len(output.Items)) >= *params.Limit {
+			// Like DynamoDB, the last returned key resumes the scan
+			last := output.Items[len(output.Items)-1]
+			output.LastEvaluatedKey = make(map[string]types.AttributeValue)
+			for _, name := range c.keys[table] {
+				output.LastEvaluatedKey[name] = last[name]
+			}
+			break
+		}
+		output.Items = append(output.Items, c.tables[table][key])
+	}
+	output.Count = int32(len(output.Items))
+	return output, nil
+}
+
+func (c *MockDynamoDBClient) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.record("BatchGetItem"); err != nil {
+		return nil, err
+	}
+
+	// Like DynamoDB, items are returned in no particular order and missing keys are skipped
+	output := &dynamodb.BatchGetItemOutput{
+		Responses:       make(map[string][]map[string]types.AttributeValue),
+		UnprocessedKeys: make(map[string]types.KeysAndAttributes),
+	}
+	for table, request := range params.RequestItems {
+		if len(request.Keys) > 100 {
+			return nil, errors.New("too many keys requested")
+		}
+		requested := make(map[string]bool, len(request.Keys))
+		for _, key := range request.Keys {
+			if requested[c.encodeKey(table, key)] {
+				return nil, errors.New("provided list of item keys contains duplicates")
+			}
+			requested[c.encodeKey(table, key)] = true
+		}
+
+		keys := request.Keys
+		if deferred := min(c.UnprocessedNext, len(keys)); deferred > 0 {
+			output.UnprocessedKeys[table] = types.KeysAndAttributes{Keys: keys[len(keys)-deferred:]}
+			keys = keys[:len(keys)-deferred]
+			c.UnprocessedNext -= deferred
+		}
+		items := make([]map[string]types.AttributeValue, 0, len(keys))
+		for _, key := range keys {
+			if item := c.get(table, key); item != nil {
+				items = append(items, item)
+			}
+		}
+		output.Responses[table] = items
+	}
+	return output, nil
+}
+
+func (c *MockDynamoDBClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.record("BatchWriteItem"); err != nil {
+		return nil, err
+	}
+
+	for table, requests := range params.RequestItems {
+		if len(requests) > 25 {
+			return nil, errors.New("too many items requested")
+		}
+		for _, request := range requests {
+			switch {
+			case request.PutRequest != nil:
+				c.put(table, request.PutRequest.Item)
+			case request.DeleteRequest != nil:
+				delete(c.tables[table], c.encodeKey(table, request.DeleteRequest.Key))
+			}
+		}
+	}
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+// Reports whether an existing item satisfies the "OR Attr = :value" alternative of a
+// condition such as "attribute_not_exists(Key) OR Attr = :value"
+func matchesOrEquals(condition string, values map[string]types.AttributeValue, existing map[string]types.AttributeValue) bool {
+	_, alternative, found := strings.Cut(condition, " OR ")
+	if !found {
+		return false
+	}
+	fields := strings.Fields(alternative)
+	if len(fields) != 3 || fields[1] != "=" {
+		return false
+	}
+	got, gotOK := existing[fields[0]].(*types.AttributeValueMemberS)
+	want, wantOK := values[fields[2]].(*types.AttributeValueMemberS)
+	return gotOK && wantOK && got.Value == want.Value
+}
+
+func (c *MockDynamoDBClient) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.record("TransactWriteItems"); err != nil {
+		return nil, err
+	}
+
+	if c.TransactErr != nil {
+		return nil, c.TransactErr
+	}
+
+	// Validate all conditions before applying anything, like DynamoDB does,
+	// reporting one cancellation reason per item
+	reasons := make([]types.CancellationReason, len(params.TransactItems))
+	failed := false
+	for i, op := range params.TransactItems {
+		ok := true
+		switch {
+		case op.ConditionCheck != nil:
+			ok = c.get(aws.ToString(op.ConditionCheck.TableName), op.ConditionCheck.Key) != nil
+		case op.Update != nil:
+			ok = c.get(aws.ToString(op.Update.TableName), op.Update.Key) != nil
+		case op.Put != nil && strings.HasPrefix(aws.ToString(op.Put.ConditionExpression), "attribute_not_exists"):
+			existing := c.get(aws.ToString(op.Put.TableName), op.Put.Item)
+			ok = existing == nil || matchesOrEquals(aws.ToString(op.Put.ConditionExpression), op.Put.ExpressionAttributeValues, existing)
+		case op.Delete != nil && strings.HasPrefix(aws.ToString(op.Delete.ConditionExpression), "attribute_exists"):
+			ok = c.get(aws.ToString(op.Delete.TableName), op.Delete.Key) != nil
+		}
+		reasons[i].Code = aws.String("None")
+		if !ok {
+			reasons[i].Code = aws.String("ConditionalCheckFailed")
+			failed = true
+		}
+	}
+	if failed {
+		return nil, &types.TransactionCanceledException{CancellationReasons: reasons}
+	}
+
+	for _, op := range params.TransactItems {
+		switch {
+		case op.Put != nil:
+			c.put(aws.ToString(op.Put.TableName), op.Put.Item)
+		case op.Delete != nil:
+			table := aws.ToString(op.Delete.TableName)
+			delete(c.tables[table], c.encodeKey(table, op.Delete.Key))
+		case op.Update != nil:
+			if err := c.update(aws.ToString(op.Update.TableName), op.Update); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+// Compile-time check
+var _ dynamodbRepo.DynamoDBAPI = (*MockDynamoDBClient)(nil)
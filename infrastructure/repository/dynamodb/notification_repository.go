@@ -0,0 +1,253 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+)
+
+// readMarkerKey is the NotificationKey of the item holding when a recipient last marked
+// everything read. It sorts before every notification ID, so descending queries reach it last.
+const readMarkerKey = "#read"
+
+// DynamoDBNotificationRepository implements the NotificationRepository interface using AWS DynamoDB.
+// The table is keyed by RecipientID (hash) and NotificationKey (range), the notification ID, which
+// starts with the creation time, so a recipient's latest notifications are read with a single
+// descending Query. Notifications expire through the table's TTL on ExpiresAt. Unlike the in-memory
+// repository, older notifications are not dropped beyond MaxNotificationsPerUser, but unread counts
+// only look at that many. Read state is a single marker item per recipient rather than a flag on
+// each notification, so marking everything read is one write.
+type DynamoDBNotificationRepository struct {
+	client    DynamoDBAPI
+	tableName string
+	opts      options
+}
+
+// dynamoDBNotification is a helper struct for marshalling/unmarshalling Notification data.
+type dynamoDBNotification struct {
+	RecipientID     string `dynamodbav:"RecipientID"`
+	NotificationKey string `dynamodbav:"NotificationKey"` // The notification ID
+	Type            string `dynamodbav:"Type"`
+	ActorID         string `dynamodbav:"ActorID"`
+	TweetID         string `dynamodbav:"TweetID,omitempty"`
+	CreatedAt       string `dynamodbav:"CreatedAt"` // Store as ISO 8601 string
+	ExpiresAt       int64  `dynamodbav:"ExpiresAt"` // Epoch seconds, the table's TTL attribute
+}
+
+// dynamoDBReadMarker is the item recording when a recipient last marked everything read.
+type dynamoDBReadMarker struct {
+	RecipientID     string `dynamodbav:"RecipientID"`
+	NotificationKey string `dynamodbav:"NotificationKey"` // Always readMarkerKey
+	ReadAt          string `dynamodbav:"ReadAt"`          // Store as ISO 8601 string
+}
+
+// NewDynamoDBNotificationRepository creates a new DynamoDB notification repository.
+func NewDynamoDBNotificationRepository(cfg aws.Config, tableName string, opts ...Option) *DynamoDBNotificationRepository {
+	return NewDynamoDBNotificationRepositoryWithClient(NewClient(cfg), tableName, opts...)
+}
+
+// NewDynamoDBNotificationRepositoryWithClient creates a new DynamoDB notification repository using the given client.
+func NewDynamoDBNotificationRepositoryWithClient(client DynamoDBAPI, tableName string, opts ...Option) *DynamoDBNotificationRepository {
+	return &DynamoDBNotificationRepository{
+		client:    client,
+		tableName: tableName,
+		opts:      newOptions(opts),
+	}
+}
+
+// Save stores a notification, set to expire NotificationRetention after it was created.
+func (r *DynamoDBNotificationRepository) Save(notification *entity.Notification) error {
+	av, err := attributevalue.MarshalMap(dynamoDBNotification{
+		RecipientID:     notification.RecipientID,
+		NotificationKey: notification.ID,
+		Type:            string(notification.Type),
+		ActorID:         notification.ActorID,
+		TweetID:         notification.TweetID,
+		CreatedAt:       notification.CreatedAt.UTC().Format(time.RFC3339Nano),
+		ExpiresAt:       notification.CreatedAt.Add(repository.NotificationRetention).Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	}
+	err = r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.PutItem(ctx, input)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save notification %s for user %s: %w", notification.ID, notification.RecipientID, err)
+	}
+	return nil
+}
+
+// FindByRecipientID retrieves up to limit of the notifications of a recipient older than afterID, newest first.
+// At most MaxNotificationsPerUser notifications are returned per call, even with a larger or non-positive limit.
+func (r *DynamoDBNotificationRepository) FindByRecipientID(recipientID, afterID string, limit int) ([]*entity.Notification, error) {
+	if limit <= 0 || limit > repository.MaxNotificationsPerUser {
+		limit = repository.MaxNotificationsPerUser
+	}
+	readAt, err := r.readAt(recipientID)
+	if err != nil {
+		return nil, err
+	}
+	notifications, err := r.query(recipientID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, notification := range notifications {
+		notification.Read = !notification.CreatedAt.After(readAt)
+	}
+	return notifications, nil
+}
+
+// CountUnread counts the notifications of a recipient created after they last marked everything read,
+// among their MaxNotificationsPerUser newest.
+func (r *DynamoDBNotificationRepository) CountUnread(recipientID string) (int, error) {
+	readAt, err := r.readAt(recipientID)
+	if err != nil {
+		return 0, err
+	}
+	notifications, err := r.query(recipientID, "", repository.MaxNotificationsPerUser)
+	if err != nil {
+		return 0, err
+	}
+	unread := 0
+	for _, notification := range notifications {
+		if notification.CreatedAt.After(readAt) {
+			unread++
+		}
+	}
+	return unread, nil
+}
+
+// MarkAllRead marks every notification of a recipient created up to readAt as read.
+// The marker only moves forward, so marking at an earlier time than a previous mark keeps the later one.
+func (r *DynamoDBNotificationRepository) MarkAllRead(recipientID string, readAt time.Time) error {
+	current, err := r.readAt(recipientID)
+	if err != nil {
+		return err
+	}
+	if !readAt.After(current) {
+		return nil
+	}
+
+	av, err := attributevalue.MarshalMap(dynamoDBReadMarker{
+		RecipientID:     recipientID,
+		NotificationKey: readMarkerKey,
+		ReadAt:          readAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal read marker: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	}
+	err = r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.PutItem(ctx, input)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark notifications of user %s as read: %w", recipientID, err)
+	}
+	return nil
+}
+
+// readAt returns when a recipient last marked everything read, or the zero time if they never did.
+func (r *DynamoDBNotificationRepository) readAt(recipientID string) (time.Time, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"RecipientID":     &types.AttributeValueMemberS{Value: recipientID},
+			"NotificationKey": &types.AttributeValueMemberS{Value: readMarkerKey},
+		},
+	}
+	var result *dynamodb.GetItemOutput
+	err := r.opts.call(context.Background(), func(ctx context.Context) (err error) {
+		result, err = r.client.GetItem(ctx, input)
+		return err
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get read marker for user %s: %w", recipientID, err)
+	}
+	if result.Item == nil {
+		return time.Time{}, nil
+	}
+
+	var marker dynamoDBReadMarker
+	if err := attributevalue.UnmarshalMap(result.Item, &marker); err != nil {
+		return time.Time{}, fmt.Errorf("failed to unmarshal read marker: %w", err)
+	}
+	readAt, err := time.Parse(time.RFC3339Nano, marker.ReadAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse ReadAt timestamp '%s': %w", marker.ReadAt, err)
+	}
+	return readAt, nil
+}
+
+// query reads up to limit of the notifications of a recipient older than afterID, newest first.
+// The read marker and expired notifications, which linger until DynamoDB deletes them, are skipped.
+func (r *DynamoDBNotificationRepository) query(recipientID, afterID string, limit int) ([]*entity.Notification, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("RecipientID = :recipientID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":recipientID": &types.AttributeValueMemberS{Value: recipientID},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int32(int32(limit)),
+	}
+	if afterID != "" {
+		input.ExclusiveStartKey = map[string]types.AttributeValue{
+			"RecipientID":     &types.AttributeValueMemberS{Value: recipientID},
+			"NotificationKey": &types.AttributeValueMemberS{Value: afterID},
+		}
+	}
+
+	var result *dynamodb.QueryOutput
+	err := r.opts.call(context.Background(), func(ctx context.Context) (err error) {
+		result, err = r.client.Query(ctx, input)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications for user %s: %w", recipientID, err)
+	}
+
+	var items []dynamoDBNotification
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal notifications: %w", err)
+	}
+
+	now := time.Now().Unix()
+	notifications := make([]*entity.Notification, 0, len(items))
+	for _, item := range items {
+		if item.NotificationKey == readMarkerKey || item.ExpiresAt <= now {
+			continue
+		}
+		createdAt, err := time.Parse(time.RFC3339Nano, item.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CreatedAt timestamp '%s': %w", item.CreatedAt, err)
+		}
+		notifications = append(notifications, &entity.Notification{
+			ID:          item.NotificationKey,
+			RecipientID: item.RecipientID,
+			Type:        entity.NotificationType(item.Type),
+			ActorID:     item.ActorID,
+			TweetID:     item.TweetID,
+			CreatedAt:   createdAt,
+		})
+	}
+	return notifications, nil
+}
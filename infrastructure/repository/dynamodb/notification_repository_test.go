@@ -0,0 +1,59 @@
+package dynamodb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	dynamodbRepo "github.com/develpudu/go-challenge/infrastructure/repository/dynamodb"
+)
+
+func TestNotificationsPagedNewestFirstWithReadState(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBNotificationRepositoryWithClient(client, "notifications")
+	base := time.Now().Add(-time.Hour)
+	repo.Save(entity.NewNotification("star", entity.NotificationFollow, "alice", "", base))
+	repo.Save(entity.NewNotification("star", entity.NotificationReply, "carol", "tweet1", base.Add(2*time.Minute)))
+	repo.Save(entity.NewNotification("star", entity.NotificationMention, "bob", "tweet2", base.Add(time.Minute)))
+	repo.Save(entity.NewNotification("alice", entity.NotificationFollow, "star", "", base))
+	// Expired, but not yet removed by the table's TTL
+	repo.Save(entity.NewNotification("star", entity.NotificationFollow, "old", "", base.Add(-60*24*time.Hour)))
+	if err := repo.MarkAllRead("star", base.Add(time.Minute)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Act
+	first, err := repo.FindByRecipientID("star", "", 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	rest, err := repo.FindByRecipientID("star", first[1].ID, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	unread, err := repo.CountUnread("star")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Assert
+	if len(first) != 2 || first[0].ActorID != "carol" || first[0].TweetID != "tweet1" || first[1].ActorID != "bob" {
+		t.Fatalf("Expected carol's reply and bob's mention first, got %d notifications", len(first))
+	}
+	if first[0].Read || !first[1].Read {
+		t.Errorf("Expected only notifications up to the read mark to be read, got %v and %v", first[0].Read, first[1].Read)
+	}
+	if len(rest) != 1 || rest[0].ActorID != "alice" || rest[0].Type != entity.NotificationFollow || !rest[0].Read {
+		t.Errorf("Expected alice's read follow last, got %d notifications", len(rest))
+	}
+	if unread != 1 {
+		t.Errorf("Expected 1 unread notification, got %d", unread)
+	}
+
+	// Marking read at an earlier time keeps the later mark
+	repo.MarkAllRead("star", base)
+	if unread, _ := repo.CountUnread("star"); unread != 1 {
+		t.Errorf("Expected the read mark not to move back, got %d unread", unread)
+	}
+}
@@ -0,0 +1,161 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/develpudu/go-challenge/infrastructure/cache"
+)
+
+const (
+	// Default deadline applied to each DynamoDB call
+	defaultTimeout = 5 * time.Second
+	// Default number of attempts for throttled calls, including the first one
+	defaultMaxAttempts = 3
+	// Default initial and maximum delay between retries
+	defaultRetryBaseDelay = 50 * time.Millisecond
+	defaultMaxRetryDelay  = 2 * time.Second
+	// Default deadline for each timeline cache call made by the tweet repository
+	defaultCacheTimeout = 300 * time.Millisecond
+	// Default time spent waiting for another instance to rebuild a locked timeline
+	defaultTimelineLockWait = 500 * time.Millisecond
+)
+
+// ErrTimeout is returned when a DynamoDB call does not complete before its deadline.
+var ErrTimeout = errors.New("dynamodb operation timed out")
+
+// ErrCacheTimeout is returned when a timeline cache call does not complete before its deadline.
+var ErrCacheTimeout = errors.New("timeline cache operation timed out")
+
+// Option configures optional settings of the DynamoDB repositories.
+type Option func(*options)
+
+// options holds the settings shared by the DynamoDB repositories.
+type options struct {
+	timeout        time.Duration
+	maxAttempts    int
+	retryBaseDelay time.Duration
+	maxRetryDelay  time.Duration
+	cacheTimeout   time.Duration
+	outboxTable    string
+	usernamesTable string
+	timelineTable  string
+	// timelineLock, when set, lets a single instance rebuild each missing timeline.
+	timelineLock     cache.Locker
+	timelineLockWait time.Duration
+}
+
+// newOptions returns the options with defaults applied, overridden by opts.
+func newOptions(opts []Option) options {
+	o := options{
+		timeout:          defaultTimeout,
+		maxAttempts:      defaultMaxAttempts,
+		retryBaseDelay:   defaultRetryBaseDelay,
+		maxRetryDelay:    defaultMaxRetryDelay,
+		cacheTimeout:     defaultCacheTimeout,
+		timelineLockWait: defaultTimelineLockWait,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithTimeout sets the deadline applied to each DynamoDB call.
+// Non-positive values keep the default.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		if timeout > 0 {
+			o.timeout = timeout
+		}
+	}
+}
+
+// WithMaxAttempts sets how many times a throttled call is attempted, including the first one.
+// Values below one keep the default.
+func WithMaxAttempts(maxAttempts int) Option {
+	return func(o *options) {
+		if maxAttempts > 0 {
+			o.maxAttempts = maxAttempts
+		}
+	}
+}
+
+// WithRetryBackoff sets the initial delay between retries and the maximum it can grow to.
+// Non-positive values keep the defaults.
+func WithRetryBackoff(baseDelay, maxDelay time.Duration) Option {
+	return func(o *options) {
+		if baseDelay > 0 {
+			o.retryBaseDelay = baseDelay
+		}
+		if maxDelay > 0 {
+			o.maxRetryDelay = maxDelay
+		}
+	}
+}
+
+// WithCacheTimeout sets the deadline for each timeline cache call. A cache call
+// that misses it is abandoned, so a hung cache cannot stall the request.
+// Non-positive values keep the default.
+func WithCacheTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		if timeout > 0 {
+			o.cacheTimeout = timeout
+		}
+	}
+}
+
+// WithOutboxTable makes the tweet repository write an outbox record in the same
+// transaction as each saved tweet, to be published later by an OutboxRelay.
+func WithOutboxTable(tableName string) Option {
+	return func(o *options) {
+		o.outboxTable = tableName
+	}
+}
+
+// WithUsernamesTable makes the user repository claim each normalized username in
+// the given table, keyed by UsernameKey, in the same transaction as the user item.
+// This keeps usernames unique regardless of case and makes FindByUsername a single read.
+func WithUsernamesTable(tableName string) Option {
+	return func(o *options) {
+		o.usernamesTable = tableName
+	}
+}
+
+// WithTimelineTable makes the tweet repository materialize each user's timeline
+// as tweet IDs in the given table, keyed by UserID and TweetID. New tweets are
+// fanned out to their followers on write, and timelines are read back with
+// BatchGetItem instead of querying the tweets of every followed user.
+func WithTimelineTable(tableName string) Option {
+	return func(o *options) {
+		o.timelineTable = tableName
+	}
+}
+
+// WithTimelineLock makes the tweet repository take a lock from locker before
+// rebuilding a timeline missing from the cache, so only one instance queries
+// DynamoDB for it. Other instances wait up to wait for the timeline to be
+// cached, then rebuild it themselves. Non-positive waits keep the default.
+func WithTimelineLock(locker cache.Locker, wait time.Duration) Option {
+	return func(o *options) {
+		o.timelineLock = locker
+		if wait > 0 {
+			o.timelineLockWait = wait
+		}
+	}
+}
+
+// callContext derives a context bounded by the per-call timeout.
+func (o options) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, o.timeout)
+}
+
+// wrapTimeout marks errors caused by an expired deadline with ErrTimeout.
+func wrapTimeout(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package dynamodb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	dynamodbRepo "github.com/develpudu/go-challenge/infrastructure/repository/dynamodb"
+)
+
+func TestCallsTimeOut(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	client.Delay = time.Second
+	timeout := dynamodbRepo.WithTimeout(20 * time.Millisecond)
+	userRepo := dynamodbRepo.NewDynamoDBUserRepositoryWithClient(client, "users", "follows", timeout)
+	tweetRepo := dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(client, "tweets", userRepo, nil, timeout)
+	tweet, _ := entity.NewTweet("tweet1", "user1", "Test tweet")
+
+	calls := map[string]func() error{
+		"FindUser":  func() error { _, err := userRepo.FindByID("user1"); return err },
+		"SaveUser":  func() error { return userRepo.Save(entity.NewUser("user1", "testuser")) },
+		"Follow":    func() error { return userRepo.Follow("user1", "user2") },
+		"FindTweet": func() error { _, err := tweetRepo.FindByID("tweet1"); return err },
+		"SaveTweet": func() error { return tweetRepo.Save(tweet) },
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			start := time.Now()
+			err := call()
+			elapsed := time.Since(start)
+
+			// Assert
+			if !errors.Is(err, dynamodbRepo.ErrTimeout) {
+				t.Errorf("Expected ErrTimeout, got %v", err)
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Expected the error to wrap context.DeadlineExceeded, got %v", err)
+			}
+			if elapsed >= client.Delay {
+				t.Errorf("Expected the call to return before the client delay, took %v", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+const (
+	// Event type of the outbox records written when a tweet is saved
+	tweetCreatedEventType = "TweetCreated"
+	// Default number of outbox records relayed per run
+	defaultOutboxBatchSize = 25
+)
+
+// TweetEventPublisher publishes tweet events relayed from the outbox.
+// It is satisfied by usecase.EventPublisher implementations.
+type TweetEventPublisher interface {
+	TweetCreated(ctx context.Context, tweet *entity.Tweet) error
+}
+
+// dynamoDBOutboxRecord is a pending event stored in the outbox table.
+type dynamoDBOutboxRecord struct {
+	ID        string        `dynamodbav:"ID"`
+	EventType string        `dynamodbav:"EventType"`
+	Tweet     dynamoDBTweet `dynamodbav:"Tweet"`
+	CreatedAt string        `dynamodbav:"CreatedAt"`
+}
+
+// newTweetCreatedRecord builds the outbox record announcing a saved tweet.
+func newTweetCreatedRecord(ddbTweet *dynamoDBTweet) *dynamoDBOutboxRecord {
+	return &dynamoDBOutboxRecord{
+		ID:        tweetCreatedEventType + "#" + ddbTweet.ID,
+		EventType: tweetCreatedEventType,
+		Tweet:     *ddbTweet,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// OutboxRelay publishes pending outbox records and removes them once published.
+// It can run in the background with Run or be invoked periodically (e.g. by a
+// scheduled Lambda) with RelayOnce.
+type OutboxRelay struct {
+	client    DynamoDBAPI
+	tableName string
+	publisher TweetEventPublisher
+	batchSize int32
+	opts      options
+}
+
+// NewOutboxRelay creates a new relay for the given outbox table.
+func NewOutboxRelay(cfg aws.Config, tableName string, publisher TweetEventPublisher, opts ...Option) *OutboxRelay {
+	return NewOutboxRelayWithClient(NewClient(cfg), tableName, publisher, opts...)
+}
+
+// NewOutboxRelayWithClient creates a new relay using the given client.
+func NewOutboxRelayWithClient(client DynamoDBAPI, tableName string, publisher TweetEventPublisher, opts ...Option) *OutboxRelay {
+	return &OutboxRelay{
+		client:    client,
+		tableName: tableName,
+		publisher: publisher,
+		batchSize: defaultOutboxBatchSize,
+		opts:      newOptions(opts),
+	}
+}
+
+// RelayOnce publishes a batch of pending outbox records, deleting each one after it is published.
+// Records that fail to publish are kept for the next run. It returns the number of published records.
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(r.tableName),
+		Limit:     aws.Int32(r.batchSize),
+	}
+
+	var result *dynamodb.ScanOutput
+	err := r.opts.call(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = r.client.Scan(ctx, input)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan outbox table: %w", err)
+	}
+
+	var records []dynamoDBOutboxRecord
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &records); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal outbox records: %w", err)
+	}
+
+	published := 0
+	var errs []error
+	for _, record := range records {
+		if err := r.relay(ctx, record); err != nil {
+			slog.WarnContext(ctx, "Failed to relay outbox record", "recordID", record.ID, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		published++
+	}
+	return published, errors.Join(errs...)
+}
+
+// relay publishes a single outbox record and removes it from the table.
+func (r *OutboxRelay) relay(ctx context.Context, record dynamoDBOutboxRecord) error {
+	if record.EventType != tweetCreatedEventType {
+		return fmt.Errorf("unknown outbox event type %q", record.EventType)
+	}
+
+	tweet, err := fromDynamoDBTweet(&record.Tweet)
+	if err != nil {
+		return err
+	}
+	if err := r.publisher.TweetCreated(ctx, tweet); err != nil {
+		return fmt.Errorf("failed to publish outbox record %s: %w", record.ID, err)
+	}
+
+	key, err := attributevalue.MarshalMap(map[string]string{"ID": record.ID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox key: %w", err)
+	}
+	err = r.opts.call(ctx, func(ctx context.Context) error {
+		_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(r.tableName),
+			Key:       key,
+		})
+		return err
+	})
+	if err != nil {
+		// The record will be published again on the next run; consumers must tolerate duplicates
+		return fmt.Errorf("failed to delete published outbox record %s: %w", record.ID, err)
+	}
+	return nil
+}
+
+// Run relays pending records every interval until the context is cancelled.
+func (r *OutboxRelay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if published, err := r.RelayOnce(ctx); err != nil {
+			slog.ErrorContext(ctx, "Outbox relay run failed", "published", published, "error", err)
+		} else if published > 0 {
+			slog.InfoContext(ctx, "Relayed outbox records", "published", published)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
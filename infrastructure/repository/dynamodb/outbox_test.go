@@ -0,0 +1,136 @@
+package dynamodb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	dynamodbRepo "github.com/develpudu/go-challenge/infrastructure/repository/dynamodb"
+)
+
+// Mock implementation of the TweetEventPublisher interface
+type MockEventPublisher struct {
+	published []*entity.Tweet
+	err       error
+}
+
+func (p *MockEventPublisher) TweetCreated(ctx context.Context, tweet *entity.Tweet) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, tweet)
+	return nil
+}
+
+// Returns a tweet repository writing to the outbox table of the given mock client
+func setupOutboxTweetRepository(client *MockDynamoDBClient) *dynamodbRepo.DynamoDBTweetRepository {
+	return dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(client, "tweets", nil, nil, dynamodbRepo.WithOutboxTable("outbox"))
+}
+
+func TestSaveWritesOutboxRecord(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := setupOutboxTweetRepository(client)
+	tweet, _ := entity.NewTweet("tweet1", "user1", "Hello")
+
+	// Act
+	err := repo.Save(tweet)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tweets := client.Items("tweets"); len(tweets) != 1 {
+		t.Errorf("Expected 1 tweet, got %d", len(tweets))
+	}
+	if records := client.Items("outbox"); len(records) != 1 {
+		t.Errorf("Expected 1 outbox record, got %d", len(records))
+	}
+	if client.Calls["TransactWriteItems"] != 1 || client.Calls["PutItem"] != 0 {
+		t.Error("Expected the tweet and outbox record to be written in a single transaction")
+	}
+}
+
+func TestSaveTransactionFailureWritesNothing(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	client.TransactErr = errors.New("transaction failed")
+	repo := setupOutboxTweetRepository(client)
+	tweet, _ := entity.NewTweet("tweet1", "user1", "Hello")
+
+	// Act
+	err := repo.Save(tweet)
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected an error when the transaction fails, got nil")
+	}
+	if len(client.Items("tweets")) != 0 || len(client.Items("outbox")) != 0 {
+		t.Error("Expected neither the tweet nor the outbox record to be stored")
+	}
+}
+
+func TestRelayPublishesAndRemovesRecords(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := setupOutboxTweetRepository(client)
+	tweet, _ := entity.NewTweet("tweet1", "user1", "Hello")
+	if err := repo.Save(tweet); err != nil {
+		t.Fatalf("Failed to save tweet: %v", err)
+	}
+	publisher := &MockEventPublisher{}
+	relay := dynamodbRepo.NewOutboxRelayWithClient(client, "outbox", publisher)
+
+	// Act
+	published, err := relay.RelayOnce(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if published != 1 || len(publisher.published) != 1 {
+		t.Fatalf("Expected 1 published event, got %d", len(publisher.published))
+	}
+	if got := publisher.published[0]; got.ID != tweet.ID || got.UserID != tweet.UserID || got.Content != tweet.Content {
+		t.Errorf("Expected the saved tweet to be published, got %+v", got)
+	}
+	if records := client.Items("outbox"); len(records) != 0 {
+		t.Errorf("Expected the outbox record to be removed after publishing, got %d", len(records))
+	}
+}
+
+func TestRelayKeepsRecordsOnPublishFailure(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := setupOutboxTweetRepository(client)
+	tweet, _ := entity.NewTweet("tweet1", "user1", "Hello")
+	if err := repo.Save(tweet); err != nil {
+		t.Fatalf("Failed to save tweet: %v", err)
+	}
+	publisher := &MockEventPublisher{err: errors.New("publish failed")}
+	relay := dynamodbRepo.NewOutboxRelayWithClient(client, "outbox", publisher)
+
+	// Act
+	published, err := relay.RelayOnce(context.Background())
+
+	// Assert
+	if err == nil {
+		t.Error("Expected the publish failure to be reported")
+	}
+	if published != 0 {
+		t.Errorf("Expected no published events, got %d", published)
+	}
+	if records := client.Items("outbox"); len(records) != 1 {
+		t.Errorf("Expected the outbox record to be kept for the next run, got %d", len(records))
+	}
+
+	// Act: the publisher recovers
+	publisher.err = nil
+	published, err = relay.RelayOnce(context.Background())
+
+	// Assert
+	if err != nil || published != 1 {
+		t.Errorf("Expected the record to be relayed on the next run, got published=%d err=%v", published, err)
+	}
+}
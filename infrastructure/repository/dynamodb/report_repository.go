@@ -0,0 +1,137 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+)
+
+// DynamoDBReportRepository implements the ReportRepository interface using AWS DynamoDB.
+// The table is keyed by TweetID (hash) and ReporterID (range), so a conditional put
+// rejects a second report of the same tweet by the same user.
+type DynamoDBReportRepository struct {
+	client    DynamoDBAPI
+	tableName string
+	opts      options
+}
+
+// dynamoDBReport is a helper struct for marshalling/unmarshalling Report data.
+type dynamoDBReport struct {
+	TweetID    string `dynamodbav:"TweetID"`
+	ReporterID string `dynamodbav:"ReporterID"`
+	Reason     string `dynamodbav:"Reason"`
+	CreatedAt  string `dynamodbav:"CreatedAt"` // Store as ISO 8601 string
+}
+
+// NewDynamoDBReportRepository creates a new DynamoDB report repository.
+func NewDynamoDBReportRepository(cfg aws.Config, tableName string, opts ...Option) *DynamoDBReportRepository {
+	return NewDynamoDBReportRepositoryWithClient(NewClient(cfg), tableName, opts...)
+}
+
+// NewDynamoDBReportRepositoryWithClient creates a new DynamoDB report repository using the given client.
+func NewDynamoDBReportRepositoryWithClient(client DynamoDBAPI, tableName string, opts ...Option) *DynamoDBReportRepository {
+	return &DynamoDBReportRepository{
+		client:    client,
+		tableName: tableName,
+		opts:      newOptions(opts),
+	}
+}
+
+// Save stores a report. It returns entity.ErrAlreadyReported if the reporter already reported the tweet.
+func (r *DynamoDBReportRepository) Save(report *entity.Report) error {
+	av, err := attributevalue.MarshalMap(dynamoDBReport{
+		TweetID:    report.TweetID,
+		ReporterID: report.ReporterID,
+		Reason:     report.Reason,
+		CreatedAt:  report.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_not_exists(ReporterID)"),
+	}
+	err = r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.PutItem(ctx, input)
+		return err
+	})
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return entity.ErrAlreadyReported
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save report of tweet %s by user %s: %w", report.TweetID, report.ReporterID, err)
+	}
+	return nil
+}
+
+// FindAll retrieves all reports, newest first.
+// WARNING: This uses Scan, which is inefficient for large tables. Consider alternatives in production.
+func (r *DynamoDBReportRepository) FindAll() ([]*entity.Report, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(r.tableName),
+	}
+
+	reports := make([]*entity.Report, 0)
+	for {
+		var result *dynamodb.ScanOutput
+		err := r.opts.call(context.Background(), func(ctx context.Context) error {
+			var err error
+			result, err = r.client.Scan(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reports: %w", err)
+		}
+
+		var items []dynamoDBReport
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal reports: %w", err)
+		}
+		for _, item := range items {
+			createdAt, err := time.Parse(time.RFC3339Nano, item.CreatedAt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse CreatedAt timestamp '%s': %w", item.CreatedAt, err)
+			}
+			reports = append(reports, &entity.Report{
+				ReporterID: item.ReporterID,
+				TweetID:    item.TweetID,
+				Reason:     item.Reason,
+				CreatedAt:  createdAt,
+			})
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+
+	// Scans return items in no particular order, so sort by report time
+	sort.Slice(reports, func(i, j int) bool {
+		if !reports[i].CreatedAt.Equal(reports[j].CreatedAt) {
+			return reports[i].CreatedAt.After(reports[j].CreatedAt)
+		}
+		if reports[i].TweetID != reports[j].TweetID {
+			return reports[i].TweetID > reports[j].TweetID
+		}
+		return reports[i].ReporterID > reports[j].ReporterID
+	})
+	return reports, nil
+}
+
+// Compile-time check to ensure DynamoDBReportRepository implements ReportRepository
+var _ repository.ReportRepository = (*DynamoDBReportRepository)(nil)
@@ -0,0 +1,39 @@
+package dynamodb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	dynamodbRepo "github.com/develpudu/go-challenge/infrastructure/repository/dynamodb"
+)
+
+func TestReportSaveRejectsDuplicates(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBReportRepositoryWithClient(client, "reports")
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	first, _ := entity.NewReport("user1", "tweet1", "Spam", base)
+	again, _ := entity.NewReport("user1", "tweet1", "Still spam", base.Add(time.Minute))
+	other, _ := entity.NewReport("user2", "tweet1", "Abuse", base.Add(2*time.Minute))
+
+	// Act
+	err1 := repo.Save(first)
+	err2 := repo.Save(again)
+	err3 := repo.Save(other)
+
+	// Assert
+	if err1 != nil || err3 != nil {
+		t.Fatalf("Expected no errors, got %v and %v", err1, err3)
+	}
+	if err2 != entity.ErrAlreadyReported {
+		t.Errorf("Expected ErrAlreadyReported, got %v", err2)
+	}
+	reports, err := repo.FindAll()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(reports) != 2 || reports[0].ReporterID != "user2" || reports[1].Reason != "Spam" {
+		t.Errorf("Expected both reports newest first with the original reason, got %+v", reports)
+	}
+}
@@ -0,0 +1,116 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Error codes returned by DynamoDB for throttled or transient failures.
+// Only these are retried; any other error is returned immediately.
+var retryableErrorCodes = map[string]bool{
+	"ProvisionedThroughputExceededException": true,
+	"ThrottlingException":                    true,
+	"RequestLimitExceeded":                   true,
+	"InternalServerError":                    true,
+	"ServiceUnavailable":                     true,
+}
+
+// Error codes among retryableErrorCodes that mean the request was throttled.
+// Once retries are exhausted they are reported as entity.ThrottledError.
+var throttlingErrorCodes = map[string]bool{
+	"ProvisionedThroughputExceededException": true,
+	"ThrottlingException":                    true,
+	"RequestLimitExceeded":                   true,
+}
+
+// isRetryable reports whether err is a throttling or transient DynamoDB error,
+// or a transaction canceled only because it conflicted with a concurrent one.
+func isRetryable(err error) bool {
+	if isTransactionConflict(err) {
+		return true
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return retryableErrorCodes[apiErr.ErrorCode()]
+	}
+	return false
+}
+
+// isThrottling reports whether err is a DynamoDB throttling error.
+func isThrottling(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return throttlingErrorCodes[apiErr.ErrorCode()]
+	}
+	return false
+}
+
+// isTransactionConflict reports whether err is a canceled transaction whose only failure is
+// another request updating one of its items at the same time. Such a transaction may
+// succeed when retried, unlike one whose conditions failed.
+func isTransactionConflict(err error) bool {
+	var canceled *types.TransactionCanceledException
+	if !errors.As(err, &canceled) {
+		return false
+	}
+	conflict := false
+	for _, reason := range canceled.CancellationReasons {
+		switch aws.ToString(reason.Code) {
+		case "TransactionConflict":
+			conflict = true
+		case "", "None":
+		default:
+			return false
+		}
+	}
+	return conflict
+}
+
+// backoff returns the delay before the given retry (1-based), using
+// exponential backoff capped at maxRetryDelay with full jitter.
+func (o options) backoff(retry int) time.Duration {
+	delay := o.retryBaseDelay << (retry - 1)
+	if delay <= 0 || delay > o.maxRetryDelay {
+		delay = o.maxRetryDelay
+	}
+	return rand.N(delay) + 1
+}
+
+// call runs op with a per-attempt timeout, retrying throttled and transient errors and
+// transaction conflicts with backoff until it succeeds or maxAttempts is reached.
+func (o options) call(ctx context.Context, op func(ctx context.Context) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		callCtx, cancel := o.callContext(ctx)
+		err = op(callCtx)
+		cancel()
+
+		if err == nil || !isRetryable(err) {
+			return wrapTimeout(err)
+		}
+		if attempt >= o.maxAttempts {
+			slog.WarnContext(ctx, "Giving up on DynamoDB call after retries", "attempts", attempt, "error", err)
+			// Callers still throttled after every retry should back off for the longest delay
+			if isThrottling(err) {
+				return &entity.ThrottledError{RetryAfter: o.maxRetryDelay, Err: err}
+			}
+			return wrapTimeout(err)
+		}
+
+		delay := o.backoff(attempt)
+		slog.WarnContext(ctx, "Retrying DynamoDB call", "attempt", attempt, "delay", delay, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return wrapTimeout(err)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package dynamodb_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/develpudu/go-challenge/domain/entity"
+	dynamodbRepo "github.com/develpudu/go-challenge/infrastructure/repository/dynamodb"
+)
+
+// Returns a throttling error as reported by DynamoDB
+func throttlingError() error {
+	return &smithy.GenericAPIError{Code: "ProvisionedThroughputExceededException", Message: "throughput exceeded"}
+}
+
+// Returns a follow transaction canceled because another request updated the follower at the same time
+func transactionConflictError() error {
+	return &types.TransactionCanceledException{
+		Message: aws.String("Transaction cancelled"),
+		CancellationReasons: []types.CancellationReason{
+			{Code: aws.String("None")},
+			{Code: aws.String("TransactionConflict")},
+			{Code: aws.String("None")},
+		},
+	}
+}
+
+// Returns a user repository that retries with short delays
+func setupRetryingUserRepository(client *MockDynamoDBClient, maxAttempts int) *dynamodbRepo.DynamoDBUserRepository {
+	return dynamodbRepo.NewDynamoDBUserRepositoryWithClient(client, "users", "follows",
+		dynamodbRepo.WithMaxAttempts(maxAttempts),
+		dynamodbRepo.WithRetryBackoff(time.Millisecond, 5*time.Millisecond),
+	)
+}
+
+func TestRetryThrottledWriteThenSucceed(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := setupRetryingUserRepository(client, 3)
+	client.FailNext("PutItem", throttlingError(), throttlingError())
+
+	// Act
+	err := repo.Save(entity.NewUser("user1", "testuser"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error after retries, got %v", err)
+	}
+
+	if client.Calls["PutItem"] != 3 {
+		t.Errorf("Expected 3 PutItem calls, got %d", client.Calls["PutItem"])
+	}
+
+	if user, _ := repo.FindByID("user1"); user == nil {
+		t.Error("Expected user to be saved after retries")
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := setupRetryingUserRepository(client, 2)
+	client.FailNext("PutItem", throttlingError(), throttlingError(), throttlingError())
+
+	// Act
+	err := repo.Save(entity.NewUser("user1", "testuser"))
+
+	// Assert
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode() != "ProvisionedThroughputExceededException" {
+		t.Errorf("Expected the throttling error once attempts run out, got %v", err)
+	}
+	var throttled *entity.ThrottledError
+	if !errors.As(err, &throttled) || throttled.RetryAfter != 5*time.Millisecond {
+		t.Errorf("Expected a throttled error suggesting the longest backoff, got %v", err)
+	}
+
+	if client.Calls["PutItem"] != 2 {
+		t.Errorf("Expected 2 PutItem calls, got %d", client.Calls["PutItem"])
+	}
+}
+
+func TestNoRetryOnNonThrottlingError(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := setupRetryingUserRepository(client, 3)
+	client.FailNext("GetItem", &smithy.GenericAPIError{Code: "ValidationException", Message: "invalid key"})
+
+	// Act
+	_, err := repo.FindByID("user1")
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+
+	if client.Calls["GetItem"] != 1 {
+		t.Errorf("Expected a single GetItem call, got %d", client.Calls["GetItem"])
+	}
+}
+
+func TestRetryTransactionConflictThenSucceed(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := setupRetryingUserRepository(client, 3)
+	repo.Save(entity.NewUser("alice", "alice"))
+	repo.Save(entity.NewUser("bob", "bob"))
+	client.FailNext("TransactWriteItems", transactionConflictError())
+	calls := client.Calls["TransactWriteItems"]
+
+	// Act
+	err := repo.Follow("alice", "bob")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error after retrying the conflict, got %v", err)
+	}
+	if client.Calls["TransactWriteItems"]-calls != 2 {
+		t.Errorf("Expected 2 TransactWriteItems calls, got %d", client.Calls["TransactWriteItems"]-calls)
+	}
+	if alice, _ := repo.FindByID("alice"); alice == nil || !alice.IsFollowing("bob") {
+		t.Error("Expected the follow to be stored after retrying")
+	}
+}
+
+func TestRepeatedTransactionConflictsReportConcurrentUpdate(t *testing.T) {
+	// Arrange: every attempt conflicts
+	client := NewMockDynamoDBClient()
+	repo := setupRetryingUserRepository(client, 3)
+	repo.Save(entity.NewUser("alice", "alice"))
+	repo.Save(entity.NewUser("bob", "bob"))
+	client.FailNext("TransactWriteItems", transactionConflictError(), transactionConflictError(), transactionConflictError(), transactionConflictError())
+	calls := client.Calls["TransactWriteItems"]
+
+	// Act
+	err := repo.Follow("alice", "bob")
+
+	// Assert: the retries are bounded by the maximum attempts
+	if err != entity.ErrConcurrentUpdate {
+		t.Errorf("Expected ErrConcurrentUpdate, got %v", err)
+	}
+	if client.Calls["TransactWriteItems"]-calls != 3 {
+		t.Errorf("Expected 3 TransactWriteItems calls, got %d", client.Calls["TransactWriteItems"]-calls)
+	}
+}
@@ -0,0 +1,326 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+)
+
+const (
+	// BatchWriteItem accepts at most 25 requests per call
+	maxBatchWriteItems = 25
+	// BatchGetItem accepts at most 100 keys per call
+	maxBatchGetTweets = 100
+)
+
+// dynamoDBTimelineEntry is a tweet ID materialized in a user's timeline.
+// The table is keyed by UserID and TweetID; AuthorID lets reads drop the
+// tweets of users that were unfollowed since the entry was written, and
+// lets unfollows remove them.
+type dynamoDBTimelineEntry struct {
+	UserID    string `dynamodbav:"UserID"`
+	TweetID   string `dynamodbav:"TweetID"`
+	AuthorID  string `dynamodbav:"AuthorID"`
+	CreatedAt string `dynamodbav:"CreatedAt"`
+	ExpiresAt int64  `dynamodbav:"ExpiresAt,omitempty"` // Epoch seconds of the tweet's expiration, the table's TTL attribute
+}
+
+// newTimelineEntry returns the entry placing tweet in the timeline of userID.
+// Entries of expiring tweets expire with them.
+func newTimelineEntry(userID string, tweet *entity.Tweet) dynamoDBTimelineEntry {
+	entry := dynamoDBTimelineEntry{
+		UserID:    userID,
+		TweetID:   tweet.ID,
+		AuthorID:  tweet.UserID,
+		CreatedAt: tweet.CreatedAt.Format(time.RFC3339Nano),
+	}
+	if !tweet.ExpiresAt.IsZero() {
+		entry.ExpiresAt = tweet.ExpiresAt.Unix()
+	}
+	return entry
+}
+
+// materializeTweets fans the tweets out to the materialized timelines of their
+// authors and of everyone following them.
+func (r *DynamoDBTweetRepository) materializeTweets(ctx context.Context, tweets []*entity.Tweet) error {
+	if r.userRepo == nil {
+		return fmt.Errorf("userRepository is nil, cannot materialize timelines")
+	}
+
+	// Followers are looked up once per author
+	readers := make(map[string][]string)
+	var entries []dynamoDBTimelineEntry
+	for _, tweet := range tweets {
+		userIDs, ok := readers[tweet.UserID]
+		if !ok {
+			followers, err := r.userRepo.FindFollowers(tweet.UserID)
+			if err != nil {
+				return fmt.Errorf("failed to find followers of %s to materialize timelines: %w", tweet.UserID, err)
+			}
+			userIDs = append(userIDs, tweet.UserID)
+			for _, follower := range followers {
+				userIDs = append(userIDs, follower.ID)
+			}
+			readers[tweet.UserID] = userIDs
+		}
+		for _, userID := range userIDs {
+			entries = append(entries, newTimelineEntry(userID, tweet))
+		}
+	}
+	return r.writeTimelineEntries(ctx, entries)
+}
+
+// backfillTimeline adds every tweet of followedID to the materialized timeline
+// of followerID, so a new follow shows past tweets like the query-based timeline does.
+func (r *DynamoDBTweetRepository) backfillTimeline(ctx context.Context, followerID, followedID string) error {
+	tweets, err := r.queryTweetsByUserIDWithContext(ctx, followedID)
+	if err != nil {
+		return err
+	}
+	entries := make([]dynamoDBTimelineEntry, len(tweets))
+	for i, tweet := range tweets {
+		entries[i] = newTimelineEntry(followerID, tweet)
+	}
+	return r.writeTimelineEntries(ctx, entries)
+}
+
+// unmaterializeTweet removes a deleted tweet from the materialized timelines of
+// its author and of everyone following them.
+func (r *DynamoDBTweetRepository) unmaterializeTweet(ctx context.Context, tweet *entity.Tweet) error {
+	readers := r.timelineReaders(ctx, tweet.UserID)
+	entries := make([]dynamoDBTimelineEntry, len(readers))
+	for i, userID := range readers {
+		entries[i] = newTimelineEntry(userID, tweet)
+	}
+	return r.deleteTimelineEntries(ctx, entries)
+}
+
+// removeAuthorFromTimeline removes the tweets of authorID from the materialized
+// timeline of userID, as after an unfollow.
+func (r *DynamoDBTweetRepository) removeAuthorFromTimeline(ctx context.Context, userID, authorID string) error {
+	entries, err := r.queryTimelineEntries(ctx, userID)
+	if err != nil {
+		return err
+	}
+	authored := entries[:0]
+	for _, entry := range entries {
+		if entry.AuthorID == authorID {
+			authored = append(authored, entry)
+		}
+	}
+	return r.deleteTimelineEntries(ctx, authored)
+}
+
+// writeTimelineEntries stores timeline entries in chunks of maxBatchWriteItems.
+func (r *DynamoDBTweetRepository) writeTimelineEntries(ctx context.Context, entries []dynamoDBTimelineEntry) error {
+	requests := make([]types.WriteRequest, 0, len(entries))
+	for _, entry := range entries {
+		item, err := attributevalue.MarshalMap(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal timeline entry: %w", err)
+		}
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+	return r.batchWriteTimeline(ctx, requests)
+}
+
+// deleteTimelineEntries removes timeline entries in chunks of maxBatchWriteItems.
+// Entries that are not stored are ignored.
+func (r *DynamoDBTweetRepository) deleteTimelineEntries(ctx context.Context, entries []dynamoDBTimelineEntry) error {
+	requests := make([]types.WriteRequest, len(entries))
+	for i, entry := range entries {
+		requests[i] = types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: map[string]types.AttributeValue{
+			"UserID":  &types.AttributeValueMemberS{Value: entry.UserID},
+			"TweetID": &types.AttributeValueMemberS{Value: entry.TweetID},
+		}}}
+	}
+	return r.batchWriteTimeline(ctx, requests)
+}
+
+// batchWriteTimeline applies write requests to the timeline table in chunks of
+// maxBatchWriteItems, retrying the items DynamoDB leaves unprocessed.
+func (r *DynamoDBTweetRepository) batchWriteTimeline(ctx context.Context, requests []types.WriteRequest) error {
+	for start := 0; start < len(requests); start += maxBatchWriteItems {
+		end := min(start+maxBatchWriteItems, len(requests))
+		input := &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{r.opts.timelineTable: requests[start:end]},
+		}
+		for len(input.RequestItems) > 0 {
+			var result *dynamodb.BatchWriteItemOutput
+			err := r.opts.call(ctx, func(ctx context.Context) (err error) {
+				result, err = r.client.BatchWriteItem(ctx, input)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to write timeline entries to DynamoDB: %w", err)
+			}
+
+			// Retry items DynamoDB could not process in this call
+			input = &dynamodb.BatchWriteItemInput{RequestItems: result.UnprocessedItems}
+		}
+	}
+	return nil
+}
+
+// queryTimelineEntries returns every entry of the materialized timeline of userID.
+func (r *DynamoDBTweetRepository) queryTimelineEntries(ctx context.Context, userID string) ([]dynamoDBTimelineEntry, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.opts.timelineTable),
+		KeyConditionExpression: aws.String("UserID = :userID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":userID": &types.AttributeValueMemberS{Value: userID},
+		},
+	}
+	paginator := dynamodb.NewQueryPaginator(r.client, input)
+
+	var entries []dynamoDBTimelineEntry
+	for paginator.HasMorePages() {
+		var page *dynamodb.QueryOutput
+		err := r.opts.call(ctx, func(ctx context.Context) (err error) {
+			page, err = paginator.NextPage(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query materialized timeline of user %s: %w", userID, err)
+		}
+
+		var pageEntries []dynamoDBTimelineEntry
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &pageEntries); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal materialized timeline of user %s: %w", userID, err)
+		}
+		entries = append(entries, pageEntries...)
+	}
+	return entries, nil
+}
+
+// readMaterializedTimeline builds the timeline from the user's materialized
+// tweet IDs, reading the tweets with BatchGetItem. Entries of users no longer
+// followed, and of the user unless includeSelf is set, are skipped; deleted
+// tweets are missing from the batch reads and so left out. Tweets are returned newest first.
+func (r *DynamoDBTweetRepository) readMaterializedTimeline(ctx context.Context, user *entity.User, includeSelf bool) ([]*entity.Tweet, error) {
+	entries, err := r.queryTimelineEntries(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tweetIDs []string
+	for _, entry := range entries {
+		if entry.AuthorID == user.ID && !includeSelf {
+			continue
+		}
+		if entry.AuthorID != user.ID && !user.IsFollowing(entry.AuthorID) {
+			continue
+		}
+		tweetIDs = append(tweetIDs, entry.TweetID)
+	}
+
+	tweets, err := r.batchGetTweets(ctx, tweetIDs)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(tweets, func(i, j int) bool {
+		return tweets[i].IsNewerThan(tweets[j])
+	})
+	slog.DebugContext(ctx, "Successfully read materialized timeline", "userID", user.ID, "tweetCount", len(tweets))
+	return tweets, nil
+}
+
+// batchGetTweets retrieves the tweets with the given IDs in chunks of
+// maxBatchGetTweets, in no particular order and skipping missing tweets.
+func (r *DynamoDBTweetRepository) batchGetTweets(ctx context.Context, ids []string) ([]*entity.Tweet, error) {
+	tweets := make([]*entity.Tweet, 0, len(ids))
+	for start := 0; start < len(ids); start += maxBatchGetTweets {
+		end := min(start+maxBatchGetTweets, len(ids))
+		keys := make([]map[string]types.AttributeValue, 0, end-start)
+		for _, id := range ids[start:end] {
+			keys = append(keys, map[string]types.AttributeValue{"ID": &types.AttributeValueMemberS{Value: id}})
+		}
+
+		input := &dynamodb.BatchGetItemInput{
+			RequestItems: map[string]types.KeysAndAttributes{
+				r.tableName: {Keys: keys},
+			},
+		}
+		for len(input.RequestItems) > 0 {
+			var result *dynamodb.BatchGetItemOutput
+			err := r.opts.call(ctx, func(ctx context.Context) (err error) {
+				result, err = r.client.BatchGetItem(ctx, input)
+				return err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to batch get tweets from DynamoDB: %w", err)
+			}
+
+			var ddbTweets []dynamoDBTweet
+			if err := attributevalue.UnmarshalListOfMaps(result.Responses[r.tableName], &ddbTweets); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal tweets from DynamoDB: %w", err)
+			}
+			for i := range ddbTweets {
+				tweet, err := fromDynamoDBTweet(&ddbTweets[i])
+				if err != nil {
+					slog.WarnContext(ctx, "Failed to convert tweet from DynamoDB format during batch get", "tweetID", ddbTweets[i].ID, "error", err)
+					continue
+				}
+				tweets = append(tweets, tweet)
+			}
+
+			// Retry keys DynamoDB could not process in this call
+			input = &dynamodb.BatchGetItemInput{RequestItems: result.UnprocessedKeys}
+		}
+	}
+	return tweets, nil
+}
+
+// materializingUserRepository backfills the materialized timeline of a user
+// each time they follow someone, and prunes it each time they unfollow someone.
+type materializingUserRepository struct {
+	repository.UserRepository
+	tweets *DynamoDBTweetRepository
+}
+
+// MaterializeFollows returns a user repository that, after each follow stored in
+// users, adds the followed user's tweets to the follower's materialized timeline,
+// and removes them again after each unfollow.
+// It returns users unchanged when the materialized timeline is disabled.
+func (r *DynamoDBTweetRepository) MaterializeFollows(users repository.UserRepository) repository.UserRepository {
+	if r.opts.timelineTable == "" {
+		return users
+	}
+	return &materializingUserRepository{UserRepository: users, tweets: r}
+}
+
+// Follow stores the follow, then backfills the follower's timeline. A failed
+// backfill is logged rather than returned, since the follow itself was stored.
+func (r *materializingUserRepository) Follow(followerID, followedID string) error {
+	if err := r.UserRepository.Follow(followerID, followedID); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if err := r.tweets.backfillTimeline(ctx, followerID, followedID); err != nil {
+		slog.WarnContext(ctx, "Failed to backfill materialized timeline after follow", "followerID", followerID, "followedID", followedID, "error", err)
+	}
+	return nil
+}
+
+// Unfollow removes the follow, then the unfollowed user's tweets from the follower's
+// timeline. A failed removal is logged rather than returned, since the follow itself
+// was removed and reads skip the tweets of users no longer followed.
+func (r *materializingUserRepository) Unfollow(followerID, followedID string) error {
+	if err := r.UserRepository.Unfollow(followerID, followedID); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if err := r.tweets.removeAuthorFromTimeline(ctx, followerID, followedID); err != nil {
+		slog.WarnContext(ctx, "Failed to prune materialized timeline after unfollow", "followerID", followerID, "followedID", followedID, "error", err)
+	}
+	return nil
+}
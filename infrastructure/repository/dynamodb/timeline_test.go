@@ -0,0 +1,176 @@
+package dynamodb_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+	dynamodbRepo "github.com/develpudu/go-challenge/infrastructure/repository/dynamodb"
+	"github.com/develpudu/go-challenge/infrastructure/repository/memory"
+)
+
+// Tweet and user repositories sharing a mock client, built with the given options
+type timelineFixture struct {
+	tweets *dynamodbRepo.DynamoDBTweetRepository
+	users  repository.UserRepository
+	client *MockDynamoDBClient
+}
+
+func setupTimelineFixture(t *testing.T, opts ...dynamodbRepo.Option) timelineFixture {
+	client := NewMockDynamoDBClient()
+	userRepo := memory.NewUserRepository()
+	for _, id := range []string{"alice", "bob", "carol"} {
+		if err := userRepo.Save(entity.NewUser(id, id)); err != nil {
+			t.Fatalf("Failed to save user %s: %v", id, err)
+		}
+	}
+	tweets := dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(client, "tweets", userRepo, nil, opts...)
+	return timelineFixture{tweets: tweets, users: tweets.MaterializeFollows(userRepo), client: client}
+}
+
+// Builds the same history in the fixture: alice follows bob before he tweets more
+// than a BatchGetItem call can read, and carol only after she has tweeted
+func (f timelineFixture) build(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := f.users.Follow("alice", "bob"); err != nil {
+		t.Fatalf("Failed to follow bob: %v", err)
+	}
+	var bobTweets []*entity.Tweet
+	for i := range 120 {
+		tweet, _ := entity.NewTweetAt(fmt.Sprintf("bob-%03d", i), "bob", "Hello", base.Add(time.Duration(i)*time.Minute))
+		bobTweets = append(bobTweets, tweet)
+	}
+	if err := f.tweets.SaveAll(bobTweets); err != nil {
+		t.Fatalf("Failed to save bob's tweets: %v", err)
+	}
+	for i := range 3 {
+		// carol's tweets share timestamps with bob's to exercise the tie-break
+		tweet, _ := entity.NewTweetAt(fmt.Sprintf("carol-%d", i), "carol", "Hi", base.Add(time.Duration(i)*time.Minute))
+		if err := f.tweets.Save(tweet); err != nil {
+			t.Fatalf("Failed to save carol's tweet: %v", err)
+		}
+	}
+	own, _ := entity.NewTweetAt("alice-0", "alice", "Mine", base.Add(3*time.Hour))
+	if err := f.tweets.Save(own); err != nil {
+		t.Fatalf("Failed to save alice's tweet: %v", err)
+	}
+	if err := f.users.Follow("alice", "carol"); err != nil {
+		t.Fatalf("Failed to follow carol: %v", err)
+	}
+}
+
+// Returns the IDs of alice's timeline with the given options
+func (f timelineFixture) timelineIDs(t *testing.T, opts repository.TimelineOptions) []string {
+	timeline, err := f.tweets.GetTimeline(context.Background(), "alice", opts)
+	if err != nil {
+		t.Fatalf("Failed to get timeline: %v", err)
+	}
+	ids := make([]string, len(timeline))
+	for i, tweet := range timeline {
+		ids[i] = tweet.ID
+	}
+	return ids
+}
+
+// Fails unless both modes return the same timeline for every variant
+func assertSameTimelines(t *testing.T, queried, materialized timelineFixture) {
+	t.Helper()
+	for _, opts := range []repository.TimelineOptions{{IncludeSelf: true}, {IncludeSelf: false}} {
+		want := queried.timelineIDs(t, opts)
+		got := materialized.timelineIDs(t, opts)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("Expected the materialized timeline to match the queried one with %+v\nwant %v\ngot  %v", opts, want, got)
+		}
+	}
+}
+
+func TestMaterializedTimelineMatchesQueriedTimeline(t *testing.T) {
+	// Arrange
+	queried := setupTimelineFixture(t)
+	materialized := setupTimelineFixture(t, dynamodbRepo.WithTimelineTable("timelines"))
+	queried.build(t)
+	materialized.build(t)
+
+	// Act & Assert
+	assertSameTimelines(t, queried, materialized)
+	if queried.timelineIDs(t, repository.TimelineOptions{IncludeSelf: true})[0] != "alice-0" {
+		t.Error("Expected alice's own tweet to lead the timeline")
+	}
+	if materialized.client.Calls["BatchGetItem"] < 2 {
+		t.Errorf("Expected the materialized timeline to be read in several BatchGetItem calls, got %d", materialized.client.Calls["BatchGetItem"])
+	}
+}
+
+func TestMaterializedTimelineFollowsUnfollowsAndDeletes(t *testing.T) {
+	// Arrange
+	queried := setupTimelineFixture(t)
+	materialized := setupTimelineFixture(t, dynamodbRepo.WithTimelineTable("timelines"))
+	for _, f := range []timelineFixture{queried, materialized} {
+		f.build(t)
+
+		// Act
+		if err := f.users.Unfollow("alice", "carol"); err != nil {
+			t.Fatalf("Failed to unfollow carol: %v", err)
+		}
+		if err := f.tweets.Delete("bob-119"); err != nil {
+			t.Fatalf("Failed to delete tweet: %v", err)
+		}
+	}
+
+	// Assert
+	assertSameTimelines(t, queried, materialized)
+	ids := materialized.timelineIDs(t, repository.TimelineOptions{IncludeSelf: false})
+	if len(ids) != 119 || ids[0] != "bob-118" {
+		t.Errorf("Expected bob's 119 remaining tweets only, got %d starting with %v", len(ids), ids[:1])
+	}
+	for _, item := range materialized.client.Items("timelines") {
+		author := item["AuthorID"].(*types.AttributeValueMemberS).Value
+		tweetID := item["TweetID"].(*types.AttributeValueMemberS).Value
+		if tweetID == "bob-119" {
+			t.Errorf("Expected the deleted tweet to be removed from every timeline, found it in %v", item["UserID"])
+		}
+		if item["UserID"].(*types.AttributeValueMemberS).Value == "alice" && author == "carol" {
+			t.Errorf("Expected carol's tweets to be removed from alice's timeline, found %s", tweetID)
+		}
+	}
+}
+
+func TestMaterializedTimelineEntriesExpireWithTheirTweet(t *testing.T) {
+	// Arrange
+	f := setupTimelineFixture(t, dynamodbRepo.WithTimelineTable("timelines"))
+	if err := f.users.Follow("alice", "bob"); err != nil {
+		t.Fatalf("Failed to follow bob: %v", err)
+	}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	lasting, _ := entity.NewTweetAt("bob-lasting", "bob", "Hello", now)
+	ephemeral, _ := entity.NewTweetAt("bob-ephemeral", "bob", "Bye", now)
+	ephemeral.ExpiresAt = now.Add(time.Hour)
+
+	// Act
+	if err := f.tweets.SaveAll([]*entity.Tweet{lasting, ephemeral}); err != nil {
+		t.Fatalf("Failed to save tweets: %v", err)
+	}
+
+	// Assert
+	entries := f.client.Items("timelines")
+	if len(entries) != 4 {
+		t.Fatalf("Expected both tweets in the timelines of bob and alice, got %d entries", len(entries))
+	}
+	for _, item := range entries {
+		expiresAt, stored := item["ExpiresAt"].(*types.AttributeValueMemberN)
+		switch item["TweetID"].(*types.AttributeValueMemberS).Value {
+		case "bob-ephemeral":
+			if !stored || expiresAt.Value != fmt.Sprint(ephemeral.ExpiresAt.Unix()) {
+				t.Errorf("Expected the entry to expire with the tweet at %d, got %v", ephemeral.ExpiresAt.Unix(), item["ExpiresAt"])
+			}
+		case "bob-lasting":
+			if stored {
+				t.Errorf("Expected no expiration on the entry of a lasting tweet, got %s", expiresAt.Value)
+			}
+		}
+	}
+}
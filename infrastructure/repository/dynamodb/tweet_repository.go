@@ -2,9 +2,11 @@ package dynamodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -15,50 +17,131 @@ import (
 	"github.com/develpudu/go-challenge/domain/entity"
 	"github.com/develpudu/go-challenge/domain/repository"
 	"github.com/develpudu/go-challenge/infrastructure/cache"
+	"github.com/develpudu/go-challenge/infrastructure/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 )
 
+// Name of the tracer used for repository spans
+const tracerName = "github.com/develpudu/go-challenge/infrastructure/repository/dynamodb"
+
 const (
-	// Assumed name for the GSI on UserID. Must match the IaC template.
+	// Assumed name for the GSI on UserID (hash) and ID (range). Must match the IaC template.
 	userIDIndexName = "UserIDIndex"
+	// Name of the GSI on ConversationID (hash) and CreatedAt (range). Must match the IaC template.
+	conversationIDIndexName = "ConversationIDIndex"
+	// Name of the GSI on CreatedDay (hash) and CreatedAtEpoch (range). Must match the IaC template.
+	createdDayIndexName = "CreatedDayIndex"
+	// Name of the GSI on UserID (hash) and CreatedAtEpoch (range), projecting CreatedAt. Must match the IaC template.
+	userCreatedIndexName = "UserCreatedIndex"
+)
+
+const (
+	// Layout of CreatedDay, the UTC calendar day a tweet was created on
+	createdDayLayout = "2006-01-02"
+	// Maximum number of day partitions of the CreatedDayIndex queried at once
+	maxConcurrentDayQueries = 8
+)
+
+const (
+	// Time after which a timeline rebuild lock expires, well above the time a rebuild takes,
+	// so an instance that dies while rebuilding never blocks the timeline for longer
+	timelineLockTTL = 10 * time.Second
+	// Interval at which instances waiting on a rebuild lock look for the rebuilt timeline
+	timelineLockPollInterval = 25 * time.Millisecond
+	// Deadline for rebuilding a stale timeline in the background
+	timelineRevalidateTimeout = 5 * time.Second
 )
 
 // DynamoDBTweetRepository implements the TweetRepository interface using AWS DynamoDB.
 type DynamoDBTweetRepository struct {
-	client    *dynamodb.Client
+	client    DynamoDBAPI
 	tableName string
 	userRepo  repository.UserRepository // Needed for GetTimeline
 	cache     cache.TimelineCache       // Added cache field
+	opts      options
+	// Cache keys of the stale timelines being rebuilt in the background
+	revalidating sync.Map
 }
 
 // dynamoDBTweet is a helper struct for marshalling/unmarshalling Tweet data.
 type dynamoDBTweet struct {
-	ID        string `dynamodbav:"ID"`
-	UserID    string `dynamodbav:"UserID"`
-	Content   string `dynamodbav:"Content"`
-	CreatedAt string `dynamodbav:"CreatedAt"` // Store as ISO 8601 string
+	ID             string `dynamodbav:"ID"`
+	UserID         string `dynamodbav:"UserID"`
+	Content        string `dynamodbav:"Content"`
+	CreatedAt      string `dynamodbav:"CreatedAt"` // Store as ISO 8601 string
+	UpdatedAt      string `dynamodbav:"UpdatedAt,omitempty"`
+	InReplyToID    string `dynamodbav:"InReplyToID,omitempty"`
+	ConversationID string `dynamodbav:"ConversationID,omitempty"`
+	QuotedTweetID  string `dynamodbav:"QuotedTweetID,omitempty"`
+	ExpiresAt      int64  `dynamodbav:"ExpiresAt,omitempty"` // Epoch seconds, the table's TTL attribute
+	Lang           string `dynamodbav:"Lang,omitempty"`
+	// Keys of the CreatedDayIndex: the UTC day of CreatedAt and CreatedAt in epoch seconds
+	CreatedDay     string `dynamodbav:"CreatedDay,omitempty"`
+	CreatedAtEpoch int64  `dynamodbav:"CreatedAtEpoch,omitempty"`
+	// Stored as a map, absent until the preview is fetched
+	LinkPreview *dynamoDBLinkPreview `dynamodbav:"LinkPreview,omitempty"`
+	// Impressions flushed from the impression counter, added with UpdateItem
+	Impressions int64 `dynamodbav:"Impressions,omitempty"`
+}
+
+// dynamoDBLinkPreview is the DynamoDB representation of the link preview of a tweet.
+type dynamoDBLinkPreview struct {
+	URL         string `dynamodbav:"URL"`
+	Title       string `dynamodbav:"Title,omitempty"`
+	Description string `dynamodbav:"Description,omitempty"`
+	ImageURL    string `dynamodbav:"ImageURL,omitempty"`
 }
 
 // NewDynamoDBTweetRepository creates a new DynamoDB tweet repository.
 // It now accepts a TimelineCache instance.
-func NewDynamoDBTweetRepository(cfg aws.Config, tableName string, userRepo repository.UserRepository, timelineCache cache.TimelineCache) *DynamoDBTweetRepository {
-	client := dynamodb.NewFromConfig(cfg)
+func NewDynamoDBTweetRepository(cfg aws.Config, tableName string, userRepo repository.UserRepository, timelineCache cache.TimelineCache, opts ...Option) *DynamoDBTweetRepository {
+	return NewDynamoDBTweetRepositoryWithClient(NewClient(cfg), tableName, userRepo, timelineCache, opts...)
+}
+
+// NewDynamoDBTweetRepositoryWithClient creates a new DynamoDB tweet repository using the given client.
+func NewDynamoDBTweetRepositoryWithClient(client DynamoDBAPI, tableName string, userRepo repository.UserRepository, timelineCache cache.TimelineCache, opts ...Option) *DynamoDBTweetRepository {
 	return &DynamoDBTweetRepository{
 		client:    client,
 		tableName: tableName,
 		userRepo:  userRepo,
 		cache:     timelineCache, // Store the cache instance
+		opts:      newOptions(opts),
 	}
 }
 
 // toDynamoDBTweet converts an entity.Tweet to its DynamoDB representation.
 func toDynamoDBTweet(tweet *entity.Tweet) (*dynamoDBTweet, error) {
-	return &dynamoDBTweet{
-		ID:        tweet.ID,
-		UserID:    tweet.UserID,
-		Content:   tweet.Content,
-		CreatedAt: tweet.CreatedAt.Format(time.RFC3339Nano),
-	}, nil
+	ddbTweet := &dynamoDBTweet{
+		ID:             tweet.ID,
+		UserID:         tweet.UserID,
+		Content:        tweet.Content,
+		CreatedAt:      tweet.CreatedAt.Format(time.RFC3339Nano),
+		InReplyToID:    tweet.InReplyToID,
+		ConversationID: tweet.ConversationID,
+		QuotedTweetID:  tweet.QuotedTweetID,
+		Lang:           tweet.Lang,
+		CreatedDay:     tweet.CreatedAt.UTC().Format(createdDayLayout),
+		CreatedAtEpoch: tweet.CreatedAt.Unix(),
+		Impressions:    tweet.Impressions,
+	}
+	if !tweet.UpdatedAt.IsZero() {
+		ddbTweet.UpdatedAt = tweet.UpdatedAt.Format(time.RFC3339Nano)
+	}
+	if !tweet.ExpiresAt.IsZero() {
+		ddbTweet.ExpiresAt = tweet.ExpiresAt.Unix()
+	}
+	if preview := tweet.LinkPreview; preview != nil {
+		ddbTweet.LinkPreview = &dynamoDBLinkPreview{
+			URL:         preview.URL,
+			Title:       preview.Title,
+			Description: preview.Description,
+			ImageURL:    preview.ImageURL,
+		}
+	}
+	return ddbTweet, nil
 }
 
 // fromDynamoDBTweet converts a DynamoDB item representation to an entity.Tweet.
@@ -67,18 +150,106 @@ func fromDynamoDBTweet(ddbTweet *dynamoDBTweet) (*entity.Tweet, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse CreatedAt timestamp '%s': %w", ddbTweet.CreatedAt, err)
 	}
-	return &entity.Tweet{
-		ID:        ddbTweet.ID,
-		UserID:    ddbTweet.UserID,
-		Content:   ddbTweet.Content,
-		CreatedAt: createdAt,
-	}, nil
+	tweet := &entity.Tweet{
+		ID:             ddbTweet.ID,
+		UserID:         ddbTweet.UserID,
+		Content:        ddbTweet.Content,
+		CreatedAt:      createdAt,
+		InReplyToID:    ddbTweet.InReplyToID,
+		ConversationID: ddbTweet.ConversationID,
+		QuotedTweetID:  ddbTweet.QuotedTweetID,
+		Lang:           ddbTweet.Lang,
+		Impressions:    ddbTweet.Impressions,
+	}
+	// Tweets stored before edits were tracked were never changed after creation
+	tweet.UpdatedAt = createdAt
+	if ddbTweet.UpdatedAt != "" {
+		updatedAt, err := time.Parse(time.RFC3339Nano, ddbTweet.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse UpdatedAt timestamp '%s': %w", ddbTweet.UpdatedAt, err)
+		}
+		tweet.UpdatedAt = updatedAt
+	}
+	if ddbTweet.ExpiresAt != 0 {
+		tweet.ExpiresAt = time.Unix(ddbTweet.ExpiresAt, 0).UTC()
+	}
+	if preview := ddbTweet.LinkPreview; preview != nil {
+		tweet.LinkPreview = &entity.LinkPreview{
+			URL:         preview.URL,
+			Title:       preview.Title,
+			Description: preview.Description,
+			ImageURL:    preview.ImageURL,
+		}
+	}
+	return tweet, nil
 }
 
 // Save stores a tweet in the DynamoDB table.
-// It also invalidates the author's timeline cache.
+// It also invalidates the timeline caches of the author and their followers.
 func (r *DynamoDBTweetRepository) Save(tweet *entity.Tweet) error {
 	ctx := context.Background() // Use a background context for now
+	if err := r.putTweet(ctx, tweet); err != nil {
+		return err
+	}
+
+	// Invalidate timeline cache for the author and their followers
+	if r.cache != nil {
+		if err := r.invalidateTimelines(ctx, r.timelineReaders(ctx, tweet.UserID)...); err != nil {
+			slog.WarnContext(ctx, "Failed to invalidate timeline cache after saving tweet", "userID", tweet.UserID, "tweetID", tweet.ID, "error", err)
+		}
+	} else {
+		slog.WarnContext(ctx, "Timeline cache is nil, skipping invalidation on Save")
+	}
+
+	// Fan the tweet out to the materialized timelines, when enabled
+	if r.opts.timelineTable != "" {
+		if err := r.materializeTweets(ctx, []*entity.Tweet{tweet}); err != nil {
+			slog.WarnContext(ctx, "Failed to materialize tweet in timelines", "userID", tweet.UserID, "tweetID", tweet.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// SaveAll stores several tweets in the DynamoDB table, then invalidates the
+// timeline caches of the authors and their followers once rather than once per tweet.
+// Tweets stored before a failure are kept.
+func (r *DynamoDBTweetRepository) SaveAll(tweets []*entity.Tweet) error {
+	ctx := context.Background() // Use a background context for now
+	authors := make(map[string]bool)
+	var saved []*entity.Tweet
+	var saveErr error
+	for _, tweet := range tweets {
+		if saveErr = r.putTweet(ctx, tweet); saveErr != nil {
+			break
+		}
+		authors[tweet.UserID] = true
+		saved = append(saved, tweet)
+	}
+
+	// Invalidate timeline cache for the authors of the stored tweets and their followers
+	if r.cache != nil && len(authors) > 0 {
+		authorIDs := make([]string, 0, len(authors))
+		for userID := range authors {
+			authorIDs = append(authorIDs, userID)
+		}
+		if err := r.invalidateTimelines(ctx, r.timelineReaders(ctx, authorIDs...)...); err != nil {
+			slog.WarnContext(ctx, "Failed to invalidate timeline cache after saving tweets", "authors", len(authorIDs), "error", err)
+		}
+	}
+
+	// Fan the stored tweets out to the materialized timelines, when enabled
+	if r.opts.timelineTable != "" && len(saved) > 0 {
+		if err := r.materializeTweets(ctx, saved); err != nil {
+			slog.WarnContext(ctx, "Failed to materialize tweets in timelines", "count", len(saved), "error", err)
+		}
+	}
+
+	return saveErr
+}
+
+// putTweet writes a tweet, together with its outbox record when the outbox is enabled.
+func (r *DynamoDBTweetRepository) putTweet(ctx context.Context, tweet *entity.Tweet) error {
 	ddbTweet, err := toDynamoDBTweet(tweet)
 	if err != nil {
 		return fmt.Errorf("failed to convert tweet to DynamoDB format: %w", err)
@@ -89,31 +260,88 @@ func (r *DynamoDBTweetRepository) Save(tweet *entity.Tweet) error {
 		return fmt.Errorf("failed to marshal tweet to attribute values: %w", err)
 	}
 
-	input := &dynamodb.PutItemInput{
-		TableName: aws.String(r.tableName),
-		Item:      av,
+	if r.opts.outboxTable != "" {
+		err = r.saveWithOutbox(ctx, ddbTweet, av)
+	} else {
+		input := &dynamodb.PutItemInput{
+			TableName: aws.String(r.tableName),
+			Item:      av,
+		}
+		err = r.opts.call(ctx, func(ctx context.Context) error {
+			_, err := r.client.PutItem(ctx, input)
+			return err
+		})
 	}
-
-	_, err = r.client.PutItem(ctx, input)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to save tweet to DynamoDB", "tweetID", tweet.ID, "userID", tweet.UserID, "error", err)
 		return fmt.Errorf("failed to save tweet to DynamoDB: %w", err)
 	}
+	return nil
+}
+
+// Update replaces an existing tweet in the DynamoDB table without publishing an event.
+// It returns entity.ErrTweetNotFound if the tweet does not exist and invalidates the author's timeline cache.
+func (r *DynamoDBTweetRepository) Update(tweet *entity.Tweet) error {
+	ctx := context.Background() // Use a background context for now
+	ddbTweet, err := toDynamoDBTweet(tweet)
+	if err != nil {
+		return fmt.Errorf("failed to convert tweet to DynamoDB format: %w", err)
+	}
 
-	// Invalidate timeline cache for the author
+	av, err := attributevalue.MarshalMap(ddbTweet)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tweet to attribute values: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_exists(ID)"),
+	}
+	err = r.opts.call(ctx, func(ctx context.Context) error {
+		_, err := r.client.PutItem(ctx, input)
+		return err
+	})
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return entity.ErrTweetNotFound
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to update tweet in DynamoDB", "tweetID", tweet.ID, "userID", tweet.UserID, "error", err)
+		return fmt.Errorf("failed to update tweet in DynamoDB: %w", err)
+	}
+
+	// Invalidate timeline cache for the author and their followers
 	if r.cache != nil {
-		if err := r.cache.InvalidateTimeline(ctx, tweet.UserID); err != nil {
-			slog.WarnContext(ctx, "Failed to invalidate timeline cache after saving tweet", "userID", tweet.UserID, "tweetID", tweet.ID, "error", err)
+		if err := r.invalidateTimelines(ctx, r.timelineReaders(ctx, tweet.UserID)...); err != nil {
+			slog.WarnContext(ctx, "Failed to invalidate timeline cache after updating tweet", "userID", tweet.UserID, "tweetID", tweet.ID, "error", err)
 		}
-	} else {
-		slog.WarnContext(ctx, "Timeline cache is nil, skipping invalidation on Save")
 	}
 
-	// TODO: Implement more robust invalidation for followers' timelines
-
 	return nil
 }
 
+// saveWithOutbox stores a tweet together with its TweetCreated outbox record in a
+// single transaction, so the event is never lost if publishing fails later.
+func (r *DynamoDBTweetRepository) saveWithOutbox(ctx context.Context, ddbTweet *dynamoDBTweet, tweetItem map[string]types.AttributeValue) error {
+	outboxItem, err := attributevalue.MarshalMap(newTweetCreatedRecord(ddbTweet))
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox record: %w", err)
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{TableName: aws.String(r.tableName), Item: tweetItem}},
+			{Put: &types.Put{TableName: aws.String(r.opts.outboxTable), Item: outboxItem}},
+		},
+	}
+	return r.opts.call(ctx, func(ctx context.Context) error {
+		_, err := r.client.TransactWriteItems(ctx, input)
+		return err
+	})
+}
+
 // FindByID retrieves a tweet by its ID from DynamoDB.
 func (r *DynamoDBTweetRepository) FindByID(id string) (*entity.Tweet, error) {
 	key, err := attributevalue.MarshalMap(map[string]string{"ID": id})
@@ -126,7 +354,11 @@ func (r *DynamoDBTweetRepository) FindByID(id string) (*entity.Tweet, error) {
 		Key:       key,
 	}
 
-	result, err := r.client.GetItem(context.TODO(), input)
+	var result *dynamodb.GetItemOutput
+	err = r.opts.call(context.Background(), func(ctx context.Context) (err error) {
+		result, err = r.client.GetItem(ctx, input)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tweet from DynamoDB: %w", err)
 	}
@@ -145,7 +377,14 @@ func (r *DynamoDBTweetRepository) FindByID(id string) (*entity.Tweet, error) {
 }
 
 // queryTweetsByUserIDWithContext performs a query against the UserIDIndex GSI, propagating context.
-func (r *DynamoDBTweetRepository) queryTweetsByUserIDWithContext(ctx context.Context, userID string) ([]*entity.Tweet, error) {
+func (r *DynamoDBTweetRepository) queryTweetsByUserIDWithContext(ctx context.Context, userID string) (tweets []*entity.Tweet, err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "dynamodb.Query", trace.WithAttributes(
+		attribute.String("db.system", "dynamodb"),
+		attribute.String("aws.dynamodb.table_names", r.tableName),
+		attribute.String("aws.dynamodb.index_name", userIDIndexName),
+	))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(r.tableName),
 		IndexName:              aws.String(userIDIndexName),
@@ -153,14 +392,19 @@ func (r *DynamoDBTweetRepository) queryTweetsByUserIDWithContext(ctx context.Con
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":userID": &types.AttributeValueMemberS{Value: userID},
 		},
-		// ScanIndexForward: aws.Bool(false), // To sort by sort key descending if one exists
+		// The index is sorted by tweet ID; with ULID IDs this returns the newest tweets first
+		ScanIndexForward: aws.Bool(false),
 	}
 
 	paginator := dynamodb.NewQueryPaginator(r.client, input)
 
-	tweets := make([]*entity.Tweet, 0)
+	tweets = make([]*entity.Tweet, 0)
 	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(ctx)
+		var page *dynamodb.QueryOutput
+		err := r.opts.call(ctx, func(ctx context.Context) (err error) {
+			page, err = paginator.NextPage(ctx)
+			return err
+		})
 		if err != nil {
 			slog.ErrorContext(ctx, "Failed to query tweets page from DynamoDB", "userID", userID, "error", err)
 			return nil, fmt.Errorf("failed to query tweets page for user %s: %w", userID, err)
@@ -193,6 +437,224 @@ func (r *DynamoDBTweetRepository) FindByUserID(userID string) ([]*entity.Tweet,
 	return r.queryTweetsByUserIDWithContext(context.Background(), userID)
 }
 
+// CountByUserID returns the number of tweets by a user that have not expired at now,
+// with a Select=COUNT query on the UserIDIndex GSI so no tweet is read. Expired tweets
+// the table's TTL has not deleted yet are filtered out, as FindByUserID callers do.
+func (r *DynamoDBTweetRepository) CountByUserID(userID string, now time.Time) (int, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String(userIDIndexName),
+		KeyConditionExpression: aws.String("UserID = :userID"),
+		FilterExpression:       aws.String("attribute_not_exists(ExpiresAt) OR ExpiresAt > :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":userID": &types.AttributeValueMemberS{Value: userID},
+			":now":    &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)},
+		},
+	}
+	count, err := countQuery(context.Background(), r.client, r.opts, input)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tweets of user %s: %w", userID, err)
+	}
+	return count, nil
+}
+
+// FindCreatedAtByUserID returns the creation times of the tweets by a user created between from and to
+// through the UserCreatedIndex GSI, which only projects CreatedAt. The index range is in epoch seconds,
+// so tweets created in the second of from before it are returned too. Tweets stored before the index
+// existed have no CreatedAtEpoch and are not returned until they are rewritten.
+func (r *DynamoDBTweetRepository) FindCreatedAtByUserID(userID string, from, to time.Time) ([]time.Time, error) {
+	ctx := context.Background()
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String(userCreatedIndexName),
+		KeyConditionExpression: aws.String("UserID = :userID AND CreatedAtEpoch BETWEEN :from AND :to"),
+		ProjectionExpression:   aws.String("CreatedAt"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":userID": &types.AttributeValueMemberS{Value: userID},
+			":from":   &types.AttributeValueMemberN{Value: strconv.FormatInt(from.Unix(), 10)},
+			":to":     &types.AttributeValueMemberN{Value: strconv.FormatInt(to.Unix(), 10)},
+		},
+	}
+
+	times := make([]time.Time, 0)
+	for {
+		var result *dynamodb.QueryOutput
+		err := r.opts.call(ctx, func(ctx context.Context) error {
+			var err error
+			result, err = r.client.Query(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query recent tweets of user %s: %w", userID, err)
+		}
+
+		var items []struct {
+			CreatedAt string `dynamodbav:"CreatedAt"`
+		}
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal recent tweets of user %s: %w", userID, err)
+		}
+		for _, item := range items {
+			createdAt, err := time.Parse(time.RFC3339Nano, item.CreatedAt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse creation time of a tweet of user %s: %w", userID, err)
+			}
+			times = append(times, createdAt)
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return times, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// FindByConversationID retrieves all tweets of a conversation through the ConversationIDIndex GSI, oldest first.
+// Root tweets stored before conversations were tracked have no ConversationID, so the root is also read by ID.
+func (r *DynamoDBTweetRepository) FindByConversationID(conversationID string) ([]*entity.Tweet, error) {
+	ctx := context.Background()
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String(conversationIDIndexName),
+		KeyConditionExpression: aws.String("ConversationID = :conversationID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":conversationID": &types.AttributeValueMemberS{Value: conversationID},
+		},
+	}
+
+	tweets := make([]*entity.Tweet, 0)
+	hasRoot := false
+	for {
+		var result *dynamodb.QueryOutput
+		err := r.opts.call(ctx, func(ctx context.Context) error {
+			var err error
+			result, err = r.client.Query(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query conversation %s: %w", conversationID, err)
+		}
+
+		var items []dynamoDBTweet
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal conversation %s: %w", conversationID, err)
+		}
+		for i := range items {
+			tweet, err := fromDynamoDBTweet(&items[i])
+			if err != nil {
+				return nil, err
+			}
+			hasRoot = hasRoot || tweet.ID == conversationID
+			tweets = append(tweets, tweet)
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+
+	if !hasRoot {
+		root, err := r.FindByID(conversationID)
+		if err != nil {
+			return nil, err
+		}
+		if root != nil {
+			tweets = append(tweets, root)
+		}
+	}
+
+	sort.Slice(tweets, func(i, j int) bool {
+		return tweets[j].IsNewerThan(tweets[i])
+	})
+	return tweets, nil
+}
+
+// Count returns the number of tweets in the tweets table.
+// Expired tweets count until DynamoDB's TTL process removes them.
+func (r *DynamoDBTweetRepository) Count() (int, error) {
+	return countItems(context.Background(), r.client, r.opts, r.tableName)
+}
+
+// CountCreatedPerBucket counts the tweets created in each bucket of the histogram through the
+// CreatedDayIndex GSI, querying the day partitions the histogram covers concurrently. Only the
+// creation epoch is projected, at second precision. Tweets stored before the index existed
+// have no CreatedDay and are not counted until they are rewritten.
+func (r *DynamoDBTweetRepository) CountCreatedPerBucket(histogram *repository.TweetHistogram) error {
+	var mu sync.Mutex
+	g, ctx := errgroup.WithContext(context.Background())
+	g.SetLimit(maxConcurrentDayQueries)
+
+	end := histogram.End()
+	for day := histogram.Start.Truncate(repository.DayBucket); day.Before(end); day = day.Add(repository.DayBucket) {
+		// Hourly histograms may start or end within the day
+		from, to := day, day.Add(repository.DayBucket)
+		if from.Before(histogram.Start) {
+			from = histogram.Start
+		}
+		if to.After(end) {
+			to = end
+		}
+		g.Go(func() error {
+			epochs, err := r.queryCreatedEpochs(ctx, day.Format(createdDayLayout), from.Unix(), to.Unix()-1)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, epoch := range epochs {
+				histogram.Add(time.Unix(epoch, 0))
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// queryCreatedEpochs returns the creation epochs of the tweets of a CreatedDayIndex partition
+// created between from and to, both inclusive, in epoch seconds.
+func (r *DynamoDBTweetRepository) queryCreatedEpochs(ctx context.Context, day string, from, to int64) ([]int64, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String(createdDayIndexName),
+		KeyConditionExpression: aws.String("CreatedDay = :day AND CreatedAtEpoch BETWEEN :from AND :to"),
+		ProjectionExpression:   aws.String("CreatedAtEpoch"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":day":  &types.AttributeValueMemberS{Value: day},
+			":from": &types.AttributeValueMemberN{Value: strconv.FormatInt(from, 10)},
+			":to":   &types.AttributeValueMemberN{Value: strconv.FormatInt(to, 10)},
+		},
+	}
+
+	var epochs []int64
+	for {
+		var result *dynamodb.QueryOutput
+		err := r.opts.call(ctx, func(ctx context.Context) error {
+			var err error
+			result, err = r.client.Query(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query tweets created on %s: %w", day, err)
+		}
+
+		var items []struct {
+			CreatedAtEpoch int64 `dynamodbav:"CreatedAtEpoch"`
+		}
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tweets created on %s: %w", day, err)
+		}
+		for _, item := range items {
+			epochs = append(epochs, item.CreatedAtEpoch)
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return epochs, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
 // FindAll retrieves all tweets from DynamoDB.
 // WARNING: This uses Scan, which is inefficient for large tables. Consider alternatives in production.
 func (r *DynamoDBTweetRepository) FindAll() ([]*entity.Tweet, error) {
@@ -205,7 +667,11 @@ func (r *DynamoDBTweetRepository) FindAll() ([]*entity.Tweet, error) {
 
 	tweets := make([]*entity.Tweet, 0)
 	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(ctx) // Pass context
+		var page *dynamodb.ScanOutput
+		err := r.opts.call(ctx, func(ctx context.Context) (err error) {
+			page, err = paginator.NextPage(ctx)
+			return err
+		})
 		if err != nil {
 			slog.ErrorContext(ctx, "Failed to scan tweets page from DynamoDB", "error", err)
 			return nil, fmt.Errorf("failed to scan tweets page: %w", err)
@@ -257,37 +723,118 @@ func (r *DynamoDBTweetRepository) Delete(id string) error {
 		Key:       key,
 	}
 
-	_, err = r.client.DeleteItem(ctx, input)
+	err = r.opts.call(ctx, func(ctx context.Context) error {
+		_, err := r.client.DeleteItem(ctx, input)
+		return err
+	})
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to delete tweet from DynamoDB", "tweetID", id, "error", err)
 		return fmt.Errorf("failed to delete tweet %s from DynamoDB: %w", id, err)
 	}
 	slog.InfoContext(ctx, "Deleted tweet from DynamoDB", "tweetID", id, "authorID", authorID)
 
-	// Invalidate timeline cache for the author
+	// Remove the tweet from the materialized timelines, when enabled; reads skip deleted tweets meanwhile
+	if r.opts.timelineTable != "" {
+		if err := r.unmaterializeTweet(ctx, tweet); err != nil {
+			slog.WarnContext(ctx, "Failed to remove deleted tweet from materialized timelines", "tweetID", id, "error", err)
+		}
+	}
+
+	// Invalidate timeline cache for the author and their followers
 	if r.cache != nil {
-		if err := r.cache.InvalidateTimeline(ctx, authorID); err != nil {
+		if err := r.invalidateTimelines(ctx, r.timelineReaders(ctx, authorID)...); err != nil {
 			slog.WarnContext(ctx, "Failed to invalidate timeline cache after deleting tweet", "userID", authorID, "tweetID", id, "error", err)
 		}
 	} else {
 		slog.WarnContext(ctx, "Timeline cache is nil, skipping invalidation on Delete")
 	}
 
-	// TODO: Implement more robust invalidation for followers' timelines
+	return nil
+}
+
+// AddImpressions adds impressions to a stored tweet with an UpdateItem ADD, so
+// concurrent flushes never overwrite each other. The condition on the tweet ID
+// keeps the update from recreating a deleted tweet and returns entity.ErrTweetNotFound.
+func (r *DynamoDBTweetRepository) AddImpressions(id string, count int64) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"ID": &types.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:    aws.String("ADD Impressions :count"),
+		ConditionExpression: aws.String("attribute_exists(ID)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":count": &types.AttributeValueMemberN{Value: strconv.FormatInt(count, 10)},
+		},
+	}
+	err := r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.UpdateItem(ctx, input)
+		return err
+	})
 
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return entity.ErrTweetNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add impressions to tweet %s: %w", id, err)
+	}
 	return nil
 }
 
-// GetTimeline retrieves tweets from the user and users they follow.
-// It first checks the cache, then queries DynamoDB, stores in cache on miss.
-func (r *DynamoDBTweetRepository) GetTimeline(userID string) ([]*entity.Tweet, error) {
-	ctx := context.Background() // Use a background context for now
+// invalidateTimelines removes every cached timeline variant of the users in a single cache call.
+func (r *DynamoDBTweetRepository) invalidateTimelines(ctx context.Context, userIDs ...string) error {
+	var keys []string
+	for _, userID := range userIDs {
+		keys = append(keys, repository.TimelineCacheKeys(userID)...)
+	}
+	return r.callCache(ctx, func(ctx context.Context) error { return r.cache.InvalidateTimelines(ctx, keys) })
+}
+
+// timelineReaders returns the authors and their followers, the users whose timelines show
+// tweets of the authors. Followers that cannot be looked up are logged and left out.
+func (r *DynamoDBTweetRepository) timelineReaders(ctx context.Context, authorIDs ...string) []string {
+	readers := append([]string(nil), authorIDs...)
+	if r.userRepo == nil {
+		return readers
+	}
+	seen := make(map[string]bool, len(authorIDs))
+	for _, authorID := range authorIDs {
+		seen[authorID] = true
+	}
+	for _, authorID := range authorIDs {
+		followers, err := r.userRepo.FindFollowers(authorID)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to find followers to invalidate their timelines", "userID", authorID, "error", err)
+			continue
+		}
+		for _, follower := range followers {
+			if !seen[follower.ID] {
+				seen[follower.ID] = true
+				readers = append(readers, follower.ID)
+			}
+		}
+	}
+	return readers
+}
+
+// GetTimeline retrieves tweets from the users a user follows, and from the user
+// when opts includes them, leaving replies out unless opts includes them. It first checks the cache, then queries DynamoDB,
+// stores in cache on miss. Each timeline variant, including its ranking, is cached under its own key.
+// A stale cached timeline is returned right away while it is rebuilt in the background.
+// Each step is traced as a child span of the span in ctx.
+func (r *DynamoDBTweetRepository) GetTimeline(ctx context.Context, userID string, opts repository.TimelineOptions) (timeline []*entity.Tweet, err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "DynamoDBTweetRepository.GetTimeline", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	cacheKey := opts.CacheKey(userID)
 
 	// 1. Check cache first
 	if r.cache != nil {
-		cachedTimeline, found, err := r.cache.GetTimeline(ctx, userID)
-		if err != nil {
-			slog.WarnContext(ctx, "Failed to get timeline from cache, proceeding to DB", "userID", userID, "error", err)
+		cachedTimeline, found, stale := r.getCachedTimeline(ctx, cacheKey)
+		span.SetAttributes(attribute.Bool("cache.hit", found), attribute.Bool("cache.stale", stale))
+		if stale {
+			r.revalidateTimeline(ctx, userID, opts, cacheKey)
 		}
 		if found {
 			return cachedTimeline, nil
@@ -296,7 +843,35 @@ func (r *DynamoDBTweetRepository) GetTimeline(userID string) ([]*entity.Tweet, e
 		slog.WarnContext(ctx, "Timeline cache is nil, cannot check cache for GetTimeline")
 	}
 
-	// 2. Cache miss or cache unavailable, fetch from DB (existing logic)
+	// 2. Cache miss: with a lock, a single instance rebuilds the timeline while the others wait for it
+	if r.cache != nil && r.opts.timelineLock != nil {
+		release, cachedTimeline, found := r.lockTimeline(ctx, cacheKey)
+		if found {
+			return cachedTimeline, nil
+		}
+		if release != nil {
+			defer release()
+		}
+	}
+
+	// 3. Cache miss or cache unavailable, fetch from DB
+	timeline, err = r.buildTimeline(ctx, userID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Store fetched result in cache
+	if r.cache != nil {
+		if err := r.callCache(ctx, func(ctx context.Context) error { return r.cache.SetTimeline(ctx, cacheKey, timeline) }); err != nil {
+			slog.WarnContext(ctx, "Failed to set timeline cache after DB fetch", "userID", userID, "error", err)
+		}
+	}
+
+	return timeline, nil
+}
+
+// buildTimeline reads the timeline of a user from DynamoDB, filtered and ranked as opts asks.
+func (r *DynamoDBTweetRepository) buildTimeline(ctx context.Context, userID string, opts repository.TimelineOptions) ([]*entity.Tweet, error) {
 	if r.userRepo == nil {
 		return nil, fmt.Errorf("userRepository is nil, cannot GetTimeline")
 	}
@@ -307,18 +882,171 @@ func (r *DynamoDBTweetRepository) GetTimeline(userID string) ([]*entity.Tweet, e
 	if user == nil {
 		return nil, entity.ErrUserNotFound
 	}
+	var timeline []*entity.Tweet
+	if r.opts.timelineTable != "" {
+		timeline, err = r.readMaterializedTimeline(ctx, user, opts.IncludeSelf)
+	} else {
+		timeline, err = r.queryTimeline(ctx, user, opts.IncludeSelf)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return opts.Rank(opts.Filter(timeline), user), nil
+}
+
+// revalidateTimeline rebuilds a stale cached timeline in the background and caches it again,
+// without blocking the caller. Only one rebuild per timeline runs at a time in this instance and,
+// with a rebuild lock, across instances: when another instance holds the lock, the rebuild is left
+// to it. Failures are only logged, the stale timeline is served until it expires.
+func (r *DynamoDBTweetRepository) revalidateTimeline(ctx context.Context, userID string, opts repository.TimelineOptions, cacheKey string) {
+	if _, running := r.revalidating.LoadOrStore(cacheKey, struct{}{}); running {
+		return
+	}
+
+	// The rebuild outlives the request, but stays linked to its trace
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timelineRevalidateTimeout)
+	go func() {
+		defer cancel()
+		defer r.revalidating.Delete(cacheKey)
+		ctx, span := otel.Tracer(tracerName).Start(ctx, "DynamoDBTweetRepository.revalidateTimeline", trace.WithAttributes(attribute.String("user.id", userID)))
+		var err error
+		defer func() { tracing.EndSpan(span, err) }()
+
+		if r.opts.timelineLock != nil {
+			var release func()
+			var acquired bool
+			lockErr := r.callCache(ctx, func(ctx context.Context) error {
+				var err error
+				release, acquired, err = r.opts.timelineLock.TryLock(ctx, cacheKey, timelineLockTTL)
+				return err
+			})
+			if lockErr == nil && !acquired {
+				slog.DebugContext(ctx, "Stale timeline already being rebuilt by another instance", "cacheKey", cacheKey)
+				return
+			}
+			if acquired {
+				defer release()
+			}
+		}
+
+		var timeline []*entity.Tweet
+		if timeline, err = r.buildTimeline(ctx, userID, opts); err != nil {
+			slog.WarnContext(ctx, "Failed to rebuild stale timeline", "userID", userID, "error", err)
+			return
+		}
+		if err = r.callCache(ctx, func(ctx context.Context) error { return r.cache.SetTimeline(ctx, cacheKey, timeline) }); err != nil {
+			slog.WarnContext(ctx, "Failed to set timeline cache after rebuilding a stale timeline", "userID", userID, "error", err)
+			return
+		}
+		slog.DebugContext(ctx, "Rebuilt stale timeline", "userID", userID)
+	}()
+}
+
+// lockTimeline takes the rebuild lock of a timeline missing from the cache, traced as its own span.
+// When another instance holds the lock, it polls the cache until that instance stores the timeline,
+// and returns it with found set. The lock is best effort: when it cannot be taken or the wait times
+// out, the caller rebuilds the timeline without it, so a slow or lost holder only costs a duplicate
+// rebuild. release is nil unless the lock was taken.
+func (r *DynamoDBTweetRepository) lockTimeline(ctx context.Context, cacheKey string) (release func(), timeline []*entity.Tweet, found bool) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "lock.Timeline")
+	defer span.End()
+
+	var acquired bool
+	err := r.callCache(ctx, func(ctx context.Context) error {
+		var err error
+		release, acquired, err = r.opts.timelineLock.TryLock(ctx, cacheKey, timelineLockTTL)
+		return err
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to take timeline rebuild lock, rebuilding without it", "cacheKey", cacheKey, "error", err)
+		return nil, nil, false
+	}
+	span.SetAttributes(attribute.Bool("lock.acquired", acquired))
+	if acquired {
+		// The previous holder may have stored the timeline right before releasing the lock
+		if timeline, found, _ := r.getCachedTimeline(ctx, cacheKey); found {
+			release()
+			return nil, timeline, true
+		}
+		return release, nil, false
+	}
+
+	deadline := time.Now().Add(r.opts.timelineLockWait)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return nil, nil, false
+		case <-time.After(timelineLockPollInterval):
+		}
+		if timeline, found, _ := r.getCachedTimeline(ctx, cacheKey); found {
+			return nil, timeline, true
+		}
+	}
+	slog.DebugContext(ctx, "Timed out waiting for another instance to rebuild the timeline", "cacheKey", cacheKey)
+	return nil, nil, false
+}
+
+// getCachedTimeline looks the timeline up in the cache by its key, traced as its own span.
+// stale is only ever set by a cache implementing cache.StaleTimelineCache.
+// Cache errors are logged and reported as a miss.
+func (r *DynamoDBTweetRepository) getCachedTimeline(ctx context.Context, cacheKey string) (timeline []*entity.Tweet, found, stale bool) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "cache.GetTimeline")
+	err := r.callCache(ctx, func(ctx context.Context) error {
+		var err error
+		if staleCache, ok := r.cache.(cache.StaleTimelineCache); ok {
+			timeline, found, stale, err = staleCache.GetStaleTimeline(ctx, cacheKey)
+		} else {
+			timeline, found, err = r.cache.GetTimeline(ctx, cacheKey)
+		}
+		return err
+	})
+	tracing.EndSpan(span, err)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to get timeline from cache, proceeding to DB", "cacheKey", cacheKey, "error", err)
+		return nil, false, false
+	}
+	return timeline, found, stale
+}
+
+// callCache runs a cache operation bounded by the cache timeout. The operation
+// runs in its own goroutine, so a cache that ignores its context is abandoned
+// with ErrCacheTimeout instead of blocking the caller.
+func (r *DynamoDBTweetRepository) callCache(ctx context.Context, fn func(ctx context.Context) error) error {
+	callCtx, cancel := context.WithTimeout(ctx, r.opts.cacheTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(callCtx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-callCtx.Done():
+		return fmt.Errorf("%w: %w", ErrCacheTimeout, callCtx.Err())
+	}
+}
+
+// queryTimeline builds the timeline from DynamoDB when it is not cached,
+// querying the tweets of everyone the user follows, and of the user when
+// includeSelf is set, concurrently. Tweets are returned newest first.
+func (r *DynamoDBTweetRepository) queryTimeline(ctx context.Context, user *entity.User, includeSelf bool) (allTweets []*entity.Tweet, err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "DynamoDBTweetRepository.queryTimeline")
+	defer func() { tracing.EndSpan(span, err) }()
 
-	idsToFetch := make([]string, 0, len(user.Following)+1)
-	idsToFetch = append(idsToFetch, userID)
-	for followedID := range user.Following {
-		idsToFetch = append(idsToFetch, followedID)
+	userID := user.ID
+	followingIDs := user.GetFollowing()
+	idsToFetch := make([]string, 0, len(followingIDs)+1)
+	if includeSelf {
+		idsToFetch = append(idsToFetch, userID)
 	}
+	idsToFetch = append(idsToFetch, followingIDs...)
 
 	slog.DebugContext(ctx, "Fetching timeline from DB", "userID", userID, "usersToQuery", len(idsToFetch))
+	span.SetAttributes(attribute.Int("timeline.users", len(idsToFetch)))
 
-	var allTweets []*entity.Tweet
 	var mu sync.Mutex
-	// Use errgroup with the same background context for now
 	g, queryCtx := errgroup.WithContext(ctx)
 
 	for _, id := range idsToFetch {
@@ -340,18 +1068,14 @@ func (r *DynamoDBTweetRepository) GetTimeline(userID string) ([]*entity.Tweet, e
 		return nil, err
 	}
 
+	// A tweet fetched through more than one user ID is kept once
+	allTweets = entity.UniqueTweets(allTweets)
+
 	sort.Slice(allTweets, func(i, j int) bool {
-		return allTweets[i].CreatedAt.After(allTweets[j].CreatedAt)
+		return allTweets[i].IsNewerThan(allTweets[j])
 	})
 	slog.DebugContext(ctx, "Successfully fetched timeline from DB", "userID", userID, "tweetCount", len(allTweets))
 
-	// 3. Store fetched result in cache
-	if r.cache != nil {
-		if err := r.cache.SetTimeline(ctx, userID, allTweets); err != nil {
-			slog.WarnContext(ctx, "Failed to set timeline cache after DB fetch", "userID", userID, "error", err)
-		}
-	}
-
 	return allTweets, nil
 }
 
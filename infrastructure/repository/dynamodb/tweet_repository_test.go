@@ -0,0 +1,623 @@
+package dynamodb_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+	dynamodbRepo "github.com/develpudu/go-challenge/infrastructure/repository/dynamodb"
+	"github.com/develpudu/go-challenge/infrastructure/repository/memory"
+)
+
+func TestFindByConversationIDOldestFirst(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(client, "tweets", nil, nil)
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// The root predates conversation tracking, so it is stored without a ConversationID
+	root := &entity.Tweet{ID: "root", UserID: "alice", Content: "Root", CreatedAt: base}
+	reply, _ := entity.NewReplyAt("reply", "bob", "Reply", root, base.Add(time.Minute))
+	nested, _ := entity.NewReplyAt("nested", "alice", "Nested", reply, base.Add(2*time.Minute))
+	other, _ := entity.NewTweetAt("other", "bob", "Other", base.Add(3*time.Minute))
+	for _, tweet := range []*entity.Tweet{nested, other, reply, root} {
+		if err := repo.Save(tweet); err != nil {
+			t.Fatalf("Failed to save tweet %s: %v", tweet.ID, err)
+		}
+	}
+
+	// Act
+	thread, err := repo.FindByConversationID("root")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []string{"root", "reply", "nested"}
+	if len(thread) != len(expected) {
+		t.Fatalf("Expected %d tweets, got %d", len(expected), len(thread))
+	}
+	for i, id := range expected {
+		if thread[i].ID != id {
+			t.Errorf("Expected tweet %d to be %s, got %s", i, id, thread[i].ID)
+		}
+	}
+	if thread[2].InReplyToID != "reply" || thread[2].ConversationID != "root" {
+		t.Errorf("Expected reply fields to round-trip, got %+v", thread[2])
+	}
+}
+
+func TestUpdateMissingTweet(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(client, "tweets", nil, nil)
+	tweet, _ := entity.NewTweetAt("missing", "alice", "Hello", time.Now())
+
+	// Act
+	err := repo.Update(tweet)
+
+	// Assert
+	if err != entity.ErrTweetNotFound {
+		t.Errorf("Expected ErrTweetNotFound, got %v", err)
+	}
+	if found, _ := repo.FindByID("missing"); found != nil {
+		t.Error("Expected the missing tweet not to be created")
+	}
+}
+
+func TestAddImpressionsAccumulates(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(client, "tweets", nil, nil)
+	tweet, _ := entity.NewTweetAt("tweet1", "alice", "Hello", time.Now())
+	if err := repo.Save(tweet); err != nil {
+		t.Fatalf("Failed to save tweet: %v", err)
+	}
+
+	// Act
+	err1 := repo.AddImpressions("tweet1", 2)
+	err2 := repo.AddImpressions("tweet1", 3)
+	missingErr := repo.AddImpressions("missing", 1)
+
+	// Assert
+	if err1 != nil || err2 != nil {
+		t.Fatalf("Expected no errors, got %v and %v", err1, err2)
+	}
+	if missingErr != entity.ErrTweetNotFound {
+		t.Errorf("Expected ErrTweetNotFound for a missing tweet, got %v", missingErr)
+	}
+	if len(client.Items("tweets")) != 1 {
+		t.Errorf("Expected no item created for the missing tweet, got %d items", len(client.Items("tweets")))
+	}
+	stored, err := repo.FindByID("tweet1")
+	if err != nil || stored.Impressions != 5 {
+		t.Errorf("Expected 5 impressions stored, got %+v, %v", stored, err)
+	}
+}
+
+func TestSaveStoresExpiresAtAsEpochSeconds(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(client, "tweets", nil, nil)
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tweet, _ := entity.NewTweetAt("ephemeral", "alice", "Hello", createdAt)
+	tweet.ExpiresAt = createdAt.Add(24 * time.Hour)
+
+	// Act
+	if err := repo.Save(tweet); err != nil {
+		t.Fatalf("Failed to save tweet: %v", err)
+	}
+	found, err := repo.FindByID("ephemeral")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !found.ExpiresAt.Equal(tweet.ExpiresAt) {
+		t.Errorf("Expected ExpiresAt %v, got %v", tweet.ExpiresAt, found.ExpiresAt)
+	}
+}
+
+func TestCountByUserIDMatchesFindByUserID(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(client, "tweets", nil, nil)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, userID := range []string{"alice", "alice", "alice", "bob"} {
+		tweet, _ := entity.NewTweetAt(fmt.Sprintf("tweet%d", i), userID, "Hello", now.Add(-time.Hour))
+		repo.Save(tweet)
+	}
+	expired, _ := entity.NewTweetAt("expired", "alice", "Gone", now.Add(-time.Hour))
+	expired.ExpiresAt = now
+	repo.Save(expired)
+
+	// Act
+	count, err := repo.CountByUserID("alice", now)
+	tweets, _ := repo.FindByUserID("alice")
+
+	// Assert: the expired tweet is still stored but not counted
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tweets) != 4 || count != 3 {
+		t.Errorf("Expected 3 of alice's 4 stored tweets to be counted, got %d of %d", count, len(tweets))
+	}
+	if count, _ := repo.CountByUserID("nobody", now); count != 0 {
+		t.Errorf("Expected no tweets for a user without tweets, got %d", count)
+	}
+}
+
+func TestFindCreatedAtByUserIDReadsOnlyTheRange(t *testing.T) {
+	// Arrange: alice's tweets before, inside and after the range, and a tweet by bob inside it
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(client, "tweets", nil, nil)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 500, time.UTC)
+	for i, offset := range []time.Duration{-2 * time.Hour, -30 * time.Minute, -time.Minute, time.Minute} {
+		tweet, _ := entity.NewTweetAt(fmt.Sprintf("tweet%d", i), "alice", "Hello", now.Add(offset))
+		repo.Save(tweet)
+	}
+	other, _ := entity.NewTweetAt("other", "bob", "Hi", now.Add(-time.Minute))
+	repo.Save(other)
+
+	// Act
+	times, err := repo.FindCreatedAtByUserID("alice", now.Add(-time.Hour), now)
+
+	// Assert: the times keep their precision
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	if len(times) != 2 || !times[0].Equal(now.Add(-30*time.Minute)) || !times[1].Equal(now.Add(-time.Minute)) {
+		t.Errorf("Expected the creation times of the 2 tweets in the range, got %v", times)
+	}
+}
+
+func TestCountCreatedPerBucketAcrossBoundaries(t *testing.T) {
+	// Arrange: tweets on both sides of an hour boundary and of a day boundary
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(client, "tweets", nil, nil)
+	midnight := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	for i, offset := range []time.Duration{-90 * time.Minute, -time.Second, 0, time.Hour - time.Second, time.Hour, 2 * time.Hour} {
+		tweet, _ := entity.NewTweetAt(fmt.Sprintf("tweet%d", i), "alice", "Hello", midnight.Add(offset))
+		repo.Save(tweet)
+	}
+
+	// Act
+	hourly := repository.NewTweetHistogram(midnight.Add(-time.Hour), midnight.Add(2*time.Hour), repository.HourBucket)
+	hourlyErr := repo.CountCreatedPerBucket(hourly)
+	daily := repository.NewTweetHistogram(midnight.Add(-24*time.Hour), midnight.Add(24*time.Hour), repository.DayBucket)
+	dailyErr := repo.CountCreatedPerBucket(daily)
+
+	// Assert: each bucket includes its start and excludes its end
+	if hourlyErr != nil || dailyErr != nil {
+		t.Fatalf("Expected no errors, got %v and %v", hourlyErr, dailyErr)
+	}
+	if fmt.Sprint(hourly.Counts) != "[1 2 1]" {
+		t.Errorf("Expected hourly counts [1 2 1], got %v", hourly.Counts)
+	}
+	if fmt.Sprint(daily.Counts) != "[2 4]" {
+		t.Errorf("Expected daily counts [2 4], got %v", daily.Counts)
+	}
+}
+
+func TestUpdatePersistsUpdatedAt(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(client, "tweets", nil, nil)
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tweet, _ := entity.NewTweetAt("tweet1", "alice", "Helo", createdAt)
+	repo.Save(tweet)
+	saved, _ := repo.FindByID("tweet1")
+	if !saved.UpdatedAt.Equal(createdAt) {
+		t.Fatalf("Expected UpdatedAt %v after saving, got %v", createdAt, saved.UpdatedAt)
+	}
+
+	// Act
+	tweet.EditWithMaxLength("Hello", entity.MaxTweetLength, createdAt.Add(time.Minute))
+	if err := repo.Update(tweet); err != nil {
+		t.Fatalf("Failed to update tweet: %v", err)
+	}
+	found, _ := repo.FindByID("tweet1")
+
+	// Assert
+	if !found.UpdatedAt.Equal(createdAt.Add(time.Minute)) || !found.CreatedAt.Equal(createdAt) {
+		t.Errorf("Expected UpdatedAt bumped and CreatedAt kept, got %v and %v", found.UpdatedAt, found.CreatedAt)
+	}
+}
+
+// Timeline cache whose calls hang until released, ignoring their context
+type HangingTimelineCache struct {
+	release chan struct{}
+}
+
+func (c *HangingTimelineCache) GetTimeline(ctx context.Context, userID string) ([]*entity.Tweet, bool, error) {
+	<-c.release
+	return nil, false, nil
+}
+
+func (c *HangingTimelineCache) SetTimeline(ctx context.Context, userID string, timeline []*entity.Tweet) error {
+	<-c.release
+	return nil
+}
+
+func (c *HangingTimelineCache) InvalidateTimeline(ctx context.Context, userID string) error {
+	<-c.release
+	return nil
+}
+
+func (c *HangingTimelineCache) InvalidateTimelines(ctx context.Context, userIDs []string) error {
+	<-c.release
+	return nil
+}
+
+func TestGetTimelineFallsBackToDBWhenCacheHangs(t *testing.T) {
+	// Arrange
+	timelineCache := &HangingTimelineCache{release: make(chan struct{})}
+	defer close(timelineCache.release)
+	userRepo := memory.NewUserRepository()
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	repo := dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(NewMockDynamoDBClient(), "tweets", userRepo, timelineCache,
+		dynamodbRepo.WithCacheTimeout(20*time.Millisecond))
+	tweet, _ := entity.NewTweetAt("tweet1", "alice", "Hello", time.Now())
+	if err := repo.Save(tweet); err != nil {
+		t.Fatalf("Failed to save tweet: %v", err)
+	}
+
+	// Act
+	start := time.Now()
+	timeline, err := repo.GetTimeline(context.Background(), "alice", repository.DefaultTimelineOptions())
+	elapsed := time.Since(start)
+
+	// Assert: both the cache read and the cache write are abandoned
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(timeline) != 1 || timeline[0].ID != "tweet1" {
+		t.Errorf("Expected the timeline from DynamoDB, got %v", timeline)
+	}
+	if elapsed > time.Second {
+		t.Errorf("Expected GetTimeline to return promptly, took %v", elapsed)
+	}
+}
+
+func TestGetTimelineDeduplicatesTweets(t *testing.T) {
+	// Arrange: alice's tweets are reachable both as her own and through a stored self-follow
+	userRepo := memory.NewUserRepository()
+	alice := entity.NewUser("alice", "alice")
+	alice.Following["alice"] = true
+	userRepo.Save(alice)
+	repo := dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(NewMockDynamoDBClient(), "tweets", userRepo, nil)
+	tweet, _ := entity.NewTweetAt("tweet1", "alice", "Hello", time.Now())
+	repo.Save(tweet)
+
+	// Act
+	timeline, err := repo.GetTimeline(context.Background(), "alice", repository.DefaultTimelineOptions())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(timeline) != 1 || timeline[0].ID != "tweet1" {
+		t.Errorf("Expected tweet1 once, got %v", timeline)
+	}
+}
+
+// Timeline cache backed by a map, keyed as given by the repository
+type MapTimelineCache struct {
+	mutex     sync.Mutex
+	timelines map[string][]*entity.Tweet
+	// Number of InvalidateTimelines calls
+	batches int
+}
+
+func (c *MapTimelineCache) GetTimeline(ctx context.Context, key string) ([]*entity.Tweet, bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	timeline, found := c.timelines[key]
+	return timeline, found, nil
+}
+
+func (c *MapTimelineCache) SetTimeline(ctx context.Context, key string, timeline []*entity.Tweet) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.timelines[key] = timeline
+	return nil
+}
+
+func (c *MapTimelineCache) InvalidateTimeline(ctx context.Context, key string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.timelines, key)
+	return nil
+}
+
+func (c *MapTimelineCache) InvalidateTimelines(ctx context.Context, keys []string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.batches++
+	for _, key := range keys {
+		delete(c.timelines, key)
+	}
+	return nil
+}
+
+func TestGetTimelineCachesEachVariantSeparately(t *testing.T) {
+	// Arrange
+	timelineCache := &MapTimelineCache{timelines: make(map[string][]*entity.Tweet)}
+	userRepo := memory.NewUserRepository()
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	repo := dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(NewMockDynamoDBClient(), "tweets", userRepo, timelineCache)
+	tweet, _ := entity.NewTweetAt("tweet1", "alice", "Hello", time.Now())
+	repo.Save(tweet)
+	ctx := context.Background()
+
+	// Act
+	withSelf, _ := repo.GetTimeline(ctx, "alice", repository.TimelineOptions{IncludeSelf: true})
+	followingOnly, _ := repo.GetTimeline(ctx, "alice", repository.TimelineOptions{IncludeSelf: false})
+	cachedVariants := len(timelineCache.timelines)
+	newer, _ := entity.NewTweetAt("tweet2", "alice", "Again", time.Now())
+	repo.Save(newer)
+
+	// Assert
+	if len(withSelf) != 1 || len(followingOnly) != 0 {
+		t.Errorf("Expected 1 tweet with self and none without, got %d and %d", len(withSelf), len(followingOnly))
+	}
+	if cachedVariants != 2 {
+		t.Errorf("Expected both variants to be cached under separate keys, got %d", cachedVariants)
+	}
+	if len(timelineCache.timelines) != 0 {
+		t.Errorf("Expected saving a tweet to invalidate every variant, got %v", timelineCache.timelines)
+	}
+}
+
+func TestSaveInvalidatesFollowerTimelinesInOneCall(t *testing.T) {
+	// Arrange
+	timelineCache := &MapTimelineCache{timelines: make(map[string][]*entity.Tweet)}
+	userRepo := memory.NewUserRepository()
+	for _, id := range []string{"alice", "bob", "carol", "dave"} {
+		userRepo.Save(entity.NewUser(id, id))
+	}
+	userRepo.Follow("bob", "alice")
+	userRepo.Follow("carol", "alice")
+	repo := dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(NewMockDynamoDBClient(), "tweets", userRepo, timelineCache)
+	ctx := context.Background()
+	for _, id := range []string{"alice", "bob", "carol", "dave"} {
+		if _, err := repo.GetTimeline(ctx, id, repository.DefaultTimelineOptions()); err != nil {
+			t.Fatalf("Failed to cache the timeline of %s: %v", id, err)
+		}
+	}
+
+	// Act
+	tweet, _ := entity.NewTweetAt("tweet1", "alice", "Hello", time.Now())
+	if err := repo.Save(tweet); err != nil {
+		t.Fatalf("Failed to save tweet: %v", err)
+	}
+
+	// Assert: the author and followers lose their cached timelines, others keep theirs
+	if timelineCache.batches != 1 {
+		t.Errorf("Expected a single invalidation call, got %d", timelineCache.batches)
+	}
+	for id, wantCached := range map[string]bool{"alice": false, "bob": false, "carol": false, "dave": true} {
+		_, cached, _ := timelineCache.GetTimeline(ctx, repository.DefaultTimelineOptions().CacheKey(id))
+		if cached != wantCached {
+			t.Errorf("Expected the timeline of %s cached=%v, got %v", id, wantCached, cached)
+		}
+	}
+}
+
+// Locker shared by the repositories of a test, standing in for Redis; locks never expire
+type MapLocker struct {
+	mutex sync.Mutex
+	held  map[string]bool
+}
+
+func (l *MapLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (func(), bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.held[key] {
+		return nil, false, nil
+	}
+	l.held[key] = true
+	return func() {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		delete(l.held, key)
+	}, true, nil
+}
+
+// Two repositories, as on two instances, sharing a slow DynamoDB, a cache and a locker
+func setupLockedTimelineRepos(t *testing.T, lockWait time.Duration) (*MockDynamoDBClient, *MapTimelineCache, *MapLocker, [2]*dynamodbRepo.DynamoDBTweetRepository) {
+	client := NewMockDynamoDBClient()
+	timelineCache := &MapTimelineCache{timelines: make(map[string][]*entity.Tweet)}
+	locker := &MapLocker{held: make(map[string]bool)}
+	userRepo := memory.NewUserRepository()
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	var repos [2]*dynamodbRepo.DynamoDBTweetRepository
+	for i := range repos {
+		repos[i] = dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(client, "tweets", userRepo, timelineCache,
+			dynamodbRepo.WithTimelineLock(locker, lockWait))
+	}
+	tweet, _ := entity.NewTweetAt("tweet1", "alice", "Hello", time.Now())
+	if err := repos[0].Save(tweet); err != nil {
+		t.Fatalf("Failed to save tweet: %v", err)
+	}
+	client.Delay = 100 * time.Millisecond
+	return client, timelineCache, locker, repos
+}
+
+func TestGetTimelineLockLetsOneInstanceRebuild(t *testing.T) {
+	// Arrange
+	client, _, _, repos := setupLockedTimelineRepos(t, time.Second)
+
+	// Act: both instances miss the cache at the same time
+	var wg sync.WaitGroup
+	var timelines [2][]*entity.Tweet
+	var errs [2]error
+	for i, repo := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			timelines[i], errs[i] = repo.GetTimeline(context.Background(), "alice", repository.DefaultTimelineOptions())
+		}()
+	}
+	wg.Wait()
+
+	// Assert: a single query rebuilt the timeline both instances return
+	for i := range repos {
+		if errs[i] != nil || len(timelines[i]) != 1 || timelines[i][0].ID != "tweet1" {
+			t.Errorf("Instance %d: expected the rebuilt timeline, got %v and %v", i, timelines[i], errs[i])
+		}
+	}
+	if client.Calls["Query"] != 1 {
+		t.Errorf("Expected DynamoDB to be queried once, got %d queries", client.Calls["Query"])
+	}
+}
+
+func TestGetTimelineRebuildsWhenLockIsNeverReleased(t *testing.T) {
+	// Arrange: another instance took the lock and died
+	client, timelineCache, locker, repos := setupLockedTimelineRepos(t, 50*time.Millisecond)
+	key := repository.DefaultTimelineOptions().CacheKey("alice")
+	locker.TryLock(context.Background(), key, time.Minute)
+
+	// Act
+	start := time.Now()
+	timeline, err := repos[1].GetTimeline(context.Background(), "alice", repository.DefaultTimelineOptions())
+
+	// Assert: the caller gives up waiting and rebuilds the timeline itself
+	if err != nil || len(timeline) != 1 {
+		t.Fatalf("Expected the timeline to be rebuilt, got %v and %v", timeline, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the wait to be bounded, took %v", elapsed)
+	}
+	if client.Calls["Query"] != 1 {
+		t.Errorf("Expected the caller to query DynamoDB, got %d queries", client.Calls["Query"])
+	}
+	if cached, found, _ := timelineCache.GetTimeline(context.Background(), key); !found || len(cached) != 1 {
+		t.Errorf("Expected the rebuilt timeline to be cached, got %v", cached)
+	}
+}
+
+// Map timeline cache whose entries can be marked stale, like Redis past the soft TTL
+type StaleMapTimelineCache struct {
+	MapTimelineCache
+	stale map[string]bool
+}
+
+func (c *StaleMapTimelineCache) GetStaleTimeline(ctx context.Context, key string) ([]*entity.Tweet, bool, bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	timeline, found := c.timelines[key]
+	return timeline, found, c.stale[key], nil
+}
+
+// Storing a timeline makes it fresh again
+func (c *StaleMapTimelineCache) SetTimeline(ctx context.Context, key string, timeline []*entity.Tweet) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.timelines[key] = timeline
+	delete(c.stale, key)
+	return nil
+}
+
+// A repository over a stale-aware cache holding alice's timeline with tweet1 only,
+// while DynamoDB also holds tweet2. Returns the cache key of the timeline.
+func setupStaleTimelineRepo(t *testing.T, stale bool) (*MockDynamoDBClient, *StaleMapTimelineCache, *dynamodbRepo.DynamoDBTweetRepository, string) {
+	client := NewMockDynamoDBClient()
+	timelineCache := &StaleMapTimelineCache{
+		MapTimelineCache: MapTimelineCache{timelines: make(map[string][]*entity.Tweet)},
+		stale:            make(map[string]bool),
+	}
+	userRepo := memory.NewUserRepository()
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	repo := dynamodbRepo.NewDynamoDBTweetRepositoryWithClient(client, "tweets", userRepo, timelineCache)
+	first, _ := entity.NewTweetAt("tweet1", "alice", "Hello", time.Now().Add(-time.Minute))
+	second, _ := entity.NewTweetAt("tweet2", "alice", "Again", time.Now())
+	if err := repo.SaveAll([]*entity.Tweet{first, second}); err != nil {
+		t.Fatalf("Failed to save tweets: %v", err)
+	}
+	key := repository.DefaultTimelineOptions().CacheKey("alice")
+	timelineCache.timelines[key] = []*entity.Tweet{first}
+	timelineCache.stale[key] = stale
+	return client, timelineCache, repo, key
+}
+
+func TestGetTimelineServesFreshCachedTimeline(t *testing.T) {
+	// Arrange
+	client, _, repo, _ := setupStaleTimelineRepo(t, false)
+
+	// Act
+	timeline, err := repo.GetTimeline(context.Background(), "alice", repository.DefaultTimelineOptions())
+	time.Sleep(50 * time.Millisecond)
+
+	// Assert
+	if err != nil || len(timeline) != 1 {
+		t.Fatalf("Expected the cached timeline, got %v and %v", timeline, err)
+	}
+	if client.Calls["Query"] != 0 {
+		t.Errorf("Expected no rebuild of a fresh timeline, got %d queries", client.Calls["Query"])
+	}
+}
+
+func TestGetTimelineServesStaleTimelineWhileRevalidating(t *testing.T) {
+	// Arrange
+	client, timelineCache, repo, key := setupStaleTimelineRepo(t, true)
+	client.Delay = 100 * time.Millisecond
+
+	// Act: two reads of the stale timeline
+	start := time.Now()
+	var timelines [2][]*entity.Tweet
+	for i := range timelines {
+		timelines[i], _ = repo.GetTimeline(context.Background(), "alice", repository.DefaultTimelineOptions())
+	}
+	elapsed := time.Since(start)
+
+	// Assert: both return the stale timeline without waiting for DynamoDB
+	for i, timeline := range timelines {
+		if len(timeline) != 1 || timeline[0].ID != "tweet1" {
+			t.Errorf("Read %d: expected the stale timeline, got %v", i, timeline)
+		}
+	}
+	if elapsed >= client.Delay {
+		t.Errorf("Expected stale reads not to wait for the rebuild, took %v", elapsed)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		cached, _, stale, _ := timelineCache.GetStaleTimeline(context.Background(), key)
+		if !stale && len(cached) == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the timeline to be rebuilt in the background, got %v (stale=%v)", cached, stale)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if client.Calls["Query"] != 1 {
+		t.Errorf("Expected a single background rebuild, got %d queries", client.Calls["Query"])
+	}
+}
+
+func TestGetTimelineRebuildsExpiredTimeline(t *testing.T) {
+	// Arrange: the entry expired from the cache
+	client, timelineCache, repo, key := setupStaleTimelineRepo(t, false)
+	timelineCache.InvalidateTimeline(context.Background(), key)
+
+	// Act
+	timeline, err := repo.GetTimeline(context.Background(), "alice", repository.DefaultTimelineOptions())
+
+	// Assert: the caller waits for the rebuilt timeline
+	if err != nil || len(timeline) != 2 {
+		t.Fatalf("Expected the rebuilt timeline, got %v and %v", timeline, err)
+	}
+	if client.Calls["Query"] != 1 {
+		t.Errorf("Expected DynamoDB to be queried once, got %d queries", client.Calls["Query"])
+	}
+	if _, found, stale, _ := timelineCache.GetStaleTimeline(context.Background(), key); !found || stale {
+		t.Errorf("Expected a fresh timeline to be cached, got found=%v stale=%v", found, stale)
+	}
+}
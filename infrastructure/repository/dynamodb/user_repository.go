@@ -2,7 +2,10 @@ package dynamodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -12,10 +15,29 @@ import (
 	"github.com/develpudu/go-challenge/domain/repository"
 )
 
+// DynamoDBAPI is the subset of the DynamoDB client used by the repositories.
+// It allows the repositories to run against a mock client in tests.
+type DynamoDBAPI interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
+}
+
+// Compile-time check to ensure the SDK client satisfies DynamoDBAPI
+var _ DynamoDBAPI = (*dynamodb.Client)(nil)
+
 // DynamoDBUserRepository implements the UserRepository interface using AWS DynamoDB.
 type DynamoDBUserRepository struct {
-	client    *dynamodb.Client
-	tableName string
+	client           DynamoDBAPI
+	tableName        string
+	followsTableName string
+	opts             options
 }
 
 // dynamoDBUser is a helper struct for marshalling/unmarshalling User data to/from DynamoDB.
@@ -24,27 +46,48 @@ type dynamoDBUser struct {
 	ID        string   `dynamodbav:"ID"`
 	Username  string   `dynamodbav:"Username"`
 	Following []string `dynamodbav:"Following,stringset,omitempty"` // Store keys of the map as a string set
+	// Stored only for deactivated users, so items written before deactivation existed read as active
+	Deactivated bool `dynamodbav:"Deactivated,omitempty"`
+}
+
+// dynamoDBUsername is a helper struct for a username claim stored in the usernames table,
+// keyed by the normalized username and pointing to the user holding it.
+type dynamoDBUsername struct {
+	UsernameKey string `dynamodbav:"UsernameKey"`
+	UserID      string `dynamodbav:"UserID"`
+}
+
+// dynamoDBFollow is a helper struct for a follow edge stored in the follows table.
+// The table is keyed by FollowedID (hash) and FollowerID (range), acting as the follower index.
+type dynamoDBFollow struct {
+	FollowedID string `dynamodbav:"FollowedID"`
+	FollowerID string `dynamodbav:"FollowerID"`
+	CreatedAt  string `dynamodbav:"CreatedAt"`
 }
 
 // NewDynamoDBUserRepository creates a new DynamoDB user repository.
-func NewDynamoDBUserRepository(cfg aws.Config, tableName string) *DynamoDBUserRepository {
-	client := dynamodb.NewFromConfig(cfg)
+// followsTableName is the table holding follower edges, written together with the users table.
+func NewDynamoDBUserRepository(cfg aws.Config, tableName, followsTableName string, opts ...Option) *DynamoDBUserRepository {
+	return NewDynamoDBUserRepositoryWithClient(NewClient(cfg), tableName, followsTableName, opts...)
+}
+
+// NewDynamoDBUserRepositoryWithClient creates a new DynamoDB user repository using the given client.
+func NewDynamoDBUserRepositoryWithClient(client DynamoDBAPI, tableName, followsTableName string, opts ...Option) *DynamoDBUserRepository {
 	return &DynamoDBUserRepository{
-		client:    client,
-		tableName: tableName,
+		client:           client,
+		tableName:        tableName,
+		followsTableName: followsTableName,
+		opts:             newOptions(opts),
 	}
 }
 
 // toDynamoDBUser converts an entity.User to its DynamoDB representation.
 func toDynamoDBUser(user *entity.User) (*dynamoDBUser, error) {
-	followingSet := make([]string, 0, len(user.Following))
-	for id := range user.Following {
-		followingSet = append(followingSet, id)
-	}
 	return &dynamoDBUser{
-		ID:        user.ID,
-		Username:  user.Username,
-		Following: followingSet,
+		ID:          user.ID,
+		Username:    user.Username,
+		Following:   user.GetFollowing(),
+		Deactivated: !user.Active,
 	}, nil
 }
 
@@ -58,10 +101,13 @@ func fromDynamoDBUser(ddbUser *dynamoDBUser) *entity.User {
 		ID:        ddbUser.ID,
 		Username:  ddbUser.Username,
 		Following: followingMap,
+		Active:    !ddbUser.Deactivated,
 	}
 }
 
 // Save stores a user in the DynamoDB table.
+// With a usernames table, the user's normalized username is claimed in the same transaction,
+// returning entity.ErrUsernameTaken if another user holds it.
 func (r *DynamoDBUserRepository) Save(user *entity.User) error {
 	ddbUser, err := toDynamoDBUser(user)
 	if err != nil {
@@ -73,18 +119,118 @@ func (r *DynamoDBUserRepository) Save(user *entity.User) error {
 		return fmt.Errorf("failed to marshal user to attribute values: %w", err)
 	}
 
+	if r.opts.usernamesTable != "" {
+		return r.saveWithUsername(user, av)
+	}
+
 	input := &dynamodb.PutItemInput{
 		TableName: aws.String(r.tableName),
 		Item:      av,
 	}
 
-	_, err = r.client.PutItem(context.TODO(), input)
+	err = r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.PutItem(ctx, input)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to save user to DynamoDB: %w", err)
 	}
 	return nil
 }
 
+// saveWithUsername writes the user item together with the claim on their normalized username.
+// The claim may be new or already held by the same user. A username left behind by a
+// changed username is released in the same transaction.
+func (r *DynamoDBUserRepository) saveWithUsername(user *entity.User, userItem map[string]types.AttributeValue) error {
+	key := entity.NormalizeUsername(user.Username)
+	claim, err := attributevalue.MarshalMap(dynamoDBUsername{UsernameKey: key, UserID: user.ID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal username claim: %w", err)
+	}
+
+	items := []types.TransactWriteItem{
+		{Put: &types.Put{TableName: aws.String(r.tableName), Item: userItem}},
+		{
+			Put: &types.Put{
+				TableName:           aws.String(r.opts.usernamesTable),
+				Item:                claim,
+				ConditionExpression: aws.String("attribute_not_exists(UsernameKey) OR UserID = :userID"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":userID": &types.AttributeValueMemberS{Value: user.ID},
+				},
+			},
+		},
+	}
+
+	// Release the previous username if it changed
+	previous, err := r.FindByID(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user %s before saving: %w", user.ID, err)
+	}
+	if previous != nil && entity.NormalizeUsername(previous.Username) != key {
+		items = append(items, types.TransactWriteItem{
+			Delete: &types.Delete{TableName: aws.String(r.opts.usernamesTable), Key: usernameKey(previous.Username)},
+		})
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{TransactItems: items}
+	err = r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.TransactWriteItems(ctx, input)
+		return err
+	})
+	if err != nil {
+		return mapTransactionError("failed to save user to DynamoDB", err, nil, entity.ErrUsernameTaken)
+	}
+	return nil
+}
+
+// FindByUsername retrieves a user by their username in any case. With a usernames table
+// it reads the claim and then the user; otherwise it scans all users.
+func (r *DynamoDBUserRepository) FindByUsername(username string) (*entity.User, error) {
+	if r.opts.usernamesTable == "" {
+		users, err := r.FindAll()
+		if err != nil {
+			return nil, err
+		}
+		key := entity.NormalizeUsername(username)
+		for _, user := range users {
+			if entity.NormalizeUsername(user.Username) == key {
+				return user, nil
+			}
+		}
+		return nil, nil
+	}
+
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(r.opts.usernamesTable),
+		Key:       usernameKey(username),
+	}
+	var result *dynamodb.GetItemOutput
+	err := r.opts.call(context.Background(), func(ctx context.Context) (err error) {
+		result, err = r.client.GetItem(ctx, input)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get username %s from DynamoDB: %w", username, err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var claim dynamoDBUsername
+	if err := attributevalue.UnmarshalMap(result.Item, &claim); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal username claim from DynamoDB: %w", err)
+	}
+	return r.FindByID(claim.UserID)
+}
+
+// usernameKey returns the key of the claim on a username in the usernames table.
+func usernameKey(username string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"UsernameKey": &types.AttributeValueMemberS{Value: entity.NormalizeUsername(username)},
+	}
+}
+
 // FindByID retrieves a user by their ID from DynamoDB.
 func (r *DynamoDBUserRepository) FindByID(id string) (*entity.User, error) {
 	key, err := attributevalue.MarshalMap(map[string]string{"ID": id})
@@ -97,7 +243,11 @@ func (r *DynamoDBUserRepository) FindByID(id string) (*entity.User, error) {
 		Key:       key,
 	}
 
-	result, err := r.client.GetItem(context.TODO(), input)
+	var result *dynamodb.GetItemOutput
+	err = r.opts.call(context.Background(), func(ctx context.Context) (err error) {
+		result, err = r.client.GetItem(ctx, input)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user from DynamoDB: %w", err)
 	}
@@ -115,6 +265,42 @@ func (r *DynamoDBUserRepository) FindByID(id string) (*entity.User, error) {
 	return fromDynamoDBUser(&ddbUser), nil
 }
 
+// Count returns the number of users in the users table.
+func (r *DynamoDBUserRepository) Count() (int, error) {
+	return countItems(context.Background(), r.client, r.opts, r.tableName)
+}
+
+// CountFollows returns the number of follow relations, one item each in the follows table.
+func (r *DynamoDBUserRepository) CountFollows() (int, error) {
+	return countItems(context.Background(), r.client, r.opts, r.followsTableName)
+}
+
+// CountFollowers returns the number of followers of a user. It queries the user's
+// partition of the follows table with Select=COUNT, so no follow edges are read.
+func (r *DynamoDBUserRepository) CountFollowers(userID string) (int, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.followsTableName),
+		KeyConditionExpression: aws.String("FollowedID = :userID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":userID": &types.AttributeValueMemberS{Value: userID},
+		},
+	}
+	return countQuery(context.Background(), r.client, r.opts, input)
+}
+
+// CountFollowing returns the number of users a user follows, the size of the
+// Following set stored on the user item. It returns entity.ErrUserNotFound if the user does not exist.
+func (r *DynamoDBUserRepository) CountFollowing(userID string) (int, error) {
+	user, err := r.FindByID(userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user %s for counting following: %w", userID, err)
+	}
+	if user == nil {
+		return 0, entity.ErrUserNotFound
+	}
+	return user.FollowingCount(), nil
+}
+
 // FindAll retrieves all users from DynamoDB.
 // WARNING: This uses Scan, which is inefficient for large tables. Consider alternatives in production.
 func (r *DynamoDBUserRepository) FindAll() ([]*entity.User, error) {
@@ -126,7 +312,11 @@ func (r *DynamoDBUserRepository) FindAll() ([]*entity.User, error) {
 
 	users := make([]*entity.User, 0)
 	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(context.TODO())
+		var page *dynamodb.ScanOutput
+		err := r.opts.call(context.Background(), func(ctx context.Context) (err error) {
+			page, err = paginator.NextPage(ctx)
+			return err
+		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan users page from DynamoDB: %w", err)
 		}
@@ -145,6 +335,51 @@ func (r *DynamoDBUserRepository) FindAll() ([]*entity.User, error) {
 	return users, nil
 }
 
+// FindAllPage retrieves up to limit users scanned after the user with ID afterID, in scan order.
+// The ID of the last user of a page is its LastEvaluatedKey, so it is used as the exclusive
+// start key of the next scan. A non-positive limit returns all remaining users.
+func (r *DynamoDBUserRepository) FindAllPage(afterID string, limit int) ([]*entity.User, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(r.tableName),
+	}
+	if afterID != "" {
+		input.ExclusiveStartKey = map[string]types.AttributeValue{
+			"ID": &types.AttributeValueMemberS{Value: afterID},
+		}
+	}
+
+	users := make([]*entity.User, 0)
+	for limit <= 0 || len(users) < limit {
+		if limit > 0 {
+			input.Limit = aws.Int32(int32(limit - len(users)))
+		}
+
+		var page *dynamodb.ScanOutput
+		err := r.opts.call(context.Background(), func(ctx context.Context) (err error) {
+			page, err = r.client.Scan(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan users page from DynamoDB: %w", err)
+		}
+
+		var pageUsers []dynamoDBUser
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &pageUsers); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal users page from DynamoDB: %w", err)
+		}
+		for i := range pageUsers {
+			users = append(users, fromDynamoDBUser(&pageUsers[i]))
+		}
+
+		if len(page.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = page.LastEvaluatedKey
+	}
+
+	return users, nil
+}
+
 // Update updates an existing user in DynamoDB.
 // This implementation replaces the entire item. More granular updates are possible.
 func (r *DynamoDBUserRepository) Update(user *entity.User) error {
@@ -153,6 +388,41 @@ func (r *DynamoDBUserRepository) Update(user *entity.User) error {
 	return r.Save(user)
 }
 
+// SetActive stores whether a user's account is active with an UpdateItem on the
+// Deactivated attribute alone, so follows written since the user was read are kept.
+// Deactivated is only stored for deactivated users; the condition on the user ID
+// keeps the update from creating a user and returns entity.ErrUserNotFound.
+func (r *DynamoDBUserRepository) SetActive(userID string, active bool) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"ID": &types.AttributeValueMemberS{Value: userID},
+		},
+		ConditionExpression: aws.String("attribute_exists(ID)"),
+	}
+	if active {
+		input.UpdateExpression = aws.String("REMOVE Deactivated")
+	} else {
+		input.UpdateExpression = aws.String("SET Deactivated = :deactivated")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":deactivated": &types.AttributeValueMemberBOOL{Value: true},
+		}
+	}
+	err := r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.UpdateItem(ctx, input)
+		return err
+	})
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return entity.ErrUserNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set active state of user %s: %w", userID, err)
+	}
+	return nil
+}
+
 // Delete removes a user from the DynamoDB table.
 func (r *DynamoDBUserRepository) Delete(id string) error {
 	key, err := attributevalue.MarshalMap(map[string]string{"ID": id})
@@ -167,7 +437,33 @@ func (r *DynamoDBUserRepository) Delete(id string) error {
 		// ConditionExpression: aws.String("attribute_exists(ID)"),
 	}
 
-	_, err = r.client.DeleteItem(context.TODO(), input)
+	// Release the user's username along with the user
+	if r.opts.usernamesTable != "" {
+		user, err := r.FindByID(id)
+		if err != nil {
+			return fmt.Errorf("failed to get user %s before deleting: %w", id, err)
+		}
+		if user != nil {
+			err = r.opts.call(context.Background(), func(ctx context.Context) error {
+				_, err := r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+					TransactItems: []types.TransactWriteItem{
+						{Delete: &types.Delete{TableName: input.TableName, Key: key}},
+						{Delete: &types.Delete{TableName: aws.String(r.opts.usernamesTable), Key: usernameKey(user.Username)}},
+					},
+				})
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to delete user from DynamoDB: %w", err)
+			}
+			return nil
+		}
+	}
+
+	err = r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.DeleteItem(ctx, input)
+		return err
+	})
 	if err != nil {
 		// Consider handling specific errors, e.g., ConditionalCheckFailedException
 		return fmt.Errorf("failed to delete user from DynamoDB: %w", err)
@@ -175,47 +471,69 @@ func (r *DynamoDBUserRepository) Delete(id string) error {
 	return nil
 }
 
-// FindFollowers retrieves all users that follow a specific user.
-// WARNING: This uses Scan with a filter, which is very inefficient for large tables.
-// A GSI on the 'Following' attribute might be needed for production use cases.
+// FindFollowers retrieves all users that follow a specific user, ordered by ID.
 func (r *DynamoDBUserRepository) FindFollowers(userID string) ([]*entity.User, error) {
-	input := &dynamodb.ScanInput{
-		TableName:        aws.String(r.tableName),
-		FilterExpression: aws.String("contains(Following, :userID)"),
+	return r.FindFollowersPage(userID, "", 0)
+}
+
+// FindFollowersPage retrieves up to limit followers of a user with an ID greater than afterID, ordered by ID.
+// It queries the follows table, whose FollowerID range key keeps each user's followers sorted,
+// starting right after afterID. A non-positive limit returns all remaining followers.
+func (r *DynamoDBUserRepository) FindFollowersPage(userID, afterID string, limit int) ([]*entity.User, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.followsTableName),
+		KeyConditionExpression: aws.String("FollowedID = :userID"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":userID": &types.AttributeValueMemberS{Value: userID},
 		},
 	}
+	if afterID != "" {
+		input.ExclusiveStartKey = map[string]types.AttributeValue{
+			"FollowedID": &types.AttributeValueMemberS{Value: userID},
+			"FollowerID": &types.AttributeValueMemberS{Value: afterID},
+		}
+	}
 
-	paginator := dynamodb.NewScanPaginator(r.client, input)
+	followerIDs := make([]string, 0)
+	for limit <= 0 || len(followerIDs) < limit {
+		if limit > 0 {
+			input.Limit = aws.Int32(int32(limit - len(followerIDs)))
+		}
 
-	followers := make([]*entity.User, 0)
-	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(context.TODO())
+		var page *dynamodb.QueryOutput
+		err := r.opts.call(context.Background(), func(ctx context.Context) (err error) {
+			page, err = r.client.Query(ctx, input)
+			return err
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan followers page from DynamoDB: %w", err)
+			return nil, fmt.Errorf("failed to query followers of user %s from DynamoDB: %w", userID, err)
 		}
 
-		var pageUsers []dynamoDBUser
-		err = attributevalue.UnmarshalListOfMaps(page.Items, &pageUsers)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal followers page from DynamoDB: %w", err)
+		var edges []dynamoDBFollow
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &edges); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal follow edges from DynamoDB: %w", err)
+		}
+		for _, edge := range edges {
+			followerIDs = append(followerIDs, edge.FollowerID)
 		}
 
-		for _, ddbUser := range pageUsers {
-			// We need to filter out the user themselves if they accidentally follow themselves in the data
-			// (though the domain logic prevents this)
-			if ddbUser.ID != userID {
-				followers = append(followers, fromDynamoDBUser(&ddbUser))
-			}
+		if len(page.LastEvaluatedKey) == 0 {
+			break
 		}
+		input.ExclusiveStartKey = page.LastEvaluatedKey
 	}
 
-	return followers, nil
+	return r.findUsersByIDs(followerIDs)
 }
 
-// FindFollowing retrieves all users that a specific user follows.
+// FindFollowing retrieves all users that a specific user follows, ordered by ID.
 func (r *DynamoDBUserRepository) FindFollowing(userID string) ([]*entity.User, error) {
+	return r.FindFollowingPage(userID, "", 0)
+}
+
+// FindFollowingPage retrieves up to limit users followed by a user with an ID greater than afterID, ordered by ID.
+// The followed IDs are read from the user's Following set. A non-positive limit returns all remaining users.
+func (r *DynamoDBUserRepository) FindFollowingPage(userID, afterID string, limit int) ([]*entity.User, error) {
 	user, err := r.FindByID(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user %s for finding following: %w", userID, err)
@@ -224,55 +542,210 @@ func (r *DynamoDBUserRepository) FindFollowing(userID string) ([]*entity.User, e
 		return nil, entity.ErrUserNotFound // Or return empty list? Interface contract unclear. Assuming error.
 	}
 
-	if len(user.Following) == 0 {
-		return []*entity.User{}, nil
+	followingIDs := user.GetFollowing()
+	sort.Strings(followingIDs)
+	start := sort.SearchStrings(followingIDs, afterID)
+	if start < len(followingIDs) && followingIDs[start] == afterID {
+		start++
+	}
+	followingIDs = followingIDs[start:]
+	if limit > 0 && len(followingIDs) > limit {
+		followingIDs = followingIDs[:limit]
 	}
 
-	// Prepare keys for BatchGetItem
-	keys := make([]map[string]types.AttributeValue, 0, len(user.Following))
-	for followedID := range user.Following {
-		key, err := attributevalue.MarshalMap(map[string]string{"ID": followedID})
-		if err != nil {
-			// Log this error, but potentially continue? Or fail fast?
-			return nil, fmt.Errorf("failed to marshal key for followed user %s: %w", followedID, err)
+	return r.findUsersByIDs(followingIDs)
+}
+
+// findUsersByIDs retrieves the users with the given IDs in the same order, skipping missing users.
+func (r *DynamoDBUserRepository) findUsersByIDs(ids []string) ([]*entity.User, error) {
+	found, err := r.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*entity.User, 0, len(ids))
+	for _, id := range ids {
+		if user, ok := found[id]; ok {
+			users = append(users, user)
 		}
-		keys = append(keys, key)
 	}
+	return users, nil
+}
 
-	// BatchGetItem has a limit of 100 items per request. Handle pagination if needed.
-	// For simplicity, assuming less than 100 followings here.
-	if len(keys) > 100 {
-		// TODO: Implement pagination for BatchGetItem if > 100 keys
-		return nil, fmt.Errorf("finding more than 100 followed users is not implemented yet")
+// FindByIDs retrieves the users with the given IDs, keyed by ID, skipping missing users.
+// BatchGetItem accepts at most 100 distinct keys, so duplicate IDs are dropped and the
+// rest are requested in chunks, retrying the keys DynamoDB leaves unprocessed.
+func (r *DynamoDBUserRepository) FindByIDs(ids []string) (map[string]*entity.User, error) {
+	const maxBatchGetKeys = 100
+
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
 	}
 
-	input := &dynamodb.BatchGetItemInput{
-		RequestItems: map[string]types.KeysAndAttributes{
-			r.tableName: {
-				Keys: keys,
+	found := make(map[string]*entity.User, len(unique))
+	for start := 0; start < len(unique); start += maxBatchGetKeys {
+		end := min(start+maxBatchGetKeys, len(unique))
+		keys := make([]map[string]types.AttributeValue, 0, end-start)
+		for _, id := range unique[start:end] {
+			keys = append(keys, userKey(id))
+		}
+
+		input := &dynamodb.BatchGetItemInput{
+			RequestItems: map[string]types.KeysAndAttributes{
+				r.tableName: {Keys: keys},
 			},
-		},
+		}
+		for len(input.RequestItems) > 0 {
+			var result *dynamodb.BatchGetItemOutput
+			err := r.opts.call(context.Background(), func(ctx context.Context) (err error) {
+				result, err = r.client.BatchGetItem(ctx, input)
+				return err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to batch get users from DynamoDB: %w", err)
+			}
+
+			var ddbUsers []dynamoDBUser
+			if err := attributevalue.UnmarshalListOfMaps(result.Responses[r.tableName], &ddbUsers); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal users from DynamoDB: %w", err)
+			}
+			for i := range ddbUsers {
+				found[ddbUsers[i].ID] = fromDynamoDBUser(&ddbUsers[i])
+			}
+
+			// Retry keys DynamoDB could not process in this call
+			input = &dynamodb.BatchGetItemInput{RequestItems: result.UnprocessedKeys}
+		}
 	}
+	return found, nil
+}
 
-	result, err := r.client.BatchGetItem(context.TODO(), input)
+// Follow records that followerID follows followedID.
+// The follower's Following set and the follower edge are written in a single transaction,
+// so either both sides reflect the follow or neither does.
+func (r *DynamoDBUserRepository) Follow(followerID, followedID string) error {
+	edge, err := attributevalue.MarshalMap(dynamoDBFollow{
+		FollowedID: followedID,
+		FollowerID: followerID,
+		CreatedAt:  time.Now().UTC().Format(time.RFC3339Nano),
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to batch get following users from DynamoDB: %w", err)
+		return fmt.Errorf("failed to marshal follow edge: %w", err)
 	}
 
-	followingUsers := make([]*entity.User, 0, len(result.Responses[r.tableName]))
-	var ddbUsers []dynamoDBUser
-	err = attributevalue.UnmarshalListOfMaps(result.Responses[r.tableName], &ddbUsers)
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				// The followed user must exist
+				ConditionCheck: &types.ConditionCheck{
+					TableName:           aws.String(r.tableName),
+					Key:                 userKey(followedID),
+					ConditionExpression: aws.String("attribute_exists(ID)"),
+				},
+			},
+			{
+				Update: &types.Update{
+					TableName:           aws.String(r.tableName),
+					Key:                 userKey(followerID),
+					UpdateExpression:    aws.String("ADD Following :followed"),
+					ConditionExpression: aws.String("attribute_exists(ID)"),
+					ExpressionAttributeValues: map[string]types.AttributeValue{
+						":followed": &types.AttributeValueMemberSS{Value: []string{followedID}},
+					},
+				},
+			},
+			{
+				// The edge must not exist yet
+				Put: &types.Put{
+					TableName:           aws.String(r.followsTableName),
+					Item:                edge,
+					ConditionExpression: aws.String("attribute_not_exists(FollowerID)"),
+				},
+			},
+		},
+	}
+
+	err = r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.TransactWriteItems(ctx, input)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal following users from DynamoDB: %w", err)
+		return mapTransactionError(fmt.Sprintf("failed to follow user %s", followedID), err,
+			entity.ErrUserNotFound, entity.ErrUserNotFound, entity.ErrAlreadyFollowing)
+	}
+	return nil
+}
+
+// Unfollow removes the follow relation between followerID and followedID.
+// Like Follow, both sides are removed in a single transaction.
+func (r *DynamoDBUserRepository) Unfollow(followerID, followedID string) error {
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Update: &types.Update{
+					TableName:           aws.String(r.tableName),
+					Key:                 userKey(followerID),
+					UpdateExpression:    aws.String("DELETE Following :followed"),
+					ConditionExpression: aws.String("attribute_exists(ID)"),
+					ExpressionAttributeValues: map[string]types.AttributeValue{
+						":followed": &types.AttributeValueMemberSS{Value: []string{followedID}},
+					},
+				},
+			},
+			{
+				// The edge must exist
+				Delete: &types.Delete{
+					TableName: aws.String(r.followsTableName),
+					Key: map[string]types.AttributeValue{
+						"FollowedID": &types.AttributeValueMemberS{Value: followedID},
+						"FollowerID": &types.AttributeValueMemberS{Value: followerID},
+					},
+					ConditionExpression: aws.String("attribute_exists(FollowerID)"),
+				},
+			},
+		},
 	}
 
-	for _, ddbUser := range ddbUsers {
-		followingUsers = append(followingUsers, fromDynamoDBUser(&ddbUser))
+	err := r.opts.call(context.Background(), func(ctx context.Context) error {
+		_, err := r.client.TransactWriteItems(ctx, input)
+		return err
+	})
+	if err != nil {
+		return mapTransactionError(fmt.Sprintf("failed to unfollow user %s", followedID), err,
+			entity.ErrUserNotFound, entity.ErrNotFollowing)
 	}
+	return nil
+}
 
-	// TODO: Handle UnprocessedKeys if any
+// userKey builds the primary key for an item in the users table.
+func userKey(id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"ID": &types.AttributeValueMemberS{Value: id},
+	}
+}
 
-	return followingUsers, nil
+// mapTransactionError converts a failed condition in a transaction into the domain error
+// for that item; conditionErrs holds one error per transaction item, in order.
+// A transaction still conflicting with concurrent ones once retries are exhausted
+// becomes entity.ErrConcurrentUpdate.
+func mapTransactionError(msg string, err error, conditionErrs ...error) error {
+	var canceled *types.TransactionCanceledException
+	if errors.As(err, &canceled) {
+		for i, reason := range canceled.CancellationReasons {
+			if aws.ToString(reason.Code) == "ConditionalCheckFailed" && i < len(conditionErrs) {
+				return conditionErrs[i]
+			}
+		}
+	}
+	if isTransactionConflict(err) {
+		return entity.ErrConcurrentUpdate
+	}
+	return fmt.Errorf("%s: %w", msg, err)
 }
 
 // Compile-time check to ensure DynamoDBUserRepository implements UserRepository
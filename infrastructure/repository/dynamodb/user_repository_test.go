@@ -0,0 +1,492 @@
+package dynamodb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/develpudu/go-challenge/domain/entity"
+	dynamodbRepo "github.com/develpudu/go-challenge/infrastructure/repository/dynamodb"
+)
+
+// Returns a user repository backed by a mock client with two saved users
+func setupUserRepository(t *testing.T) (*dynamodbRepo.DynamoDBUserRepository, *MockDynamoDBClient) {
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBUserRepositoryWithClient(client, "users", "follows")
+
+	for _, user := range []*entity.User{
+		entity.NewUser("follower", "followerUser"),
+		entity.NewUser("followed", "followedUser"),
+	} {
+		if err := repo.Save(user); err != nil {
+			t.Fatalf("Failed to save user %s: %v", user.ID, err)
+		}
+	}
+	return repo, client
+}
+
+func TestFollowWritesBothSides(t *testing.T) {
+	// Arrange
+	repo, client := setupUserRepository(t)
+
+	// Act
+	err := repo.Follow("follower", "followed")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	follower, _ := repo.FindByID("follower")
+	if !follower.IsFollowing("followed") {
+		t.Error("Expected follower to be following followed")
+	}
+
+	if edges := client.Items("follows"); len(edges) != 1 {
+		t.Errorf("Expected 1 follow edge, got %d", len(edges))
+	}
+
+	if client.Calls["TransactWriteItems"] != 1 {
+		t.Errorf("Expected a single transaction, got %d", client.Calls["TransactWriteItems"])
+	}
+}
+
+func TestFollowTransactionFailureLeavesNoSide(t *testing.T) {
+	// Arrange
+	repo, client := setupUserRepository(t)
+	client.TransactErr = errors.New("transaction failed")
+	putsBefore := client.Calls["PutItem"]
+
+	// Act
+	err := repo.Follow("follower", "followed")
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected an error when the transaction fails, got nil")
+	}
+
+	follower, _ := repo.FindByID("follower")
+	if follower.IsFollowing("followed") {
+		t.Error("Expected follower not to be following followed after a failed transaction")
+	}
+
+	if edges := client.Items("follows"); len(edges) != 0 {
+		t.Errorf("Expected no follow edge after a failed transaction, got %d", len(edges))
+	}
+
+	if client.Calls["PutItem"] != putsBefore || client.Calls["UpdateItem"] != 0 {
+		t.Error("Expected no writes outside the transaction")
+	}
+}
+
+func TestFollowMissingUser(t *testing.T) {
+	// Arrange
+	repo, client := setupUserRepository(t)
+
+	// Act
+	err := repo.Follow("follower", "nonexistent")
+
+	// Assert
+	if err != entity.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+
+	if edges := client.Items("follows"); len(edges) != 0 {
+		t.Errorf("Expected no follow edge, got %d", len(edges))
+	}
+}
+
+func TestFollowAlreadyFollowing(t *testing.T) {
+	// Arrange
+	repo, client := setupUserRepository(t)
+	if err := repo.Follow("follower", "followed"); err != nil {
+		t.Fatalf("Failed to follow: %v", err)
+	}
+
+	// Act
+	err := repo.Follow("follower", "followed")
+
+	// Assert
+	if err != entity.ErrAlreadyFollowing {
+		t.Errorf("Expected ErrAlreadyFollowing, got %v", err)
+	}
+
+	if edges := client.Items("follows"); len(edges) != 1 {
+		t.Errorf("Expected a single follow edge, got %d", len(edges))
+	}
+}
+
+func TestUnfollowNotFollowing(t *testing.T) {
+	// Arrange
+	repo, _ := setupUserRepository(t)
+
+	// Act
+	err := repo.Unfollow("follower", "followed")
+
+	// Assert
+	if err != entity.ErrNotFollowing {
+		t.Errorf("Expected ErrNotFollowing, got %v", err)
+	}
+}
+
+func TestUnfollowRemovesBothSides(t *testing.T) {
+	// Arrange
+	repo, client := setupUserRepository(t)
+	if err := repo.Follow("follower", "followed"); err != nil {
+		t.Fatalf("Failed to follow: %v", err)
+	}
+
+	// Act
+	err := repo.Unfollow("follower", "followed")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	follower, _ := repo.FindByID("follower")
+	if follower.IsFollowing("followed") {
+		t.Error("Expected follower not to be following followed after unfollowing")
+	}
+
+	if edges := client.Items("follows"); len(edges) != 0 {
+		t.Errorf("Expected no follow edge after unfollowing, got %d", len(edges))
+	}
+}
+
+func TestUnfollowTransactionFailureKeepsBothSides(t *testing.T) {
+	// Arrange
+	repo, client := setupUserRepository(t)
+	if err := repo.Follow("follower", "followed"); err != nil {
+		t.Fatalf("Failed to follow: %v", err)
+	}
+	client.TransactErr = errors.New("transaction failed")
+
+	// Act
+	err := repo.Unfollow("follower", "followed")
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected an error when the transaction fails, got nil")
+	}
+
+	follower, _ := repo.FindByID("follower")
+	if !follower.IsFollowing("followed") {
+		t.Error("Expected follower to still be following followed after a failed transaction")
+	}
+
+	if edges := client.Items("follows"); len(edges) != 1 {
+		t.Errorf("Expected the follow edge to remain, got %d", len(edges))
+	}
+}
+
+func TestFindFollowersPageTraversal(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBUserRepositoryWithClient(client, "users", "follows")
+	repo.Save(entity.NewUser("star", "star"))
+	followerIDs := []string{"f5", "f1", "f4", "f2", "f3"}
+	for _, id := range followerIDs {
+		repo.Save(entity.NewUser(id, id))
+		if err := repo.Follow(id, "star"); err != nil {
+			t.Fatalf("Failed to follow: %v", err)
+		}
+	}
+
+	// Act: read pages of 2 followers, resuming after the last one returned
+	var traversed []string
+	afterID := ""
+	for {
+		page, err := repo.FindFollowersPage("star", afterID, 2)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, user := range page {
+			traversed = append(traversed, user.ID)
+		}
+		afterID = page[len(page)-1].ID
+	}
+
+	// Assert
+	expected := []string{"f1", "f2", "f3", "f4", "f5"}
+	if len(traversed) != len(expected) {
+		t.Fatalf("Expected followers %v, got %v", expected, traversed)
+	}
+	for i := range expected {
+		if traversed[i] != expected[i] {
+			t.Fatalf("Expected followers %v, got %v", expected, traversed)
+		}
+	}
+}
+
+func TestFindByIDsChunksAndSkipsMissingUsers(t *testing.T) {
+	// Arrange: more users than a BatchGetItem call can read, some IDs repeated or missing
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBUserRepositoryWithClient(client, "users", "follows")
+	var ids []string
+	for i := range 150 {
+		id := fmt.Sprintf("user%03d", i)
+		repo.Save(entity.NewUser(id, id))
+		ids = append(ids, id)
+	}
+	ids = append(ids, "user000", "missing1", "missing2")
+	client.UnprocessedNext = 10
+
+	// Act
+	users, err := repo.FindByIDs(ids)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(users) != 150 {
+		t.Fatalf("Expected 150 users, got %d", len(users))
+	}
+	if users["user149"] == nil || users["user149"].Username != "user149" {
+		t.Errorf("Expected user149 to be found, got %v", users["user149"])
+	}
+	if _, ok := users["missing1"]; ok {
+		t.Error("Expected missing IDs to be absent")
+	}
+	// Two chunks plus one retry of the unprocessed keys
+	if calls := client.Calls["BatchGetItem"]; calls != 3 {
+		t.Errorf("Expected 3 BatchGetItem calls, got %d", calls)
+	}
+}
+
+func TestFindAllPageTraversal(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBUserRepositoryWithClient(client, "users", "follows")
+	for _, id := range []string{"u3", "u1", "u5", "u2", "u4"} {
+		repo.Save(entity.NewUser(id, id))
+	}
+
+	// Act: scan pages of 2 users, resuming after the last one returned
+	seen := make(map[string]int)
+	afterID := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("Expected the scan to end")
+		}
+		page, err := repo.FindAllPage(afterID, 2)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(page) > 2 {
+			t.Fatalf("Expected at most 2 users per page, got %d", len(page))
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, user := range page {
+			seen[user.ID]++
+		}
+		afterID = page[len(page)-1].ID
+	}
+
+	// Assert: every user is listed exactly once
+	if len(seen) != 5 {
+		t.Fatalf("Expected 5 users, got %v", seen)
+	}
+	for id, count := range seen {
+		if count != 1 {
+			t.Errorf("Expected user %s to be listed once, got %d", id, count)
+		}
+	}
+}
+
+func TestSaveRoundTripsDeactivation(t *testing.T) {
+	// Arrange
+	repo, client := setupUserRepository(t)
+	user, _ := repo.FindByID("follower")
+	user.Active = false
+
+	// Act
+	if err := repo.Update(user); err != nil {
+		t.Fatalf("Failed to update user: %v", err)
+	}
+	deactivated, _ := repo.FindByID("follower")
+	active, _ := repo.FindByID("followed")
+
+	// Assert
+	if deactivated.Active {
+		t.Error("Expected the deactivation to be stored")
+	}
+	if !active.Active {
+		t.Error("Expected users stored without the attribute to be active")
+	}
+	for _, item := range client.Items("users") {
+		if _, stored := item["Deactivated"]; stored && item["ID"].(*types.AttributeValueMemberS).Value != "follower" {
+			t.Errorf("Expected Deactivated to be stored only for deactivated users, got %v", item)
+		}
+	}
+}
+
+func TestSetActiveKeepsConcurrentFollows(t *testing.T) {
+	// Arrange: the follow is stored after the user was saved, so only a partial update keeps it
+	repo, client := setupUserRepository(t)
+	if err := repo.Follow("follower", "followed"); err != nil {
+		t.Fatalf("Failed to follow: %v", err)
+	}
+
+	// Act
+	deactivateErr := repo.SetActive("follower", false)
+	deactivated, _ := repo.FindByID("follower")
+	reactivateErr := repo.SetActive("follower", true)
+	reactivated, _ := repo.FindByID("follower")
+	missingErr := repo.SetActive("missing", false)
+
+	// Assert
+	if deactivateErr != nil || reactivateErr != nil {
+		t.Fatalf("Expected no errors, got %v and %v", deactivateErr, reactivateErr)
+	}
+	if deactivated.Active || !deactivated.IsFollowing("followed") {
+		t.Errorf("Expected the user deactivated with the follow kept, got %+v", deactivated)
+	}
+	if !reactivated.Active || !reactivated.IsFollowing("followed") {
+		t.Errorf("Expected the user reactivated with the follow kept, got %+v", reactivated)
+	}
+	if missingErr != entity.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound for a missing user, got %v", missingErr)
+	}
+	for _, item := range client.Items("users") {
+		if _, stored := item["Deactivated"]; stored {
+			t.Errorf("Expected Deactivated to be removed on reactivation, got %v", item)
+		}
+	}
+}
+
+func TestCountUsersAndFollows(t *testing.T) {
+	// Arrange
+	repo, client := setupUserRepository(t)
+	repo.Follow("follower", "followed")
+
+	// Act
+	users, usersErr := repo.Count()
+	follows, followsErr := repo.CountFollows()
+
+	// Assert
+	if usersErr != nil || followsErr != nil {
+		t.Fatalf("Expected no errors, got %v and %v", usersErr, followsErr)
+	}
+	if users != 2 || follows != 1 {
+		t.Errorf("Expected 2 users and 1 follow, got %d and %d", users, follows)
+	}
+	if client.Calls["Scan"] != 2 {
+		t.Errorf("Expected one count scan per table, got %d", client.Calls["Scan"])
+	}
+}
+
+func TestCountFollowersAndFollowingMatchLists(t *testing.T) {
+	// Arrange
+	repo, _ := setupUserRepository(t)
+	repo.Save(entity.NewUser("other", "otherUser"))
+	repo.Follow("follower", "followed")
+	repo.Follow("other", "followed")
+	repo.Follow("follower", "other")
+
+	for _, userID := range []string{"follower", "followed", "other"} {
+		// Act
+		followerCount, err := repo.CountFollowers(userID)
+		if err != nil {
+			t.Fatalf("Expected no error counting followers of %s, got %v", userID, err)
+		}
+		followingCount, err := repo.CountFollowing(userID)
+		if err != nil {
+			t.Fatalf("Expected no error counting following of %s, got %v", userID, err)
+		}
+
+		// Assert
+		followers, _ := repo.FindFollowers(userID)
+		following, _ := repo.FindFollowing(userID)
+		if followerCount != len(followers) || followingCount != len(following) {
+			t.Errorf("Expected counts %d and %d for %s, got %d and %d", len(followers), len(following), userID, followerCount, followingCount)
+		}
+	}
+
+	if _, err := repo.CountFollowing("missing"); err != entity.ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound for a missing user, got %v", err)
+	}
+}
+
+func TestUsernamesTableKeepsUsernamesUniqueIgnoringCase(t *testing.T) {
+	// Arrange
+	client := NewMockDynamoDBClient()
+	repo := dynamodbRepo.NewDynamoDBUserRepositoryWithClient(client, "users", "follows", dynamodbRepo.WithUsernamesTable("usernames"))
+	if err := repo.Save(entity.NewUser("user1", "Alice")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Act
+	err := repo.Save(entity.NewUser("user2", "alice"))
+
+	// Assert
+	if err != entity.ErrUsernameTaken {
+		t.Errorf("Expected ErrUsernameTaken, got %v", err)
+	}
+	if len(client.Items("users")) != 1 {
+		t.Errorf("Expected the rejected user not to be stored, got %d users", len(client.Items("users")))
+	}
+	for _, username := range []string{"Alice", "alice"} {
+		user, err := repo.FindByUsername(username)
+		if err != nil || user == nil || user.ID != "user1" || user.Username != "Alice" {
+			t.Errorf("Expected to find Alice by %q, got %v and %v", username, user, err)
+		}
+	}
+
+	// Renaming releases the old username
+	if err := repo.Save(entity.NewUser("user1", "Alicia")); err != nil {
+		t.Fatalf("Expected the holder to rename, got %v", err)
+	}
+	if err := repo.Save(entity.NewUser("user2", "alice")); err != nil {
+		t.Errorf("Expected the old username to be free, got %v", err)
+	}
+	if len(client.Items("usernames")) != 2 {
+		t.Errorf("Expected one claim per user, got %d", len(client.Items("usernames")))
+	}
+}
+
+func TestBackfillFollowsCopiesFollowingSets(t *testing.T) {
+	// Arrange: "legacy" was saved with a follow before the follows table existed
+	repo, client := setupUserRepository(t)
+	legacy := entity.NewUser("legacy", "legacyUser")
+	legacy.Follow("followed")
+	if err := repo.Save(legacy); err != nil {
+		t.Fatalf("Failed to save user: %v", err)
+	}
+	if err := repo.Follow("follower", "followed"); err != nil {
+		t.Fatalf("Failed to follow: %v", err)
+	}
+	before, _ := repo.CountFollowers("followed")
+	edges := make(map[string]types.AttributeValue)
+	for _, item := range client.Items("follows") {
+		edges[item["FollowerID"].(*types.AttributeValueMemberS).Value] = item["CreatedAt"]
+	}
+
+	// Act
+	written, err := repo.BackfillFollows(context.Background())
+	again, againErr := repo.BackfillFollows(context.Background())
+
+	// Assert
+	if err != nil || againErr != nil {
+		t.Fatalf("Expected no errors, got %v and %v", err, againErr)
+	}
+	if before != 1 || written != 1 || again != 0 {
+		t.Errorf("Expected the missing edge to be written once, got %d before, %d and %d written", before, written, again)
+	}
+	followers, _ := repo.FindFollowers("followed")
+	if len(followers) != 2 || followers[0].ID != "follower" || followers[1].ID != "legacy" {
+		t.Errorf("Expected follower and legacy as followers, got %v", followers)
+	}
+	for _, item := range client.Items("follows") {
+		if item["FollowerID"].(*types.AttributeValueMemberS).Value == "follower" && fmt.Sprint(item["CreatedAt"]) != fmt.Sprint(edges["follower"]) {
+			t.Error("Expected the existing edge to be kept as it was")
+		}
+	}
+}
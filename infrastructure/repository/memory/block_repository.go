@@ -0,0 +1,45 @@
+package memory
+
+import "sync"
+
+// Implements the block repository interface with an in-memory storage
+type BlockRepository struct {
+	blocks map[string]map[string]bool // Map of blocker ID to the IDs of the users they block
+	mutex  sync.RWMutex
+}
+
+// Creates a new in-memory block repository
+func NewBlockRepository() *BlockRepository {
+	return &BlockRepository{
+		blocks: make(map[string]map[string]bool),
+	}
+}
+
+// Records that a user blocks another
+func (r *BlockRepository) Block(blockerID, blockedID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.blocks[blockerID] == nil {
+		r.blocks[blockerID] = make(map[string]bool)
+	}
+	r.blocks[blockerID][blockedID] = true
+	return nil
+}
+
+// Removes a block
+func (r *BlockRepository) Unblock(blockerID, blockedID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.blocks[blockerID], blockedID)
+	return nil
+}
+
+// Checks if a user blocks another
+func (r *BlockRepository) IsBlocked(blockerID, blockedID string) (bool, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.blocks[blockerID][blockedID], nil
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Implements the bookmark repository interface with an in-memory storage
+type BookmarkRepository struct {
+	bookmarks map[string]map[string]entity.Bookmark // Map of user ID to bookmarks by tweet ID
+	mutex     sync.RWMutex
+}
+
+// Creates a new in-memory bookmark repository
+func NewBookmarkRepository() *BookmarkRepository {
+	return &BookmarkRepository{
+		bookmarks: make(map[string]map[string]entity.Bookmark),
+	}
+}
+
+// Stores a bookmark, keeping the original one if it already exists
+func (r *BookmarkRepository) Save(bookmark *entity.Bookmark) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	userBookmarks, exists := r.bookmarks[bookmark.UserID]
+	if !exists {
+		userBookmarks = make(map[string]entity.Bookmark)
+		r.bookmarks[bookmark.UserID] = userBookmarks
+	}
+	if _, exists := userBookmarks[bookmark.TweetID]; !exists {
+		userBookmarks[bookmark.TweetID] = *bookmark
+	}
+	return nil
+}
+
+// Removes a user's bookmark of a tweet
+func (r *BookmarkRepository) Delete(userID, tweetID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.bookmarks[userID], tweetID)
+	return nil
+}
+
+// Retrieves all bookmarks of a user, newest first
+func (r *BookmarkRepository) FindByUserID(userID string) ([]*entity.Bookmark, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	bookmarks := make([]*entity.Bookmark, 0, len(r.bookmarks[userID]))
+	for _, bookmark := range r.bookmarks[userID] {
+		bookmark := bookmark
+		bookmarks = append(bookmarks, &bookmark)
+	}
+
+	sortBookmarks(bookmarks)
+	return bookmarks, nil
+}
+
+// Sorts bookmarks newest first, breaking ties by tweet ID
+func sortBookmarks(bookmarks []*entity.Bookmark) {
+	sort.Slice(bookmarks, func(i, j int) bool {
+		if !bookmarks[i].CreatedAt.Equal(bookmarks[j].CreatedAt) {
+			return bookmarks[i].CreatedAt.After(bookmarks[j].CreatedAt)
+		}
+		return bookmarks[i].TweetID > bookmarks[j].TweetID
+	})
+}
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Implements the list repository interface with an in-memory storage
+// Lists are copied on the way in and out, so callers never share the stored member set
+type ListRepository struct {
+	lists map[string]*entity.List // Map of list ID to list
+	mutex sync.RWMutex
+}
+
+// Creates a new in-memory list repository
+func NewListRepository() *ListRepository {
+	return &ListRepository{
+		lists: make(map[string]*entity.List),
+	}
+}
+
+// Stores a list in the repository
+func (r *ListRepository) SaveList(list *entity.List) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.lists[list.ID] = list.Clone()
+	return nil
+}
+
+// Retrieves a list by its ID
+func (r *ListRepository) FindListByID(id string) (*entity.List, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	list, exists := r.lists[id]
+	if !exists {
+		return nil, nil
+	}
+	return list.Clone(), nil
+}
+
+// Retrieves all lists owned by a user, oldest first
+func (r *ListRepository) FindListsByOwner(ownerID string) ([]*entity.List, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	lists := make([]*entity.List, 0)
+	for _, list := range r.lists {
+		if list.OwnerID == ownerID {
+			lists = append(lists, list.Clone())
+		}
+	}
+
+	sort.Slice(lists, func(i, j int) bool {
+		if !lists[i].CreatedAt.Equal(lists[j].CreatedAt) {
+			return lists[i].CreatedAt.Before(lists[j].CreatedAt)
+		}
+		return lists[i].ID < lists[j].ID
+	})
+	return lists, nil
+}
+
+// Adds a user to a list
+func (r *ListRepository) AddMember(listID, userID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	list, exists := r.lists[listID]
+	if !exists {
+		return entity.ErrListNotFound
+	}
+	list.AddMember(userID)
+	return nil
+}
+
+// Removes a user from a list
+func (r *ListRepository) RemoveMember(listID, userID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	list, exists := r.lists[listID]
+	if !exists {
+		return entity.ErrListNotFound
+	}
+	list.RemoveMember(userID)
+	return nil
+}
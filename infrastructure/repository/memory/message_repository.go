@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Implements the message repository interface with an in-memory storage
+type MessageRepository struct {
+	messages map[string][]entity.Message // Map of conversation ID to its messages, oldest first
+	mutex    sync.RWMutex
+}
+
+// Creates a new in-memory message repository
+func NewMessageRepository() *MessageRepository {
+	return &MessageRepository{
+		messages: make(map[string][]entity.Message),
+	}
+}
+
+// Stores a message in its conversation, keeping the conversation in time order
+func (r *MessageRepository) Save(message *entity.Message) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	messages := append(r.messages[message.ConversationID], *message)
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+	r.messages[message.ConversationID] = messages
+	return nil
+}
+
+// Retrieves up to limit of the latest messages of a conversation, oldest first
+func (r *MessageRepository) FindByConversationID(conversationID string, limit int) ([]*entity.Message, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	stored := r.messages[conversationID]
+	if limit > 0 && len(stored) > limit {
+		stored = stored[len(stored)-limit:]
+	}
+	messages := make([]*entity.Message, len(stored))
+	for i := range stored {
+		message := stored[i]
+		messages[i] = &message
+	}
+	return messages, nil
+}
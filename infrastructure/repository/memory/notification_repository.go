@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+)
+
+// Implements the notification repository interface with an in-memory storage
+type NotificationRepository struct {
+	notifications map[string][]entity.Notification // Map of recipient ID to their notifications, newest first
+	readAt        map[string]time.Time             // Map of recipient ID to the time they last marked everything read
+	mutex         sync.RWMutex
+}
+
+// Creates a new in-memory notification repository
+func NewNotificationRepository() *NotificationRepository {
+	return &NotificationRepository{
+		notifications: make(map[string][]entity.Notification),
+		readAt:        make(map[string]time.Time),
+	}
+}
+
+// Saves a notification, dropping the recipient's notifications beyond the cap
+// or older than the retention period before their newest one
+func (r *NotificationRepository) Save(notification *entity.Notification) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	notifications := r.notifications[notification.RecipientID]
+	for i := range notifications {
+		if notifications[i].ID == notification.ID {
+			notifications[i] = *notification
+			return nil
+		}
+	}
+	notifications = append(notifications, *notification)
+	sort.SliceStable(notifications, func(i, j int) bool {
+		return notifications[i].ID > notifications[j].ID
+	})
+
+	oldest := notifications[0].CreatedAt.Add(-repository.NotificationRetention)
+	kept := 0
+	for kept < len(notifications) && kept < repository.MaxNotificationsPerUser && !notifications[kept].CreatedAt.Before(oldest) {
+		kept++
+	}
+	r.notifications[notification.RecipientID] = notifications[:kept]
+	return nil
+}
+
+// Retrieves up to limit of the notifications of a recipient older than afterID, newest first
+func (r *NotificationRepository) FindByRecipientID(recipientID, afterID string, limit int) ([]*entity.Notification, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	stored := r.notifications[recipientID]
+	if afterID != "" {
+		// The notification afterID may have been dropped meanwhile, so look for the first older one
+		start := sort.Search(len(stored), func(i int) bool {
+			return stored[i].ID < afterID
+		})
+		stored = stored[start:]
+	}
+	if limit > 0 && len(stored) > limit {
+		stored = stored[:limit]
+	}
+
+	readAt := r.readAt[recipientID]
+	notifications := make([]*entity.Notification, len(stored))
+	for i := range stored {
+		notification := stored[i]
+		notification.Read = !notification.CreatedAt.After(readAt)
+		notifications[i] = &notification
+	}
+	return notifications, nil
+}
+
+// Counts the notifications of a recipient created after they last marked everything read
+func (r *NotificationRepository) CountUnread(recipientID string) (int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	readAt := r.readAt[recipientID]
+	unread := 0
+	for _, notification := range r.notifications[recipientID] {
+		if notification.CreatedAt.After(readAt) {
+			unread++
+		}
+	}
+	return unread, nil
+}
+
+// Marks every notification of a recipient created up to readAt as read
+// Marking at an earlier time than a previous mark keeps the later one
+func (r *NotificationRepository) MarkAllRead(recipientID string, readAt time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if readAt.After(r.readAt[recipientID]) {
+		r.readAt[recipientID] = readAt
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package memory_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+	"github.com/develpudu/go-challenge/infrastructure/repository/memory"
+)
+
+func TestNotificationRepositoryCapsNotifications(t *testing.T) {
+	// Arrange: a notification past the retention period, then more notifications than are kept
+	repo := memory.NewNotificationRepository()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo.Save(entity.NewNotification("star", entity.NotificationFollow, "ancient", "", base.Add(-repository.NotificationRetention-time.Hour)))
+	for i := range repository.MaxNotificationsPerUser + 5 {
+		repo.Save(entity.NewNotification("star", entity.NotificationFollow, fmt.Sprintf("user%03d", i), "", base.Add(time.Duration(i)*time.Minute)))
+	}
+
+	// Act
+	notifications, err := repo.FindByRecipientID("star", "", 0)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(notifications) != repository.MaxNotificationsPerUser {
+		t.Fatalf("Expected %d notifications, got %d", repository.MaxNotificationsPerUser, len(notifications))
+	}
+	if notifications[0].ActorID != "user104" || notifications[len(notifications)-1].ActorID != "user005" {
+		t.Errorf("Expected the newest notifications first, got %s to %s", notifications[0].ActorID, notifications[len(notifications)-1].ActorID)
+	}
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+)
+
+// Implements the report repository interface with an in-memory storage
+type ReportRepository struct {
+	reports map[string]map[string]entity.Report // Map of tweet ID to reports by reporter ID
+	mutex   sync.RWMutex
+}
+
+// Creates a new in-memory report repository
+func NewReportRepository() *ReportRepository {
+	return &ReportRepository{
+		reports: make(map[string]map[string]entity.Report),
+	}
+}
+
+// Stores a report, rejecting a second report of the same tweet by the same user
+func (r *ReportRepository) Save(report *entity.Report) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	tweetReports, exists := r.reports[report.TweetID]
+	if !exists {
+		tweetReports = make(map[string]entity.Report)
+		r.reports[report.TweetID] = tweetReports
+	}
+	if _, exists := tweetReports[report.ReporterID]; exists {
+		return entity.ErrAlreadyReported
+	}
+	tweetReports[report.ReporterID] = *report
+	return nil
+}
+
+// Retrieves all reports, newest first
+func (r *ReportRepository) FindAll() ([]*entity.Report, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	reports := make([]*entity.Report, 0)
+	for _, tweetReports := range r.reports {
+		for _, report := range tweetReports {
+			report := report
+			reports = append(reports, &report)
+		}
+	}
+
+	sortReports(reports)
+	return reports, nil
+}
+
+// Sorts reports newest first, breaking ties by tweet and reporter ID
+func sortReports(reports []*entity.Report) {
+	sort.Slice(reports, func(i, j int) bool {
+		if !reports[i].CreatedAt.Equal(reports[j].CreatedAt) {
+			return reports[i].CreatedAt.After(reports[j].CreatedAt)
+		}
+		if reports[i].TweetID != reports[j].TweetID {
+			return reports[i].TweetID > reports[j].TweetID
+		}
+		return reports[i].ReporterID > reports[j].ReporterID
+	})
+}
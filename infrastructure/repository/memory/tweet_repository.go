@@ -1,12 +1,21 @@
 package memory
 
 import (
+	"context"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// Name of the tracer used for repository spans
+const tracerName = "github.com/develpudu/go-challenge/infrastructure/repository/memory"
+
 // Implements the tweet repository interface with an in-memory storage
 type TweetRepository struct {
 	tweets       map[string]*entity.Tweet   // Map of tweet ID to tweet
@@ -44,6 +53,50 @@ func (r *TweetRepository) Save(tweet *entity.Tweet) error {
 	return nil
 }
 
+// Stores several tweets in the repository, invalidating timelines once
+func (r *TweetRepository) SaveAll(tweets []*entity.Tweet) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, tweet := range tweets {
+		r.tweets[tweet.ID] = tweet
+		r.userTweets[tweet.UserID] = append(r.userTweets[tweet.UserID], tweet)
+	}
+
+	// Invalidate timelines once for the whole batch
+	if len(tweets) > 0 {
+		r.invalidateTimelines(tweets[0].UserID)
+	}
+
+	return nil
+}
+
+// Replaces an existing tweet
+func (r *TweetRepository) Update(tweet *entity.Tweet) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Check if tweet exists
+	if _, exists := r.tweets[tweet.ID]; !exists {
+		return entity.ErrTweetNotFound
+	}
+
+	// Replace the tweet in both indexes
+	r.tweets[tweet.ID] = tweet
+	tweets := r.userTweets[tweet.UserID]
+	for i, t := range tweets {
+		if t.ID == tweet.ID {
+			tweets[i] = tweet
+			break
+		}
+	}
+
+	// Invalidate timelines that include the old content
+	r.invalidateTimelines(tweet.UserID)
+
+	return nil
+}
+
 // Retrieves a tweet by its ID
 func (r *TweetRepository) FindByID(id string) (*entity.Tweet, error) {
 	r.mutex.RLock()
@@ -67,16 +120,44 @@ func (r *TweetRepository) FindByUserID(userID string) ([]*entity.Tweet, error) {
 		return []*entity.Tweet{}, nil // Return empty slice when no tweets found
 	}
 
-	// Sort tweets by creation time (newest first)
+	// Sort tweets by creation time (newest first), breaking ties by ID
 	sortedTweets := make([]*entity.Tweet, len(tweets))
 	copy(sortedTweets, tweets)
 	sort.Slice(sortedTweets, func(i, j int) bool {
-		return sortedTweets[i].CreatedAt.After(sortedTweets[j].CreatedAt)
+		return sortedTweets[i].IsNewerThan(sortedTweets[j])
 	})
 
 	return sortedTweets, nil
 }
 
+// Returns the number of tweets by a specific user that have not expired at now
+func (r *TweetRepository) CountByUserID(userID string, now time.Time) (int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	count := 0
+	for _, tweet := range r.userTweets[userID] {
+		if !tweet.IsExpired(now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Returns the creation times of the tweets by a specific user created between from and to, both inclusive
+func (r *TweetRepository) FindCreatedAtByUserID(userID string, from, to time.Time) ([]time.Time, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	times := make([]time.Time, 0)
+	for _, tweet := range r.userTweets[userID] {
+		if !tweet.CreatedAt.Before(from) && !tweet.CreatedAt.After(to) {
+			times = append(times, tweet.CreatedAt)
+		}
+	}
+	return times, nil
+}
+
 // Retrieves all tweets
 func (r *TweetRepository) FindAll() ([]*entity.Tweet, error) {
 	r.mutex.RLock()
@@ -87,9 +168,48 @@ func (r *TweetRepository) FindAll() ([]*entity.Tweet, error) {
 		tweets = append(tweets, tweet)
 	}
 
-	// Sort tweets by creation time (newest first)
+	// Sort tweets by creation time (newest first), breaking ties by ID
+	sort.Slice(tweets, func(i, j int) bool {
+		return tweets[i].IsNewerThan(tweets[j])
+	})
+
+	return tweets, nil
+}
+
+// Returns the number of stored tweets
+func (r *TweetRepository) Count() (int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return len(r.tweets), nil
+}
+
+// Counts the stored tweets created in each bucket of the histogram
+func (r *TweetRepository) CountCreatedPerBucket(histogram *repository.TweetHistogram) error {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, tweet := range r.tweets {
+		histogram.Add(tweet.CreatedAt)
+	}
+	return nil
+}
+
+// Retrieves all tweets of a conversation, oldest first
+func (r *TweetRepository) FindByConversationID(conversationID string) ([]*entity.Tweet, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	tweets := make([]*entity.Tweet, 0)
+	for _, tweet := range r.tweets {
+		if tweet.RootID() == conversationID {
+			tweets = append(tweets, tweet)
+		}
+	}
+
+	// Sort tweets by creation time (oldest first), breaking ties by ID
 	sort.Slice(tweets, func(i, j int) bool {
-		return tweets[i].CreatedAt.After(tweets[j].CreatedAt)
+		return tweets[j].IsNewerThan(tweets[i])
 	})
 
 	return tweets, nil
@@ -128,13 +248,32 @@ func (r *TweetRepository) Delete(id string) error {
 	return nil
 }
 
-// Retrieves tweets from users that a specific user follows
-// ordered by creation time (newest first)
-func (r *TweetRepository) GetTimeline(userID string) ([]*entity.Tweet, error) {
+// Adds a number of impressions to a stored tweet
+func (r *TweetRepository) AddImpressions(id string, count int64) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	tweet, exists := r.tweets[id]
+	if !exists {
+		return entity.ErrTweetNotFound
+	}
+	tweet.Impressions += count
+	return nil
+}
+
+// Retrieves tweets from users that a specific user follows, and their own tweets
+// and replies when opts includes them, ordered by creation time (newest first)
+// unless opts has a ranker
+func (r *TweetRepository) GetTimeline(ctx context.Context, userID string, opts repository.TimelineOptions) ([]*entity.Tweet, error) {
+	_, span := otel.Tracer(tracerName).Start(ctx, "memory.TweetRepository.GetTimeline", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer span.End()
+
 	r.mutex.RLock()
 
-	// Check if we have a cached timeline
-	cachedTimeline, exists := r.userTimeline[userID]
+	// Check if we have a cached timeline, each variant is cached under its own key
+	cacheKey := opts.CacheKey(userID)
+	cachedTimeline, exists := r.userTimeline[cacheKey]
+	span.SetAttributes(attribute.Bool("cache.hit", exists))
 	if exists {
 		r.mutex.RUnlock()
 		return cachedTimeline, nil
@@ -156,27 +295,35 @@ func (r *TweetRepository) GetTimeline(userID string) ([]*entity.Tweet, error) {
 	followingIDs := user.GetFollowing()
 
 	// Add the user's own ID to include their tweets in the timeline
-	followingIDs = append(followingIDs, userID)
-
-	// Lock for writing as we'll update the cache
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+	if opts.IncludeSelf {
+		followingIDs = append(followingIDs, userID)
+	}
 
 	// Collect tweets from followed users and the user themselves
+	r.mutex.RLock()
 	timeline := make([]*entity.Tweet, 0)
 	for _, followedID := range followingIDs {
 		if tweets, exists := r.userTweets[followedID]; exists {
 			timeline = append(timeline, tweets...)
 		}
 	}
+	r.mutex.RUnlock()
+
+	// A tweet reachable through more than one followed ID is kept once
+	timeline = opts.Filter(entity.UniqueTweets(timeline))
 
-	// Sort timeline by creation time (newest first)
+	// Sort timeline by creation time (newest first), breaking ties by ID
 	sort.Slice(timeline, func(i, j int) bool {
-		return timeline[i].CreatedAt.After(timeline[j].CreatedAt)
+		return timeline[i].IsNewerThan(timeline[j])
 	})
 
+	// Rank without holding the lock, rankers may read tweets back from this repository
+	timeline = opts.Rank(timeline, user)
+
 	// Cache the timeline
-	r.userTimeline[userID] = timeline
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.userTimeline[cacheKey] = timeline
 
 	return timeline, nil
 }
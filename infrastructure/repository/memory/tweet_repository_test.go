@@ -0,0 +1,89 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+	"github.com/develpudu/go-challenge/infrastructure/repository/memory"
+)
+
+func TestGetTimelineDeduplicatesTweets(t *testing.T) {
+	// Arrange: alice's tweets are reachable both as her own and through a stored self-follow
+	userRepo := memory.NewUserRepository()
+	alice := entity.NewUser("alice", "alice")
+	alice.Following["alice"] = true
+	userRepo.Save(alice)
+	repo := memory.NewTweetRepository(userRepo)
+	tweet, _ := entity.NewTweet("tweet1", "alice", "Hello")
+	repo.Save(tweet)
+
+	// Act
+	timeline, err := repo.GetTimeline(context.Background(), "alice", repository.DefaultTimelineOptions())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(timeline) != 1 || timeline[0].ID != "tweet1" {
+		t.Errorf("Expected tweet1 once, got %v", timeline)
+	}
+}
+
+func TestTweetsSharingTimestampHaveStableOrder(t *testing.T) {
+	// Arrange: tweets created in the same instant, saved in an arbitrary order
+	userRepo := memory.NewUserRepository()
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	repo := memory.NewTweetRepository(userRepo)
+	createdAt := time.Now()
+	for _, id := range []string{"tweet2", "tweet4", "tweet1", "tweet3"} {
+		tweet, _ := entity.NewTweetAt(id, "alice", "Same instant "+id, createdAt)
+		repo.Save(tweet)
+	}
+	expected := "[tweet4 tweet3 tweet2 tweet1]"
+
+	for i := 0; i < 20; i++ {
+		// Act
+		timeline, _ := repo.GetTimeline(context.Background(), "alice", repository.DefaultTimelineOptions())
+		byUser, _ := repo.FindByUserID("alice")
+		all, _ := repo.FindAll()
+
+		// Assert
+		for name, tweets := range map[string][]*entity.Tweet{"timeline": timeline, "FindByUserID": byUser, "FindAll": all} {
+			ids := make([]string, len(tweets))
+			for j, tweet := range tweets {
+				ids[j] = tweet.ID
+			}
+			if got := fmt.Sprint(ids); got != expected {
+				t.Fatalf("Expected %s ordered %s, got %s", name, expected, got)
+			}
+		}
+	}
+}
+
+func TestCountCreatedPerBucketAlignsToUTCDays(t *testing.T) {
+	// Arrange: tweets created just before and at midnight UTC, expressed in another zone
+	userRepo := memory.NewUserRepository()
+	repo := memory.NewTweetRepository(userRepo)
+	zone := time.FixedZone("UTC-3", -3*60*60)
+	midnight := time.Date(2024, 1, 1, 21, 0, 0, 0, zone)
+	for i, createdAt := range []time.Time{midnight.Add(-time.Nanosecond), midnight, midnight.Add(24 * time.Hour)} {
+		tweet, _ := entity.NewTweetAt(fmt.Sprintf("tweet%d", i), "alice", "Hello", createdAt)
+		repo.Save(tweet)
+	}
+
+	// Act: the range starts and ends mid-day
+	histogram := repository.NewTweetHistogram(midnight.Add(-time.Hour), midnight.Add(time.Hour), repository.DayBucket)
+	err := repo.CountCreatedPerBucket(histogram)
+
+	// Assert: the buckets widen to whole UTC days and the last day is excluded
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !histogram.Start.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || fmt.Sprint(histogram.Counts) != "[1 1]" {
+		t.Errorf("Expected counts [1 1] from 2024-01-01, got %v from %v", histogram.Counts, histogram.Start)
+	}
+}
@@ -1,21 +1,27 @@
 package memory
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/develpudu/go-challenge/domain/entity"
 )
 
 // Implements the user repository interface with an in-memory storage
+// Users are copied on the way in and out, so callers can only change
+// the stored state through the repository methods
 type UserRepository struct {
 	users map[string]*entity.User
-	mutex sync.RWMutex
+	// Map of normalized username to the ID of the user holding it
+	usernames map[string]string
+	mutex     sync.RWMutex
 }
 
 // Creates a new in-memory user repository
 func NewUserRepository() *UserRepository {
 	return &UserRepository{
-		users: make(map[string]*entity.User),
+		users:     make(map[string]*entity.User),
+		usernames: make(map[string]string),
 	}
 }
 
@@ -24,8 +30,26 @@ func (r *UserRepository) Save(user *entity.User) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
+	if err := r.claimUsername(user); err != nil {
+		return err
+	}
+
 	// Store a copy of the user to prevent external modifications
-	r.users[user.ID] = user
+	r.users[user.ID] = user.Clone()
+	return nil
+}
+
+// Records the user as the holder of their normalized username, releasing the one they had before
+// Returns ErrUsernameTaken if another user holds it; the caller must hold the write lock
+func (r *UserRepository) claimUsername(user *entity.User) error {
+	key := entity.NormalizeUsername(user.Username)
+	if holderID, taken := r.usernames[key]; taken && holderID != user.ID {
+		return entity.ErrUsernameTaken
+	}
+	if previous, exists := r.users[user.ID]; exists {
+		delete(r.usernames, entity.NormalizeUsername(previous.Username))
+	}
+	r.usernames[key] = user.ID
 	return nil
 }
 
@@ -39,7 +63,33 @@ func (r *UserRepository) FindByID(id string) (*entity.User, error) {
 		return nil, nil // Return nil, nil when user not found as per interface contract
 	}
 
-	return user, nil
+	return user.Clone(), nil
+}
+
+// Retrieves the users with the given IDs, keyed by ID, skipping missing users
+func (r *UserRepository) FindByIDs(ids []string) (map[string]*entity.User, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	users := make(map[string]*entity.User, len(ids))
+	for _, id := range ids {
+		if user, exists := r.users[id]; exists {
+			users[id] = user.Clone()
+		}
+	}
+	return users, nil
+}
+
+// Retrieves a user by their username in any case
+func (r *UserRepository) FindByUsername(username string) (*entity.User, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	userID, exists := r.usernames[entity.NormalizeUsername(username)]
+	if !exists {
+		return nil, nil
+	}
+	return r.users[userID].Clone(), nil
 }
 
 // Retrieves all users
@@ -49,12 +99,67 @@ func (r *UserRepository) FindAll() ([]*entity.User, error) {
 
 	users := make([]*entity.User, 0, len(r.users))
 	for _, user := range r.users {
-		users = append(users, user)
+		users = append(users, user.Clone())
 	}
 
 	return users, nil
 }
 
+// Retrieves up to limit users with an ID greater than afterID, ordered by ID
+func (r *UserRepository) FindAllPage(afterID string, limit int) ([]*entity.User, error) {
+	users, err := r.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return pageUsers(users, afterID, limit), nil
+}
+
+// Returns the number of stored users
+func (r *UserRepository) Count() (int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return len(r.users), nil
+}
+
+// Returns the number of follow relations between users
+func (r *UserRepository) CountFollows() (int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	follows := 0
+	for _, user := range r.users {
+		follows += user.FollowingCount()
+	}
+	return follows, nil
+}
+
+// Returns the number of users that follow a specific user
+func (r *UserRepository) CountFollowers(userID string) (int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	followers := 0
+	for _, user := range r.users {
+		if user.IsFollowing(userID) {
+			followers++
+		}
+	}
+	return followers, nil
+}
+
+// Returns the number of users a specific user follows
+func (r *UserRepository) CountFollowing(userID string) (int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	user, exists := r.users[userID]
+	if !exists {
+		return 0, entity.ErrUserNotFound
+	}
+	return user.FollowingCount(), nil
+}
+
 // Updates an existing user
 func (r *UserRepository) Update(user *entity.User) error {
 	r.mutex.Lock()
@@ -65,9 +170,25 @@ func (r *UserRepository) Update(user *entity.User) error {
 	if !exists {
 		return entity.ErrUserNotFound
 	}
+	if err := r.claimUsername(user); err != nil {
+		return err
+	}
 
-	// Update user
-	r.users[user.ID] = user
+	// Update user with a copy to prevent external modifications
+	r.users[user.ID] = user.Clone()
+	return nil
+}
+
+// Stores whether a user's account is active
+func (r *UserRepository) SetActive(userID string, active bool) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	user, exists := r.users[userID]
+	if !exists {
+		return entity.ErrUserNotFound
+	}
+	user.Active = active
 	return nil
 }
 
@@ -77,13 +198,14 @@ func (r *UserRepository) Delete(id string) error {
 	defer r.mutex.Unlock()
 
 	// Check if user exists
-	_, exists := r.users[id]
+	user, exists := r.users[id]
 	if !exists {
 		return entity.ErrUserNotFound
 	}
 
-	// Delete user
+	// Delete user and release their username
 	delete(r.users, id)
+	delete(r.usernames, entity.NormalizeUsername(user.Username))
 	return nil
 }
 
@@ -97,7 +219,7 @@ func (r *UserRepository) FindFollowers(userID string) ([]*entity.User, error) {
 	// Iterate through all users and check if they follow the specified user
 	for _, user := range r.users {
 		if user.IsFollowing(userID) {
-			followers = append(followers, user)
+			followers = append(followers, user.Clone())
 		}
 	}
 
@@ -124,9 +246,76 @@ func (r *UserRepository) FindFollowing(userID string) ([]*entity.User, error) {
 	// Get the user objects for each following ID
 	for _, id := range followingIDs {
 		if followedUser, exists := r.users[id]; exists {
-			following = append(following, followedUser)
+			following = append(following, followedUser.Clone())
 		}
 	}
 
 	return following, nil
 }
+
+// Retrieves up to limit followers of a user with an ID greater than afterID, ordered by ID
+func (r *UserRepository) FindFollowersPage(userID, afterID string, limit int) ([]*entity.User, error) {
+	followers, err := r.FindFollowers(userID)
+	if err != nil {
+		return nil, err
+	}
+	return pageUsers(followers, afterID, limit), nil
+}
+
+// Retrieves up to limit users followed by a user with an ID greater than afterID, ordered by ID
+func (r *UserRepository) FindFollowingPage(userID, afterID string, limit int) ([]*entity.User, error) {
+	following, err := r.FindFollowing(userID)
+	if err != nil {
+		return nil, err
+	}
+	return pageUsers(following, afterID, limit), nil
+}
+
+// Sorts users by ID and returns up to limit of them with an ID greater than afterID
+// A non-positive limit returns all remaining users
+func pageUsers(users []*entity.User, afterID string, limit int) []*entity.User {
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+
+	page := make([]*entity.User, 0)
+	for _, user := range users {
+		if limit > 0 && len(page) == limit {
+			break
+		}
+		if user.ID > afterID {
+			page = append(page, user)
+		}
+	}
+	return page
+}
+
+// Records that a user follows another user
+func (r *UserRepository) Follow(followerID, followedID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Both users must exist
+	follower, exists := r.users[followerID]
+	if !exists {
+		return entity.ErrUserNotFound
+	}
+	if _, exists := r.users[followedID]; !exists {
+		return entity.ErrUserNotFound
+	}
+
+	return follower.Follow(followedID)
+}
+
+// Removes the follow relation between two users
+func (r *UserRepository) Unfollow(followerID, followedID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	follower, exists := r.users[followerID]
+	if !exists {
+		return entity.ErrUserNotFound
+	}
+
+	return follower.Unfollow(followedID)
+}
@@ -0,0 +1,143 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/infrastructure/repository/memory"
+)
+
+func TestUserRepositoryReturnsCopies(t *testing.T) {
+	// Arrange
+	repo := memory.NewUserRepository()
+	repo.Save(entity.NewUser("user1", "testuser1"))
+	repo.Save(entity.NewUser("user2", "testuser2"))
+
+	// Act: mutate a returned user without calling Update
+	user, _ := repo.FindByID("user1")
+	_ = user.Follow("user2")
+	user.Username = "changed"
+
+	// Assert
+	stored, _ := repo.FindByID("user1")
+	if stored.IsFollowing("user2") {
+		t.Error("Expected stored user to be unchanged before Update, but it is following user2")
+	}
+	if stored.Username != "testuser1" {
+		t.Errorf("Expected stored username to be testuser1, got %s", stored.Username)
+	}
+
+	// Act: persist the change
+	if err := repo.Update(user); err != nil {
+		t.Fatalf("Expected no error on Update, got %v", err)
+	}
+
+	// Assert
+	stored, _ = repo.FindByID("user1")
+	if !stored.IsFollowing("user2") {
+		t.Error("Expected stored user to be following user2 after Update")
+	}
+}
+
+func TestUserRepositoryStoresCopyOnSave(t *testing.T) {
+	// Arrange
+	repo := memory.NewUserRepository()
+	user := entity.NewUser("user1", "testuser1")
+	repo.Save(user)
+
+	// Act: mutate the saved pointer
+	_ = user.Follow("user2")
+
+	// Assert
+	stored, _ := repo.FindByID("user1")
+	if stored.IsFollowing("user2") {
+		t.Error("Expected stored user to be unaffected by mutations of the saved pointer")
+	}
+
+	all, _ := repo.FindAll()
+	_ = all[0].Follow("user3")
+	stored, _ = repo.FindByID("user1")
+	if stored.IsFollowing("user3") {
+		t.Error("Expected stored user to be unaffected by mutations of FindAll results")
+	}
+}
+
+func TestUserRepositoryCountsMatchLists(t *testing.T) {
+	// Arrange
+	repo := memory.NewUserRepository()
+	for _, id := range []string{"user1", "user2", "user3"} {
+		repo.Save(entity.NewUser(id, "test"+id))
+	}
+	repo.Follow("user1", "user2")
+	repo.Follow("user3", "user2")
+	repo.Follow("user1", "user3")
+
+	for _, userID := range []string{"user1", "user2", "user3"} {
+		// Act
+		followerCount, followersErr := repo.CountFollowers(userID)
+		followingCount, followingErr := repo.CountFollowing(userID)
+
+		// Assert
+		if followersErr != nil || followingErr != nil {
+			t.Fatalf("Expected no errors for %s, got %v and %v", userID, followersErr, followingErr)
+		}
+		followers, _ := repo.FindFollowers(userID)
+		following, _ := repo.FindFollowing(userID)
+		if followerCount != len(followers) || followingCount != len(following) {
+			t.Errorf("Expected counts %d and %d for %s, got %d and %d", len(followers), len(following), userID, followerCount, followingCount)
+		}
+	}
+}
+
+func TestUserRepositoryUsernamesIgnoreCase(t *testing.T) {
+	// Arrange
+	repo := memory.NewUserRepository()
+	if err := repo.Save(entity.NewUser("user1", "Alice")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Act
+	err := repo.Save(entity.NewUser("user2", "alice"))
+
+	// Assert
+	if err != entity.ErrUsernameTaken {
+		t.Errorf("Expected ErrUsernameTaken, got %v", err)
+	}
+	for _, username := range []string{"Alice", "alice", "ALICE"} {
+		user, err := repo.FindByUsername(username)
+		if err != nil || user == nil || user.ID != "user1" || user.Username != "Alice" {
+			t.Errorf("Expected to find Alice by %q, got %v and %v", username, user, err)
+		}
+	}
+
+	// Saving the holder again keeps the username, deleting it frees the username
+	if err := repo.Update(entity.NewUser("user1", "Alice")); err != nil {
+		t.Errorf("Expected the holder to update their user, got %v", err)
+	}
+	repo.Delete("user1")
+	if err := repo.Save(entity.NewUser("user2", "alice")); err != nil {
+		t.Errorf("Expected the username to be free after deleting its holder, got %v", err)
+	}
+}
+
+func TestUserRepositoryFindByIDsSkipsMissingUsers(t *testing.T) {
+	// Arrange
+	repo := memory.NewUserRepository()
+	repo.Save(entity.NewUser("alice", "alice"))
+	repo.Save(entity.NewUser("bob", "bob"))
+
+	// Act
+	users, err := repo.FindByIDs([]string{"alice", "ghost", "bob", "alice"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(users) != 2 || users["alice"] == nil || users["bob"] == nil {
+		t.Fatalf("Expected alice and bob only, got %v", users)
+	}
+	users["alice"].Username = "mallory"
+	if stored, _ := repo.FindByID("alice"); stored.Username != "alice" {
+		t.Error("Expected FindByIDs to return copies")
+	}
+}
@@ -2,15 +2,31 @@ package integration
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/develpudu/go-challenge/application/usecase"
 	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
 	"github.com/develpudu/go-challenge/infrastructure/api/handler"
 	"github.com/develpudu/go-challenge/infrastructure/repository/memory"
+	"github.com/develpudu/go-challenge/infrastructure/tracing"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // Returns a test API server
@@ -24,16 +40,31 @@ func setupTestAPI(t *testing.T) (http.Handler, *memory.UserRepository, *memory.T
 
 	// Initialize use cases
 	// Pass nil for TimelineCache as it's not used in memory-based integration tests
-	userUseCase := usecase.NewUserUseCase(userRepo, nil)
-	tweetUseCase := usecase.NewTweetUseCase(tweetRepo, userRepo)
+	notificationRepo := memory.NewNotificationRepository()
+	blockRepo := memory.NewBlockRepository()
+	userUseCase := usecase.NewUserUseCase(userRepo, nil, usecase.WithNotifications(notificationRepo), usecase.WithBlocks(blockRepo))
+	tweetUseCase := usecase.NewTweetUseCase(tweetRepo, userRepo, usecase.WithNotifications(notificationRepo))
+	bookmarkUseCase := usecase.NewBookmarkUseCase(memory.NewBookmarkRepository(), tweetRepo, userRepo)
+	listUseCase := usecase.NewListUseCase(memory.NewListRepository(), tweetRepo, userRepo, nil)
+	notificationUseCase := usecase.NewNotificationUseCase(notificationRepo, userRepo)
+	messageUseCase := usecase.NewMessageUseCase(memory.NewMessageRepository(), blockRepo, userRepo, usecase.WithFollowRemoval(userUseCase))
 
 	// Initialize handlers
-	userHandler := handler.NewUserHandler(userUseCase)
-	tweetHandler := handler.NewTweetHandler(tweetUseCase)
+	identity := handler.NewIdentityMiddleware(userUseCase)
+	userHandler := handler.NewUserHandler(userUseCase, identity)
+	tweetHandler := handler.NewTweetHandler(tweetUseCase, identity)
+	bookmarkHandler := handler.NewBookmarkHandler(bookmarkUseCase, identity)
+	listHandler := handler.NewListHandler(listUseCase, identity)
+	notificationHandler := handler.NewNotificationHandler(notificationUseCase, identity)
+	messageHandler := handler.NewMessageHandler(messageUseCase, identity)
 
 	// Register routes
 	userHandler.RegisterRoutes()
 	tweetHandler.RegisterRoutes()
+	bookmarkHandler.RegisterRoutes()
+	listHandler.RegisterRoutes()
+	notificationHandler.RegisterRoutes()
+	messageHandler.RegisterRoutes()
 
 	return http.DefaultServeMux, userRepo, tweetRepo
 }
@@ -144,6 +175,31 @@ func TestCreateAndGetTweet(t *testing.T) {
 	}
 }
 
+func TestGetMissingOrExpiredTweet(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	expired, _ := entity.NewTweetAt("expired", "alice", "Gone soon", time.Now().Add(-2*time.Hour))
+	expired.ExpiresAt = time.Now().Add(-time.Hour)
+	tweetRepo.Save(expired)
+
+	for _, tweetID := range []string{"missing", "expired"} {
+		req, _ := http.NewRequest("GET", "/tweets/"+tweetID, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		// Check response
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("GET /tweets/%s: handler returned wrong status code: got %v want %v", tweetID, rr.Code, http.StatusNotFound)
+		}
+		var response handler.ErrorResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		if response.Code != "tweet_not_found" {
+			t.Errorf("GET /tweets/%s: expected code tweet_not_found, got %+v", tweetID, response)
+		}
+	}
+}
+
 func TestFollowUserAndGetTimeline(t *testing.T) {
 	// Setup
 	router, userRepo, tweetRepo := setupTestAPI(t)
@@ -203,3 +259,2385 @@ func TestFollowUserAndGetTimeline(t *testing.T) {
 		t.Error("Expected timeline to contain the tweet from followed user, but it was not found")
 	}
 }
+
+func TestFollowPayloadIsNormalized(t *testing.T) {
+	// Setup
+	router, userRepo, _ := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	userRepo.Save(entity.NewUser("bob", "bob"))
+
+	tests := []struct {
+		name       string
+		followedID string
+		wantStatus int
+		wantCode   string
+	}{
+		{"surrounding whitespace is trimmed", "  bob\t", http.StatusOK, ""},
+		{"self-follow", "alice", http.StatusBadRequest, "cannot_follow_self"},
+		{"self-follow padded with whitespace", " alice ", http.StatusBadRequest, "cannot_follow_self"},
+		{"blank", "   ", http.StatusBadRequest, ""},
+		{"malformed", "b ob", http.StatusBadRequest, "invalid_id"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]string{"followed_id": tc.followedID})
+			req, _ := http.NewRequest("POST", "/users/follow", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("User-ID", "alice")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			// Check response
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, tc.wantStatus)
+			}
+			if tc.wantCode != "" {
+				var response handler.ErrorResponse
+				json.NewDecoder(rr.Body).Decode(&response)
+				if response.Code != tc.wantCode {
+					t.Errorf("Expected error code %q, got %q", tc.wantCode, response.Code)
+				}
+			}
+		})
+	}
+
+	// The trimmed ID reached the repository
+	alice, _ := userRepo.FindByID("alice")
+	if !alice.IsFollowing("bob") || len(alice.GetFollowing()) != 1 {
+		t.Errorf("Expected alice to follow bob only, got %v", alice.GetFollowing())
+	}
+}
+
+func TestBookmarkTweet(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+
+	user := entity.NewUser("reader123", "reader")
+	author := entity.NewUser("author123", "author")
+	userRepo.Save(user)
+	userRepo.Save(author)
+	tweet, _ := entity.NewTweet("tweet123", author.ID, "Worth saving")
+	tweetRepo.Save(tweet)
+
+	// Bookmark the tweet
+	req, _ := http.NewRequest("POST", "/tweets/"+tweet.ID+"/bookmark", nil)
+	req.Header.Set("User-ID", user.ID)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNoContent {
+		t.Errorf("Handler returned wrong status code: got %v want %v", status, http.StatusNoContent)
+	}
+
+	// List bookmarks
+	req, _ = http.NewRequest("GET", "/users/bookmarks", nil)
+	req.Header.Set("User-ID", user.ID)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var bookmarks []map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &bookmarks)
+	if len(bookmarks) != 1 || bookmarks[0]["id"] != tweet.ID {
+		t.Fatalf("Expected the bookmarked tweet to be listed, got %v", bookmarks)
+	}
+
+	// Remove the bookmark
+	req, _ = http.NewRequest("DELETE", "/tweets/"+tweet.ID+"/bookmark", nil)
+	req.Header.Set("User-ID", user.ID)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNoContent {
+		t.Errorf("Handler returned wrong status code: got %v want %v", status, http.StatusNoContent)
+	}
+
+	// The tweet itself is still served by the tweet routes
+	req, _ = http.NewRequest("GET", "/tweets/"+tweet.ID, nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+}
+
+func TestReplyConversation(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+
+	user := entity.NewUser("user123", "testuser")
+	userRepo.Save(user)
+	root, _ := entity.NewTweet("root123", user.ID, "Root tweet")
+	tweetRepo.Save(root)
+
+	// Reply to the root tweet
+	replyJSON, _ := json.Marshal(map[string]string{"content": "A reply", "in_reply_to_id": root.ID})
+	req, _ := http.NewRequest("POST", "/tweets", bytes.NewBuffer(replyJSON))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-ID", user.ID)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", status, http.StatusCreated)
+	}
+	var reply map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &reply)
+	if reply["in_reply_to_id"] != root.ID || reply["conversation_id"] != root.ID {
+		t.Errorf("Expected the reply to join the root conversation, got %v", reply)
+	}
+
+	// Get the conversation from the reply
+	req, _ = http.NewRequest("GET", "/tweets/"+reply["id"].(string)+"/conversation", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	var thread []map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &thread)
+	if len(thread) != 2 || thread[0]["id"] != root.ID || thread[1]["id"] != reply["id"] {
+		t.Errorf("Expected the root followed by the reply, got %v", thread)
+	}
+}
+
+func TestQuoteTweet(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	original, _ := entity.NewTweet("original", "alice", "Worth quoting")
+	tweetRepo.Save(original)
+
+	quote := func(tweetID string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(handler.QuoteTweetRequest{Content: "So true"})
+		req, _ := http.NewRequest("POST", "/tweets/"+tweetID+"/quote", bytes.NewBuffer(body))
+		req.Header.Set("User-ID", "bob")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Quoting a missing tweet fails
+	if rr := quote("missing"); rr.Code != http.StatusNotFound {
+		t.Fatalf("Expected 404 for a missing quoted tweet, got %v", rr.Code)
+	}
+
+	// The quote embeds a summary of the quoted tweet
+	rr := quote(original.ID)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusCreated)
+	}
+	var created handler.TweetResponse
+	json.Unmarshal(rr.Body.Bytes(), &created)
+	if created.Content != "So true" || created.QuotedTweetID != original.ID {
+		t.Errorf("Expected bob's quote of the original, got %+v", created)
+	}
+	if created.QuotedTweet == nil || created.QuotedTweet.UserID != "alice" || created.QuotedTweet.Content != "Worth quoting" {
+		t.Errorf("Expected the summary of the quoted tweet, got %+v", created.QuotedTweet)
+	}
+
+	// Fetching the quote embeds the summary too
+	req, _ := http.NewRequest("GET", "/tweets/"+created.ID, nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	var fetched handler.TweetResponse
+	json.Unmarshal(rr.Body.Bytes(), &fetched)
+	if fetched.QuotedTweet == nil || fetched.QuotedTweet.ID != original.ID {
+		t.Errorf("Expected the fetched quote to embed the quoted tweet, got %+v", fetched)
+	}
+}
+
+func TestConditionalGetWithETag(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	alice := entity.NewUser("alice", "alice")
+	alice.Follow("bob")
+	userRepo.Save(alice)
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	tweet, _ := entity.NewTweet("tweet1", "bob", "Hello")
+	tweetRepo.Save(tweet)
+
+	get := func(target, etag string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", target, nil)
+		req.Header.Set("User-ID", "alice")
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for _, target := range []string{"/tweets/tweet1", "/timeline"} {
+		// The first response carries an ETag
+		rr := get(target, "")
+		etag := rr.Header().Get("ETag")
+		if rr.Code != http.StatusOK || etag == "" {
+			t.Fatalf("Expected 200 with an ETag for %s, got %v and %q", target, rr.Code, etag)
+		}
+
+		// Repeating the request with the ETag returns 304 without a body
+		rr = get(target, etag)
+		if rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+			t.Errorf("Expected 304 without body for %s, got %v with %q", target, rr.Code, rr.Body.String())
+		}
+	}
+
+	// Editing the tweet changes the ETag
+	rr := get("/timeline", "")
+	etag := rr.Header().Get("ETag")
+	tweet.EditWithMaxLength("Hello, edited", entity.MaxTweetLength, time.Now().Add(time.Second))
+	tweetRepo.Update(tweet)
+	if rr := get("/timeline", etag); rr.Code != http.StatusOK {
+		t.Errorf("Expected 200 once the tweet changed, got %v", rr.Code)
+	}
+}
+
+func TestETagChangesWhenLinkPreviewIsStored(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	alice := entity.NewUser("alice", "alice")
+	alice.Follow("bob")
+	userRepo.Save(alice)
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	tweet, _ := entity.NewTweet("tweet1", "bob", "Read https://example.com/post")
+	tweetRepo.Save(tweet)
+
+	get := func(target, etag string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", target, nil)
+		req.Header.Set("User-ID", "alice")
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	etags := make(map[string]string)
+	for _, target := range []string{"/tweets/tweet1", "/timeline"} {
+		etags[target] = get(target, "").Header().Get("ETag")
+	}
+
+	// Store the preview as the link previewer does, without changing UpdatedAt
+	withPreview := *tweet
+	withPreview.LinkPreview = &entity.LinkPreview{URL: "https://example.com/post", Title: "A post"}
+	tweetRepo.Update(&withPreview)
+
+	// Clients holding the old ETag get the tweet with its preview
+	for target, etag := range etags {
+		rr := get(target, etag)
+		if rr.Code != http.StatusOK || rr.Header().Get("ETag") == etag {
+			t.Errorf("Expected 200 with a new ETag for %s once the preview is stored, got %v with %q", target, rr.Code, rr.Header().Get("ETag"))
+		}
+	}
+}
+
+func TestErrorMessagesFollowAcceptLanguage(t *testing.T) {
+	// Setup
+	router, userRepo, _ := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+
+	tests := []struct {
+		acceptLanguage string
+		message        string
+	}{
+		{"es-AR,es;q=0.9", "tweet no encontrado"},
+		{"fr-FR, es;q=0.5", "tweet no encontrado"},
+		{"fr", "tweet not found"},
+		{"", "tweet not found"},
+		{"not a language", "tweet not found"},
+	}
+	for _, tt := range tests {
+		body, _ := json.Marshal(handler.UpdateTweetRequest{Content: "Hello"})
+		req, _ := http.NewRequest("PUT", "/tweets/missing", bytes.NewBuffer(body))
+		req.Header.Set("User-ID", "alice")
+		if tt.acceptLanguage != "" {
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+		}
+		var response handler.ErrorResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		if response.Error != tt.message || response.Code != "tweet_not_found" {
+			t.Errorf("Expected %q with code tweet_not_found for Accept-Language %q, got %+v", tt.message, tt.acceptLanguage, response)
+		}
+	}
+}
+
+func TestMalformedIDsAreRejected(t *testing.T) {
+	// Setup
+	router, userRepo, _ := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+
+	// Every route with an ID in the path, called by an existing user with a valid body
+	routes := []struct {
+		method, path, body string
+	}{
+		{"GET", "/tweets/%s", ""},
+		{"PUT", "/tweets/%s", `{"content": "Edited"}`},
+		{"DELETE", "/tweets/%s", ""},
+		{"GET", "/tweets/%s/conversation", ""},
+		{"POST", "/tweets/%s/quote", `{"content": "Quoting"}`},
+		{"POST", "/tweets/%s/bookmark", ""},
+		{"DELETE", "/tweets/%s/bookmark", ""},
+		{"GET", "/users/%s", ""},
+		{"GET", "/users/%s/followers", ""},
+		{"GET", "/users/%s/following", ""},
+		{"GET", "/users/%s/followed-by-friends", ""},
+		{"PUT", "/users/%s/follow", ""},
+		{"DELETE", "/users/%s/follow", ""},
+		{"POST", "/users/%s/block", ""},
+		{"POST", "/lists/%s/members", `{"user_id": "alice"}`},
+		{"DELETE", "/lists/%s/members/alice", ""},
+		{"GET", "/lists/%s/timeline", ""},
+	}
+	for _, id := range []string{"%00", "tweet%20one", "tweet.1", strings.Repeat("a", entity.MaxIDLength+1)} {
+		for _, route := range routes {
+			target := fmt.Sprintf(route.path, id)
+			req, _ := http.NewRequest(route.method, target, strings.NewReader(route.body))
+			req.Header.Set("User-ID", "alice")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected 400 for %s %s, got %v", route.method, target, rr.Code)
+				continue
+			}
+			var response handler.ErrorResponse
+			json.Unmarshal(rr.Body.Bytes(), &response)
+			if response.Code != "invalid_id" {
+				t.Errorf("Expected code invalid_id for %s %s, got %+v", route.method, target, response)
+			}
+		}
+	}
+
+	// Well-formed IDs that do not exist are still looked up
+	req, _ := http.NewRequest("GET", "/users/"+strings.Repeat("a", entity.MaxIDLength), nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a well-formed missing user, got %v", rr.Code)
+	}
+}
+
+func TestSubPathsAreNotFound(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	tweet, _ := entity.NewTweet("tweet1", "alice", "Hello")
+	tweetRepo.Save(tweet)
+
+	for _, target := range []string{
+		"/tweets/tweet1/extra",
+		"/tweets/tweet1/",
+		"/tweets/",
+		"/users/alice/extra",
+		"/users/alice/",
+		"/users/follow/",
+		"/users/",
+	} {
+		req, _ := http.NewRequest("GET", target, nil)
+		req.Header.Set("User-ID", "alice")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 for %s, got %v", target, rr.Code)
+		}
+	}
+
+	// Routes nested under an ID keep working
+	req, _ := http.NewRequest("GET", "/tweets/tweet1/conversation", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected 200 for the conversation route, got %v", rr.Code)
+	}
+}
+
+func TestHeadAndOptionsRequests(t *testing.T) {
+	// Setup
+	mux, userRepo, tweetRepo := setupTestAPI(t)
+	router := handler.MethodsMiddleware(mux.(*http.ServeMux))
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	tweet, _ := entity.NewTweet("tweet1", "alice", "Hello")
+	tweetRepo.Save(tweet)
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, target, nil)
+		req.Header.Set("User-ID", "alice")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// HEAD gets the status and headers of GET without a body
+	for _, target := range []string{"/tweets/tweet1", "/tweets", "/users/alice", "/timeline"} {
+		get := serve("GET", target)
+		head := serve("HEAD", target)
+		if head.Code != get.Code || head.Code != http.StatusOK {
+			t.Errorf("HEAD %s: expected status %v like GET, got %v", target, get.Code, head.Code)
+		}
+		if head.Body.Len() != 0 || get.Body.Len() == 0 {
+			t.Errorf("HEAD %s: expected an empty body, got %q", target, head.Body.String())
+		}
+		if head.Header().Get("Content-Type") != get.Header().Get("Content-Type") {
+			t.Errorf("HEAD %s: expected Content-Type %q, got %q", target, get.Header().Get("Content-Type"), head.Header().Get("Content-Type"))
+		}
+	}
+	if get, head := serve("GET", "/tweets/missing"), serve("HEAD", "/tweets/missing"); get.Code != http.StatusNotFound || head.Code != http.StatusNotFound || head.Body.Len() != 0 {
+		t.Errorf("Expected GET and HEAD of a missing tweet to get %v, HEAD without a body, got %v and %v %q", http.StatusNotFound, get.Code, head.Code, head.Body.String())
+	}
+
+	// OPTIONS lists the methods routed for each resource
+	for target, allow := range map[string]string{
+		"/tweets/tweet1":         "GET, HEAD, PUT, DELETE, OPTIONS",
+		"/tweets":                "GET, HEAD, POST, OPTIONS",
+		"/users/alice":           "GET, HEAD, DELETE, OPTIONS",
+		"/users/alice/follow":    "PUT, DELETE, OPTIONS",
+		"/users/follow/batch":    "POST, OPTIONS",
+		"/tweets/tweet1/comment": "",
+	} {
+		rr := serve("OPTIONS", target)
+		if allow == "" {
+			if rr.Code != http.StatusNotFound {
+				t.Errorf("OPTIONS %s: expected status %v, got %v", target, http.StatusNotFound, rr.Code)
+			}
+			continue
+		}
+		if rr.Code != http.StatusNoContent || rr.Header().Get("Allow") != allow || rr.Body.Len() != 0 {
+			t.Errorf("OPTIONS %s: expected an empty 204 allowing %q, got %v allowing %q", target, allow, rr.Code, rr.Header().Get("Allow"))
+		}
+	}
+
+	// Other methods are rejected, listing the allowed ones
+	rr := serve("PATCH", "/tweets/tweet1")
+	if rr.Code != http.StatusMethodNotAllowed || !strings.Contains(rr.Header().Get("Allow"), "GET") {
+		t.Errorf("Expected 405 with an Allow header, got %v allowing %q", rr.Code, rr.Header().Get("Allow"))
+	}
+}
+
+func TestCreateTweetReportsAllFieldErrors(t *testing.T) {
+	// Setup
+	router, userRepo, _ := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+
+	// Empty content and an invalid expiration in the same request
+	payload, _ := json.Marshal(map[string]string{"content": "", "expires_in": "soon"})
+	req, _ := http.NewRequest("POST", "/tweets", bytes.NewBuffer(payload))
+	req.Header.Set("User-ID", "alice")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	// Check response
+	if status := rr.Code; status != http.StatusUnprocessableEntity {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", status, http.StatusUnprocessableEntity)
+	}
+
+	var response handler.ValidationErrorResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	fields := make(map[string]string)
+	for _, fieldError := range response.Errors {
+		fields[fieldError.Field] = fieldError.Message
+	}
+	if len(fields) != 2 || fields["content"] == "" || fields["expires_in"] == "" {
+		t.Errorf("Expected errors for content and expires_in, got %+v", response.Errors)
+	}
+}
+
+func TestCreateUserValidationError(t *testing.T) {
+	// Setup
+	router, _, _ := setupTestAPI(t)
+
+	payload, _ := json.Marshal(map[string]string{"username": "a_very_long_username"})
+	req, _ := http.NewRequest("POST", "/users", bytes.NewBuffer(payload))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	// Check response
+	if status := rr.Code; status != http.StatusUnprocessableEntity {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", status, http.StatusUnprocessableEntity)
+	}
+
+	var response handler.ValidationErrorResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	if len(response.Errors) != 1 || response.Errors[0].Field != "username" {
+		t.Errorf("Expected a single username error, got %+v", response.Errors)
+	}
+}
+
+func TestIdentityMiddleware(t *testing.T) {
+	// Setup
+	router, userRepo, _ := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+
+	tests := []struct {
+		name     string
+		userID   string
+		expected int
+	}{
+		{name: "missing User-ID", userID: "", expected: http.StatusUnauthorized},
+		{name: "unknown user", userID: "ghost", expected: http.StatusNotFound},
+		{name: "existing user", userID: "alice", expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/timeline", nil)
+			if tt.userID != "" {
+				req.Header.Set("User-ID", tt.userID)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.expected {
+				t.Errorf("Handler returned wrong status code: got %v want %v", status, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIdentityFromAuthorizationHeader(t *testing.T) {
+	// Setup
+	router, userRepo, _ := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+
+	tests := []struct {
+		name          string
+		authorization string
+		userID        string
+		expected      int
+	}{
+		{name: "bearer token", authorization: "Bearer alice", expected: http.StatusOK},
+		{name: "legacy User-ID", userID: "alice", expected: http.StatusOK},
+		{name: "authorization takes precedence", authorization: "Bearer ghost", userID: "alice", expected: http.StatusNotFound},
+		{name: "unsupported scheme", authorization: "Basic YWxpY2U6", expected: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/timeline", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.userID != "" {
+				req.Header.Set("User-ID", tt.userID)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.expected {
+				t.Errorf("Handler returned wrong status code: got %v want %v", status, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetVersion(t *testing.T) {
+	// Setup
+	router, _, _ := setupTestAPI(t)
+	handler.NewVersionHandler("abc123", "2024-01-01T00:00:00Z", "local").RegisterRoutes()
+
+	req, _ := http.NewRequest("GET", "/version", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	// Check response
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response map[string]string
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	for _, key := range []string{"git_commit", "build_time", "go_version", "run_mode"} {
+		if response[key] == "" {
+			t.Errorf("Expected %s in response, got %v", key, response)
+		}
+	}
+	if response["git_commit"] != "abc123" || response["run_mode"] != "local" {
+		t.Errorf("Unexpected version info: %v", response)
+	}
+}
+
+func TestGzipCompressesLargeResponses(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	for i := 0; i < 50; i++ {
+		tweet, _ := entity.NewTweet(fmt.Sprintf("tweet%d", i), "alice", "A tweet long enough to make the response worth compressing")
+		tweetRepo.Save(tweet)
+	}
+	compressed := handler.GzipMiddleware(router)
+
+	// Uncompressed response for comparison
+	req, _ := http.NewRequest("GET", "/tweets", nil)
+	plain := httptest.NewRecorder()
+	compressed.ServeHTTP(plain, req)
+
+	// Compressed response
+	req, _ = http.NewRequest("GET", "/tweets", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	compressed.ServeHTTP(rr, req)
+
+	// Check response
+	if encoding := rr.Header().Get("Content-Encoding"); encoding != "gzip" {
+		t.Fatalf("Expected Content-Encoding gzip, got %q", encoding)
+	}
+	if rr.Body.Len() >= plain.Body.Len() {
+		t.Errorf("Expected compressed body (%d bytes) to be smaller than the original (%d bytes)", rr.Body.Len(), plain.Body.Len())
+	}
+	reader, err := gzip.NewReader(rr.Body)
+	if err != nil {
+		t.Fatalf("Failed to read gzip body: %v", err)
+	}
+	body, _ := io.ReadAll(reader)
+	if !bytes.Equal(body, plain.Body.Bytes()) {
+		t.Error("Expected the decompressed body to match the uncompressed response")
+	}
+}
+
+func TestGzipSkipsSmallResponses(t *testing.T) {
+	// Setup
+	router, _, _ := setupTestAPI(t)
+
+	req, _ := http.NewRequest("GET", "/tweets", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	handler.GzipMiddleware(router).ServeHTTP(rr, req)
+
+	// Check response
+	if encoding := rr.Header().Get("Content-Encoding"); encoding != "" {
+		t.Errorf("Expected no Content-Encoding for a small response, got %q", encoding)
+	}
+	if rr.Code != http.StatusOK {
+		t.Errorf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+}
+
+func TestTimelineRequestSpans(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	tweet, _ := entity.NewTweet("tweet1", "alice", "Hello")
+	tweetRepo.Save(tweet)
+
+	req, _ := http.NewRequest("GET", "/timeline", nil)
+	req.Header.Set("User-ID", "alice")
+	req.Header.Set(tracing.RequestIDHeader, "req-123")
+	rr := httptest.NewRecorder()
+	tracing.Middleware(router).ServeHTTP(rr, req)
+
+	// Check response
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	// Check the span hierarchy: request -> use case -> repository
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	request, ok := spans["GET /timeline"]
+	if !ok {
+		t.Fatalf("Expected a request span, got %v", spans)
+	}
+	useCase, ok := spans["TweetUseCase.GetTimeline"]
+	if !ok || useCase.Parent.SpanID() != request.SpanContext.SpanID() {
+		t.Errorf("Expected the use case span to be a child of the request span")
+	}
+	repository, ok := spans["memory.TweetRepository.GetTimeline"]
+	if !ok || repository.Parent.SpanID() != useCase.SpanContext.SpanID() {
+		t.Errorf("Expected the repository span to be a child of the use case span")
+	}
+
+	var requestID string
+	for _, attr := range request.Attributes {
+		if attr.Key == "request.id" {
+			requestID = attr.Value.AsString()
+		}
+	}
+	if requestID != "req-123" {
+		t.Errorf("Expected request.id attribute 'req-123', got %q", requestID)
+	}
+}
+
+// Tweet repository whose timelines are throttled, like DynamoDB once retries run out
+type ThrottledTweetRepository struct {
+	*memory.TweetRepository
+	retryAfter time.Duration
+}
+
+func (r *ThrottledTweetRepository) GetTimeline(ctx context.Context, userID string, opts repository.TimelineOptions) ([]*entity.Tweet, error) {
+	return nil, &entity.ThrottledError{RetryAfter: r.retryAfter}
+}
+
+func TestThrottledResponsesCarryRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter time.Duration
+		want       string
+	}{
+		{"rounded up to whole seconds", 1500 * time.Millisecond, "2"},
+		{"unknown delay", 0, "1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup: a tweet handler over a throttled repository
+			http.DefaultServeMux = new(http.ServeMux)
+			userRepo := memory.NewUserRepository()
+			userRepo.Save(entity.NewUser("alice", "alice"))
+			tweetRepo := &ThrottledTweetRepository{TweetRepository: memory.NewTweetRepository(userRepo), retryAfter: tc.retryAfter}
+			identity := handler.NewIdentityMiddleware(usecase.NewUserUseCase(userRepo, nil))
+			handler.NewTweetHandler(usecase.NewTweetUseCase(tweetRepo, userRepo), identity).RegisterRoutes()
+
+			req, _ := http.NewRequest("GET", "/timeline", nil)
+			req.Header.Set("User-ID", "alice")
+			rr := httptest.NewRecorder()
+			http.DefaultServeMux.ServeHTTP(rr, req)
+
+			// Check response
+			if rr.Code != http.StatusTooManyRequests {
+				t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusTooManyRequests)
+			}
+			retryAfter := rr.Header().Get("Retry-After")
+			if _, err := strconv.Atoi(retryAfter); err != nil || retryAfter != tc.want {
+				t.Errorf("Expected a numeric Retry-After of %s, got %q", tc.want, retryAfter)
+			}
+			var body handler.ErrorResponse
+			json.NewDecoder(rr.Body).Decode(&body)
+			if body.Code != "throttled" {
+				t.Errorf("Expected error code throttled, got %q", body.Code)
+			}
+		})
+	}
+}
+
+func TestAccessLogRecordsEveryRequest(t *testing.T) {
+	// Setup: capture the default logger's JSON output
+	router, userRepo, _ := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	defer slog.SetDefault(previous)
+
+	req, _ := http.NewRequest("GET", "/users/alice", nil)
+	req.Header.Set(tracing.RequestIDHeader, "req-42")
+	rr := httptest.NewRecorder()
+	tracing.Middleware(handler.AccessLogMiddleware(router)).ServeHTTP(rr, req)
+
+	// Find the access log line
+	var entry map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var candidate map[string]any
+		if json.Unmarshal([]byte(line), &candidate) == nil && candidate["msg"] == "HTTP request" {
+			entry = candidate
+		}
+	}
+	if entry == nil {
+		t.Fatalf("Expected an access log line, got %q", logs.String())
+	}
+	want := map[string]any{
+		"level":     "INFO",
+		"method":    "GET",
+		"path":      "/users/alice",
+		"status":    float64(http.StatusOK),
+		"bytes":     float64(rr.Body.Len()),
+		"requestID": "req-42",
+	}
+	for field, value := range want {
+		if entry[field] != value {
+			t.Errorf("Expected %s %v, got %v", field, value, entry[field])
+		}
+	}
+	if latency, ok := entry["latency"].(float64); !ok || latency <= 0 {
+		t.Errorf("Expected a positive latency, got %v", entry["latency"])
+	}
+}
+
+func TestDebugRequestLogRedactsCredentials(t *testing.T) {
+	// Setup: capture the default logger's JSON output at debug level
+	router, _, _ := setupTestAPI(t)
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer slog.SetDefault(previous)
+
+	// A body longer than what is logged, padded with whitespace so it stays valid JSON
+	body := `{"username": "alice"` + strings.Repeat(" ", 1000) + `}`
+	req, _ := http.NewRequest("POST", "/users", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("X-Admin-Token", "admin-secret")
+	rr := httptest.NewRecorder()
+	tracing.Middleware(handler.AccessLogMiddleware(router)).ServeHTTP(rr, req)
+
+	// The handler still received the whole body
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusCreated)
+	}
+
+	// Find the request details line
+	var entry struct {
+		Msg     string              `json:"msg"`
+		Headers map[string][]string `json:"headers"`
+		Body    string              `json:"body"`
+	}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		if json.Unmarshal([]byte(line), &entry) == nil && entry.Msg == "HTTP request details" {
+			break
+		}
+	}
+	if entry.Msg != "HTTP request details" {
+		t.Fatalf("Expected a request details line, got %q", logs.String())
+	}
+	if strings.Contains(logs.String(), "secret") {
+		t.Errorf("Expected credentials to stay out of the logs, got %q", logs.String())
+	}
+	for _, name := range []string{"Authorization", "X-Admin-Token"} {
+		if values := entry.Headers[name]; len(values) != 1 || values[0] != "[REDACTED]" {
+			t.Errorf("Expected %s to be redacted, got %v", name, values)
+		}
+	}
+	if entry.Headers["Content-Type"][0] != "application/json" {
+		t.Errorf("Expected other headers to be logged as is, got %v", entry.Headers)
+	}
+	if !strings.HasSuffix(entry.Body, "...[truncated]") || len(entry.Body) >= len(body) {
+		t.Errorf("Expected the logged body to be truncated, got %d bytes", len(entry.Body))
+	}
+}
+
+// Returns the URL of the rel link in a Link header, or an empty string when absent
+func linkURL(header, rel string) string {
+	match := regexp.MustCompile(`<([^>]*)>; rel="` + rel + `"`).FindStringSubmatch(header)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+func TestUsersPagination(t *testing.T) {
+	// Setup
+	router, userRepo, _ := setupTestAPI(t)
+	for _, id := range []string{"carol", "alice", "erin", "bob", "dave"} {
+		userRepo.Save(entity.NewUser(id, id))
+	}
+
+	// Follow the next links until the last page
+	var ids []string
+	target := "/users?limit=2"
+	for pages := 0; target != ""; pages++ {
+		if pages > 5 {
+			t.Fatal("Expected pagination to end")
+		}
+		req, _ := http.NewRequest("GET", target, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		var page handler.UserPageResponse
+		json.Unmarshal(rr.Body.Bytes(), &page)
+		if len(page.Users) > 2 {
+			t.Fatalf("Expected at most 2 users per page, got %d", len(page.Users))
+		}
+		for _, user := range page.Users {
+			ids = append(ids, user.ID)
+		}
+		target = linkURL(rr.Header().Get("Link"), "next")
+	}
+
+	// Users are listed once each, ordered by ID
+	if got := strings.Join(ids, ","); got != "alice,bob,carol,dave,erin" {
+		t.Errorf("Expected every user in ID order, got %s", got)
+	}
+
+	// A malformed cursor is rejected
+	req, _ := http.NewRequest("GET", "/users?cursor=!!!", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid cursor, got %v", rr.Code)
+	}
+}
+
+func TestTweetsLinkHeaderPagination(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		tweet, _ := entity.NewTweet(fmt.Sprintf("tweet%d", i), "alice", "Hello")
+		tweet.CreatedAt = start.Add(time.Duration(i) * time.Second)
+		tweetRepo.Save(tweet)
+	}
+
+	// Follow the next links until the last page
+	var ids []string
+	target := "/tweets?limit=2"
+	for pages := 0; target != ""; pages++ {
+		if pages > 5 {
+			t.Fatal("Expected pagination to end")
+		}
+		req, _ := http.NewRequest("GET", target, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		if total := rr.Header().Get("X-Total-Count"); total != "5" {
+			t.Errorf("Expected X-Total-Count 5, got %q", total)
+		}
+		if pages > 0 && linkURL(rr.Header().Get("Link"), "prev") == "" {
+			t.Error("Expected a prev link after the first page")
+		}
+
+		var tweets []handler.TweetResponse
+		json.Unmarshal(rr.Body.Bytes(), &tweets)
+		for _, tweet := range tweets {
+			ids = append(ids, tweet.ID)
+		}
+		target = linkURL(rr.Header().Get("Link"), "next")
+	}
+
+	// Check every tweet was returned once, newest first
+	expected := []string{"tweet4", "tweet3", "tweet2", "tweet1", "tweet0"}
+	if fmt.Sprint(ids) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v across pages, got %v", expected, ids)
+	}
+}
+
+func TestTweetsCursorSurvivesDeletion(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		tweet, _ := entity.NewTweet(fmt.Sprintf("tweet%d", i), "alice", fmt.Sprintf("Hello %d", i))
+		tweet.CreatedAt = start.Add(time.Duration(i) * time.Second)
+		tweetRepo.Save(tweet)
+	}
+
+	page := func(target string) ([]string, string) {
+		req, _ := http.NewRequest("GET", target, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		var tweets []handler.TweetResponse
+		json.Unmarshal(rr.Body.Bytes(), &tweets)
+		ids := make([]string, len(tweets))
+		for i, tweet := range tweets {
+			ids[i] = tweet.ID
+		}
+		return ids, linkURL(rr.Header().Get("Link"), "next")
+	}
+
+	// Read the first page, then delete its last tweet and one already seen
+	first, next := page("/tweets?limit=2")
+	tweetRepo.Delete("tweet4")
+	tweetRepo.Delete("tweet5")
+
+	// The next page starts right after the deleted tweet, without repeating or skipping any
+	second, _ := page(next)
+	if fmt.Sprint(first) != "[tweet5 tweet4]" || fmt.Sprint(second) != "[tweet3 tweet2]" {
+		t.Errorf("Expected pages [tweet5 tweet4] and [tweet3 tweet2], got %v and %v", first, second)
+	}
+}
+
+func TestTweetsRejectMalformedCursor(t *testing.T) {
+	// Setup
+	router, _, _ := setupTestAPI(t)
+
+	for _, cursor := range []string{"not base64!", "MTIz", "YWJjOnR3ZWV0MQ"} {
+		req, _ := http.NewRequest("GET", "/tweets?cursor="+url.QueryEscape(cursor), nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for cursor %q, got %v", cursor, rr.Code)
+		}
+	}
+}
+
+func TestFollowersLinkHeaderCarriesNextCursor(t *testing.T) {
+	// Setup
+	router, userRepo, _ := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	for _, id := range []string{"bob", "carol", "dave"} {
+		follower := entity.NewUser(id, id)
+		follower.Follow("alice")
+		userRepo.Save(follower)
+	}
+
+	req, _ := http.NewRequest("GET", "/users/alice/followers?limit=2", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	// Check headers: counting followers is not cheap, so no total is sent
+	if total := rr.Header().Get("X-Total-Count"); total != "" {
+		t.Errorf("Expected no X-Total-Count for followers, got %q", total)
+	}
+	next := linkURL(rr.Header().Get("Link"), "next")
+	parsed, err := url.Parse(next)
+	if next == "" || err != nil || parsed.Query().Get("cursor") == "" || parsed.Query().Get("limit") != "2" {
+		t.Fatalf("Expected a next link keeping limit and carrying a cursor, got %q", rr.Header().Get("Link"))
+	}
+
+	// Fetch the next page through the link
+	req, _ = http.NewRequest("GET", next, nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	var page handler.UserPageResponse
+	json.Unmarshal(rr.Body.Bytes(), &page)
+	if len(page.Users) != 1 || page.Users[0].ID != "dave" {
+		t.Errorf("Expected the last follower on the next page, got %+v", page.Users)
+	}
+	if link := rr.Header().Get("Link"); link != "" {
+		t.Errorf("Expected no next link on the last page, got %q", link)
+	}
+}
+
+func TestFollowedByFriends(t *testing.T) {
+	// Setup: alice follows bob and carol, who both follow dave
+	router, userRepo, _ := setupTestAPI(t)
+	for id, follows := range map[string][]string{"alice": {"bob", "carol"}, "bob": {"dave"}, "carol": {"dave"}, "dave": nil} {
+		user := entity.NewUser(id, id)
+		for _, followed := range follows {
+			user.Follow(followed)
+		}
+		userRepo.Save(user)
+	}
+
+	req, _ := http.NewRequest("GET", "/users/dave/followed-by-friends?limit=1", nil)
+	req.Header.Set("User-ID", "alice")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	// Check response: the first friend by ID, out of both
+	var response []handler.UserResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	if rr.Code != http.StatusOK || len(response) != 1 || response[0].ID != "bob" {
+		t.Errorf("Expected bob as the first friend following dave, got %v %+v", rr.Code, response)
+	}
+	if total := rr.Header().Get("X-Total-Count"); total != "2" {
+		t.Errorf("Expected X-Total-Count 2, got %q", total)
+	}
+
+	// The viewer is required
+	req, _ = http.NewRequest("GET", "/users/dave/followed-by-friends", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %v without User-ID, got %v", http.StatusUnauthorized, rr.Code)
+	}
+}
+
+func TestFollowResource(t *testing.T) {
+	// Setup
+	router, userRepo, _ := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	send := func(method, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("User-ID", "alice")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Follow bob
+	if rr := send("PUT", "/users/bob/follow"); rr.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %v following bob, got %v", http.StatusNoContent, rr.Code)
+	}
+	if alice, _ := userRepo.FindByID("alice"); !alice.IsFollowing("bob") {
+		t.Error("Expected alice to follow bob")
+	}
+
+	// Self, missing and malformed targets
+	tests := []struct {
+		method, path string
+		status       int
+		code         string
+	}{
+		{"PUT", "/users/alice/follow", http.StatusBadRequest, "cannot_follow_self"},
+		{"DELETE", "/users/alice/follow", http.StatusBadRequest, "cannot_follow_self"},
+		{"PUT", "/users/nonexistent/follow", http.StatusNotFound, "user_not_found"},
+		{"PUT", "/users/bad%20id/follow", http.StatusBadRequest, "invalid_id"},
+	}
+	for _, tt := range tests {
+		rr := send(tt.method, tt.path)
+		var response handler.ErrorResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		if rr.Code != tt.status || response.Code != tt.code {
+			t.Errorf("%s %s: expected %v with code %q, got %v with %q", tt.method, tt.path, tt.status, tt.code, rr.Code, response.Code)
+		}
+	}
+
+	// Unfollow bob, then again
+	if rr := send("DELETE", "/users/bob/follow"); rr.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %v unfollowing bob, got %v", http.StatusNoContent, rr.Code)
+	}
+	if alice, _ := userRepo.FindByID("alice"); alice.IsFollowing("bob") {
+		t.Error("Expected alice to no longer follow bob")
+	}
+
+	// The caller is required
+	req, _ := http.NewRequest("PUT", "/users/bob/follow", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %v without User-ID, got %v", http.StatusUnauthorized, rr.Code)
+	}
+}
+
+func TestFollowResourceByBlockedUser(t *testing.T) {
+	// Setup
+	router, userRepo, _ := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	send := func(method, path, userID string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("User-ID", userID)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Bob blocks alice
+	if rr := send("POST", "/users/alice/block", "bob"); rr.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %v blocking alice, got %v", http.StatusNoContent, rr.Code)
+	}
+
+	// Alice cannot follow bob
+	rr := send("PUT", "/users/bob/follow", "alice")
+	var response handler.ErrorResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	if rr.Code != http.StatusForbidden || response.Code != "blocked" {
+		t.Errorf("Expected %v with code %q, got %v with %q", http.StatusForbidden, "blocked", rr.Code, response.Code)
+	}
+	if alice, _ := userRepo.FindByID("alice"); alice.IsFollowing("bob") {
+		t.Error("Expected alice not to follow bob")
+	}
+}
+
+// Memory user repository whose follow changes always conflict with concurrent updates, as a
+// storage does once its retries are exhausted
+type conflictingUserRepository struct {
+	*memory.UserRepository
+	attempts int
+}
+
+func (r *conflictingUserRepository) Follow(followerID, followedID string) error {
+	r.attempts++
+	return entity.ErrConcurrentUpdate
+}
+
+func (r *conflictingUserRepository) Unfollow(followerID, followedID string) error {
+	r.attempts++
+	return entity.ErrConcurrentUpdate
+}
+
+func TestFollowConflictsReturnConflictWithGuidance(t *testing.T) {
+	// Setup
+	http.DefaultServeMux = new(http.ServeMux)
+	userRepo := &conflictingUserRepository{UserRepository: memory.NewUserRepository()}
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	userUseCase := usecase.NewUserUseCase(userRepo, nil)
+	handler.NewUserHandler(userUseCase, handler.NewIdentityMiddleware(userUseCase)).RegisterRoutes()
+
+	// Every follow route reports the conflict and tells the client to refetch and retry
+	for _, tc := range []struct{ method, path, body string }{
+		{"PUT", "/users/bob/follow", ""},
+		{"DELETE", "/users/bob/follow", ""},
+		{"POST", "/users/follow", `{"followed_id":"bob"}`},
+		{"POST", "/users/unfollow", `{"followed_id":"bob"}`},
+	} {
+		req, _ := http.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("User-ID", "alice")
+		req.Header.Set("Accept-Language", "es")
+		rr := httptest.NewRecorder()
+		http.DefaultServeMux.ServeHTTP(rr, req)
+
+		var response handler.ErrorResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		if rr.Code != http.StatusConflict || response.Code != "concurrent_update" || !strings.Contains(response.Error, "reintenta") {
+			t.Errorf("%s %s: expected 409 asking to retry, got %v %+v", tc.method, tc.path, rr.Code, response)
+		}
+	}
+	if userRepo.attempts != 4 {
+		t.Errorf("Expected one storage call per request, got %d", userRepo.attempts)
+	}
+}
+
+func TestFollowResourceIsIdempotent(t *testing.T) {
+	// Setup
+	router, userRepo, _ := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	send := func(method, path, userID string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("User-ID", userID)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Following twice leaves a single follow, and notifies bob once
+	for i := range 2 {
+		if rr := send("PUT", "/users/bob/follow", "alice"); rr.Code != http.StatusNoContent {
+			t.Errorf("Follow %d: expected status %v, got %v", i+1, http.StatusNoContent, rr.Code)
+		}
+	}
+	if followers, _ := userRepo.FindFollowers("bob"); len(followers) != 1 {
+		t.Errorf("Expected bob to have a single follower, got %d", len(followers))
+	}
+	rr := send("GET", "/users/notifications", "bob")
+	var notifications handler.NotificationPageResponse
+	json.Unmarshal(rr.Body.Bytes(), &notifications)
+	if len(notifications.Notifications) != 1 {
+		t.Errorf("Expected a single follow notification, got %d", len(notifications.Notifications))
+	}
+
+	// Unfollowing twice leaves no follow
+	for i := range 2 {
+		if rr := send("DELETE", "/users/bob/follow", "alice"); rr.Code != http.StatusNoContent {
+			t.Errorf("Unfollow %d: expected status %v, got %v", i+1, http.StatusNoContent, rr.Code)
+		}
+	}
+	if alice, _ := userRepo.FindByID("alice"); alice.IsFollowing("bob") {
+		t.Error("Expected alice to no longer follow bob")
+	}
+
+	// The legacy route still reports the follow it could not add
+	req, _ := http.NewRequest("POST", "/users/follow", strings.NewReader(`{"followed_id":"bob"}`))
+	req.Header.Set("User-ID", "alice")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	req, _ = http.NewRequest("POST", "/users/follow", strings.NewReader(`{"followed_id":"bob"}`))
+	req.Header.Set("User-ID", "alice")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected status %v following twice through POST, got %v", http.StatusConflict, rr.Code)
+	}
+}
+
+func TestCountUserTweets(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	for i, userID := range []string{"alice", "alice", "bob"} {
+		tweet, _ := entity.NewTweet(fmt.Sprintf("tweet%d", i), userID, "Hello")
+		tweetRepo.Save(tweet)
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// The count matches the listed tweets
+	var listed []handler.TweetResponse
+	json.Unmarshal(get("/users/tweets?user_id=alice").Body.Bytes(), &listed)
+	rr := get("/users/tweets/count?user_id=alice")
+	var response handler.TweetCountResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	if rr.Code != http.StatusOK || response.UserID != "alice" || response.TweetCount != len(listed) || len(listed) != 2 {
+		t.Errorf("Expected a count of %d matching the listed tweets, got %v %+v", len(listed), rr.Code, response)
+	}
+
+	// Unknown and missing users
+	if rr := get("/users/tweets/count?user_id=nonexistent"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status %v for an unknown user, got %v", http.StatusNotFound, rr.Code)
+	}
+	if rr := get("/users/tweets/count"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %v without user_id, got %v", http.StatusBadRequest, rr.Code)
+	}
+}
+
+// Returns the IDs of the tweets in the timeline of a user, fetched with the given query string
+func timelineIDs(t *testing.T, router http.Handler, userID, query string) []string {
+	req, _ := http.NewRequest("GET", "/timeline"+query, nil)
+	req.Header.Set("User-ID", userID)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	var timeline []handler.TweetResponse
+	json.Unmarshal(rr.Body.Bytes(), &timeline)
+	ids := make([]string, len(timeline))
+	for i, tweet := range timeline {
+		ids[i] = tweet.ID
+	}
+	return ids
+}
+
+func TestTimelineFollowingCountTellsEmptyStates(t *testing.T) {
+	// Setup
+	router, userRepo, _ := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	bob := entity.NewUser("bob", "bob")
+	bob.Follow("carol")
+	userRepo.Save(bob)
+	userRepo.Save(entity.NewUser("carol", "carol"))
+
+	tests := []struct {
+		userID         string
+		followingCount string
+	}{
+		// Follows no one
+		{"alice", "0"},
+		// Follows carol, who has not tweeted
+		{"bob", "1"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", "/timeline", nil)
+		req.Header.Set("User-ID", tt.userID)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		var tweets []handler.TweetResponse
+		json.Unmarshal(rr.Body.Bytes(), &tweets)
+		if len(tweets) != 0 {
+			t.Errorf("Expected an empty timeline for %s, got %v", tt.userID, tweets)
+		}
+		if got := rr.Header().Get("X-Following-Count"); got != tt.followingCount {
+			t.Errorf("Expected X-Following-Count %s for %s, got %q", tt.followingCount, tt.userID, got)
+		}
+	}
+}
+
+func TestTimelineIncludeSelf(t *testing.T) {
+	// Setup: alice follows bob and both have tweeted
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	alice := entity.NewUser("alice", "alice")
+	alice.Follow("bob")
+	userRepo.Save(alice)
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	start := time.Now()
+	own, _ := entity.NewTweetAt("own", "alice", "Mine", start)
+	followed, _ := entity.NewTweetAt("followed", "bob", "Theirs", start.Add(time.Second))
+	tweetRepo.Save(own)
+	tweetRepo.Save(followed)
+
+	// Default view first, so the following-only view must not reuse its cached timeline
+	if ids := timelineIDs(t, router, "alice", ""); fmt.Sprint(ids) != "[followed own]" {
+		t.Errorf("Expected both tweets by default, got %v", ids)
+	}
+	if ids := timelineIDs(t, router, "alice", "?include_self=false"); fmt.Sprint(ids) != "[followed]" {
+		t.Errorf("Expected only followed tweets with include_self=false, got %v", ids)
+	}
+	if ids := timelineIDs(t, router, "alice", "?include_self=true"); fmt.Sprint(ids) != "[followed own]" {
+		t.Errorf("Expected both tweets with include_self=true, got %v", ids)
+	}
+
+	// Invalid values are rejected
+	req, _ := http.NewRequest("GET", "/timeline?include_self=maybe", nil)
+	req.Header.Set("User-ID", "alice")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestTweetResponsesIncludeAuthorUsername(t *testing.T) {
+	// Setup: alice follows bob; ghost's tweet outlived their account
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	alice := entity.NewUser("alice", "Alice")
+	alice.Follow("bob")
+	userRepo.Save(alice)
+	userRepo.Save(entity.NewUser("bob", "Bob"))
+	start := time.Now()
+	own, _ := entity.NewTweetAt("own", "alice", "Mine", start)
+	followed, _ := entity.NewTweetAt("followed", "bob", "Theirs", start.Add(time.Second))
+	orphan, _ := entity.NewTweetAt("orphan", "ghost", "Still here", start.Add(2*time.Second))
+	tweetRepo.Save(own)
+	tweetRepo.Save(followed)
+	tweetRepo.Save(orphan)
+
+	// Returns the author usernames of the tweets at target, by tweet ID
+	usernames := func(target string) map[string]string {
+		req, _ := http.NewRequest("GET", target, nil)
+		req.Header.Set("User-ID", "alice")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Handler returned wrong status code for %s: got %v want %v", target, rr.Code, http.StatusOK)
+		}
+		var tweets []handler.TweetResponse
+		json.Unmarshal(rr.Body.Bytes(), &tweets)
+		byID := make(map[string]string, len(tweets))
+		for _, tweet := range tweets {
+			byID[tweet.ID] = tweet.AuthorUsername
+		}
+		return byID
+	}
+
+	// The timeline names every author
+	timeline := usernames("/timeline")
+	if timeline["own"] != "Alice" || timeline["followed"] != "Bob" {
+		t.Errorf("Expected the timeline to carry author usernames, got %v", timeline)
+	}
+
+	// A tweet whose author is gone is still listed, without a username
+	all := usernames("/tweets")
+	if len(all) != 3 {
+		t.Fatalf("Expected all 3 tweets, got %v", all)
+	}
+	if all["orphan"] != "" || all["followed"] != "Bob" {
+		t.Errorf("Expected only the orphaned tweet to lack a username, got %v", all)
+	}
+}
+
+func TestDeletedUserVanishesFromFollowerTimelines(t *testing.T) {
+	// Setup: alice follows bob and carol, who have both tweeted
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	alice := entity.NewUser("alice", "alice")
+	alice.Follow("bob")
+	alice.Follow("carol")
+	userRepo.Save(alice)
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	userRepo.Save(entity.NewUser("carol", "carol"))
+	start := time.Now()
+	bobTweet, _ := entity.NewTweetAt("bob-tweet", "bob", "Hi", start)
+	carolTweet, _ := entity.NewTweetAt("carol-tweet", "carol", "Hello", start.Add(time.Second))
+	tweetRepo.Save(bobTweet)
+	tweetRepo.Save(carolTweet)
+	if ids := timelineIDs(t, router, "alice", "?include_self=false"); fmt.Sprint(ids) != "[carol-tweet bob-tweet]" {
+		t.Fatalf("Expected both tweets before any deletion, got %v", ids)
+	}
+
+	// Only bob can delete bob's account
+	deleteUser := func(target, userID string) int {
+		req, _ := http.NewRequest("DELETE", target, nil)
+		req.Header.Set("User-ID", userID)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	if code := deleteUser("/users/bob", "alice"); code != http.StatusForbidden {
+		t.Errorf("Expected 403 deleting another user, got %v", code)
+	}
+	if code := deleteUser("/users/bob", "bob"); code != http.StatusNoContent {
+		t.Fatalf("Expected 204 deleting own account, got %v", code)
+	}
+
+	// bob's tweets leave alice's timeline along with the follow
+	if ids := timelineIDs(t, router, "alice", "?include_self=false"); fmt.Sprint(ids) != "[carol-tweet]" {
+		t.Errorf("Expected bob's tweets to vanish, got %v", ids)
+	}
+	if stored, _ := userRepo.FindByID("alice"); stored.IsFollowing("bob") {
+		t.Error("Expected alice to no longer follow bob")
+	}
+
+	// A user removed without updating followers is reconciled on the next timeline read
+	userRepo.Delete("carol")
+	if ids := timelineIDs(t, router, "alice", "?include_self=false"); len(ids) != 0 {
+		t.Errorf("Expected carol's tweets to be skipped, got %v", ids)
+	}
+	if stored, _ := userRepo.FindByID("alice"); stored.IsFollowing("carol") {
+		t.Error("Expected the follow of the deleted user to be removed")
+	}
+}
+
+// Reports a fixed cache reachability
+type StaticCacheHealth bool
+
+func (h StaticCacheHealth) Reachable() bool { return bool(h) }
+
+func TestReadyReportsCacheState(t *testing.T) {
+	cases := map[string]handler.CacheHealth{
+		"up":       StaticCacheHealth(true),
+		"down":     StaticCacheHealth(false),
+		"disabled": nil,
+	}
+	for want, health := range cases {
+		// Setup
+		http.DefaultServeMux = new(http.ServeMux)
+		handler.NewHealthHandler(health).RegisterRoutes()
+
+		// Execute
+		req, _ := http.NewRequest("GET", "/ready", nil)
+		rr := httptest.NewRecorder()
+		http.DefaultServeMux.ServeHTTP(rr, req)
+
+		// Check: a cache outage is reported without failing the check
+		if rr.Code != http.StatusOK {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", want, rr.Code, http.StatusOK)
+		}
+		var response handler.ReadyResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		if response.Status != "ready" || response.Cache != want {
+			t.Errorf("Expected cache %q, got %+v", want, response)
+		}
+	}
+}
+
+func TestNotificationsFeed(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	for _, id := range []string{"alice", "bob", "carol"} {
+		userRepo.Save(entity.NewUser(id, strings.ToUpper(id[:1])+id[1:]))
+	}
+	root, _ := entity.NewTweet("root", "alice", "Hello")
+	tweetRepo.Save(root)
+
+	// Sends a request as the user and checks the status code
+	send := func(method, path, userID string, body any, want int) *httptest.ResponseRecorder {
+		var payload []byte
+		if body != nil {
+			payload, _ = json.Marshal(body)
+		}
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-ID", userID)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != want {
+			t.Fatalf("%s %s returned wrong status code: got %v want %v", method, path, rr.Code, want)
+		}
+		return rr
+	}
+	notifications := func(userID string) handler.NotificationPageResponse {
+		var page handler.NotificationPageResponse
+		json.Unmarshal(send("GET", "/users/notifications", userID, nil, http.StatusOK).Body.Bytes(), &page)
+		return page
+	}
+
+	// bob follows alice, replies to her and mentions carol
+	send("POST", "/users/follow", "bob", handler.FollowRequest{FollowedID: "alice"}, http.StatusOK)
+	var reply handler.TweetResponse
+	json.Unmarshal(send("POST", "/tweets", "bob", map[string]string{"content": "Hi! cc @carol", "in_reply_to_id": root.ID}, http.StatusCreated).Body.Bytes(), &reply)
+
+	// alice is notified of the follow and the reply, newest first
+	page := notifications("alice")
+	if len(page.Notifications) != 2 || page.UnreadCount != 2 {
+		t.Fatalf("Expected 2 unread notifications for alice, got %+v", page)
+	}
+	if got := page.Notifications[0]; got.Type != "reply" || got.ActorID != "bob" || got.ActorUsername != "Bob" || got.TweetID != reply.ID || got.Read {
+		t.Errorf("Expected an unread reply by bob first, got %+v", got)
+	}
+	if got := page.Notifications[1]; got.Type != "follow" || got.ActorID != "bob" || got.TweetID != "" {
+		t.Errorf("Expected a follow by bob second, got %+v", got)
+	}
+
+	// carol is notified of the mention, bob of nothing
+	if page := notifications("carol"); len(page.Notifications) != 1 || page.Notifications[0].Type != "mention" || page.Notifications[0].TweetID != reply.ID {
+		t.Errorf("Expected a mention in bob's reply for carol, got %+v", page)
+	}
+	if page := notifications("bob"); len(page.Notifications) != 0 || page.UnreadCount != 0 {
+		t.Errorf("Expected no notifications for bob, got %+v", page)
+	}
+
+	// Pages follow the cursor
+	var first handler.NotificationPageResponse
+	json.Unmarshal(send("GET", "/users/notifications?limit=1", "alice", nil, http.StatusOK).Body.Bytes(), &first)
+	if len(first.Notifications) != 1 || first.NextCursor == "" {
+		t.Fatalf("Expected one notification and a next cursor, got %+v", first)
+	}
+	var second handler.NotificationPageResponse
+	json.Unmarshal(send("GET", "/users/notifications?limit=1&cursor="+first.NextCursor, "alice", nil, http.StatusOK).Body.Bytes(), &second)
+	if len(second.Notifications) != 1 || second.Notifications[0].Type != "follow" || second.NextCursor != "" {
+		t.Errorf("Expected the follow on the last page, got %+v", second)
+	}
+	send("GET", "/users/notifications?cursor=%21", "alice", nil, http.StatusBadRequest)
+
+	// Marking read clears the unread count
+	send("POST", "/users/notifications/read", "alice", nil, http.StatusNoContent)
+	page = notifications("alice")
+	if page.UnreadCount != 0 || !page.Notifications[0].Read || !page.Notifications[1].Read {
+		t.Errorf("Expected every notification read, got %+v", page)
+	}
+	send("POST", "/users/notifications/read", "", nil, http.StatusUnauthorized)
+}
+
+func TestDirectMessages(t *testing.T) {
+	// Setup
+	router, userRepo, _ := setupTestAPI(t)
+	for _, id := range []string{"alice", "bob", "carol"} {
+		userRepo.Save(entity.NewUser(id, id))
+	}
+
+	// Sends a request as the user and checks the status code
+	send := func(method, path, userID string, body any, want int) *httptest.ResponseRecorder {
+		var payload []byte
+		if body != nil {
+			payload, _ = json.Marshal(body)
+		}
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-ID", userID)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != want {
+			t.Fatalf("%s %s as %s returned wrong status code: got %v want %v", method, path, userID, rr.Code, want)
+		}
+		return rr
+	}
+	conversation := func(userID, otherID string) []handler.MessageResponse {
+		var messages []handler.MessageResponse
+		json.Unmarshal(send("GET", "/messages?with="+otherID, userID, nil, http.StatusOK).Body.Bytes(), &messages)
+		return messages
+	}
+
+	// alice and bob message each other
+	var sent handler.MessageResponse
+	json.Unmarshal(send("POST", "/messages", "alice", handler.SendMessageRequest{RecipientID: "bob", Content: "Hi bob"}, http.StatusCreated).Body.Bytes(), &sent)
+	if sent.ID == "" || sent.SenderID != "alice" || sent.RecipientID != "bob" || sent.Content != "Hi bob" {
+		t.Errorf("Expected the sent message back, got %+v", sent)
+	}
+	send("POST", "/messages", "bob", handler.SendMessageRequest{RecipientID: "alice", Content: "Hi alice"}, http.StatusCreated)
+
+	// Both read the conversation; carol only reads her own, empty one
+	if messages := conversation("bob", "alice"); len(messages) != 2 || messages[0].ID != sent.ID || messages[1].SenderID != "bob" {
+		t.Errorf("Expected bob to read both messages oldest first, got %+v", messages)
+	}
+	if messages := conversation("carol", "alice"); len(messages) != 0 {
+		t.Errorf("Expected carol to read no messages, got %+v", messages)
+	}
+	send("GET", "/messages?with=alice", "", nil, http.StatusUnauthorized)
+	send("GET", "/messages", "alice", nil, http.StatusBadRequest)
+	send("GET", "/messages?with=ghost", "alice", nil, http.StatusNotFound)
+
+	// Invalid messages
+	send("POST", "/messages", "alice", handler.SendMessageRequest{RecipientID: "bob"}, http.StatusUnprocessableEntity)
+	send("POST", "/messages", "alice", handler.SendMessageRequest{RecipientID: "alice", Content: "Hi me"}, http.StatusBadRequest)
+
+	// Once bob blocks alice, neither can message the other
+	send("POST", "/users/alice/block", "bob", nil, http.StatusNoContent)
+	rr := send("POST", "/messages", "alice", handler.SendMessageRequest{RecipientID: "bob", Content: "Still there?"}, http.StatusForbidden)
+	var errorResponse handler.ErrorResponse
+	json.Unmarshal(rr.Body.Bytes(), &errorResponse)
+	if errorResponse.Code != "messaging_blocked" {
+		t.Errorf("Expected code messaging_blocked, got %q", errorResponse.Code)
+	}
+	send("POST", "/messages", "bob", handler.SendMessageRequest{RecipientID: "alice", Content: "Bye"}, http.StatusForbidden)
+	send("POST", "/users/bob/block", "bob", nil, http.StatusBadRequest)
+
+	// Unblocking lets them talk again
+	send("DELETE", "/users/alice/block", "bob", nil, http.StatusNoContent)
+	send("POST", "/messages", "alice", handler.SendMessageRequest{RecipientID: "bob", Content: "Still there?"}, http.StatusCreated)
+}
+
+func TestTimelineIncludeReplies(t *testing.T) {
+	// Setup: alice follows bob, who replied to his own tweet
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	alice := entity.NewUser("alice", "alice")
+	alice.Follow("bob")
+	userRepo.Save(alice)
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	start := time.Now()
+	root, _ := entity.NewTweetAt("root", "bob", "Question", start)
+	reply, _ := entity.NewReplyAt("reply", "bob", "Answer", root, start.Add(time.Second))
+	tweetRepo.Save(root)
+	tweetRepo.Save(reply)
+
+	// Replies are left out by default and included on request
+	if ids := timelineIDs(t, router, "alice", ""); fmt.Sprint(ids) != "[root]" {
+		t.Errorf("Expected replies to be excluded by default, got %v", ids)
+	}
+	if ids := timelineIDs(t, router, "alice", "?include_replies=true"); fmt.Sprint(ids) != "[reply root]" {
+		t.Errorf("Expected replies with include_replies=true, got %v", ids)
+	}
+	if ids := timelineIDs(t, router, "alice", "?include_replies=false"); fmt.Sprint(ids) != "[root]" {
+		t.Errorf("Expected no replies with include_replies=false, got %v", ids)
+	}
+}
+
+// Posts content to the tweet validation endpoint and returns the decoded result
+func validateContent(t *testing.T, router http.Handler, content string) handler.ValidateTweetResponse {
+	body, _ := json.Marshal(map[string]string{"content": content})
+	req, _ := http.NewRequest("POST", "/tweets/validate", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	var response handler.ValidateTweetResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	return response
+}
+
+func TestValidateTweetCountsCharacters(t *testing.T) {
+	// Setup
+	router, _, _ := setupTestAPI(t)
+
+	// Multibyte characters count once each, after trimming
+	response := validateContent(t, router, "  ¡Hola, señor! 👋  ")
+	if !response.Valid || response.Length != 15 || response.MaxLength != entity.MaxTweetLength {
+		t.Errorf("Expected valid content of 15 characters out of %d, got %+v", entity.MaxTweetLength, response)
+	}
+
+	// 280 multibyte characters fit even though they take more bytes
+	response = validateContent(t, router, strings.Repeat("ñ", entity.MaxTweetLength))
+	if !response.Valid || response.Length != entity.MaxTweetLength {
+		t.Errorf("Expected %d multibyte characters to be valid, got %+v", entity.MaxTweetLength, response)
+	}
+}
+
+func TestValidateTweetOverLimit(t *testing.T) {
+	// Setup
+	router, _, _ := setupTestAPI(t)
+
+	response := validateContent(t, router, strings.Repeat("a", entity.MaxTweetLength+1))
+
+	// Check response
+	if response.Valid || response.Length != entity.MaxTweetLength+1 || response.Error == "" {
+		t.Errorf("Expected invalid content of %d characters with an error, got %+v", entity.MaxTweetLength+1, response)
+	}
+}
+
+func TestTweetsPreview(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	tweet, _ := entity.NewTweet("tweet1", "alice", "Hola 👋🏽 mundo")
+	tweetRepo.Save(tweet)
+
+	// Request previews cut inside the emoji with its skin tone modifier
+	req, _ := http.NewRequest("GET", "/tweets?preview=6", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	// Check response
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var response []handler.TweetResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	if len(response) != 1 || response[0].Preview != "Hola…" || response[0].Content != tweet.Content {
+		t.Errorf("Expected the preview to drop the whole emoji, got %+v", response)
+	}
+
+	// An invalid length is rejected
+	req, _ = http.NewRequest("GET", "/tweets?preview=0", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %v for an invalid preview, got %v", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestTweetsLang(t *testing.T) {
+	// Setup
+	router, userRepo, _ := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	post := func(body map[string]string) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", "/tweets", bytes.NewBuffer(payload))
+		req.Header.Set("User-ID", "alice")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Tweets in Spanish, English and an unknown language
+	rr := post(map[string]string{"content": "Hola", "lang": "es"})
+	var created handler.TweetResponse
+	json.Unmarshal(rr.Body.Bytes(), &created)
+	if rr.Code != http.StatusCreated || created.Lang != "es" {
+		t.Fatalf("Expected a tweet in es, got %v %+v", rr.Code, created)
+	}
+	post(map[string]string{"content": "Hello", "lang": "en"})
+	post(map[string]string{"content": "?"})
+
+	// An unknown language code is rejected
+	if rr := post(map[string]string{"content": "Hallo", "lang": "german"}); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %v for an invalid lang, got %v", http.StatusUnprocessableEntity, rr.Code)
+	}
+
+	// Only the Spanish tweet is listed when filtering by es
+	req, _ := http.NewRequest("GET", "/tweets?lang=es", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	var response []handler.TweetResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	if rr.Code != http.StatusOK || len(response) != 1 || response[0].ID != created.ID {
+		t.Errorf("Expected only the Spanish tweet, got %v %+v", rr.Code, response)
+	}
+
+	// An invalid filter is rejected
+	req, _ = http.NewRequest("GET", "/tweets?lang=xx", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %v for an invalid lang filter, got %v", http.StatusBadRequest, rr.Code)
+	}
+}
+
+// Registers the admin routes on top of the test API with the given admin token
+func setupAdminAPI(t *testing.T, token string) (http.Handler, *memory.UserRepository, *memory.TweetRepository) {
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	tweetUseCase := usecase.NewTweetUseCase(tweetRepo, userRepo)
+	reportUseCase := usecase.NewReportUseCase(memory.NewReportRepository(), tweetRepo)
+	identity := handler.NewIdentityMiddleware(usecase.NewUserUseCase(userRepo, nil))
+	admin := handler.NewAdminMiddleware(token)
+	handler.NewAdminHandler(tweetUseCase, usecase.NewStatsUseCase(userRepo, tweetRepo), admin).RegisterRoutes()
+	handler.NewReportHandler(reportUseCase, identity, admin).RegisterRoutes()
+	return router, userRepo, tweetRepo
+}
+
+func TestAdminDeletesAnyTweet(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupAdminAPI(t, "secret")
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	tweet, _ := entity.NewTweet("tweet1", "alice", "Abusive")
+	tweetRepo.Save(tweet)
+
+	// Delete the tweet as a moderator
+	req, _ := http.NewRequest("DELETE", "/admin/tweets/tweet1", nil)
+	req.Header.Set(handler.AdminTokenHeader, "secret")
+	req.Header.Set(tracing.RequestIDHeader, "req-456")
+	rr := httptest.NewRecorder()
+	tracing.Middleware(router).ServeHTTP(rr, req)
+
+	// Check response
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusNoContent)
+	}
+	if found, _ := tweetRepo.FindByID("tweet1"); found != nil {
+		t.Error("Expected the tweet to be deleted")
+	}
+}
+
+func TestAdminDeleteRejectsNonAdmins(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupAdminAPI(t, "secret")
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	tweet, _ := entity.NewTweet("tweet1", "alice", "Hello")
+	tweetRepo.Save(tweet)
+
+	// Neither a missing nor a wrong token is accepted, even from the author
+	for name, token := range map[string]string{"missing": "", "wrong": "guess"} {
+		req, _ := http.NewRequest("DELETE", "/admin/tweets/tweet1", nil)
+		req.Header.Set("User-ID", "alice")
+		if token != "" {
+			req.Header.Set(handler.AdminTokenHeader, token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("%s token: expected status %v, got %v", name, http.StatusForbidden, rr.Code)
+		}
+	}
+	if found, _ := tweetRepo.FindByID("tweet1"); found == nil {
+		t.Error("Expected the tweet to remain")
+	}
+}
+
+func TestDeleteOwnTweetOnly(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	tweet, _ := entity.NewTweet("tweet1", "alice", "Hello")
+	tweetRepo.Save(tweet)
+
+	// Another user cannot delete the tweet, its author can
+	for _, tc := range []struct {
+		userID string
+		status int
+	}{
+		{"bob", http.StatusForbidden},
+		{"alice", http.StatusNoContent},
+	} {
+		req, _ := http.NewRequest("DELETE", "/tweets/tweet1", nil)
+		req.Header.Set("User-ID", tc.userID)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != tc.status {
+			t.Errorf("Deleting as %s: expected status %v, got %v", tc.userID, tc.status, rr.Code)
+		}
+	}
+}
+
+// Reports a tweet as the given user and returns the response recorder
+func reportTweet(router http.Handler, userID, tweetID, reason string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]string{"reason": reason})
+	req, _ := http.NewRequest("POST", "/tweets/"+tweetID+"/report", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-ID", userID)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestReportTweetAndListReports(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupAdminAPI(t, "secret")
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	tweet, _ := entity.NewTweet("tweet1", "alice", "Abusive")
+	tweetRepo.Save(tweet)
+
+	// Report the tweet
+	rr := reportTweet(router, "bob", "tweet1", "Harassment")
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusCreated)
+	}
+
+	// Reporting it again is rejected
+	if rr := reportTweet(router, "bob", "tweet1", "Still harassment"); rr.Code != http.StatusConflict {
+		t.Errorf("Expected status %v for a duplicate report, got %v", http.StatusConflict, rr.Code)
+	}
+
+	// Moderators see the report
+	req, _ := http.NewRequest("GET", "/admin/reports", nil)
+	req.Header.Set(handler.AdminTokenHeader, "secret")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var reports []handler.ReportResponse
+	json.Unmarshal(rr.Body.Bytes(), &reports)
+	if len(reports) != 1 || reports[0].TweetID != "tweet1" || reports[0].ReporterID != "bob" || reports[0].Reason != "Harassment" {
+		t.Errorf("Expected bob's original report, got %+v", reports)
+	}
+
+	// Other users do not
+	req, _ = http.NewRequest("GET", "/admin/reports", nil)
+	req.Header.Set("User-ID", "bob")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status %v for a non-admin, got %v", http.StatusForbidden, rr.Code)
+	}
+}
+
+func TestDeactivateAndReactivateUser(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	alice := entity.NewUser("alice", "alice")
+	alice.Follow("bob")
+	userRepo.Save(alice)
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	tweet, _ := entity.NewTweet("tweet1", "bob", "Hello")
+	tweetRepo.Save(tweet)
+
+	// Sends a request as the given user and returns the status code
+	send := func(method, target, userID string) int {
+		req, _ := http.NewRequest(method, target, nil)
+		req.Header.Set("User-ID", userID)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	// While deactivated, bob's profile is hidden from others and his tweets from timelines
+	if code := send("POST", "/users/deactivate", "bob"); code != http.StatusNoContent {
+		t.Fatalf("Expected status %v deactivating, got %v", http.StatusNoContent, code)
+	}
+	if code := send("GET", "/users/bob", "alice"); code != http.StatusNotFound {
+		t.Errorf("Expected status %v for a deactivated profile, got %v", http.StatusNotFound, code)
+	}
+	if code := send("GET", "/users/bob", "bob"); code != http.StatusOK {
+		t.Errorf("Expected bob to see his own profile, got %v", code)
+	}
+	if ids := timelineIDs(t, router, "alice", ""); len(ids) != 0 {
+		t.Errorf("Expected bob's tweets to be hidden, got %v", ids)
+	}
+
+	// Reactivation restores both
+	if code := send("POST", "/users/reactivate", "bob"); code != http.StatusNoContent {
+		t.Fatalf("Expected status %v reactivating, got %v", http.StatusNoContent, code)
+	}
+	if code := send("GET", "/users/bob", "alice"); code != http.StatusOK {
+		t.Errorf("Expected the profile to be visible again, got %v", code)
+	}
+	if ids := timelineIDs(t, router, "alice", ""); len(ids) != 1 || ids[0] != "tweet1" {
+		t.Errorf("Expected bob's tweet back in the timeline, got %v", ids)
+	}
+}
+
+func TestTimelineRanking(t *testing.T) {
+	// Setup: alice's older tweet has a reply, her newer one does not
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	now := time.Now()
+	popular, _ := entity.NewTweetAt("popular", "alice", "Popular", now.Add(-2*time.Hour))
+	quiet, _ := entity.NewTweetAt("quiet", "alice", "Quiet", now.Add(-time.Hour))
+	reply, _ := entity.NewReplyAt("reply", "alice", "Reply", popular, now.Add(-90*time.Minute))
+	for _, tweet := range []*entity.Tweet{popular, quiet, reply} {
+		tweetRepo.Save(tweet)
+	}
+
+	// Check both orders
+	if ids := timelineIDs(t, router, "alice", ""); len(ids) != 2 || ids[0] != "quiet" {
+		t.Errorf("Expected the newest tweet first by default, got %v", ids)
+	}
+	if ids := timelineIDs(t, router, "alice", "?ranking=engagement"); len(ids) != 2 || ids[0] != "popular" {
+		t.Errorf("Expected the replied tweet first by engagement, got %v", ids)
+	}
+
+	// An unknown ranking is rejected
+	req, _ := http.NewRequest("GET", "/timeline?ranking=random", nil)
+	req.Header.Set("User-ID", "alice")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %v for an unknown ranking, got %v", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestAdminStats(t *testing.T) {
+	// Setup: three users, three follows and six tweets
+	router, userRepo, tweetRepo := setupAdminAPI(t, "secret")
+	alice := entity.NewUser("alice", "alice")
+	alice.Follow("bob")
+	alice.Follow("carol")
+	bob := entity.NewUser("bob", "bob")
+	bob.Follow("alice")
+	for _, user := range []*entity.User{alice, bob, entity.NewUser("carol", "carol")} {
+		userRepo.Save(user)
+	}
+	for i := 0; i < 6; i++ {
+		tweet, _ := entity.NewTweet(fmt.Sprintf("tweet%d", i), []string{"alice", "bob"}[i%2], "Hello")
+		tweetRepo.Save(tweet)
+	}
+
+	// Get stats
+	req, _ := http.NewRequest("GET", "/admin/stats", nil)
+	req.Header.Set(handler.AdminTokenHeader, "secret")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	// Check response
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var fields map[string]any
+	json.Unmarshal(rr.Body.Bytes(), &fields)
+	expected := map[string]float64{"users": 3, "tweets": 6, "follows": 3, "average_tweets_per_user": 2}
+	for name, value := range expected {
+		if fields[name] != value {
+			t.Errorf("Expected %s to be %v, got %v", name, value, fields[name])
+		}
+	}
+
+	// Stats are reserved to moderators
+	req, _ = http.NewRequest("GET", "/admin/stats", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status %v without the admin token, got %v", http.StatusForbidden, rr.Code)
+	}
+}
+
+func TestAdminTweetAnalytics(t *testing.T) {
+	// Setup: tweets on both sides of an hour boundary and of a day boundary
+	router, userRepo, tweetRepo := setupAdminAPI(t, "secret")
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	for i, createdAt := range []string{
+		"2024-01-01T22:30:00Z",
+		"2024-01-01T23:59:59Z",
+		"2024-01-02T00:00:00Z",
+		"2024-01-02T00:59:59Z",
+		"2024-01-02T01:00:00Z",
+		"2024-01-02T02:00:00Z",
+	} {
+		at, _ := time.Parse(time.RFC3339, createdAt)
+		tweet, _ := entity.NewTweetAt(fmt.Sprintf("tweet%d", i), "alice", "Hello", at)
+		tweetRepo.Save(tweet)
+	}
+	getAnalytics := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/admin/analytics/tweets?"+query, nil)
+		req.Header.Set(handler.AdminTokenHeader, "secret")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Hourly buckets: the range end is exclusive and an unaligned start widens to its hour
+	rr := getAnalytics("from=2024-01-01T23:15:00Z&to=2024-01-02T02:00:00Z&bucket=hour")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var hourly handler.TweetHistogramResponse
+	json.Unmarshal(rr.Body.Bytes(), &hourly)
+	if hourly.From.Format(time.RFC3339) != "2024-01-01T23:00:00Z" || hourly.To.Format(time.RFC3339) != "2024-01-02T02:00:00Z" {
+		t.Errorf("Expected the buckets to cover 23:00 to 02:00, got %v to %v", hourly.From, hourly.To)
+	}
+	expected := []int{1, 2, 1}
+	if len(hourly.Buckets) != len(expected) || hourly.Total != 4 {
+		t.Fatalf("Expected %d hourly buckets with 4 tweets, got %+v", len(expected), hourly)
+	}
+	for i, count := range expected {
+		if hourly.Buckets[i].Count != count {
+			t.Errorf("Expected %d tweets in the bucket starting at %v, got %d", count, hourly.Buckets[i].Start, hourly.Buckets[i].Count)
+		}
+	}
+
+	// Daily buckets
+	rr = getAnalytics("from=2024-01-01T00:00:00Z&to=2024-01-03T00:00:00Z&bucket=day")
+	var daily handler.TweetHistogramResponse
+	json.Unmarshal(rr.Body.Bytes(), &daily)
+	if len(daily.Buckets) != 2 || daily.Buckets[0].Count != 2 || daily.Buckets[1].Count != 4 || daily.Bucket != "day" {
+		t.Errorf("Expected 2 tweets on the first day and 4 on the second, got %+v", daily)
+	}
+
+	// Invalid ranges and buckets are rejected
+	for _, query := range []string{
+		"from=2024-01-02T00:00:00Z&to=2024-01-01T00:00:00Z",
+		"from=yesterday&to=2024-01-01T00:00:00Z",
+		"to=2024-01-01T00:00:00Z",
+		"from=2024-01-01T00:00:00Z&to=2024-01-02T00:00:00Z&bucket=minute",
+		"from=2024-01-01T00:00:00Z&to=2024-03-01T00:00:00Z&bucket=hour",
+	} {
+		if rr := getAnalytics(query); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %v for %q, got %v", http.StatusBadRequest, query, rr.Code)
+		}
+	}
+
+	// Analytics are reserved to moderators
+	req, _ := http.NewRequest("GET", "/admin/analytics/tweets?from=2024-01-01T00:00:00Z&to=2024-01-02T00:00:00Z", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status %v without the admin token, got %v", http.StatusForbidden, rr.Code)
+	}
+}
+
+func TestExportTweets(t *testing.T) {
+	// Setup
+	router, userRepo, tweetRepo := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	start := time.Now().Add(-time.Hour)
+	for i, content := range []string{"First", "Second, with a comma", "Third \"quoted\"\nand multiline"} {
+		tweet, _ := entity.NewTweetAt(fmt.Sprintf("tweet%d", i+1), "alice", content, start.Add(time.Duration(i)*time.Minute))
+		tweetRepo.Save(tweet)
+	}
+	other, _ := entity.NewTweet("other", "bob", "Not alice's")
+	tweetRepo.Save(other)
+
+	export := func(format string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/users/tweets/export?format="+format, nil)
+		req.Header.Set("User-ID", "alice")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// JSON export holds every tweet of the caller, oldest first
+	rr := export("json")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", got)
+	}
+	if got := rr.Header().Get("Content-Disposition"); got != "attachment; filename=tweets-alice.json" {
+		t.Errorf("Expected an attachment disposition, got %q", got)
+	}
+	var tweets []handler.TweetResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &tweets); err != nil {
+		t.Fatalf("Failed to parse JSON export: %v", err)
+	}
+	if len(tweets) != 3 || tweets[0].ID != "tweet1" || tweets[2].ID != "tweet3" || tweets[1].Content != "Second, with a comma" {
+		t.Errorf("Expected alice's three tweets oldest first, got %+v", tweets)
+	}
+
+	// CSV export has a header row and one record per tweet
+	rr = export("csv")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Errorf("Expected CSV content type, got %q", got)
+	}
+	if got := rr.Header().Get("Content-Disposition"); got != "attachment; filename=tweets-alice.csv" {
+		t.Errorf("Expected an attachment disposition, got %q", got)
+	}
+	records, err := csv.NewReader(rr.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV export: %v", err)
+	}
+	if len(records) != 4 || records[0][0] != "id" {
+		t.Fatalf("Expected a header and three records, got %v", records)
+	}
+	if records[3][0] != "tweet3" || records[3][2] != "Third \"quoted\"\nand multiline" {
+		t.Errorf("Expected the quoted multiline tweet to round-trip, got %v", records[3])
+	}
+
+	// Unknown formats are rejected
+	if rr := export("xml"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %v for an unknown format, got %v", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestImportTweets(t *testing.T) {
+	// Setup
+	router, userRepo, _ := setupTestAPI(t)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+
+	// Read the timeline first so it is cached
+	if ids := timelineIDs(t, router, "alice", ""); len(ids) != 0 {
+		t.Fatalf("Expected an empty timeline, got %v", ids)
+	}
+
+	// Import a mix of valid and invalid entries
+	createdAt := time.Now().Add(-24 * time.Hour).UTC().Truncate(time.Second)
+	body := fmt.Sprintf(`[
+		{"content": "Imported tweet", "created_at": %q},
+		{"content": "", "created_at": %q},
+		{"content": "Bad time", "created_at": "yesterday"},
+		{"content": %q, "created_at": %q}
+	]`, createdAt.Format(time.RFC3339), createdAt.Format(time.RFC3339), strings.Repeat("a", entity.MaxTweetLength+1), createdAt.Format(time.RFC3339))
+	req, _ := http.NewRequest("POST", "/users/tweets/import", strings.NewReader(body))
+	req.Header.Set("User-ID", "alice")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	// Check response
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var response handler.ImportTweetsResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	if response.Imported != 1 || response.Failed != 3 || len(response.Results) != 4 {
+		t.Fatalf("Expected 1 imported and 3 failed rows, got %+v", response)
+	}
+	imported := response.Results[0].Tweet
+	if imported == nil || imported.CreatedAt != createdAt.Format(time.RFC3339) {
+		t.Errorf("Expected the first row imported with its original time, got %+v", response.Results[0])
+	}
+	for _, result := range response.Results[1:] {
+		if result.Tweet != nil || result.Error == "" {
+			t.Errorf("Expected row %d to fail with an error, got %+v", result.Index, result)
+		}
+	}
+
+	// The cached timeline was invalidated
+	if ids := timelineIDs(t, router, "alice", ""); len(ids) != 1 || ids[0] != imported.ID {
+		t.Errorf("Expected the imported tweet in the timeline, got %v", ids)
+	}
+
+	// A body that is not an array is rejected
+	req, _ = http.NewRequest("POST", "/users/tweets/import", strings.NewReader(`{"content": "x"}`))
+	req.Header.Set("User-ID", "alice")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %v for a non-array body, got %v", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestPprofRoutes(t *testing.T) {
+	// Disabled by default: the routes are not registered
+	router, _, _ := setupTestAPI(t)
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/cmdline"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status %v for %s when disabled, got %v", http.StatusNotFound, path, rr.Code)
+		}
+	}
+
+	// Enabled: the index and the profiles are served
+	router, _, _ = setupTestAPI(t)
+	handler.NewPprofHandler().RegisterRoutes()
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/cmdline", "/debug/pprof/goroutine?debug=1"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected status %v for %s when enabled, got %v", http.StatusOK, path, rr.Code)
+		}
+	}
+}
+
+func TestUserProfileFollowCounts(t *testing.T) {
+	// Setup
+	router, userRepo, _ := setupTestAPI(t)
+	for _, id := range []string{"alice", "bob", "carol"} {
+		userRepo.Save(entity.NewUser(id, id))
+	}
+	userRepo.Follow("bob", "alice")
+	userRepo.Follow("carol", "alice")
+	userRepo.Follow("alice", "carol")
+
+	// Get alice's profile
+	req, _ := http.NewRequest("GET", "/users/alice", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	// Check response
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var profile handler.ProfileResponse
+	json.Unmarshal(rr.Body.Bytes(), &profile)
+	if profile.ID != "alice" || profile.FollowersCount != 2 || profile.FollowingCount != 1 {
+		t.Errorf("Expected alice with 2 followers and 1 followed user, got %+v", profile)
+	}
+}
+
+func TestCreateUserWithReservedUsername(t *testing.T) {
+	// Setup
+	router, _, _ := setupTestAPI(t)
+
+	for username, want := range map[string]int{"Support": http.StatusConflict, "root": http.StatusConflict, "supporter": http.StatusCreated} {
+		req, _ := http.NewRequest("POST", "/users", strings.NewReader(fmt.Sprintf(`{"username": %q}`, username)))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != want {
+			t.Errorf("Expected status %v creating %q, got %v", want, username, rr.Code)
+		}
+	}
+}
+
+func TestUsernamesIgnoreCase(t *testing.T) {
+	// Setup
+	router, _, _ := setupTestAPI(t)
+	createUser := func(username string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/users", strings.NewReader(fmt.Sprintf(`{"username": %q}`, username)))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Alice is created, alice is taken
+	if rr := createUser("Alice"); rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status %v creating Alice, got %v", http.StatusCreated, rr.Code)
+	}
+	if rr := createUser("alice"); rr.Code != http.StatusConflict {
+		t.Errorf("Expected status %v creating alice, got %v", http.StatusConflict, rr.Code)
+	}
+
+	// Both casings find Alice, with her original casing
+	for _, username := range []string{"alice", "ALICE"} {
+		req, _ := http.NewRequest("GET", "/users/lookup?username="+username, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		var profile handler.ProfileResponse
+		json.Unmarshal(rr.Body.Bytes(), &profile)
+		if rr.Code != http.StatusOK || profile.Username != "Alice" {
+			t.Errorf("Expected to find Alice by %q, got %v and %+v", username, rr.Code, profile)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+//go:build integration
+
+// Package storage runs the DynamoDB repositories and the Redis cache end to end
+// against DynamoDB Local and Redis, started with docker/docker-compose.test.yml:
+//
+//	docker compose -f docker/docker-compose.test.yml up -d
+//	DYNAMODB_ENDPOINT=http://localhost:8000 REDIS_ENDPOINT=localhost:6379 go test -tags integration ./integration/storage/...
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/develpudu/go-challenge/domain/entity"
+	"github.com/develpudu/go-challenge/domain/repository"
+	"github.com/develpudu/go-challenge/infrastructure/cache"
+	dynamodbRepo "github.com/develpudu/go-challenge/infrastructure/repository/dynamodb"
+)
+
+// Names of the tables created for one test, unique per run
+type tables struct {
+	users, follows, tweets string
+}
+
+// Connects to DynamoDB Local and Redis, skipping the test when either is not configured
+func setup(t *testing.T) (aws.Config, *cache.RedisTimelineCache, tables) {
+	if testing.Short() {
+		t.Skip("Skipping storage integration test in short mode")
+	}
+	if os.Getenv(dynamodbRepo.EndpointEnv) == "" || os.Getenv("REDIS_ENDPOINT") == "" {
+		t.Skip("DYNAMODB_ENDPOINT and REDIS_ENDPOINT must be set")
+	}
+
+	// DynamoDB Local accepts any credentials
+	cfg := aws.Config{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "local", SecretAccessKey: "local"}, nil
+		}),
+	}
+	names := createTables(t, dynamodbRepo.NewClient(cfg))
+
+	timelineCache, err := cache.NewRedisTimelineCache(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	t.Cleanup(func() { timelineCache.Close() })
+	return cfg, timelineCache, names
+}
+
+// Creates the users, follows and tweets tables with the keys and indexes of infrastructure/aws/template.yaml
+func createTables(t *testing.T, client *dynamodb.Client) tables {
+	suffix := time.Now().UnixNano()
+	names := tables{
+		users:   fmt.Sprintf("users-%d", suffix),
+		follows: fmt.Sprintf("follows-%d", suffix),
+		tweets:  fmt.Sprintf("tweets-%d", suffix),
+	}
+
+	stringAttr := func(name string) types.AttributeDefinition {
+		return types.AttributeDefinition{AttributeName: aws.String(name), AttributeType: types.ScalarAttributeTypeS}
+	}
+	key := func(name string, keyType types.KeyType) types.KeySchemaElement {
+		return types.KeySchemaElement{AttributeName: aws.String(name), KeyType: keyType}
+	}
+	index := func(name, hashKey, rangeKey string) types.GlobalSecondaryIndex {
+		return types.GlobalSecondaryIndex{
+			IndexName:  aws.String(name),
+			KeySchema:  []types.KeySchemaElement{key(hashKey, types.KeyTypeHash), key(rangeKey, types.KeyTypeRange)},
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		}
+	}
+
+	inputs := []*dynamodb.CreateTableInput{
+		{
+			TableName:            aws.String(names.users),
+			AttributeDefinitions: []types.AttributeDefinition{stringAttr("ID")},
+			KeySchema:            []types.KeySchemaElement{key("ID", types.KeyTypeHash)},
+		},
+		{
+			TableName:            aws.String(names.follows),
+			AttributeDefinitions: []types.AttributeDefinition{stringAttr("FollowedID"), stringAttr("FollowerID")},
+			KeySchema:            []types.KeySchemaElement{key("FollowedID", types.KeyTypeHash), key("FollowerID", types.KeyTypeRange)},
+		},
+		{
+			TableName: aws.String(names.tweets),
+			AttributeDefinitions: []types.AttributeDefinition{
+				stringAttr("ID"), stringAttr("UserID"), stringAttr("ConversationID"), stringAttr("CreatedAt"), stringAttr("CreatedDay"),
+				{AttributeName: aws.String("CreatedAtEpoch"), AttributeType: types.ScalarAttributeTypeN},
+			},
+			KeySchema: []types.KeySchemaElement{key("ID", types.KeyTypeHash)},
+			GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+				index("UserIDIndex", "UserID", "ID"),
+				index("ConversationIDIndex", "ConversationID", "CreatedAt"),
+				index("CreatedDayIndex", "CreatedDay", "CreatedAtEpoch"),
+				index("UserCreatedIndex", "UserID", "CreatedAtEpoch"),
+			},
+		},
+	}
+
+	ctx := context.Background()
+	for _, input := range inputs {
+		input.BillingMode = types.BillingModePayPerRequest
+		if _, err := client.CreateTable(ctx, input); err != nil {
+			t.Fatalf("Failed to create table %s: %v", *input.TableName, err)
+		}
+		tableName := input.TableName
+		t.Cleanup(func() {
+			client.DeleteTable(context.Background(), &dynamodb.DeleteTableInput{TableName: tableName})
+		})
+	}
+	return names
+}
+
+func TestUserRepositorySaveFindAndFollow(t *testing.T) {
+	cfg, _, names := setup(t)
+	repo := dynamodbRepo.NewDynamoDBUserRepository(cfg, names.users, names.follows)
+
+	// Save two users and a follow between them
+	for _, user := range []*entity.User{entity.NewUser("alice", "alice"), entity.NewUser("bob", "bob")} {
+		if err := repo.Save(user); err != nil {
+			t.Fatalf("Failed to save user %s: %v", user.ID, err)
+		}
+	}
+	if err := repo.Follow("bob", "alice"); err != nil {
+		t.Fatalf("Failed to follow: %v", err)
+	}
+
+	// Read them back
+	bob, err := repo.FindByID("bob")
+	if err != nil || bob == nil || bob.Username != "bob" {
+		t.Fatalf("Expected to find bob, got %v and %v", bob, err)
+	}
+	if !bob.IsFollowing("alice") {
+		t.Error("Expected bob to follow alice")
+	}
+	followers, err := repo.FindFollowersPage("alice", "", 10)
+	if err != nil || len(followers) != 1 || followers[0].ID != "bob" {
+		t.Errorf("Expected bob as alice's only follower, got %v and %v", followers, err)
+	}
+}
+
+func TestTweetRepositoryTimelineAndCache(t *testing.T) {
+	cfg, timelineCache, names := setup(t)
+	ctx := context.Background()
+	userRepo := dynamodbRepo.NewDynamoDBUserRepository(cfg, names.users, names.follows)
+	tweetRepo := dynamodbRepo.NewDynamoDBTweetRepository(cfg, names.tweets, userRepo, timelineCache)
+	userRepo.Save(entity.NewUser("alice", "alice"))
+	userRepo.Save(entity.NewUser("bob", "bob"))
+	userRepo.Follow("bob", "alice")
+	for _, key := range repository.TimelineCacheKeys("bob") {
+		timelineCache.InvalidateTimeline(ctx, key)
+	}
+
+	// Tweets are read back through the UserIDIndex, ConversationIDIndex, CreatedDayIndex and UserCreatedIndex
+	start := time.Now().UTC().Truncate(time.Second)
+	root, _ := entity.NewTweetAt("tweet1", "alice", "Hello", start)
+	reply, _ := entity.NewReplyAt("tweet2", "bob", "Hi alice", root, start.Add(time.Second))
+	for _, tweet := range []*entity.Tweet{root, reply} {
+		if err := tweetRepo.Save(tweet); err != nil {
+			t.Fatalf("Failed to save tweet %s: %v", tweet.ID, err)
+		}
+	}
+	found, err := tweetRepo.FindByID("tweet2")
+	if err != nil || found == nil || found.InReplyToID != "tweet1" || !found.CreatedAt.Equal(reply.CreatedAt) {
+		t.Fatalf("Expected the reply to round-trip, got %+v and %v", found, err)
+	}
+	conversation, err := tweetRepo.FindByConversationID("tweet1")
+	if err != nil || len(conversation) != 2 {
+		t.Errorf("Expected both tweets in the conversation, got %v and %v", conversation, err)
+	}
+
+	// Both tweets are counted through the CreatedDayIndex, in the buckets of their creation second
+	histogram := repository.NewTweetHistogram(start, start.Add(2*time.Second), time.Second)
+	if err := tweetRepo.CountCreatedPerBucket(histogram); err != nil || fmt.Sprint(histogram.Counts) != "[1 1]" {
+		t.Errorf("Expected one tweet per second, got %v and %v", histogram.Counts, err)
+	}
+
+	// Only the tweet created in the range is read through the UserCreatedIndex
+	if times, err := tweetRepo.FindCreatedAtByUserID("alice", start, start); err != nil || len(times) != 1 || !times[0].Equal(start) {
+		t.Errorf("Expected alice's tweet created at %v, got %v and %v", start, times, err)
+	}
+
+	// The first timeline read queries DynamoDB and fills the cache
+	opts := repository.TimelineOptions{IncludeSelf: true, IncludeReplies: true}
+	timeline, err := tweetRepo.GetTimeline(ctx, "bob", opts)
+	if err != nil || len(timeline) != 2 || timeline[0].ID != "tweet2" {
+		t.Fatalf("Expected bob's timeline newest first, got %v and %v", timeline, err)
+	}
+	cached, hit, err := timelineCache.GetTimeline(ctx, opts.CacheKey("bob"))
+	if err != nil || !hit || len(cached) != 2 {
+		t.Fatalf("Expected the timeline to be cached, got hit=%v %v and %v", hit, cached, err)
+	}
+
+	// A new tweet by bob invalidates his cached timeline
+	newer, _ := entity.NewTweetAt("tweet3", "bob", "Another one", start.Add(2*time.Second))
+	if err := tweetRepo.Save(newer); err != nil {
+		t.Fatalf("Failed to save tweet: %v", err)
+	}
+	if _, hit, _ := timelineCache.GetTimeline(ctx, opts.CacheKey("bob")); hit {
+		t.Error("Expected saving a tweet to invalidate the author's cached timeline")
+	}
+	timeline, err = tweetRepo.GetTimeline(ctx, "bob", opts)
+	if err != nil || len(timeline) != 3 || timeline[0].ID != "tweet3" {
+		t.Errorf("Expected the new tweet first in the timeline, got %v and %v", timeline, err)
+	}
+}
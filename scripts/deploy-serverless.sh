@@ -45,7 +45,10 @@ echo "------------------------------"
 # 1. Build the Go Binary for Lambda (Linux AMD64)
 # Assumes script is run from the project root directory
 echo "\n---> Building Go binary..."
-GOOS=linux GOARCH=amd64 CGO_ENABLED=0 go build -o main ./cmd/main.go
+# Embed the commit and build time reported by GET /version
+GIT_COMMIT=$(git rev-parse HEAD 2>/dev/null || echo unknown)
+BUILD_TIME=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+GOOS=linux GOARCH=amd64 CGO_ENABLED=0 go build -ldflags "-X main.gitCommit=${GIT_COMMIT} -X main.buildTime=${BUILD_TIME}" -o main ./cmd/main.go
 echo "Build complete."
 
 # 2. Build the SAM deployment package
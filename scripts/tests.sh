@@ -10,7 +10,7 @@ BLUE='\033[0;34m'
 NC='\033[0m' # No Color
 
 echo -e "${BLUE}Ejecutando tests unitarios de la capa de dominio...${NC}"
-go test -v ./domain/entity/...
+go test -v -race ./domain/entity/...
 
 echo -e "\n${BLUE}Ejecutando tests unitarios de la capa de aplicación...${NC}"
 go test -v ./application/usecase/...